@@ -3,12 +3,16 @@ package main
 import (
 	"os"
 
+	"github.com/lydakis/jul/cli/internal/agent"
 	"github.com/lydakis/jul/cli/internal/cli"
 )
 
 var version = "0.0.1"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == agent.SandboxHelperArg {
+		os.Exit(agent.RunSandboxHelper(os.Args[2:]))
+	}
 	app := &cli.App{
 		Commands: cli.Commands(version),
 		Version:  version,
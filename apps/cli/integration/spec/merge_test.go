@@ -73,7 +73,7 @@ func TestIT_MERGE_001(t *testing.T) {
 
 	mergeOut, err := runCmdAllowFailure(t, repoB, deviceB.Env, julPath, "merge", "--apply", "--json")
 	if err != nil {
-		worktree := filepath.Join(repoB, ".jul", "agent-workspace", "worktree")
+		worktree := filepath.Join(repoB, ".jul", "agent-workspace", "merge")
 		if werr := os.WriteFile(filepath.Join(worktree, "conflict.txt"), []byte("manual resolution\n"), 0o644); werr != nil {
 			t.Fatalf("failed to write manual resolution: %v", werr)
 		}
@@ -122,7 +122,7 @@ func TestIT_MERGE_007(t *testing.T) {
 		t.Fatalf("failed to decode pending suggestions: %v", err)
 	}
 	if len(pending.Suggestions) == 0 {
-		worktree := filepath.Join(repo, ".jul", "agent-workspace", "worktree")
+		worktree := filepath.Join(repo, ".jul", "agent-workspace", "merge")
 		if err := os.WriteFile(filepath.Join(worktree, "conflict.txt"), []byte("manual resolution\n"), 0o644); err != nil {
 			t.Fatalf("failed to write manual resolution: %v", err)
 		}
@@ -143,7 +143,7 @@ func TestIT_MERGE_007(t *testing.T) {
 	}
 
 	// Manually resolve in the agent worktree.
-	worktree := filepath.Join(repo, ".jul", "agent-workspace", "worktree")
+	worktree := filepath.Join(repo, ".jul", "agent-workspace", "merge")
 	if err := os.WriteFile(filepath.Join(worktree, "conflict.txt"), []byte("manual resolution\n"), 0o644); err != nil {
 		t.Fatalf("failed to write manual resolution: %v", err)
 	}
@@ -191,7 +191,7 @@ func TestIT_MERGE_008(t *testing.T) {
 	_, _, baseSHA := setupMergeConflictWithContents(t, repo, device, julPath, "base\n", "ours-one\n", "theirs-one\n")
 	_, _ = runCmdInput(t, repo, device.Env, "n\n", julPath, "merge", "--json")
 
-	worktree := filepath.Join(repo, ".jul", "agent-workspace", "worktree")
+	worktree := filepath.Join(repo, ".jul", "agent-workspace", "merge")
 	if err := os.WriteFile(filepath.Join(worktree, "conflict.txt"), []byte("stale resolution\n"), 0o644); err != nil {
 		t.Fatalf("failed to write stale resolution: %v", err)
 	}
@@ -109,7 +109,7 @@ func ensurePendingSuggestion(t *testing.T, repo string, env map[string]string, j
 		return view.Suggestions[0]
 	}
 
-	worktree := filepath.Join(repo, ".jul", "agent-workspace", "worktree")
+	worktree := filepath.Join(repo, ".jul", "agent-workspace", "merge")
 	if err := os.WriteFile(filepath.Join(worktree, "conflict.txt"), []byte("manual resolution\n"), 0o644); err != nil {
 		t.Fatalf("failed to write manual resolution: %v", err)
 	}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -39,3 +40,160 @@ func TestWorkspaceFlows(t *testing.T) {
 		t.Fatalf("expected switch output, got %s", out)
 	}
 }
+
+func TestWorkspaceRestackStack(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "demo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatalf("failed to create repo dir: %v", err)
+	}
+
+	julPath := buildCLI(t)
+	env := map[string]string{
+		"HOME":        filepath.Join(t.TempDir(), "home"),
+		"JUL_NO_SYNC": "1",
+	}
+
+	runCmd(t, repo, nil, "git", "init", "-b", "main")
+	runCmd(t, repo, nil, "git", "config", "user.name", "Test User")
+	runCmd(t, repo, nil, "git", "config", "user.email", "test@example.com")
+	writeFile(t, repo, "README.md", "hello\n")
+	runCmd(t, repo, nil, "git", "add", "README.md")
+	runCmd(t, repo, nil, "git", "commit", "-m", "initial")
+	runCmd(t, repo, env, julPath, "init", "demo")
+
+	writeFile(t, repo, "base.txt", "base\n")
+	runCmd(t, repo, env, julPath, "checkpoint", "-m", "feat: base", "--no-ci", "--no-review")
+	runCmd(t, repo, env, julPath, "ws", "stack", "middle")
+	writeFile(t, repo, "middle.txt", "middle\n")
+	runCmd(t, repo, env, julPath, "checkpoint", "-m", "feat: middle", "--no-ci", "--no-review")
+	runCmd(t, repo, env, julPath, "ws", "stack", "top")
+	writeFile(t, repo, "top.txt", "top\n")
+	runCmd(t, repo, env, julPath, "checkpoint", "-m", "feat: top", "--no-ci", "--no-review")
+
+	runCmd(t, repo, env, julPath, "ws", "switch", "@")
+	writeFile(t, repo, "base.txt", "base\nmore\n")
+	runCmd(t, repo, env, julPath, "checkpoint", "-m", "feat: base more", "--no-ci", "--no-review")
+
+	out := runCmd(t, repo, env, julPath, "ws", "restack", "--stack", "--json")
+	var res struct {
+		Status string `json:"status"`
+		Layers []struct {
+			Workspace      string   `json:"workspace_id"`
+			Status         string   `json:"status"`
+			BaseTip        string   `json:"base_tip"`
+			OldCheckpoints []string `json:"old_checkpoints"`
+			NewCheckpoints []string `json:"new_checkpoints"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("failed to decode restack output: %v (%s)", err, out)
+	}
+	if res.Status != "ok" || len(res.Layers) != 3 {
+		t.Fatalf("expected 3 layers, got %s", out)
+	}
+	if res.Layers[0].Status != "up_to_date" {
+		t.Fatalf("expected base layer up to date, got %+v", res.Layers[0])
+	}
+	for i, name := range []string{"middle", "top"} {
+		layer := res.Layers[i+1]
+		if !strings.HasSuffix(layer.Workspace, "/"+name) || layer.Status != "restacked" {
+			t.Fatalf("expected %s restacked in order, got %+v", name, layer)
+		}
+		if len(layer.OldCheckpoints) != 1 || len(layer.NewCheckpoints) != 1 || layer.OldCheckpoints[0] == layer.NewCheckpoints[0] {
+			t.Fatalf("expected old→new checkpoints for %s, got %+v", name, layer)
+		}
+	}
+	if res.Layers[2].BaseTip != res.Layers[1].NewCheckpoints[0] {
+		t.Fatalf("expected top to be restacked onto middle's new checkpoint, got %+v", res.Layers)
+	}
+
+	head := strings.TrimSpace(runCmd(t, repo, nil, "git", "symbolic-ref", "HEAD"))
+	if head != "refs/heads/jul/@" {
+		t.Fatalf("expected HEAD to stay on the current workspace, got %s", head)
+	}
+
+	again := runCmd(t, repo, env, julPath, "ws", "restack", "--stack")
+	if strings.Count(again, "up to date") != 3 {
+		t.Fatalf("expected every layer up to date on rerun, got %s", again)
+	}
+}
+
+func TestWorkspaceRestackContinueAndAbort(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "demo")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatalf("failed to create repo dir: %v", err)
+	}
+
+	julPath := buildCLI(t)
+	env := map[string]string{
+		"HOME":        filepath.Join(t.TempDir(), "home"),
+		"JUL_NO_SYNC": "1",
+	}
+
+	runCmd(t, repo, nil, "git", "init", "-b", "main")
+	runCmd(t, repo, nil, "git", "config", "user.name", "Test User")
+	runCmd(t, repo, nil, "git", "config", "user.email", "test@example.com")
+	writeFile(t, repo, "README.md", "hello\n")
+	runCmd(t, repo, nil, "git", "add", "README.md")
+	runCmd(t, repo, nil, "git", "commit", "-m", "initial")
+	runCmd(t, repo, env, julPath, "init", "demo")
+
+	writeFile(t, repo, "base.txt", "base\n")
+	runCmd(t, repo, env, julPath, "checkpoint", "-m", "feat: base", "--no-ci", "--no-review")
+	runCmd(t, repo, env, julPath, "ws", "stack", "feature")
+	writeFile(t, repo, "base.txt", "feature\n")
+	runCmd(t, repo, env, julPath, "checkpoint", "-m", "feat: feature", "--no-ci", "--no-review")
+	writeFile(t, repo, "other.txt", "other\n")
+	runCmd(t, repo, env, julPath, "checkpoint", "-m", "feat: other", "--no-ci", "--no-review")
+	runCmd(t, repo, env, julPath, "ws", "switch", "@")
+	writeFile(t, repo, "base.txt", "base two\n")
+	runCmd(t, repo, env, julPath, "checkpoint", "-m", "feat: base two", "--no-ci", "--no-review")
+	runCmd(t, repo, env, julPath, "ws", "switch", "feature")
+
+	refs := func() string {
+		return runCmd(t, repo, nil, "git", "for-each-ref", "refs/jul/workspaces", "refs/jul/changes", "refs/heads")
+	}
+	before := refs()
+	statePath := filepath.Join(repo, ".jul", "restack", "state.json")
+
+	out, err := runCmdAllowFailure(t, repo, env, julPath, "ws", "restack", "--json")
+	if err == nil || !strings.Contains(out, "restack_conflict") || !strings.Contains(out, "jul ws restack --continue") {
+		t.Fatalf("expected restack conflict with continue action, got %v: %s", err, out)
+	}
+	if _, err := os.Stat(statePath); err != nil {
+		t.Fatalf("expected restack state to be saved: %v", err)
+	}
+	if out, err := runCmdAllowFailure(t, repo, env, julPath, "ws", "restack"); err == nil || !strings.Contains(out, "in progress") {
+		t.Fatalf("expected a second restack to be refused, got %s", out)
+	}
+
+	runCmd(t, repo, env, julPath, "ws", "restack", "--abort")
+	if after := refs(); after != before {
+		t.Fatalf("expected abort to restore refs\nbefore:\n%s\nafter:\n%s", before, after)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Fatalf("expected restack state to be cleared, got %v", err)
+	}
+
+	_, _ = runCmdAllowFailure(t, repo, env, julPath, "ws", "restack")
+	worktree := filepath.Join(repo, ".jul", "agent-workspace", "merge")
+	if out, err := runCmdAllowFailure(t, repo, env, julPath, "ws", "restack", "--continue"); err == nil {
+		t.Fatalf("expected continue to refuse unresolved conflict markers, got %s", out)
+	}
+	writeFile(t, worktree, "base.txt", "resolved\n")
+	runCmd(t, repo, env, julPath, "ws", "restack", "--continue")
+
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Fatalf("expected restack state to be cleared after continue, got %v", err)
+	}
+	if got := readFile(t, repo, "base.txt"); got != "resolved\n" {
+		t.Fatalf("expected resolved file in working tree, got %q", got)
+	}
+	if got := readFile(t, repo, "other.txt"); got != "other\n" {
+		t.Fatalf("expected remaining checkpoint replayed, got %q", got)
+	}
+	log := runCmd(t, repo, nil, "git", "log", "--format=%s", "-3", "refs/heads/jul/feature")
+	if !strings.HasPrefix(log, "feat: other\nfeat: feature\nfeat: base two") {
+		t.Fatalf("expected feature checkpoints on the new base, got %s", log)
+	}
+}
@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lydakis/jul/cli/internal/config"
+)
+
+// MergeWorktree is the agent worktree reserved for merges and restacks.
+// It lives outside the pool because it keeps conflicts between commands.
+const MergeWorktree = "merge"
+
+var ErrPoolExhausted = errors.New("all agent worktrees are in use")
+
+var ErrMergeWorktreeBusy = errors.New("the merge worktree is in use")
+
+const (
+	poolLockTimeout = 5 * time.Second
+	poolLockStale   = 30 * time.Second
+)
+
+// Lease holds one pool worktree for the lifetime of a run.
+type Lease struct {
+	Worktree string
+	path     string
+}
+
+type leaseRecord struct {
+	PID       int       `json:"pid"`
+	Action    string    `json:"action,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// AcquireWorktree leases a free pool worktree reset to baseSHA. Leases whose
+// process has exited are reclaimed, so a crashed run never pins a worktree.
+func AcquireWorktree(repoRoot, baseSHA, action string) (*Lease, error) {
+	leaseDir := filepath.Join(repoRoot, ".jul", "agent-workspace", "leases")
+	if err := os.MkdirAll(leaseDir, 0o755); err != nil {
+		return nil, err
+	}
+	size := config.AgentPoolSize()
+	for i := 0; i < size; i++ {
+		name := poolMemberName(i)
+		path := filepath.Join(leaseDir, name+".json")
+		ok, err := claimLease(leaseDir, path, action)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if i == 0 && operationPending(WorktreePath(repoRoot, name)) {
+			// A merge or restack left here by an older jul still needs it.
+			_ = os.Remove(path)
+			continue
+		}
+		worktree, err := EnsureWorktree(repoRoot, baseSHA, WorktreeOptions{Name: name})
+		if err != nil {
+			_ = os.Remove(path)
+			if errors.Is(err, ErrMergeInProgress) {
+				continue
+			}
+			return nil, err
+		}
+		return &Lease{Worktree: worktree, path: path}, nil
+	}
+	return nil, fmt.Errorf("%w (%d); raise agent.pool_size or wait for running reviews and fixes", ErrPoolExhausted, size)
+}
+
+// AcquireMergeWorktree leases MergeWorktree for one merge, restack or draft
+// adopt. The worktree is not reset: callers decide whether to resume what a
+// previous command left there. A merge left in the legacy shared worktree is
+// moved here first.
+func AcquireMergeWorktree(repoRoot, action string) (*Lease, error) {
+	leaseDir := filepath.Join(repoRoot, ".jul", "agent-workspace", "leases")
+	if err := os.MkdirAll(leaseDir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(leaseDir, MergeWorktree+".json")
+	ok, err := claimLease(leaseDir, path, action)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w; wait for the running merge or restack to finish", ErrMergeWorktreeBusy)
+	}
+	lease := &Lease{Worktree: WorktreePath(repoRoot, MergeWorktree), path: path}
+	if err := adoptLegacyMerge(repoRoot, leaseDir); err != nil {
+		lease.Release()
+		return nil, err
+	}
+	return lease, nil
+}
+
+// adoptLegacyMerge moves the legacy shared worktree to MergeWorktree when it
+// holds a merge in progress and no merge worktree exists yet.
+func adoptLegacyMerge(repoRoot, leaseDir string) error {
+	legacy := WorktreePath(repoRoot, poolMemberName(0))
+	if _, err := os.Stat(WorktreePath(repoRoot, MergeWorktree)); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(legacy); err != nil || !MergeInProgress(legacy) {
+		return nil
+	}
+	path := filepath.Join(leaseDir, poolMemberName(0)+".json")
+	ok, err := claimLease(leaseDir, path, "adopt_merge")
+	if err != nil || !ok {
+		return err
+	}
+	defer os.Remove(path)
+	if err := runGit(repoRoot, "worktree", "move", legacy, WorktreePath(repoRoot, MergeWorktree)); err != nil {
+		return fmt.Errorf("failed to move the merge in progress from %s: %w", legacy, err)
+	}
+	return nil
+}
+
+// operationPending reports a merge or cherry-pick in progress in worktree.
+func operationPending(worktree string) bool {
+	if _, err := os.Stat(worktree); err != nil {
+		return false
+	}
+	for _, head := range []string{"MERGE_HEAD", "CHERRY_PICK_HEAD"} {
+		if err := runGit(worktree, "rev-parse", "-q", "--verify", head); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Release returns the worktree to the pool.
+func (l *Lease) Release() {
+	if l == nil || l.path == "" {
+		return
+	}
+	_ = os.Remove(l.path)
+	l.path = ""
+}
+
+// WorktreePath is where the named agent worktree lives.
+func WorktreePath(repoRoot, name string) string {
+	return filepath.Join(repoRoot, ".jul", "agent-workspace", name)
+}
+
+func poolMemberName(i int) string {
+	if i == 0 {
+		return "worktree"
+	}
+	return "worktree-" + strconv.Itoa(i+1)
+}
+
+// claimLease writes a lease for this process at path unless a live process
+// already holds it. The pool lock keeps two runs from claiming one member.
+func claimLease(leaseDir, path, action string) (bool, error) {
+	unlock, err := lockPool(filepath.Join(leaseDir, ".lock"))
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if data, err := os.ReadFile(path); err == nil {
+		var held leaseRecord
+		if json.Unmarshal(data, &held) == nil && processAlive(held.PID) {
+			return false, nil
+		}
+	}
+	data, err := json.Marshal(leaseRecord{PID: os.Getpid(), Action: strings.TrimSpace(action), StartedAt: time.Now().UTC()})
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// lockPool takes an exclusive lock file, breaking locks left by a crashed
+// holder once they are older than poolLockStale.
+func lockPool(path string) (func(), error) {
+	deadline := time.Now().Add(poolLockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_ = file.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > poolLockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for agent pool lock %s", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
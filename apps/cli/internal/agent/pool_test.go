@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquireWorktreeLeasesAndReclaims(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "--allow-empty", "-m", "base"},
+	} {
+		if err := runGit(repo, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	baseSHA, err := gitOutput(repo, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("base sha: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, ".jul"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".jul", "config.toml"), []byte("[agent]\npool_size = 2\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	first, err := AcquireWorktree(repo, baseSHA, "review")
+	if err != nil {
+		t.Fatalf("first lease: %v", err)
+	}
+	second, err := AcquireWorktree(repo, baseSHA, "fix_failing_test")
+	if err != nil {
+		t.Fatalf("second lease: %v", err)
+	}
+	if first.Worktree == second.Worktree {
+		t.Fatalf("expected distinct worktrees, got %s twice", first.Worktree)
+	}
+	if _, err := AcquireWorktree(repo, baseSHA, "review"); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected exhausted pool, got %v", err)
+	}
+
+	// A lease left by a process that has exited is reclaimed.
+	dead := exec.Command("true")
+	if err := dead.Run(); err != nil {
+		t.Fatalf("run true: %v", err)
+	}
+	record := fmt.Sprintf(`{"pid":%d,"action":"review"}`, dead.Process.Pid)
+	if err := os.WriteFile(first.path, []byte(record), 0o644); err != nil {
+		t.Fatalf("write stale lease: %v", err)
+	}
+	third, err := AcquireWorktree(repo, baseSHA, "review")
+	if err != nil {
+		t.Fatalf("expected stale lease to be reclaimed: %v", err)
+	}
+	if third.Worktree != first.Worktree {
+		t.Fatalf("expected %s to be reused, got %s", first.Worktree, third.Worktree)
+	}
+
+	second.Release()
+	fourth, err := AcquireWorktree(repo, baseSHA, "review")
+	if err != nil {
+		t.Fatalf("expected released worktree to be leased again: %v", err)
+	}
+	if fourth.Worktree != second.Worktree {
+		t.Fatalf("expected %s to be reused, got %s", second.Worktree, fourth.Worktree)
+	}
+	third.Release()
+	fourth.Release()
+}
+
+func TestAcquireMergeWorktreeAdoptsLegacyMerge(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "--allow-empty", "-m", "base"},
+	} {
+		if err := runGit(repo, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	baseSHA, err := gitOutput(repo, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("base sha: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "f.txt"), []byte("ours\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := runGit(repo, "add", "f.txt"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if err := runGit(repo, "commit", "-m", "ours"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	oursSHA, _ := gitOutput(repo, "rev-parse", "HEAD")
+	if err := os.MkdirAll(filepath.Join(repo, ".jul"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".jul", "config.toml"), []byte("[agent]\npool_size = 1\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	// An older jul merged in the shared worktree and left a conflict there.
+	legacy := WorktreePath(repo, poolMemberName(0))
+	if err := runGit(repo, "worktree", "add", "--detach", legacy, baseSHA); err != nil {
+		t.Fatalf("add legacy worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "f.txt"), []byte("theirs\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := runGit(legacy, "add", "f.txt"); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if err := runGit(legacy, "commit", "-m", "theirs"); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+	_ = runGit(legacy, "merge", "--no-commit", "--no-ff", oursSHA)
+	if !MergeInProgress(legacy) {
+		t.Fatalf("expected a merge in progress in the legacy worktree")
+	}
+
+	if _, err := AcquireWorktree(repo, baseSHA, "review"); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected the legacy merge to be left alone, got %v", err)
+	}
+	if !MergeInProgress(legacy) {
+		t.Fatalf("expected the legacy merge to survive a review lease")
+	}
+
+	lease, err := AcquireMergeWorktree(repo, "merge")
+	if err != nil {
+		t.Fatalf("merge lease: %v", err)
+	}
+	if lease.Worktree != WorktreePath(repo, MergeWorktree) || !MergeInProgress(lease.Worktree) {
+		t.Fatalf("expected the legacy merge to move to %s", WorktreePath(repo, MergeWorktree))
+	}
+	if _, err := AcquireMergeWorktree(repo, "restack"); !errors.Is(err, ErrMergeWorktreeBusy) {
+		t.Fatalf("expected the merge worktree to be busy, got %v", err)
+	}
+	lease.Release()
+
+	review, err := AcquireWorktree(repo, baseSHA, "review")
+	if err != nil {
+		t.Fatalf("expected the shared worktree to be free again: %v", err)
+	}
+	review.Release()
+}
@@ -26,6 +26,9 @@ type Provider struct {
 	Bundled  bool
 	Headless string
 	Actions  map[string]string
+	Sandbox  Sandbox
+	// MaxIterations bounds edit-test cycles such as `jul fix`.
+	MaxIterations int
 }
 
 func ResolveProvider() (Provider, error) {
@@ -34,28 +37,51 @@ func ResolveProvider() (Provider, error) {
 		if mode == "" {
 			mode = "stdin"
 		}
+		sandbox := config.LoadAgentConfig().Sandbox
 		return Provider{
-			Name:     "custom",
-			Command:  cmd,
-			Protocol: "jul-agent-v1",
-			Mode:     mode,
-			Timeout:  5 * time.Minute,
+			Name:          "custom",
+			Command:       cmd,
+			Protocol:      "jul-agent-v1",
+			Mode:          mode,
+			Timeout:       5 * time.Minute,
+			Sandbox:       sandboxFromConfig(sandbox, nil),
+			MaxIterations: maxIterations(0),
 		}, nil
 	}
 
-	cfg := config.DefaultAgentProvider()
+	return providerFromConfig(config.DefaultAgentProvider())
+}
+
+// ResolveNamedProvider resolves a provider from agents.toml by name, falling
+// back to ResolveProvider when name is empty or JUL_AGENT_CMD is set.
+func ResolveNamedProvider(name string) (Provider, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.TrimSpace(os.Getenv("JUL_AGENT_CMD")) != "" {
+		return ResolveProvider()
+	}
+	cfg, ok := config.AgentProviderConfig(name)
+	if !ok {
+		return Provider{}, fmt.Errorf("agent provider %q not configured", name)
+	}
+	return providerFromConfig(cfg)
+}
+
+func providerFromConfig(cfg config.AgentProvider) (Provider, error) {
 	if cfg.Name == "" {
 		return Provider{}, ErrAgentNotConfigured
 	}
+	sandbox := config.LoadAgentConfig().Sandbox
 	provider := Provider{
-		Name:     cfg.Name,
-		Command:  cfg.Command,
-		Protocol: cfg.Protocol,
-		Mode:     cfg.Mode,
-		Timeout:  cfg.Timeout,
-		Bundled:  cfg.Bundled,
-		Headless: cfg.Headless,
-		Actions:  cfg.Actions,
+		Name:          cfg.Name,
+		Command:       cfg.Command,
+		Protocol:      cfg.Protocol,
+		Mode:          cfg.Mode,
+		Timeout:       cfg.Timeout,
+		Bundled:       cfg.Bundled,
+		Headless:      cfg.Headless,
+		Actions:       cfg.Actions,
+		Sandbox:       sandboxFromConfig(sandbox, cfg.EnableNetwork),
+		MaxIterations: maxIterations(cfg.MaxIterations),
 	}
 	if provider.Mode == "" {
 		provider.Mode = "stdin"
@@ -76,6 +102,14 @@ func ResolveProvider() (Provider, error) {
 	return provider, nil
 }
 
+// maxIterations returns the provider's max_iterations, falling back to 5.
+func maxIterations(provider int) int {
+	if provider > 0 {
+		return provider
+	}
+	return 5
+}
+
 func (p Provider) HeadlessFor(action string) string {
 	if p.Actions != nil {
 		if cmd := strings.TrimSpace(p.Actions[action]); cmd != "" {
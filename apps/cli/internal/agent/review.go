@@ -51,22 +51,25 @@ func RunReviewWithStream(ctx context.Context, provider Provider, req ReviewReque
 	case "file":
 		return runJSONAgentFile(ctx, provider, req, payload, stream)
 	default:
-		return runJSONAgentStdin(ctx, provider, payload, req.WorkspacePath, stream)
+		return runJSONAgentStdin(ctx, provider, req, payload, stream)
 	}
 }
 
-func runJSONAgentStdin(ctx context.Context, provider Provider, payload []byte, workdir string, stream io.Writer) (ReviewResponse, error) {
+func runJSONAgentStdin(ctx context.Context, provider Provider, req ReviewRequest, payload []byte, stream io.Writer) (ReviewResponse, error) {
 	cmdPath, cmdArgs, err := splitCommand(provider.Command)
 	if err != nil {
 		return ReviewResponse{}, err
 	}
-	cmd := exec.CommandContext(ctx, cmdPath, cmdArgs...)
-	cmd.Dir = workdir
-	cmd.Env = append(os.Environ(),
+	workdir := req.WorkspacePath
+	cmd, cleanup, err := provider.command(ctx, workdir, writablePaths(req), cmdPath, cmdArgs,
 		"JUL_AGENT_MODE=stdin",
 		"JUL_AGENT_ACTION=review",
 		"JUL_AGENT_WORKSPACE="+workdir,
 	)
+	if err != nil {
+		return ReviewResponse{}, err
+	}
+	defer cleanup()
 	cmd.Stdin = bytes.NewReader(payload)
 	output, err := runCommandWithStream(cmd, stream)
 	if err != nil {
@@ -101,15 +104,17 @@ func runJSONAgentFile(ctx context.Context, provider Provider, req ReviewRequest,
 	if err != nil {
 		return ReviewResponse{}, err
 	}
-	cmd := exec.CommandContext(ctx, cmdPath, cmdArgs...)
-	cmd.Dir = req.WorkspacePath
-	cmd.Env = append(os.Environ(),
+	cmd, cleanup, err := provider.command(ctx, req.WorkspacePath, append(writablePaths(req), outputFile.Name()), cmdPath, cmdArgs,
 		"JUL_AGENT_MODE=file",
 		"JUL_AGENT_ACTION=review",
 		"JUL_AGENT_WORKSPACE="+req.WorkspacePath,
 		"JUL_AGENT_INPUT="+input.Name(),
 		"JUL_AGENT_OUTPUT="+outputFile.Name(),
 	)
+	if err != nil {
+		return ReviewResponse{}, err
+	}
+	defer cleanup()
 	output, err := runCommandWithStream(cmd, stream)
 	if err != nil {
 		return ReviewResponse{}, fmt.Errorf("agent failed: %w (%s)", err, strings.TrimSpace(string(output)))
@@ -129,16 +134,18 @@ func runBundledOpenCode(ctx context.Context, provider Provider, req ReviewReques
 	}
 	defer os.Remove(tempFile)
 
-	prompt := buildReviewPrompt(req.Action, tempFile)
+	prompt := buildReviewPrompt(req.Action, tempFile, req.Context.Focus)
 	cmdPath := provider.Command
 	args := []string{"run", "--format", "json", "--file", tempFile, "--", prompt}
-	cmd := exec.CommandContext(ctx, cmdPath, args...)
-	cmd.Dir = req.WorkspacePath
-	cmd.Env = append(os.Environ(),
+	cmd, cleanup, err := provider.command(ctx, req.WorkspacePath, writablePaths(req), cmdPath, args,
 		"JUL_AGENT_MODE=prompt",
 		"JUL_AGENT_ACTION="+req.Action,
 		"JUL_AGENT_WORKSPACE="+req.WorkspacePath,
 	)
+	if err != nil {
+		return ReviewResponse{}, err
+	}
+	defer cleanup()
 	output, err := runCommandWithStream(cmd, stream)
 	if err != nil {
 		return ReviewResponse{}, fmt.Errorf("opencode failed: %w (%s)", err, strings.TrimSpace(string(output)))
@@ -154,7 +161,7 @@ func runPromptAgent(ctx context.Context, provider Provider, req ReviewRequest, h
 	}
 	defer os.Remove(tempFile)
 
-	prompt := buildReviewPrompt(req.Action, tempFile)
+	prompt := buildReviewPrompt(req.Action, tempFile, req.Context.Focus)
 	command := strings.TrimSpace(headless)
 	if command == "" {
 		command = provider.Command
@@ -167,15 +174,17 @@ func runPromptAgent(ctx context.Context, provider Provider, req ReviewRequest, h
 	if !replaced {
 		cmdArgs = append(cmdArgs, prompt)
 	}
-	cmd := exec.CommandContext(ctx, cmdPath, cmdArgs...)
-	cmd.Dir = req.WorkspacePath
-	cmd.Env = append(os.Environ(),
+	cmd, cleanup, err := provider.command(ctx, req.WorkspacePath, writablePaths(req), cmdPath, cmdArgs,
 		"JUL_AGENT_MODE=prompt",
 		"JUL_AGENT_ACTION="+req.Action,
 		"JUL_AGENT_WORKSPACE="+req.WorkspacePath,
 		"JUL_AGENT_INPUT="+tempFile,
 		"JUL_AGENT_PROMPT="+prompt,
 	)
+	if err != nil {
+		return ReviewResponse{}, err
+	}
+	defer cleanup()
 	output, err := runCommandWithStream(cmd, stream)
 	if err != nil {
 		return ReviewResponse{}, fmt.Errorf("agent failed: %w (%s)", err, strings.TrimSpace(string(output)))
@@ -559,7 +568,7 @@ func extractOpenCodeText(data []byte) (string, bool, error) {
 	return text.String(), true, nil
 }
 
-func buildReviewPrompt(action, attachmentPath string) string {
+func buildReviewPrompt(action, attachmentPath, focus string) string {
 	var buf strings.Builder
 	action = strings.TrimSpace(action)
 	if action == "" {
@@ -570,6 +579,10 @@ func buildReviewPrompt(action, attachmentPath string) string {
 		buf.WriteString("You are the Jul internal merge agent.\n")
 	case "generate_message":
 		buf.WriteString("You are the Jul internal checkpoint message agent.\n")
+	case "fix_failing_test":
+		buf.WriteString("You are the Jul internal fix agent.\n")
+	case "setup_ci":
+		buf.WriteString("You are the Jul internal checks setup agent.\n")
 	case "review_summary":
 		buf.WriteString("You are the Jul internal review agent.\n")
 	default:
@@ -581,6 +594,14 @@ func buildReviewPrompt(action, attachmentPath string) string {
 			buf.WriteString("Resolve merge conflicts using the context file at ")
 			buf.WriteString(attachmentPath)
 			buf.WriteString(". Fix conflicts in the workspace, ensure it builds, commit the resolution, and respond with JSON ONLY.\n")
+		case "fix_failing_test":
+			buf.WriteString("The checks under CI results in the context file at ")
+			buf.WriteString(attachmentPath)
+			buf.WriteString(" are failing. Fix the code in the workspace so they pass, re-run them, commit the fix, and respond with JSON ONLY.\n")
+		case "setup_ci":
+			buf.WriteString("Propose fast local check commands (lint, build, test) for the repository described by the layout and manifest files in the context file at ")
+			buf.WriteString(attachmentPath)
+			buf.WriteString(". Prefer the project's own tooling and scripts. Do not modify the workspace. Respond with JSON ONLY.\n")
 		case "generate_message":
 			buf.WriteString("Generate a concise checkpoint commit message using the context file at ")
 			buf.WriteString(attachmentPath)
@@ -598,6 +619,10 @@ func buildReviewPrompt(action, attachmentPath string) string {
 		switch action {
 		case "resolve_conflict":
 			buf.WriteString("Resolve merge conflicts using the attached context file. Fix conflicts in the workspace, ensure it builds, commit the resolution, and respond with JSON ONLY.\n")
+		case "fix_failing_test":
+			buf.WriteString("The checks under CI results in the attached context file are failing. Fix the code in the workspace so they pass, re-run them, commit the fix, and respond with JSON ONLY.\n")
+		case "setup_ci":
+			buf.WriteString("Propose fast local check commands (lint, build, test) for the repository described by the layout and manifest files in the attached context file. Prefer the project's own tooling and scripts. Do not modify the workspace. Respond with JSON ONLY.\n")
 		case "generate_message":
 			buf.WriteString("Generate a concise checkpoint commit message using the attached context file. Do not modify the workspace. Respond with JSON ONLY. Put the full commit message text in summary. Do not include Change-Id or Trace trailers.\n")
 		case "review_summary":
@@ -606,18 +631,35 @@ func buildReviewPrompt(action, attachmentPath string) string {
 			buf.WriteString("Review the attached context file, make fixes in the workspace, commit them, and respond with JSON ONLY.\n")
 		}
 	}
+	if focus = strings.TrimSpace(focus); focus != "" {
+		buf.WriteString("Review focus: ")
+		buf.WriteString(focus)
+		buf.WriteString("\n")
+	}
 	buf.WriteString("Response schema:\n")
 	switch action {
 	case "generate_message":
 		buf.WriteString("{\"version\":1,\"status\":\"completed\",\"summary\":\"feat: ...\"}\n")
 	case "review_summary":
-		buf.WriteString("{\"version\":1,\"status\":\"completed\",\"summary\":\"...\"}\n")
+		buf.WriteString("{\"version\":1,\"status\":\"completed\",\"summary\":\"...\",\"findings\":[" + findingSchema + "]}\n")
+	case "fix_failing_test":
+		buf.WriteString("{\"version\":1,\"status\":\"completed\",\"summary\":\"fix: ...\"}\n")
+	case "setup_ci":
+		buf.WriteString("{\"version\":1,\"status\":\"completed\",\"checks\":[{\"name\":\"test\",\"command\":\"...\"}]}\n")
+	case "review", "review_suggest":
+		buf.WriteString("{\"version\":1,\"status\":\"completed\",\"suggestions\":[{\"commit\":\"<sha>\",\"reason\":\"...\",\"description\":\"...\",\"confidence\":0.0}],\"findings\":[" + findingSchema + "]}\n")
 	default:
 		buf.WriteString("{\"version\":1,\"status\":\"completed\",\"suggestions\":[{\"commit\":\"<sha>\",\"reason\":\"...\",\"description\":\"...\",\"confidence\":0.0}]}\n")
 	}
+	switch action {
+	case "review", "review_suggest", "review_summary":
+		buf.WriteString("Report each concrete issue as a finding anchored to lines of the checkpoint's files; severity is one of info, low, medium, high, critical.\n")
+	}
 	return buf.String()
 }
 
+const findingSchema = `{"path":"...","start_line":1,"end_line":1,"severity":"medium","category":"...","message":"..."}`
+
 func buildReviewAttachment(req ReviewRequest) string {
 	var attachment strings.Builder
 	if req.Context.Checkpoint != "" {
@@ -626,6 +668,13 @@ func buildReviewAttachment(req ReviewRequest) string {
 	if req.Context.ChangeID != "" {
 		attachment.WriteString("Change-Id: " + req.Context.ChangeID + "\n")
 	}
+	if req.Context.Profile != "" {
+		attachment.WriteString("Review profile: " + req.Context.Profile + "\n")
+	}
+	if req.Context.ReviewedCheckpoint != "" {
+		attachment.WriteString("Previously reviewed checkpoint: " + req.Context.ReviewedCheckpoint + "\n")
+		attachment.WriteString("The diff and files below only cover changes since that checkpoint. Review those changes; do not repeat the prior findings or open suggestions listed below.\n")
+	}
 	if len(req.Context.Conflicts) > 0 {
 		attachment.WriteString("\nConflicts:\n")
 		for _, conflict := range req.Context.Conflicts {
@@ -635,6 +684,12 @@ func buildReviewAttachment(req ReviewRequest) string {
 			attachment.WriteString("- " + strings.TrimSpace(conflict) + "\n")
 		}
 	}
+	if len(req.Context.Layout) > 0 {
+		attachment.WriteString("\nLayout:\n")
+		for _, path := range req.Context.Layout {
+			attachment.WriteString(path + "\n")
+		}
+	}
 	if req.Context.Diff != "" {
 		attachment.WriteString("\nDiff:\n")
 		attachment.WriteString(req.Context.Diff)
@@ -656,6 +711,35 @@ func buildReviewAttachment(req ReviewRequest) string {
 		attachment.Write(req.Context.CIResults)
 		attachment.WriteString("\n")
 	}
+	if len(req.Context.PriorFindings) > 0 {
+		attachment.WriteString("\nPrior findings:\n")
+		for _, finding := range req.Context.PriorFindings {
+			attachment.WriteString(fmt.Sprintf("- %s:%d-%d [%s] %s\n", finding.Path, finding.StartLine, finding.EndLine, finding.Severity, finding.Message))
+		}
+	}
+	if len(req.Context.OpenSuggestions) > 0 {
+		attachment.WriteString("\nOpen suggestions:\n")
+		for _, sug := range req.Context.OpenSuggestions {
+			line := "- " + sug.ID + ": " + sug.Reason
+			if sug.Description != "" {
+				line += " — " + sug.Description
+			}
+			if len(sug.FilesChanged) > 0 {
+				line += " (" + strings.Join(sug.FilesChanged, ", ") + ")"
+			}
+			attachment.WriteString(line + "\n")
+		}
+	}
+	if sug := req.Context.Regenerate; sug != nil {
+		attachment.WriteString("\nRegenerate suggestion " + sug.ID + ": " + sug.Reason)
+		if sug.Description != "" {
+			attachment.WriteString(" — " + sug.Description)
+		}
+		attachment.WriteString("\nIts patch below no longer applies to this checkpoint. Make the same change against the current code as a new suggestion; do not propose unrelated changes.\n")
+		attachment.WriteString("```diff\n")
+		attachment.WriteString(req.Context.RegeneratePatch)
+		attachment.WriteString("\n```\n")
+	}
 	if strings.TrimSpace(req.Context.PriorSummary) != "" {
 		attachment.WriteString("\nPrior review summary:\n")
 		attachment.WriteString(strings.TrimSpace(req.Context.PriorSummary))
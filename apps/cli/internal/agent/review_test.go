@@ -64,7 +64,7 @@ func TestReviewPromptKeepsDiffInAttachment(t *testing.T) {
 		},
 	}
 	attachment := buildReviewAttachment(req)
-	prompt := buildReviewPrompt("review", "/tmp/review.txt")
+	prompt := buildReviewPrompt("review", "/tmp/review.txt", "")
 	if !strings.Contains(attachment, "diff --git") || !strings.Contains(attachment, "file.txt") {
 		t.Fatalf("expected diff and file content in attachment")
 	}
@@ -89,7 +89,7 @@ func TestWriteReviewAttachmentOutsideWorktree(t *testing.T) {
 }
 
 func TestBuildReviewPromptGenerateMessage(t *testing.T) {
-	prompt := buildReviewPrompt("generate_message", "/tmp/checkpoint.txt")
+	prompt := buildReviewPrompt("generate_message", "/tmp/checkpoint.txt", "")
 	if !strings.Contains(prompt, "checkpoint message") {
 		t.Fatalf("expected checkpoint message instructions")
 	}
@@ -100,3 +100,13 @@ func TestBuildReviewPromptGenerateMessage(t *testing.T) {
 		t.Fatalf("did not expect suggestions schema in generate_message prompt")
 	}
 }
+
+func TestBuildReviewPromptIncludesFocus(t *testing.T) {
+	prompt := buildReviewPrompt("review_suggest", "/tmp/review.txt", "Look for injection and authz bugs.")
+	if !strings.Contains(prompt, "Review focus: Look for injection and authz bugs.") {
+		t.Fatalf("expected focus in prompt, got %q", prompt)
+	}
+	if strings.Index(prompt, "Review focus") > strings.Index(prompt, "Response schema") {
+		t.Fatalf("expected focus before the response schema")
+	}
+}
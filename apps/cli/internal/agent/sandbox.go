@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/lydakis/jul/cli/internal/config"
+)
+
+// SandboxHelperArg is the hidden first argument that re-executes jul as the
+// sandbox helper; cmd/jul dispatches it to RunSandboxHelper.
+const SandboxHelperArg = "__jul-agent-sandbox"
+
+var ErrSandboxUnavailable = errors.New("agent sandbox unavailable")
+
+// Sandbox confines an agent run: read-only filesystem outside the agent
+// worktree, optional network isolation, scrubbed environment and rlimits.
+type Sandbox struct {
+	Enabled    bool
+	Network    bool
+	CPUSeconds int
+	MemoryMB   int
+	Writable   []string
+}
+
+var (
+	sandboxProbeOnce   sync.Once
+	sandboxProbeReason string
+)
+
+// SandboxAvailable reports whether this host can run agents confined, with
+// the reason when it cannot. The probe runs once per process.
+func SandboxAvailable() (bool, string) {
+	sandboxProbeOnce.Do(func() {
+		sandboxProbeReason = probeSandbox()
+	})
+	return sandboxProbeReason == "", sandboxProbeReason
+}
+
+// SandboxStatus describes the configured sandbox for `jul doctor`.
+func SandboxStatus() string {
+	sb := sandboxFromConfig(config.LoadAgentConfig().Sandbox, nil)
+	if !sb.Enabled {
+		return "disabled"
+	}
+	if ok, reason := SandboxAvailable(); !ok {
+		return "unavailable (" + reason + ")"
+	}
+	if sb.Network {
+		return "available (network allowed)"
+	}
+	return "available (network isolated)"
+}
+
+// sandboxFromConfig resolves the sandbox for a provider. Confinement is
+// opt-in, except that enable_network = false (in [sandbox] or on the
+// provider) can only be honoured inside the sandbox, so it turns it on.
+func sandboxFromConfig(cfg config.AgentSandbox, network *bool) Sandbox {
+	sb := Sandbox{
+		Enabled:    cfg.Enabled,
+		Network:    cfg.EnableNetwork,
+		CPUSeconds: cfg.CPUSeconds,
+		MemoryMB:   cfg.MemoryMB,
+		Writable:   cfg.Writable,
+	}
+	if network != nil {
+		sb.Network = *network
+	}
+	if !sb.Network {
+		sb.Enabled = true
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("JUL_AGENT_SANDBOX"))) {
+	case "off", "0", "false":
+		sb.Enabled = false
+	case "on", "1", "true":
+		sb.Enabled = true
+	}
+	return sb
+}
+
+// command builds the agent process for name/args in workdir. When the
+// sandbox is enabled only writable (plus a scratch TMPDIR and the configured
+// extra paths) stays writable; the returned cleanup removes the scratch dir.
+func (p Provider) command(ctx context.Context, workdir string, writable []string, name string, args []string, env ...string) (*exec.Cmd, func(), error) {
+	// Commits the agent makes in its worktree must not trigger jul's
+	// post-commit sync or adoption.
+	env = append(env, "JUL_NO_SYNC=1")
+	if !p.Sandbox.Enabled {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Dir = workdir
+		cmd.Env = append(os.Environ(), env...)
+		return cmd, func() {}, nil
+	}
+	if ok, reason := SandboxAvailable(); !ok {
+		if !p.Sandbox.Network {
+			return nil, nil, fmt.Errorf("%w: %s; enable_network = false needs it, so the agent was not run (set enable_network = true in %s to run it unconfined)", ErrSandboxUnavailable, reason, config.AgentConfigPath())
+		}
+		return nil, nil, fmt.Errorf("%w: %s; set enabled = false under [sandbox] in %s to run agents unconfined", ErrSandboxUnavailable, reason, config.AgentConfigPath())
+	}
+	scratch, err := os.MkdirTemp("", "jul-agent-tmp-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(scratch) }
+	paths := append([]string{scratch}, writable...)
+	for _, path := range p.Sandbox.Writable {
+		paths = append(paths, expandHome(path))
+	}
+	cmd, err := sandboxCommand(ctx, p.Sandbox, paths, name, args)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("%w: %v", ErrSandboxUnavailable, err)
+	}
+	cmd.Dir = workdir
+	cmd.Env = append(scrubEnv(os.Environ()), "TMPDIR="+scratch)
+	cmd.Env = append(cmd.Env, env...)
+	return cmd, cleanup, nil
+}
+
+// RunInWorktree runs a shell command in worktree the way an agent would,
+// confined by the provider's sandbox and bounded by its timeout. It is used
+// to try agent-proposed commands before jul trusts them; the user cache dir
+// stays writable so build tools can keep their caches.
+func RunInWorktree(ctx context.Context, provider Provider, worktree, command string) ([]byte, error) {
+	if provider.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, provider.Timeout)
+		defer cancel()
+	}
+	writable := agentWritable(worktree)
+	if dir, err := os.UserCacheDir(); err == nil && os.MkdirAll(dir, 0o755) == nil {
+		writable = append(writable, dir)
+	}
+	cmd, cleanup, err := provider.command(ctx, worktree, writable, "sh", []string{"-c", command})
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return cmd.CombinedOutput()
+}
+
+// agentWritable lists what an agent that edits and commits in worktree must
+// be able to write: the worktree, its git dir and the shared object store.
+func agentWritable(worktree string) []string {
+	paths := []string{worktree}
+	for _, args := range [][]string{
+		{"rev-parse", "--absolute-git-dir"},
+		{"rev-parse", "--path-format=absolute", "--git-path", "objects"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", worktree}, args...)...)
+		if out, err := cmd.Output(); err == nil {
+			if path := strings.TrimSpace(string(out)); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// writablePaths is what req's agent may write when sandboxed: nothing for
+// actions that must leave the workspace alone, else the agent worktree.
+func writablePaths(req ReviewRequest) []string {
+	switch req.Action {
+	case "generate_message", "review_summary", "setup_ci":
+		return nil
+	}
+	if strings.TrimSpace(req.WorkspacePath) == "" {
+		return nil
+	}
+	return agentWritable(req.WorkspacePath)
+}
+
+var (
+	scrubbedEnvPrefixes = []string{
+		"AWS_", "AZURE_", "ARM_", "GOOGLE_", "GCLOUD_", "CLOUDSDK_", "GCP_",
+		"DIGITALOCEAN_", "HEROKU_", "ALIBABA_CLOUD_", "OCI_", "IBMCLOUD_",
+		"VAULT_", "KUBE", "DOCKER_", "SSH_",
+	}
+	scrubbedEnvSuffixes = []string{
+		"_TOKEN", "_SECRET", "_SECRET_KEY", "_ACCESS_KEY", "_PASSWORD", "_CREDENTIALS",
+	}
+)
+
+// scrubEnv drops cloud credentials and other secrets from environ. Model
+// provider keys (*_API_KEY) pass through so hosted agents still work.
+func scrubEnv(environ []string) []string {
+	out := make([]string, 0, len(environ))
+	for _, entry := range environ {
+		key, _, _ := strings.Cut(entry, "=")
+		if scrubbedEnvKey(strings.ToUpper(key)) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func scrubbedEnvKey(key string) bool {
+	if strings.HasSuffix(key, "_API_KEY") {
+		return false
+	}
+	for _, prefix := range scrubbedEnvPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	for _, suffix := range scrubbedEnvSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func expandHome(path string) string {
+	path = strings.TrimSpace(path)
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
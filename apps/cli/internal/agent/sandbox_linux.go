@@ -0,0 +1,320 @@
+//go:build linux
+
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// sandboxCommand re-executes jul as the sandbox helper inside fresh user,
+// mount and pid namespaces (plus a network namespace when network is off).
+// The helper sets up the mounts and limits, then runs name as the calling
+// user in a nested user namespace so the agent holds no capabilities.
+func sandboxCommand(ctx context.Context, sb Sandbox, writable []string, name string, args []string) (*exec.Cmd, error) {
+	self, err := sandboxHelperPath()
+	if err != nil {
+		return nil, err
+	}
+	helperArgs := []string{
+		SandboxHelperArg,
+		"--uid", strconv.Itoa(os.Getuid()),
+		"--gid", strconv.Itoa(os.Getgid()),
+		"--cpu", strconv.Itoa(sb.CPUSeconds),
+		"--memory", strconv.Itoa(sb.MemoryMB),
+	}
+	for _, path := range writable {
+		helperArgs = append(helperArgs, "--writable", path)
+	}
+	helperArgs = append(helperArgs, "--", name)
+	helperArgs = append(helperArgs, args...)
+	cmd := exec.CommandContext(ctx, self, helperArgs...)
+	cmd.SysProcAttr = sandboxAttr(sb.Network)
+	return cmd, nil
+}
+
+func sandboxAttr(network bool) *syscall.SysProcAttr {
+	flags := syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID
+	if !network {
+		flags |= syscall.CLONE_NEWNET
+	}
+	return &syscall.SysProcAttr{
+		Cloneflags:  uintptr(flags),
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		Pdeathsig:   syscall.SIGKILL,
+	}
+}
+
+func sandboxHelperPath() (string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	// Test binaries do not dispatch the helper argument.
+	if strings.HasSuffix(self, ".test") {
+		return "", fmt.Errorf("sandbox helper needs the jul binary")
+	}
+	return self, nil
+}
+
+// probeSandbox runs the helper's mount setup without a command, with network
+// isolation on, and returns why it failed ("" when it worked).
+func probeSandbox() string {
+	self, err := sandboxHelperPath()
+	if err != nil {
+		return err.Error()
+	}
+	dir, err := os.MkdirTemp("", "jul-sandbox-probe-")
+	if err != nil {
+		return err.Error()
+	}
+	defer os.RemoveAll(dir)
+	cmd := exec.Command(self, SandboxHelperArg, "--probe", "--writable", dir)
+	cmd.SysProcAttr = sandboxAttr(false)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return msg
+		}
+		return fmt.Sprintf("namespaces not permitted: %v", err)
+	}
+	return ""
+}
+
+type pathList []string
+
+func (p *pathList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// RunSandboxHelper is the helper side of sandboxCommand. It runs as root of
+// the new user namespace and returns the agent's exit code.
+func RunSandboxHelper(args []string) int {
+	fs := flag.NewFlagSet(SandboxHelperArg, flag.ContinueOnError)
+	uid := fs.Int("uid", os.Getuid(), "")
+	gid := fs.Int("gid", os.Getgid(), "")
+	cpu := fs.Int("cpu", 0, "")
+	memory := fs.Int("memory", 0, "")
+	probe := fs.Bool("probe", false, "")
+	var writable pathList
+	fs.Var(&writable, "writable", "")
+	if err := fs.Parse(args); err != nil {
+		return 126
+	}
+	if err := confineMounts(writable); err != nil {
+		fmt.Fprintf(os.Stderr, "jul sandbox: %v\n", err)
+		return 126
+	}
+	if *probe {
+		return 0
+	}
+	// The working directory still points into the mount it was opened on;
+	// re-enter it by path so a writable bind over it takes effect.
+	if wd, err := os.Getwd(); err == nil {
+		_ = os.Chdir(wd)
+	}
+	command := fs.Args()
+	if len(command) == 0 {
+		fmt.Fprintln(os.Stderr, "jul sandbox: command required")
+		return 126
+	}
+	if err := applyLimits(*cpu, *memory); err != nil {
+		fmt.Fprintf(os.Stderr, "jul sandbox: %v\n", err)
+		return 126
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: *uid, HostID: 0, Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: *gid, HostID: 0, Size: 1}},
+		Pdeathsig:   syscall.SIGKILL,
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "jul sandbox: %v\n", err)
+		return 127
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+	err := cmd.Wait()
+	signal.Stop(signals)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// pseudoFilesystems may refuse a read-only remount; they hold no user data.
+var pseudoFilesystems = map[string]bool{
+	"proc": true, "sysfs": true, "cgroup": true, "cgroup2": true, "devpts": true,
+	"mqueue": true, "securityfs": true, "debugfs": true, "tracefs": true,
+	"pstore": true, "bpf": true, "fusectl": true, "configfs": true,
+	"binfmt_misc": true, "autofs": true, "hugetlbfs": true, "nsfs": true,
+}
+
+// confineMounts makes every mount read-only except the writable paths, which
+// are bind-mounted onto themselves first so they keep their own mount.
+func confineMounts(writable []string) error {
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	keep := make([]string, 0, len(writable))
+	for _, path := range writable {
+		path, err := filepath.EvalSymlinks(filepath.Clean(path))
+		if err != nil {
+			continue
+		}
+		if err := syscall.Mount(path, path, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+			return fmt.Errorf("bind %s: %w", path, err)
+		}
+		keep = append(keep, path)
+	}
+	// A fresh /proc matches the new pid namespace; not every host allows it.
+	_ = syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+
+	mounts, err := readMountInfo()
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		// proc stays writable: the nested user namespace needs its uid_map.
+		if underAny(m.point, keep) || m.fstype == "proc" {
+			continue
+		}
+		flags := uintptr(syscall.MS_REMOUNT|syscall.MS_BIND|syscall.MS_RDONLY) | m.flags
+		if err := syscall.Mount("", m.point, "", flags, ""); err != nil {
+			if m.point == "/" || !pseudoFilesystems[m.fstype] {
+				return fmt.Errorf("remount %s read-only: %w", m.point, err)
+			}
+		}
+	}
+	return nil
+}
+
+type mountEntry struct {
+	point  string
+	fstype string
+	flags  uintptr
+}
+
+// readMountInfo lists mount points shallowest first, with the per-mount flags
+// a bind remount has to preserve.
+func readMountInfo() ([]mountEntry, error) {
+	data, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	var mounts []mountEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		pre, post, ok := strings.Cut(scanner.Text(), " - ")
+		fields := strings.Fields(pre)
+		if !ok || len(fields) < 6 {
+			continue
+		}
+		entry := mountEntry{point: unescapeMountPath(fields[4])}
+		if post := strings.Fields(post); len(post) > 0 {
+			entry.fstype = post[0]
+		}
+		for _, opt := range strings.Split(fields[5], ",") {
+			switch opt {
+			case "nosuid":
+				entry.flags |= syscall.MS_NOSUID
+			case "nodev":
+				entry.flags |= syscall.MS_NODEV
+			case "noexec":
+				entry.flags |= syscall.MS_NOEXEC
+			case "noatime":
+				entry.flags |= syscall.MS_NOATIME
+			case "nodiratime":
+				entry.flags |= syscall.MS_NODIRATIME
+			case "relatime":
+				entry.flags |= syscall.MS_RELATIME
+			case "strictatime":
+				entry.flags |= syscall.MS_STRICTATIME
+			}
+		}
+		mounts = append(mounts, entry)
+	}
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return strings.Count(mounts[i].point, "/") < strings.Count(mounts[j].point, "/")
+	})
+	return mounts, scanner.Err()
+}
+
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+	var out strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if n, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				out.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		out.WriteByte(path[i])
+	}
+	return out.String()
+}
+
+func underAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if path == root || strings.HasPrefix(path, strings.TrimSuffix(root, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func applyLimits(cpuSeconds, memoryMB int) error {
+	if cpuSeconds > 0 {
+		limit := &syscall.Rlimit{Cur: uint64(cpuSeconds), Max: uint64(cpuSeconds)}
+		if err := syscall.Setrlimit(syscall.RLIMIT_CPU, limit); err != nil {
+			return fmt.Errorf("cpu limit: %w", err)
+		}
+	}
+	if memoryMB > 0 {
+		size := uint64(memoryMB) << 20
+		limit := &syscall.Rlimit{Cur: size, Max: size}
+		if err := syscall.Setrlimit(syscall.RLIMIT_DATA, limit); err != nil {
+			return fmt.Errorf("memory limit: %w", err)
+		}
+	}
+	return nil
+}
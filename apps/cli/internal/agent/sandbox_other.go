@@ -0,0 +1,23 @@
+//go:build !linux
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+func sandboxCommand(ctx context.Context, sb Sandbox, writable []string, name string, args []string) (*exec.Cmd, error) {
+	return nil, fmt.Errorf("agent sandbox requires Linux namespaces")
+}
+
+func probeSandbox() string {
+	return "agent sandbox requires Linux namespaces"
+}
+
+func RunSandboxHelper(args []string) int {
+	fmt.Fprintln(os.Stderr, "jul sandbox: agent sandbox requires Linux namespaces")
+	return 126
+}
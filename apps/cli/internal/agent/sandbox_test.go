@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/config"
+)
+
+func TestScrubEnvDropsCloudCredentials(t *testing.T) {
+	env := scrubEnv([]string{
+		"PATH=/usr/bin",
+		"AWS_SECRET_ACCESS_KEY=x",
+		"GOOGLE_APPLICATION_CREDENTIALS=/key.json",
+		"GITHUB_TOKEN=y",
+		"SSH_AUTH_SOCK=/tmp/agent",
+		"ANTHROPIC_API_KEY=z",
+	})
+	got := strings.Join(env, " ")
+	if got != "PATH=/usr/bin ANTHROPIC_API_KEY=z" {
+		t.Fatalf("unexpected scrubbed env: %s", got)
+	}
+}
+
+func TestSandboxFromConfigProviderNetworkOverride(t *testing.T) {
+	t.Setenv("JUL_AGENT_SANDBOX", "")
+	off := false
+	sb := sandboxFromConfig(config.AgentSandbox{Enabled: true, EnableNetwork: true}, &off)
+	if !sb.Enabled || sb.Network {
+		t.Fatalf("expected provider enable_network=false to isolate network, got %+v", sb)
+	}
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+	if sb := sandboxFromConfig(config.AgentSandbox{Enabled: true}, nil); sb.Enabled {
+		t.Fatalf("expected JUL_AGENT_SANDBOX=off to disable sandbox")
+	}
+}
+
+func TestProviderWithoutNetworkIsConfined(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("JUL_AGENT_SANDBOX", "")
+	t.Setenv("JUL_AGENT_CMD", "")
+	path := filepath.Join(home, ".config", "jul", "agents.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	content := "[default]\nprovider = \"offline\"\n\n[providers.offline]\ncommand = \"true\"\nenable_network = false\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	provider, err := ResolveProvider()
+	if err != nil {
+		t.Fatalf("ResolveProvider failed: %v", err)
+	}
+	if !provider.Sandbox.Enabled || provider.Sandbox.Network {
+		t.Fatalf("expected enable_network=false to confine the agent, got %+v", provider.Sandbox)
+	}
+	cmd, cleanup, err := provider.command(context.Background(), t.TempDir(), nil, "true", nil)
+	if err != nil {
+		if !errors.Is(err, ErrSandboxUnavailable) || !strings.Contains(err.Error(), "enable_network") {
+			t.Fatalf("expected a sandbox error naming enable_network, got %v", err)
+		}
+		return
+	}
+	defer cleanup()
+	if filepath.Base(cmd.Path) == "true" {
+		t.Fatalf("expected a confined command, got %v", cmd.Args)
+	}
+}
+
+func TestLoadAgentConfigSandboxSection(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "jul", "agents.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	content := "[sandbox]\nenabled = true\nenable_network = false\ncpu_seconds = 60\nwritable = [\"~/.agent\"]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+	cfg := config.LoadAgentConfig().Sandbox
+	if !cfg.Enabled || cfg.EnableNetwork || cfg.CPUSeconds != 60 {
+		t.Fatalf("unexpected sandbox config: %+v", cfg)
+	}
+	if last := cfg.Writable[len(cfg.Writable)-1]; expandHome(last) != filepath.Join(home, ".agent") {
+		t.Fatalf("expected extra writable path, got %v", cfg.Writable)
+	}
+}
+
+func TestWritablePathsReadOnlyActions(t *testing.T) {
+	if paths := writablePaths(ReviewRequest{Action: "generate_message", WorkspacePath: t.TempDir()}); len(paths) != 0 {
+		t.Fatalf("expected no writable paths for generate_message, got %v", paths)
+	}
+	dir := t.TempDir()
+	if paths := writablePaths(ReviewRequest{Action: "review_suggest", WorkspacePath: dir}); len(paths) == 0 || paths[0] != dir {
+		t.Fatalf("expected worktree writable for review_suggest, got %v", paths)
+	}
+}
@@ -17,6 +17,20 @@ type ReviewContext struct {
 	Conflicts    []string        `json:"conflicts,omitempty"`
 	CIResults    json.RawMessage `json:"ci_results,omitempty"`
 	PriorSummary string          `json:"prior_summary,omitempty"`
+	Layout       []string        `json:"layout,omitempty"`
+	// Profile and Focus name the review persona and its prompt addendum.
+	Profile string `json:"profile,omitempty"`
+	Focus   string `json:"focus,omitempty"`
+	// ReviewedCheckpoint is set on incremental reviews: Diff and Files then
+	// cover only the changes since it, and PriorFindings and OpenSuggestions
+	// hold what earlier reviews already reported.
+	ReviewedCheckpoint string             `json:"reviewed_checkpoint,omitempty"`
+	PriorFindings      []ReviewFinding    `json:"prior_findings,omitempty"`
+	OpenSuggestions    []ReviewSuggestion `json:"open_suggestions,omitempty"`
+	// Regenerate is a suggestion whose patch (RegeneratePatch) no longer
+	// applies; the agent is asked to make the same change again.
+	Regenerate      *ReviewSuggestion `json:"regenerate,omitempty"`
+	RegeneratePatch string            `json:"regenerate_patch,omitempty"`
 }
 
 type ReviewFile struct {
@@ -29,6 +43,25 @@ type ReviewResponse struct {
 	Status      string             `json:"status"`
 	Summary     string             `json:"summary,omitempty"`
 	Suggestions []ReviewSuggestion `json:"suggestions,omitempty"`
+	Checks      []ProposedCheck    `json:"checks,omitempty"`
+	Findings    []ReviewFinding    `json:"findings,omitempty"`
+}
+
+// ReviewFinding is a line-anchored issue reported by a review. Lines refer
+// to the reviewed checkpoint's version of the file.
+type ReviewFinding struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Severity  string `json:"severity,omitempty"`
+	Category  string `json:"category,omitempty"`
+	Message   string `json:"message"`
+}
+
+// ProposedCheck is a check command returned by the setup_ci action.
+type ProposedCheck struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
 }
 
 type ReviewSuggestion struct {
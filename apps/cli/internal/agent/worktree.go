@@ -11,13 +11,20 @@ import (
 
 type WorktreeOptions struct {
 	AllowMergeInProgress bool
+	// Name selects a worktree under .jul/agent-workspace other than the
+	// shared "worktree", so agents can run side by side.
+	Name string
 }
 
 var ErrMergeInProgress = errors.New("merge in progress in agent worktree")
 
 func EnsureWorktree(repoRoot, baseSHA string, opts WorktreeOptions) (string, error) {
 	agentRoot := filepath.Join(repoRoot, ".jul", "agent-workspace")
-	worktree := filepath.Join(agentRoot, "worktree")
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		name = "worktree"
+	}
+	worktree := filepath.Join(agentRoot, name)
 
 	if err := os.MkdirAll(agentRoot, 0o755); err != nil {
 		return "", err
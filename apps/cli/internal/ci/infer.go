@@ -1,40 +1,380 @@
 package ci
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+const maxInferredDirs = 20
+
+// InferDefaultCommands returns the commands of InferDefaultChecks.
 func InferDefaultCommands(root string) []string {
+	checks := InferDefaultChecks(root)
+	cmds := make([]string, 0, len(checks))
+	for _, check := range checks {
+		cmds = append(cmds, check.Command)
+	}
+	return cmds
+}
+
+// InferDefaultChecks derives named checks from the project's own tooling when
+// .jul/ci.toml is missing. Makefile test/lint targets win over ecosystem
+// defaults (Go, Node, Python, Rust). Directories one or two levels down that
+// the root does not already cover get their own checks prefixed with a cd,
+// which handles go.work modules and polyglot monorepos alike.
+func InferDefaultChecks(root string) []CommandSpec {
 	if root == "" {
-		return []string{"true"}
-	}
-	if fileExists(filepath.Join(root, "go.mod")) {
-		return []string{"go test ./..."}
-	}
-	workPath := filepath.Join(root, "go.work")
-	if fileExists(workPath) {
-		data, err := os.ReadFile(workPath)
-		if err == nil {
-			uses := parseGoWorkUses(string(data))
-			if len(uses) > 0 {
-				cmds := make([]string, 0, len(uses))
-				for _, use := range uses {
-					path := strings.TrimSpace(use)
-					if path == "" {
-						continue
-					}
-					cmds = append(cmds, "cd "+shellQuote(path)+" && go test ./...")
-				}
-				if len(cmds) > 0 {
-					return cmds
+		return []CommandSpec{{Name: "none", Command: "true"}}
+	}
+	checks := inferDirChecks(root)
+	// A root Makefile test target is the project's single entry point.
+	if makeTarget(root, "test") {
+		return checks
+	}
+	rootModule := fileExists(filepath.Join(root, "go.mod"))
+	covered := map[string]bool{}
+	for _, use := range goWorkUses(root) {
+		rel := filepath.ToSlash(filepath.Clean(use))
+		if rel == "." {
+			continue
+		}
+		covered[rel] = true
+		if rootModule {
+			continue
+		}
+		checks = append(checks, CommandSpec{
+			Name:    checkName(rel, "test"),
+			Command: "cd " + shellQuote(rel) + " && go test ./...",
+		})
+	}
+	for _, rel := range manifestDirs(root) {
+		if covered[rel] || coveredByRoot(root, rel) {
+			continue
+		}
+		for _, check := range inferDirChecks(filepath.Join(root, filepath.FromSlash(rel))) {
+			checks = append(checks, CommandSpec{
+				Name:    checkName(rel, check.Name),
+				Command: "cd " + shellQuote(rel) + " && " + check.Command,
+			})
+		}
+	}
+	if len(checks) == 0 {
+		return []CommandSpec{{Name: "none", Command: "true"}}
+	}
+	return checks
+}
+
+// inferDirChecks looks at the manifests directly in dir. When two ecosystems
+// share a directory (go.mod next to package.json), their clashing check names
+// get an ecosystem prefix (go-test, node-test) so both run.
+func inferDirChecks(dir string) []CommandSpec {
+	var checks []CommandSpec
+	makeTargets := map[string]bool{}
+	for _, target := range []string{"lint", "test"} {
+		if makeTarget(dir, target) {
+			makeTargets[target] = true
+			checks = append(checks, CommandSpec{Name: target, Command: "make " + target})
+		}
+	}
+	var goChecks, cargoChecks []CommandSpec
+	if fileExists(filepath.Join(dir, "go.mod")) {
+		goChecks = append(goChecks, CommandSpec{Name: "test", Command: "go test ./..."})
+	}
+	if fileExists(filepath.Join(dir, "Cargo.toml")) {
+		cmd := "cargo test"
+		if cargoWorkspace(dir) {
+			cmd += " --workspace"
+		}
+		cargoChecks = append(cargoChecks, CommandSpec{Name: "test", Command: cmd})
+	}
+	ecosystems := []struct {
+		name   string
+		checks []CommandSpec
+	}{
+		{"node", nodeChecks(dir)},
+		{"python", pythonChecks(dir)},
+		{"go", goChecks},
+		{"cargo", cargoChecks},
+	}
+	claims := map[string]int{}
+	for _, eco := range ecosystems {
+		for _, spec := range eco.checks {
+			claims[spec.Name]++
+		}
+	}
+	for _, eco := range ecosystems {
+		for _, spec := range eco.checks {
+			// Makefile targets win over ecosystem defaults of the same name.
+			if spec.Command == "" || makeTargets[spec.Name] || hasCommand(checks, spec.Command) {
+				continue
+			}
+			if claims[spec.Name] > 1 {
+				spec.Name = eco.name + "-" + spec.Name
+			}
+			checks = append(checks, spec)
+		}
+	}
+	sort.SliceStable(checks, func(i, j int) bool {
+		return checkOrder(checks[i].Name) < checkOrder(checks[j].Name)
+	})
+	return checks
+}
+
+func checkOrder(name string) int {
+	switch name[strings.LastIndex(name, "-")+1:] {
+	case "lint":
+		return 0
+	case "typecheck":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// npmPlaceholderTest is the script `npm init` writes; it always fails.
+const npmPlaceholderTest = "echo \"Error: no test specified\" && exit 1"
+
+func nodeChecks(dir string) []CommandSpec {
+	pkg, ok := readPackageJSON(dir)
+	if !ok {
+		return nil
+	}
+	manager := nodePackageManager(dir, pkg.PackageManager)
+	var checks []CommandSpec
+	for _, script := range []struct{ name, key string }{
+		{"lint", "lint"},
+		{"typecheck", "typecheck"},
+		{"typecheck", "type-check"},
+		{"typecheck", "tsc"},
+		{"test", "test"},
+	} {
+		body, ok := pkg.Scripts[script.key]
+		if !ok || strings.TrimSpace(body) == "" || hasCheck(checks, script.name) {
+			continue
+		}
+		if script.key == "test" && strings.TrimSpace(body) == npmPlaceholderTest {
+			continue
+		}
+		checks = append(checks, CommandSpec{Name: script.name, Command: nodeRun(manager, script.key)})
+	}
+	return checks
+}
+
+type packageJSON struct {
+	PackageManager string            `json:"packageManager"`
+	Scripts        map[string]string `json:"scripts"`
+	Workspaces     json.RawMessage   `json:"workspaces"`
+}
+
+func readPackageJSON(dir string) (packageJSON, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return packageJSON{}, false
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return packageJSON{}, false
+	}
+	return pkg, true
+}
+
+// nodePackageManager prefers the packageManager field, then the lockfile.
+func nodePackageManager(dir, declared string) string {
+	if name, _, _ := strings.Cut(strings.TrimSpace(declared), "@"); name != "" {
+		return name
+	}
+	for _, lock := range []struct{ file, manager string }{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lockb", "bun"},
+		{"bun.lock", "bun"},
+	} {
+		if fileExists(filepath.Join(dir, lock.file)) {
+			return lock.manager
+		}
+	}
+	return "npm"
+}
+
+func nodeRun(manager, script string) string {
+	switch {
+	case script == "test" && manager != "bun":
+		// `bun test` is bun's own runner, not the test script.
+		return manager + " test"
+	case manager == "yarn":
+		return "yarn " + script
+	default:
+		return manager + " run " + script
+	}
+}
+
+func pythonChecks(dir string) []CommandSpec {
+	pyproject := readFile(filepath.Join(dir, "pyproject.toml"))
+	setupCfg := readFile(filepath.Join(dir, "setup.cfg"))
+	hasTox := fileExists(filepath.Join(dir, "tox.ini")) || strings.Contains(pyproject, "[tool.tox")
+	python := pyproject != "" || setupCfg != "" || hasTox ||
+		fileExists(filepath.Join(dir, "setup.py")) || fileExists(filepath.Join(dir, "requirements.txt"))
+	if !python {
+		return nil
+	}
+	run := ""
+	switch {
+	case fileExists(filepath.Join(dir, "uv.lock")):
+		run = "uv run "
+	case fileExists(filepath.Join(dir, "poetry.lock")):
+		run = "poetry run "
+	}
+	var checks []CommandSpec
+	if fileExists(filepath.Join(dir, "ruff.toml")) || fileExists(filepath.Join(dir, ".ruff.toml")) || strings.Contains(pyproject, "[tool.ruff") {
+		checks = append(checks, CommandSpec{Name: "lint", Command: run + "ruff check ."})
+	} else if fileExists(filepath.Join(dir, ".flake8")) || strings.Contains(setupCfg, "[flake8]") {
+		checks = append(checks, CommandSpec{Name: "lint", Command: run + "flake8"})
+	}
+	if fileExists(filepath.Join(dir, "mypy.ini")) || strings.Contains(pyproject, "[tool.mypy") || strings.Contains(setupCfg, "[mypy") {
+		checks = append(checks, CommandSpec{Name: "typecheck", Command: run + "mypy ."})
+	}
+	pytest := fileExists(filepath.Join(dir, "pytest.ini")) || fileExists(filepath.Join(dir, "conftest.py")) ||
+		strings.Contains(pyproject, "[tool.pytest") || strings.Contains(setupCfg, "[tool:pytest]") ||
+		strings.Contains(pyproject, "pytest") || dirExists(filepath.Join(dir, "tests"))
+	switch {
+	case pytest:
+		checks = append(checks, CommandSpec{Name: "test", Command: run + "python -m pytest"})
+	case hasTox:
+		checks = append(checks, CommandSpec{Name: "test", Command: "tox"})
+	}
+	return checks
+}
+
+func cargoWorkspace(dir string) bool {
+	return strings.Contains(readFile(filepath.Join(dir, "Cargo.toml")), "[workspace]")
+}
+
+var makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9_.-]+(?:\s+[A-Za-z0-9_.-]+)*)\s*::?(?:[^=]|$)`)
+
+func makeTarget(dir, target string) bool {
+	for _, name := range []string{"GNUmakefile", "makefile", "Makefile"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			match := makeTargetPattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			for _, field := range strings.Fields(match[1]) {
+				if field == target {
+					return true
 				}
 			}
 		}
+		return false
+	}
+	return false
+}
+
+var skippedInferDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "target": true, "dist": true, "build": true,
+	"testdata": true, "third_party": true, "venv": true, "__pycache__": true,
+}
+
+// manifestDirs lists directories up to two levels below root that hold a
+// manifest inference understands, shallowest first.
+func manifestDirs(root string) []string {
+	var dirs []string
+	var walk func(rel string, depth int)
+	walk = func(rel string, depth int) {
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return
+		}
+		var next []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || strings.HasPrefix(name, ".") || skippedInferDirs[name] {
+				continue
+			}
+			child := name
+			if rel != "" {
+				child = rel + "/" + name
+			}
+			if len(dirs) < maxInferredDirs && hasManifest(filepath.Join(root, filepath.FromSlash(child))) {
+				dirs = append(dirs, child)
+				continue
+			}
+			next = append(next, child)
+		}
+		if depth < 2 {
+			for _, child := range next {
+				walk(child, depth+1)
+			}
+		}
 	}
-	return []string{"true"}
+	walk("", 1)
+	return dirs
+}
+
+func hasManifest(dir string) bool {
+	for _, name := range []string{"go.mod", "package.json", "pyproject.toml", "setup.py", "tox.ini", "Cargo.toml"} {
+		if fileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return makeTarget(dir, "test")
+}
+
+// coveredByRoot reports whether a root-level workspace already runs rel's
+// checks: npm/pnpm/yarn workspaces or a Cargo workspace.
+func coveredByRoot(root, rel string) bool {
+	dir := filepath.Join(root, filepath.FromSlash(rel))
+	if fileExists(filepath.Join(dir, "package.json")) {
+		if pkg, ok := readPackageJSON(root); ok && (len(pkg.Workspaces) > 0 || fileExists(filepath.Join(root, "pnpm-workspace.yaml"))) {
+			return true
+		}
+	}
+	if fileExists(filepath.Join(dir, "Cargo.toml")) && cargoWorkspace(root) {
+		return true
+	}
+	return false
+}
+
+func checkName(rel, name string) string {
+	prefix := strings.NewReplacer("/", "-", ".", "-", " ", "-").Replace(strings.Trim(rel, "/."))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "-" + name
+}
+
+func hasCheck(checks []CommandSpec, name string) bool {
+	for _, check := range checks {
+		if check.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCommand(checks []CommandSpec, command string) bool {
+	for _, check := range checks {
+		if check.Command == command {
+			return true
+		}
+	}
+	return false
+}
+
+func goWorkUses(root string) []string {
+	data, err := os.ReadFile(filepath.Join(root, "go.work"))
+	if err != nil {
+		return nil
+	}
+	return parseGoWorkUses(string(data))
 }
 
 func parseGoWorkUses(raw string) []string {
@@ -88,3 +428,16 @@ func fileExists(path string) bool {
 	info, err := os.Stat(path)
 	return err == nil && !info.IsDir()
 }
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func readFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -39,3 +39,122 @@ func TestInferDefaultCommandsGoMod(t *testing.T) {
 		t.Fatalf("unexpected commands: %v", cmds)
 	}
 }
+
+func writeInferFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+}
+
+func TestInferDefaultChecksNodeScripts(t *testing.T) {
+	root := t.TempDir()
+	writeInferFiles(t, root, map[string]string{
+		"package.json":   `{"scripts":{"test":"vitest run","lint":"eslint .","typecheck":"tsc --noEmit","build":"vite build"}}`,
+		"pnpm-lock.yaml": "lockfileVersion: '9.0'\n",
+	})
+	checks := InferDefaultChecks(root)
+	want := []CommandSpec{
+		{Name: "lint", Command: "pnpm run lint"},
+		{Name: "typecheck", Command: "pnpm run typecheck"},
+		{Name: "test", Command: "pnpm test"},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("unexpected checks: %+v", checks)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Fatalf("unexpected checks: %+v", checks)
+		}
+	}
+}
+
+func TestInferDefaultChecksPythonAndCargo(t *testing.T) {
+	root := t.TempDir()
+	writeInferFiles(t, root, map[string]string{
+		"pyproject.toml": "[tool.ruff]\nline-length = 100\n\n[tool.pytest.ini_options]\n",
+	})
+	cmds := InferDefaultCommands(root)
+	if strings.Join(cmds, "|") != "ruff check .|python -m pytest" {
+		t.Fatalf("unexpected python commands: %v", cmds)
+	}
+
+	root = t.TempDir()
+	writeInferFiles(t, root, map[string]string{
+		"Cargo.toml":             "[workspace]\nmembers = [\"crates/*\"]\n",
+		"crates/core/Cargo.toml": "[package]\nname = \"core\"\n",
+	})
+	cmds = InferDefaultCommands(root)
+	if len(cmds) != 1 || cmds[0] != "cargo test --workspace" {
+		t.Fatalf("unexpected cargo commands: %v", cmds)
+	}
+}
+
+func TestInferDefaultChecksMakefileWins(t *testing.T) {
+	root := t.TempDir()
+	writeInferFiles(t, root, map[string]string{
+		"Makefile":         ".PHONY: test lint\nlint:\n\tgolangci-lint run\ntest:\n\tgo test ./...\n",
+		"go.mod":           "module example.com/demo\n",
+		"web/package.json": `{"scripts":{"test":"jest"}}`,
+	})
+	cmds := InferDefaultCommands(root)
+	if strings.Join(cmds, "|") != "make lint|make test" {
+		t.Fatalf("unexpected commands: %v", cmds)
+	}
+}
+
+func TestInferDefaultChecksPolyglotMonorepo(t *testing.T) {
+	root := t.TempDir()
+	writeInferFiles(t, root, map[string]string{
+		"services/api/go.mod":         "module example.com/api\n",
+		"web/package.json":            `{"scripts":{"test":"jest","lint":"eslint ."}}`,
+		"web/yarn.lock":               "",
+		"node_modules/x/package.json": `{"scripts":{"test":"nope"}}`,
+	})
+	checks := InferDefaultChecks(root)
+	got := map[string]string{}
+	for _, check := range checks {
+		got[check.Name] = check.Command
+	}
+	want := map[string]string{
+		"services-api-test": `cd "services/api" && go test ./...`,
+		"web-lint":          `cd "web" && yarn lint`,
+		"web-test":          `cd "web" && yarn test`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected checks: %+v", checks)
+	}
+	for name, cmd := range want {
+		if got[name] != cmd {
+			t.Fatalf("expected %s=%q, got %+v", name, cmd, checks)
+		}
+	}
+}
+
+func TestInferDefaultChecksGoAndNodeAtRoot(t *testing.T) {
+	root := t.TempDir()
+	writeInferFiles(t, root, map[string]string{
+		"go.mod":       "module example.com/demo\n",
+		"package.json": `{"scripts":{"test":"vitest run","lint":"eslint ."}}`,
+	})
+	checks := InferDefaultChecks(root)
+	want := []CommandSpec{
+		{Name: "lint", Command: "npm run lint"},
+		{Name: "node-test", Command: "npm test"},
+		{Name: "go-test", Command: "go test ./..."},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("unexpected checks: %+v", checks)
+	}
+	for i := range want {
+		if checks[i] != want[i] {
+			t.Fatalf("unexpected checks: %+v", checks)
+		}
+	}
+}
@@ -27,6 +27,18 @@ type Result struct {
 }
 
 func RunCommands(cmds []string, workdir string) (Result, error) {
+	return RunCommandsWith(cmds, func(command string) ([]byte, error) {
+		cmd := exec.Command("sh", "-c", command)
+		if workdir != "" {
+			cmd.Dir = workdir
+		}
+		return cmd.CombinedOutput()
+	})
+}
+
+// RunCommandsWith runs each command through run, which returns its combined
+// output, stopping at the first failure.
+func RunCommandsWith(cmds []string, run func(command string) ([]byte, error)) (Result, error) {
 	if len(cmds) == 0 {
 		return Result{}, errors.New("no commands provided")
 	}
@@ -36,11 +48,7 @@ func RunCommands(cmds []string, workdir string) (Result, error) {
 
 	for _, command := range cmds {
 		cmdStart := time.Now()
-		cmd := exec.Command("sh", "-c", command)
-		if workdir != "" {
-			cmd.Dir = workdir
-		}
-		output, err := cmd.CombinedOutput()
+		output, err := run(command)
 		code := 0
 		status := "pass"
 		if err != nil {
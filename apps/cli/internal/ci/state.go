@@ -232,6 +232,17 @@ func MarkRunCanceledByPID(pid int) error {
 	return WriteRun(run)
 }
 
+// MarkSetupAttempted records that the agent was asked for a checks config,
+// so later checkpoints do not ask again.
+func MarkSetupAttempted() error {
+	return writeTextFile("setup_attempted", time.Now().UTC().Format(time.RFC3339))
+}
+
+func SetupAttempted() bool {
+	value, err := readTextFile("setup_attempted")
+	return err == nil && value != ""
+}
+
 func ciPath(name string) (string, error) {
 	root, err := gitutil.RepoTopLevel()
 	if err != nil {
@@ -20,7 +20,10 @@ func newApplyCommand() Command {
 			fs, jsonOut := newFlagSet("apply")
 			checkpoint := fs.Bool("checkpoint", false, "Checkpoint after applying")
 			force := fs.Bool("force", false, "Apply even if suggestion is stale")
-			_ = fs.Parse(args)
+			var pathArgs, hunkArgs stringList
+			fs.Var(&pathArgs, "paths", "Only apply changes to these files (comma-separated, repeatable)")
+			fs.Var(&hunkArgs, "hunks", "Only apply these hunks, numbered as in 'jul show <id> --hunks' (e.g. 1,3 or 2-4)")
+			_ = fs.Parse(flagsFirst(args, "paths", "hunks"))
 
 			id := strings.TrimSpace(fs.Arg(0))
 			if id == "" {
@@ -66,12 +69,20 @@ func newApplyCommand() Command {
 					currentBase = draftSHA
 				}
 				message := fmt.Sprintf("Suggestion is stale (created for %s, current base is %s). Use --force to apply anyway.", sug.BaseCommitSHA, currentBase)
+				actions := []output.NextAction{
+					{Action: "force", Command: fmt.Sprintf("jul apply %s --force --json", id)},
+				}
+				if sug.Status == "conflicted" {
+					actions = append(actions, output.NextAction{Action: "regenerate", Command: fmt.Sprintf("jul review --regenerate %s --json", id)})
+				}
 				if *jsonOut {
-					_ = output.EncodeError(os.Stdout, "apply_suggestion_stale", message, []output.NextAction{
-						{Action: "force", Command: fmt.Sprintf("jul apply %s --force --json", id)},
-					})
+					_ = output.EncodeError(os.Stdout, "apply_suggestion_stale", message, actions)
 				} else {
 					fmt.Fprintf(os.Stderr, "Suggestion is stale (created for %s, current base is %s)\n", sug.BaseCommitSHA, currentBase)
+					if sug.ResolutionMessage != "" && sug.Status == "conflicted" {
+						fmt.Fprintf(os.Stderr, "It could not be rebased: %s\n", sug.ResolutionMessage)
+						fmt.Fprintf(os.Stderr, "Run 'jul review --regenerate %s' to have the agent redo it.\n", id)
+					}
 					fmt.Fprintln(os.Stderr, "Use --force to apply anyway.")
 				}
 				return 1
@@ -87,6 +98,29 @@ func newApplyCommand() Command {
 				return 1
 			}
 			filesChanged, _ := diffNameOnly(sug.BaseCommitSHA, sug.SuggestedCommitSHA)
+			status := "applied"
+			var selection *partialSelection
+			switch {
+			case len(pathArgs) > 0 || len(hunkArgs) > 0:
+				selection, err = selectSuggestionSubset(sug, patch, splitPathList(pathArgs), hunkArgs)
+			case sug.Status == "partially_applied":
+				selection, err = remainingSuggestionSubset(sug, patch)
+			}
+			if err != nil {
+				if *jsonOut {
+					_ = output.EncodeError(os.Stdout, "apply_selection_invalid", err.Error(), []output.NextAction{
+						{Action: "hunks", Command: fmt.Sprintf("jul show %s --hunks --json", id)},
+					})
+				} else {
+					fmt.Fprintln(os.Stderr, err.Error())
+				}
+				return 1
+			}
+			if selection != nil {
+				patch = selection.patch
+				filesChanged = selection.paths
+				status = selection.status
+			}
 			if err := applyPatch(patch, *force); err != nil {
 				if *jsonOut {
 					_ = output.EncodeError(os.Stdout, "apply_failed", fmt.Sprintf("failed to apply suggestion: %v", err), nil)
@@ -106,7 +140,12 @@ func newApplyCommand() Command {
 				return 1
 			}
 
-			if _, err := metadata.UpdateSuggestionStatus(id, "applied", ""); err != nil {
+			if selection != nil {
+				_, err = metadata.RecordSuggestionApply(id, status, selection.appliedPaths, selection.appliedHunks)
+			} else {
+				_, err = metadata.UpdateSuggestionStatus(id, status, "")
+			}
+			if err != nil {
 				if *jsonOut {
 					_ = output.EncodeError(os.Stdout, "apply_status_failed", fmt.Sprintf("failed to update suggestion status: %v", err), nil)
 				} else {
@@ -119,6 +158,11 @@ func newApplyCommand() Command {
 				SuggestionID: id,
 				Applied:      true,
 				FilesChanged: filesChanged,
+				Status:       status,
+			}
+			if selection != nil {
+				res.Hunks = selection.hunks
+				res.TotalHunks = selection.total
 			}
 			res.Draft = buildApplyDraft(syncRes.DraftSHA)
 			if *checkpoint {
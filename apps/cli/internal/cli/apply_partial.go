@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/output"
+)
+
+// patchFile is one file section of a suggestion patch: the git headers and
+// the hunks that follow them. Hunks are numbered across the whole patch,
+// starting at 1, in the order git diff prints them.
+type patchFile struct {
+	path   string
+	header string
+	hunks  []patchHunk
+}
+
+type patchHunk struct {
+	index int
+	text  string
+}
+
+func splitPatch(patch string) []patchFile {
+	var files []patchFile
+	var current *patchFile
+	var hunk *strings.Builder
+	next := 0
+	var header strings.Builder
+	flushHunk := func() {
+		if current != nil && hunk != nil {
+			current.hunks = append(current.hunks, patchHunk{index: next, text: hunk.String()})
+		}
+		hunk = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if current == nil {
+			return
+		}
+		if current.header == "" {
+			current.header = header.String()
+		}
+		files = append(files, *current)
+	}
+	oldPath := ""
+	for _, line := range strings.SplitAfter(patch, "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(trimmed, "diff --git "):
+			flushFile()
+			header.Reset()
+			header.WriteString(line)
+			current = &patchFile{path: diffGitPath(trimmed)}
+			oldPath = ""
+			continue
+		case current == nil:
+			continue
+		case strings.HasPrefix(trimmed, "@@ "):
+			flushHunk()
+			if current.header == "" {
+				current.header = header.String()
+			}
+			next++
+			hunk = &strings.Builder{}
+			hunk.WriteString(line)
+			continue
+		}
+		if hunk != nil {
+			hunk.WriteString(line)
+			continue
+		}
+		header.WriteString(line)
+		switch {
+		case strings.HasPrefix(trimmed, "--- "):
+			oldPath = strings.TrimPrefix(strings.TrimPrefix(trimmed, "--- "), "a/")
+		case strings.HasPrefix(trimmed, "+++ "):
+			path := strings.TrimPrefix(strings.TrimPrefix(trimmed, "+++ "), "b/")
+			if path == "/dev/null" {
+				path = oldPath
+			}
+			current.path = path
+		}
+	}
+	flushFile()
+	return files
+}
+
+func diffGitPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if idx := strings.LastIndex(rest, " b/"); idx >= 0 {
+		return rest[idx+3:]
+	}
+	return strings.TrimPrefix(rest, "a/")
+}
+
+func patchHunkCount(files []patchFile) int {
+	total := 0
+	for _, file := range files {
+		total += len(file.hunks)
+	}
+	return total
+}
+
+// partialSelection is the part of a suggestion picked by --paths/--hunks.
+// appliedPaths and appliedHunks add it to what earlier partial applies took.
+type partialSelection struct {
+	patch        string
+	paths        []string
+	hunks        []int
+	total        int
+	status       string
+	appliedPaths []string
+	appliedHunks []int
+}
+
+// selectSuggestionSubset narrows patch to the requested paths and hunks. The
+// suggestion becomes partially_applied until every file and hunk has been
+// taken.
+func selectSuggestionSubset(sug client.Suggestion, patch string, paths, hunkArgs []string) (*partialSelection, error) {
+	hunks, err := parseHunkList(hunkArgs)
+	if err != nil {
+		return nil, err
+	}
+	return selectSuggestionHunks(sug, splitPatch(patch), paths, hunks)
+}
+
+// remainingSuggestionSubset selects what earlier partial applies left out, so
+// a plain apply of a partially_applied suggestion finishes it instead of
+// replaying hunks that are already in the draft.
+func remainingSuggestionSubset(sug client.Suggestion, patch string) (*partialSelection, error) {
+	files := splitPatch(patch)
+	var paths []string
+	var hunks []int
+	for _, file := range files {
+		if len(file.hunks) == 0 {
+			if !slices.Contains(sug.AppliedPaths, file.path) {
+				paths = append(paths, file.path)
+			}
+			continue
+		}
+		for _, hunk := range file.hunks {
+			if !slices.Contains(sug.AppliedHunks, hunk.index) {
+				hunks = append(hunks, hunk.index)
+			}
+		}
+	}
+	return selectSuggestionHunks(sug, files, paths, hunks)
+}
+
+func selectSuggestionHunks(sug client.Suggestion, files []patchFile, paths []string, hunks []int) (*partialSelection, error) {
+	subset, selectedPaths, selectedHunks, err := selectPatch(files, paths, hunks)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(subset) == "" {
+		return nil, fmt.Errorf("nothing selected from suggestion %s", sug.SuggestionID)
+	}
+	sel := &partialSelection{
+		patch:        subset,
+		paths:        selectedPaths,
+		hunks:        selectedHunks,
+		total:        patchHunkCount(files),
+		appliedPaths: unionStrings(sug.AppliedPaths, selectedPaths),
+		appliedHunks: unionInts(sug.AppliedHunks, selectedHunks),
+		status:       "applied",
+	}
+	taken := map[int]bool{}
+	for _, index := range sel.appliedHunks {
+		taken[index] = true
+	}
+	for _, file := range files {
+		if len(file.hunks) == 0 && !slices.Contains(sel.appliedPaths, file.path) {
+			sel.status = "partially_applied"
+		}
+		for _, hunk := range file.hunks {
+			if !taken[hunk.index] {
+				sel.status = "partially_applied"
+			}
+		}
+	}
+	return sel, nil
+}
+
+func unionStrings(a, b []string) []string {
+	out := append([]string(nil), a...)
+	for _, value := range b {
+		if !slices.Contains(out, value) {
+			out = append(out, value)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func unionInts(a, b []int) []int {
+	seen := map[int]bool{}
+	var out []int
+	for _, value := range append(append([]int(nil), a...), b...) {
+		if !seen[value] {
+			seen[value] = true
+			out = append(out, value)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// suggestionHunks lists the numbered hunks of a suggestion for jul show.
+func suggestionHunks(baseSHA, suggestedSHA string) ([]output.ShowHunk, error) {
+	patch, err := suggestionPatch(baseSHA, suggestedSHA)
+	if err != nil {
+		return nil, err
+	}
+	var hunks []output.ShowHunk
+	for _, file := range splitPatch(patch) {
+		for _, hunk := range file.hunks {
+			hunks = append(hunks, output.ShowHunk{Index: hunk.index, Path: file.path, Patch: hunk.text})
+		}
+	}
+	return hunks, nil
+}
+
+// selectPatch keeps the files named in paths and the hunks numbered in
+// hunks. It returns the reduced patch with the files and hunk numbers it
+// covers; a file without hunks (binary, mode-only) can only be picked by path.
+func selectPatch(files []patchFile, paths []string, hunks []int) (string, []string, []int, error) {
+	wantPath := map[string]bool{}
+	for _, path := range paths {
+		wantPath[strings.TrimPrefix(path, "./")] = true
+	}
+	wantHunk := map[int]bool{}
+	total := patchHunkCount(files)
+	for _, index := range hunks {
+		if index < 1 || index > total {
+			return "", nil, nil, fmt.Errorf("hunk %d out of range (suggestion has %d)", index, total)
+		}
+		wantHunk[index] = true
+	}
+	seenPath := map[string]bool{}
+	var patch strings.Builder
+	var selectedPaths []string
+	var selectedHunks []int
+	for _, file := range files {
+		wholeFile := wantPath[file.path]
+		if wholeFile {
+			seenPath[file.path] = true
+		}
+		var body strings.Builder
+		picked := false
+		for _, hunk := range file.hunks {
+			if !wholeFile && !wantHunk[hunk.index] {
+				continue
+			}
+			body.WriteString(hunk.text)
+			selectedHunks = append(selectedHunks, hunk.index)
+			picked = true
+		}
+		if len(file.hunks) == 0 && wholeFile {
+			picked = true
+		}
+		if !picked {
+			continue
+		}
+		patch.WriteString(file.header)
+		patch.WriteString(body.String())
+		selectedPaths = append(selectedPaths, file.path)
+	}
+	for path := range wantPath {
+		if !seenPath[path] {
+			return "", nil, nil, fmt.Errorf("suggestion does not change %s", path)
+		}
+	}
+	return patch.String(), selectedPaths, selectedHunks, nil
+}
+
+// parseHunkList parses "1,3" and "2-4" style hunk selections.
+func parseHunkList(values []string) ([]int, error) {
+	seen := map[int]bool{}
+	var out []int
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			first, last := part, part
+			if idx := strings.Index(part, "-"); idx > 0 {
+				first, last = part[:idx], part[idx+1:]
+			}
+			lo, err := strconv.Atoi(strings.TrimSpace(first))
+			if err != nil {
+				return nil, fmt.Errorf("invalid hunk %q", part)
+			}
+			hi, err := strconv.Atoi(strings.TrimSpace(last))
+			if err != nil || hi < lo {
+				return nil, fmt.Errorf("invalid hunk range %q", part)
+			}
+			for i := lo; i <= hi; i++ {
+				if !seen[i] {
+					seen[i] = true
+					out = append(out, i)
+				}
+			}
+		}
+	}
+	sort.Ints(out)
+	return out, nil
+}
+
+func splitPathList(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/metadata"
+	"github.com/lydakis/jul/cli/internal/output"
+)
+
+func TestApplyHunksAndPathsRecordPartialApply(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+
+	long := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	writeFilePath(t, repo, "a.txt", long)
+	writeFilePath(t, repo, "b.txt", "bee\n")
+	runGitCmd(t, repo, "add", ".")
+	runGitCmd(t, repo, "commit", "-m", "base")
+	baseSHA := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+
+	writeFilePath(t, repo, "a.txt", strings.Replace(strings.Replace(long, "2\n", "two\n", 1), "11\n", "eleven\n", 1))
+	writeFilePath(t, repo, "b.txt", "BEE\n")
+	runGitCmd(t, repo, "commit", "-am", "suggested")
+	suggestedSHA := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+	runGitCmd(t, repo, "reset", "--hard", baseSHA)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+
+	sug, err := metadata.CreateSuggestion(metadata.SuggestionCreate{
+		ChangeID:           "I4444444444444444444444444444444444444444",
+		BaseCommitSHA:      baseSHA,
+		SuggestedCommitSHA: suggestedSHA,
+		CreatedBy:          "tester",
+		Reason:             "naming",
+	})
+	if err != nil {
+		t.Fatalf("CreateSuggestion failed: %v", err)
+	}
+
+	out := captureStdout(t, func() int {
+		return newShowCommand().Run([]string{sug.SuggestionID, "--hunks", "--json"})
+	})
+	var show output.ShowResult
+	if err := json.Unmarshal([]byte(out), &show); err != nil {
+		t.Fatalf("failed to decode show output: %v\n%s", err, out)
+	}
+	if len(show.Hunks) != 3 || show.Hunks[1].Path != "a.txt" || !strings.Contains(show.Hunks[1].Patch, "+eleven") || show.Hunks[2].Path != "b.txt" {
+		t.Fatalf("expected three numbered hunks, got %+v", show.Hunks)
+	}
+
+	out = captureStdout(t, func() int {
+		return newApplyCommand().Run([]string{sug.SuggestionID, "--hunks", "2", "--json"})
+	})
+	var res output.ApplyResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("failed to decode apply output: %v\n%s", err, out)
+	}
+	if res.Status != "partially_applied" || len(res.Hunks) != 1 || res.Hunks[0] != 2 || res.TotalHunks != 3 {
+		t.Fatalf("unexpected apply result: %+v", res)
+	}
+	data, _ := os.ReadFile(filepath.Join(repo, "a.txt"))
+	if !strings.Contains(string(data), "eleven") || strings.Contains(string(data), "two") {
+		t.Fatalf("expected only hunk 2 applied, got:\n%s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, "b.txt")); string(data) != "bee\n" {
+		t.Fatalf("expected b.txt untouched, got %q", data)
+	}
+	updated, _, _ := metadata.GetSuggestionByID(sug.SuggestionID)
+	if updated.Status != "partially_applied" || len(updated.AppliedHunks) != 1 || updated.AppliedPaths[0] != "a.txt" {
+		t.Fatalf("expected subset recorded on note, got %+v", updated)
+	}
+
+	out = captureStdout(t, func() int {
+		return newApplyCommand().Run([]string{sug.SuggestionID, "--paths", "b.txt", "--hunks", "1", "--json"})
+	})
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("failed to decode apply output: %v\n%s", err, out)
+	}
+	updated, _, _ = metadata.GetSuggestionByID(sug.SuggestionID)
+	if updated.Status != "applied" || len(updated.AppliedHunks) != 3 {
+		t.Fatalf("expected suggestion fully applied after remaining hunks, got %+v", updated)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, "a.txt")); !strings.Contains(string(data), "two") {
+		t.Fatalf("expected hunk 1 applied, got:\n%s", data)
+	}
+}
+
+func TestPlainApplyFinishesPartiallyAppliedSuggestion(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+
+	long := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	writeFilePath(t, repo, "a.txt", long)
+	runGitCmd(t, repo, "add", ".")
+	runGitCmd(t, repo, "commit", "-m", "base")
+	baseSHA := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+
+	writeFilePath(t, repo, "a.txt", strings.Replace(strings.Replace(long, "2\n", "two\n", 1), "11\n", "eleven\n", 1))
+	runGitCmd(t, repo, "commit", "-am", "suggested")
+	suggestedSHA := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+	runGitCmd(t, repo, "reset", "--hard", baseSHA)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+
+	sug, err := metadata.CreateSuggestion(metadata.SuggestionCreate{
+		ChangeID:           "I5555555555555555555555555555555555555555",
+		BaseCommitSHA:      baseSHA,
+		SuggestedCommitSHA: suggestedSHA,
+		CreatedBy:          "tester",
+		Reason:             "naming",
+	})
+	if err != nil {
+		t.Fatalf("CreateSuggestion failed: %v", err)
+	}
+
+	_ = captureStdout(t, func() int {
+		return newApplyCommand().Run([]string{sug.SuggestionID, "--hunks", "1", "--json"})
+	})
+	out := captureStdout(t, func() int {
+		return newApplyCommand().Run([]string{sug.SuggestionID, "--json"})
+	})
+	var res output.ApplyResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("failed to decode apply output: %v\n%s", err, out)
+	}
+	if res.Status != "applied" || len(res.Hunks) != 1 || res.Hunks[0] != 2 {
+		t.Fatalf("expected only the remaining hunk applied, got %+v", res)
+	}
+	data, _ := os.ReadFile(filepath.Join(repo, "a.txt"))
+	if strings.Count(string(data), "two") != 1 || !strings.Contains(string(data), "eleven") {
+		t.Fatalf("expected both hunks applied once, got:\n%s", data)
+	}
+	updated, _, _ := metadata.GetSuggestionByID(sug.SuggestionID)
+	if updated.Status != "applied" || len(updated.AppliedHunks) != 2 {
+		t.Fatalf("expected suggestion applied, got %+v", updated)
+	}
+}
+
+func TestSelectPatchRejectsUnknownSelections(t *testing.T) {
+	files := splitPatch("diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n")
+	if _, _, _, err := selectPatch(files, nil, []int{2}); err == nil {
+		t.Fatalf("expected out of range hunk to fail")
+	}
+	if _, _, _, err := selectPatch(files, []string{"y"}, nil); err == nil {
+		t.Fatalf("expected unknown path to fail")
+	}
+	if _, err := parseHunkList([]string{"3-1"}); err == nil {
+		t.Fatalf("expected invalid range to fail")
+	}
+	hunks, err := parseHunkList([]string{"1,3", "2-3"})
+	if err != nil || len(hunks) != 3 {
+		t.Fatalf("expected 1,2,3, got %v (%v)", hunks, err)
+	}
+}
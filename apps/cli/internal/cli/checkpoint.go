@@ -24,6 +24,18 @@ func newCheckpointCommand() Command {
 		Name:    "checkpoint",
 		Summary: "Record a checkpoint for the current commit",
 		Run: func(args []string) int {
+			if jsonFlag, rest := stripJSONFlag(args); len(rest) > 0 {
+				subArgs := rest[1:]
+				if jsonFlag {
+					subArgs = ensureJSONFlag(subArgs)
+				}
+				switch rest[0] {
+				case "squash":
+					return runCheckpointSquash(subArgs)
+				case "split":
+					return runCheckpointSplit(subArgs)
+				}
+			}
 			fs, jsonOut := newFlagSet("checkpoint")
 			message := fs.String("m", "", "Checkpoint message")
 			prompt := fs.String("prompt", "", "Attach prompt metadata via trace")
@@ -120,6 +132,15 @@ func newCheckpointCommand() Command {
 				_, _ = updateStatusCacheForCheckpoint(repoRoot, res)
 			}
 
+			retargeted, err := retargetStaleSuggestions()
+			if err != nil && !*jsonOut {
+				fmt.Fprintf(os.Stderr, "failed to rebase stale suggestions: %v\n", err)
+			}
+
+			if config.CIRunOnCheckpoint() && !skipCI {
+				maybeSetupCIOnCheckpoint(*jsonOut, stream)
+			}
+
 			var ciRun *ciRun
 			var reviewRun *reviewRun
 			if config.CIRunOnCheckpoint() && !skipCI {
@@ -134,7 +155,7 @@ func newCheckpointCommand() Command {
 			}
 
 			if config.ReviewEnabled() && config.ReviewRunOnCheckpoint() && !skipReview {
-				run, err := startBackgroundReview(reviewModeSuggest, "")
+				run, err := startBackgroundReview(reviewModeSuggest, "", "", nil, false)
 				if err != nil {
 					if !*jsonOut && !errors.Is(err, agent.ErrAgentNotConfigured) && !errors.Is(err, agent.ErrBundledMissing) {
 						fmt.Fprintf(os.Stderr, "failed to start review: %v\n", err)
@@ -208,6 +229,7 @@ func newCheckpointCommand() Command {
 			}
 
 			output.RenderCheckpoint(os.Stdout, res)
+			renderRetargetedSuggestions(retargeted)
 			if !watch {
 				if ciRun != nil {
 					fmt.Fprintln(os.Stdout, "  ⚡ CI running in background... (jul ci status)")
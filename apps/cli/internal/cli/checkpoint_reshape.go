@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/output"
+	remotesel "github.com/lydakis/jul/cli/internal/remote"
+	"github.com/lydakis/jul/cli/internal/restack"
+)
+
+func runCheckpointSquash(args []string) int {
+	jsonOut, args := stripJSONFlag(args)
+	fs, _ := newFlagSet("checkpoint squash")
+	message := fs.String("m", "", "Message for the squashed checkpoint")
+	rangeArg, args := splitLeadingArg(args)
+	_ = fs.Parse(args)
+	if rangeArg == "" {
+		rangeArg = strings.TrimSpace(fs.Arg(0))
+	}
+	from, to, ok := strings.Cut(rangeArg, "..")
+	if !ok || strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+		return commandError(jsonOut, "checkpoint_squash_invalid_args", "usage: jul checkpoint squash <from>..<to>", nil)
+	}
+	opts, err := reshapeOptions(*message)
+	if err != nil {
+		return commandError(jsonOut, "checkpoint_squash_failed", err.Error(), nil)
+	}
+	res, err := restack.Squash(opts, strings.TrimSpace(from), strings.TrimSpace(to))
+	if err != nil {
+		return commandError(jsonOut, "checkpoint_squash_failed", fmt.Sprintf("squash failed: %v", err), nil)
+	}
+	return finishCheckpointReshape("squash", res, jsonOut)
+}
+
+func runCheckpointSplit(args []string) int {
+	jsonOut, args := stripJSONFlag(args)
+	fs, _ := newFlagSet("checkpoint split")
+	message := fs.String("m", "", "Message for the checkpoint holding the split paths")
+	var paths stringList
+	fs.Var(&paths, "paths", "Paths to move into their own checkpoint (repeatable, comma-separated)")
+	sha, args := splitLeadingArg(args)
+	_ = fs.Parse(args)
+	rest := fs.Args()
+	if sha == "" && len(rest) > 0 {
+		sha, rest = strings.TrimSpace(rest[0]), rest[1:]
+	}
+	selected := make([]string, 0, len(paths)+len(rest))
+	for _, value := range append([]string(paths), rest...) {
+		for _, path := range strings.Split(value, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				selected = append(selected, path)
+			}
+		}
+	}
+	if sha == "" || len(selected) == 0 {
+		return commandError(jsonOut, "checkpoint_split_invalid_args", "usage: jul checkpoint split <sha> --paths <path>[,<path>...]", nil)
+	}
+	opts, err := reshapeOptions(*message)
+	if err != nil {
+		return commandError(jsonOut, "checkpoint_split_failed", err.Error(), nil)
+	}
+	res, err := restack.Split(opts, sha, selected)
+	if err != nil {
+		return commandError(jsonOut, "checkpoint_split_failed", fmt.Sprintf("split failed: %v", err), nil)
+	}
+	return finishCheckpointReshape("split", res, jsonOut)
+}
+
+// splitLeadingArg takes a leading positional argument off args so flags may
+// follow it; --json is stripped beforehand since the dispatcher prepends it.
+func splitLeadingArg(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return strings.TrimSpace(args[0]), args[1:]
+	}
+	return "", args
+}
+
+func reshapeOptions(message string) (restack.ReshapeOptions, error) {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return restack.ReshapeOptions{}, err
+	}
+	user, ws := workspaceParts()
+	return restack.ReshapeOptions{
+		RepoRoot:  repoRoot,
+		User:      user,
+		Workspace: ws,
+		Message:   message,
+	}, nil
+}
+
+func finishCheckpointReshape(action string, res restack.ReshapeResult, jsonOut bool) int {
+	if repoRoot, err := gitutil.RepoTopLevel(); err == nil {
+		_, _ = refreshStatusCache(repoRoot)
+	}
+	out := output.CheckpointReshapeOutput{
+		Status:         "ok",
+		Action:         action,
+		ChangeID:       res.ChangeID,
+		Rewritten:      res.Rewritten,
+		OldCheckpoints: res.OldCheckpoints,
+		NewCheckpoints: res.NewCheckpoints,
+		DraftSHA:       res.NewDraftSHA,
+	}
+	if jsonOut {
+		if code := writeJSON(out); code != 0 {
+			return code
+		}
+	} else {
+		output.RenderCheckpointReshape(os.Stdout, out, output.DefaultOptions())
+	}
+	if err := pushReshapedRefs(res); err != nil {
+		return commandError(jsonOut, "checkpoint_push_failed", err.Error(), nil)
+	}
+	return 0
+}
+
+// pushReshapedRefs publishes the rewritten change ref, and the anchor when the
+// first checkpoint was replaced.
+func pushReshapedRefs(res restack.ReshapeResult) error {
+	if err := pushRestackedRefs(res.ChangeID, res.NewCheckpoints); err != nil {
+		return err
+	}
+	if len(res.NewCheckpoints) == 0 || len(res.OldCheckpoints) == 0 || res.NewCheckpoints[0] == res.OldCheckpoints[0] {
+		return nil
+	}
+	remote, err := remotesel.Resolve()
+	if err != nil || strings.TrimSpace(remote.Name) == "" {
+		return nil
+	}
+	if err := pushRef(remote.Name, res.NewCheckpoints[0], anchorRef(res.ChangeID), true); err != nil {
+		return fmt.Errorf("failed to push anchor ref: %v", err)
+	}
+	return nil
+}
@@ -70,7 +70,7 @@ func runCIRun(args []string) int {
 func runCIRunWithStream(args []string, stream io.Writer, out io.Writer, errOut io.Writer, targetSHA string, mode string) int {
 	fs, jsonOut := newFlagSetWithOutput("ci run", out)
 	var commands stringList
-	fs.Var(&commands, "cmd", "Command to run (repeatable). Default: go test ./...")
+	fs.Var(&commands, "cmd", "Command to run (repeatable). Default: .jul/ci.toml or inferred checks")
 	attType := fs.String("type", "ci", "Attestation type")
 	target := fs.String("target", "", "Revision to attach results to (default: current draft)")
 	commitSHA := fs.String("commit", "", "Alias for --target")
@@ -125,17 +125,7 @@ func runCIRunWithStream(args []string, stream io.Writer, out io.Writer, errOut i
 	}
 
 	if len(cmds) == 0 {
-		if cfg, ok, err := cicmd.LoadConfig(); err == nil && ok && len(cfg.Commands) > 0 {
-			for _, cmd := range cfg.Commands {
-				if strings.TrimSpace(cmd.Command) == "" {
-					continue
-				}
-				cmds = append(cmds, cmd.Command)
-			}
-		}
-	}
-	if len(cmds) == 0 {
-		cmds = cicmd.InferDefaultCommands(workdir)
+		cmds = ciCommands(workdir)
 	}
 	deviceID, err := config.DeviceID()
 	if err != nil {
@@ -261,11 +251,29 @@ func runCIRunWithStream(args []string, stream io.Writer, out io.Writer, errOut i
 	return exitCodeForStatus(result.Status)
 }
 
+// ciCommands returns the checks from .jul/ci.toml, or the defaults inferred
+// for workdir when none are configured.
+func ciCommands(workdir string) []string {
+	var cmds []string
+	if cfg, ok, err := cicmd.LoadConfig(); err == nil && ok && len(cfg.Commands) > 0 {
+		for _, cmd := range cfg.Commands {
+			if strings.TrimSpace(cmd.Command) == "" {
+				continue
+			}
+			cmds = append(cmds, cmd.Command)
+		}
+	}
+	if len(cmds) == 0 {
+		cmds = cicmd.InferDefaultCommands(workdir)
+	}
+	return cmds
+}
+
 func printCIUsage() {
 	fmt.Fprintln(os.Stdout, "Usage: jul ci run [--cmd <command>] [--watch] [--type ci] [--coverage-line <pct>] [--coverage-branch <pct>] [--target <rev>] [--change <id>] [--json]")
 	fmt.Fprintln(os.Stdout, "       jul ci status [--json]")
 	fmt.Fprintln(os.Stdout, "       jul ci list [--limit N] [--json]")
-	fmt.Fprintln(os.Stdout, "       jul ci config [--init] [--set name=cmd] [--show] [--json]")
+	fmt.Fprintln(os.Stdout, "       jul ci config [--init [--agent [--unconfined]]] [--set name=cmd] [--show] [--json]")
 	fmt.Fprintln(os.Stdout, "       jul ci cancel [--json]")
 }
 
@@ -274,13 +282,12 @@ func inferCIStatuses(commands []string, overall string) (string, string) {
 	compileStatus := ""
 	for _, cmd := range commands {
 		normalized := strings.ToLower(strings.TrimSpace(cmd))
-		if strings.Contains(normalized, "go test") || strings.Contains(normalized, "pytest") ||
-			strings.Contains(normalized, "npm test") || strings.Contains(normalized, "yarn test") ||
-			strings.Contains(normalized, "pnpm test") {
+		if label := output.LabelForCommand(cmd); label == "test" || strings.HasPrefix(label, "test (") {
 			testStatus = overall
 			compileStatus = overall
 		}
-		if strings.Contains(normalized, "go build") || strings.Contains(normalized, "go test") {
+		if strings.Contains(normalized, "go build") || strings.Contains(normalized, "cargo build") ||
+			strings.Contains(normalized, "go test") || strings.Contains(normalized, "cargo test") {
 			if compileStatus == "" {
 				compileStatus = overall
 			}
@@ -343,7 +350,9 @@ func runCIStatus(args []string) int {
 
 func runCIConfig(args []string) int {
 	fs, jsonOut := newFlagSet("ci config")
-	initCfg := fs.Bool("init", false, "Create a default .jul/ci.toml if missing")
+	initCfg := fs.Bool("init", false, "Create .jul/ci.toml from the inferred checks if missing")
+	useAgent := fs.Bool("agent", false, "With --init, let the agent propose checks and keep those that pass a dry run")
+	unconfined := fs.Bool("unconfined", false, "With --agent, dry-run proposed checks even when the agent sandbox is disabled")
 	showCfg := fs.Bool("show", false, "Show resolved commands")
 	var sets stringList
 	fs.Var(&sets, "set", "Add command (name=command)")
@@ -362,6 +371,13 @@ func runCIConfig(args []string) int {
 		return writeCIConfigOutput(out, *jsonOut)
 	}
 
+	if *unconfined && !*useAgent {
+		return commandError(*jsonOut, "ci_config_invalid_args", "--unconfined requires --agent", nil)
+	}
+	if *useAgent && (!*initCfg || len(sets) > 0) {
+		return commandError(*jsonOut, "ci_config_invalid_args", "--agent requires --init and cannot be combined with --set", nil)
+	}
+
 	if *initCfg || len(sets) > 0 {
 		if *initCfg && len(sets) == 0 {
 			if path, err := cicmd.ConfigPath(); err == nil {
@@ -371,6 +387,13 @@ func runCIConfig(args []string) int {
 				}
 			}
 		}
+		if *useAgent {
+			out, err := setupCIWithAgent(watchStream(*jsonOut, os.Stdout, os.Stderr), *unconfined)
+			if err != nil {
+				return commandError(*jsonOut, "ci_config_agent_failed", fmt.Sprintf("checks setup failed: %v", err), nil)
+			}
+			return writeCIConfigOutput(out, *jsonOut)
+		}
 		commands := []cicmd.CommandSpec{}
 		if len(sets) > 0 {
 			for i, raw := range sets {
@@ -388,6 +411,8 @@ func runCIConfig(args []string) int {
 				}
 				commands = append(commands, cicmd.CommandSpec{Name: name, Command: cmd})
 			}
+		} else if root, err := gitutil.RepoTopLevel(); err == nil {
+			commands = cicmd.InferDefaultChecks(root)
 		} else {
 			commands = []cicmd.CommandSpec{{Name: "test", Command: "go test ./..."}}
 		}
@@ -434,7 +459,7 @@ func buildCIConfigResolvedOutput() (ciConfigOutput, error) {
 		cmds = append(cmds, formatCICommandSpecs(cfg.Commands)...)
 		source = ".jul/ci.toml"
 	} else if err == nil {
-		cmds = cicmd.InferDefaultCommands(root)
+		cmds = formatCICommandSpecs(cicmd.InferDefaultChecks(root))
 	} else {
 		return ciConfigOutput{}, err
 	}
@@ -454,6 +479,7 @@ type ciConfigOutput struct {
 	DraftBlocking   *bool    `json:"draft_ci_blocking,omitempty"`
 	Source          string   `json:"source,omitempty"`
 	Commands        []string `json:"commands,omitempty"`
+	Rejected        []string `json:"rejected,omitempty"`
 	Resolved        bool     `json:"resolved,omitempty"`
 }
 
@@ -481,7 +507,11 @@ func resolveCIConfigCommands() (string, []string, error) {
 	} else if err != nil {
 		return "", nil, err
 	}
-	return "default", []string{"go test ./..."}, nil
+	root, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return "", nil, err
+	}
+	return "inferred", formatCICommandSpecs(cicmd.InferDefaultChecks(root)), nil
 }
 
 func formatCICommandSpecs(cmds []cicmd.CommandSpec) []string {
@@ -510,6 +540,12 @@ func writeCIConfigOutput(out ciConfigOutput, jsonOut bool) int {
 func renderCIConfigOutput(out ciConfigOutput) {
 	if out.Message != "" {
 		fmt.Fprintln(os.Stdout, out.Message)
+		for _, cmd := range out.Commands {
+			fmt.Fprintf(os.Stdout, "  - %s\n", cmd)
+		}
+		for _, cmd := range out.Rejected {
+			fmt.Fprintf(os.Stdout, "  skipped %s\n", cmd)
+		}
 		return
 	}
 	if out.Resolved {
@@ -535,10 +571,6 @@ func renderCIConfigOutput(out ciConfigOutput) {
 	if out.DraftBlocking != nil {
 		fmt.Fprintf(os.Stdout, "  draft_ci_blocking: %t\n", *out.DraftBlocking)
 	}
-	if out.Source == "default" && len(out.Commands) > 0 {
-		fmt.Fprintf(os.Stdout, "  default command: %s\n", out.Commands[0])
-		return
-	}
 	if len(out.Commands) > 0 {
 		label := "commands"
 		if out.Source != "" {
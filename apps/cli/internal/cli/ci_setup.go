@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/agent"
+	cicmd "github.com/lydakis/jul/cli/internal/ci"
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+const (
+	ciSetupLayoutLimit   = 400
+	ciSetupManifestLimit = 20
+)
+
+// ciManifestNames are build and tooling files that tell the agent how a
+// project is checked.
+var ciManifestNames = map[string]bool{
+	"go.mod": true, "go.work": true, "Makefile": true, "GNUmakefile": true, "justfile": true, "Taskfile.yml": true,
+	"package.json": true, "deno.json": true, "tsconfig.json": true,
+	"pyproject.toml": true, "setup.py": true, "setup.cfg": true, "requirements.txt": true, "tox.ini": true, "noxfile.py": true,
+	"Cargo.toml": true, "pom.xml": true, "build.gradle": true, "build.gradle.kts": true,
+	"Gemfile": true, "Rakefile": true, "composer.json": true, "mix.exs": true, "CMakeLists.txt": true,
+	"pubspec.yaml": true, "Package.swift": true,
+}
+
+// setupCIWithAgent sends the repo layout and manifests at the review base to
+// the agent's setup_ci action, dry-runs each proposed check in the agent
+// worktree and writes .jul/ci.toml with the ones that succeed. The dry runs
+// execute agent-written commands, so without the agent sandbox they need
+// unconfined.
+func setupCIWithAgent(stream io.Writer, unconfined bool) (ciConfigOutput, error) {
+	baseSHA, changeID, err := reviewBase()
+	if err != nil {
+		return ciConfigOutput{}, err
+	}
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return ciConfigOutput{}, err
+	}
+	provider, err := agent.ResolveProvider()
+	if err != nil {
+		return ciConfigOutput{}, err
+	}
+	if !provider.Sandbox.Enabled && !unconfined {
+		return ciConfigOutput{}, fmt.Errorf("the agent sandbox is disabled, so proposed checks would run unconfined; enable [sandbox] in %s or pass --unconfined", config.AgentConfigPath())
+	}
+	lease, err := agent.AcquireWorktree(repoRoot, baseSHA, "setup_ci")
+	if err != nil {
+		return ciConfigOutput{}, err
+	}
+	defer lease.Release()
+	worktree := lease.Worktree
+	layout, manifests, err := ciSetupContext(baseSHA)
+	if err != nil {
+		return ciConfigOutput{}, err
+	}
+	req := agent.ReviewRequest{
+		Version:       1,
+		Action:        "setup_ci",
+		WorkspacePath: worktree,
+		Context: agent.ReviewContext{
+			Checkpoint: baseSHA,
+			ChangeID:   changeID,
+			Files:      manifests,
+			Layout:     layout,
+		},
+	}
+	resp, err := agent.RunReviewWithStream(context.Background(), provider, req, stream)
+	if err != nil {
+		return ciConfigOutput{}, err
+	}
+	if len(resp.Checks) == 0 {
+		return ciConfigOutput{}, fmt.Errorf("agent proposed no checks")
+	}
+
+	var accepted []cicmd.CommandSpec
+	var rejected []string
+	for i, check := range resp.Checks {
+		command := strings.TrimSpace(check.Command)
+		if command == "" {
+			continue
+		}
+		name := strings.TrimSpace(check.Name)
+		if name == "" {
+			name = fmt.Sprintf("cmd%d", i+1)
+		}
+		if stream != nil {
+			fmt.Fprintf(stream, "trying %s: %s\n", name, command)
+		}
+		if out, err := agent.RunInWorktree(context.Background(), provider, worktree, command); err != nil {
+			rejected = append(rejected, fmt.Sprintf("%s: %s (%s)", name, command, dryRunFailure(err, out)))
+			continue
+		}
+		accepted = append(accepted, cicmd.CommandSpec{Name: name, Command: command})
+	}
+	if len(accepted) == 0 {
+		return ciConfigOutput{}, fmt.Errorf("none of the proposed checks succeeded: %s", strings.Join(rejected, "; "))
+	}
+	if err := cicmd.WriteConfig(accepted); err != nil {
+		return ciConfigOutput{}, err
+	}
+	return ciConfigOutput{
+		Status:   "ok",
+		Message:  "CI configuration saved to .jul/ci.toml",
+		Source:   "agent",
+		Commands: formatCICommandSpecs(accepted),
+		Rejected: rejected,
+	}, nil
+}
+
+// dryRunFailure describes a failed dry run by exit code and the last line
+// of its output.
+func dryRunFailure(err error, out []byte) string {
+	reason := err.Error()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		reason = fmt.Sprintf("exit %d", exitErr.ExitCode())
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		if len(last) > 200 {
+			last = last[:200]
+		}
+		reason += ": " + last
+	}
+	return reason
+}
+
+// ciSetupContext lists the tracked files at sha (capped) and returns the
+// manifests near the repo root with their content.
+func ciSetupContext(sha string) ([]string, []agent.ReviewFile, error) {
+	out, err := gitutil.Git("ls-tree", "-r", "--name-only", sha)
+	if err != nil {
+		return nil, nil, err
+	}
+	var layout []string
+	var manifests []agent.ReviewFile
+	total := 0
+	for _, p := range strings.Split(out, "\n") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		total++
+		if len(layout) < ciSetupLayoutLimit {
+			layout = append(layout, p)
+		}
+		if len(manifests) >= ciSetupManifestLimit || !isCIManifest(p) {
+			continue
+		}
+		content, err := reviewFileContent(sha, p)
+		if err != nil {
+			continue
+		}
+		manifests = append(manifests, agent.ReviewFile{Path: p, Content: content})
+	}
+	if total > len(layout) {
+		layout = append(layout, fmt.Sprintf("... %d more files", total-len(layout)))
+	}
+	return layout, manifests, nil
+}
+
+func isCIManifest(p string) bool {
+	if strings.HasPrefix(p, ".github/workflows/") {
+		return strings.Count(p, "/") == 2
+	}
+	return strings.Count(p, "/") <= 1 && ciManifestNames[path.Base(p)]
+}
+
+// maybeSetupCIOnCheckpoint runs setup_ci once, on the first checkpoint that
+// finds no .jul/ci.toml. It blocks the checkpoint so the checks it writes
+// are the ones the checkpoint's background run uses, and never dry-runs
+// outside the agent sandbox.
+func maybeSetupCIOnCheckpoint(jsonOut bool, stream io.Writer) {
+	if !config.CISetupOnCheckpoint() || cicmd.SetupAttempted() {
+		return
+	}
+	if _, ok, err := cicmd.LoadConfig(); err != nil || ok {
+		return
+	}
+	if provider, err := agent.ResolveProvider(); err != nil || !provider.Sandbox.Enabled {
+		return
+	}
+	if !jsonOut {
+		fmt.Fprintln(os.Stderr, "No checks configuration found. Asking the agent to propose one...")
+	}
+	out, err := setupCIWithAgent(stream, false)
+	_ = cicmd.MarkSetupAttempted()
+	if jsonOut {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "checks setup skipped: %v\n", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, out.Message)
+	for _, cmd := range out.Commands {
+		fmt.Fprintf(os.Stderr, "  - %s\n", cmd)
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cicmd "github.com/lydakis/jul/cli/internal/ci"
+)
+
+func TestSetupCIWithAgentKeepsChecksThatPass(t *testing.T) {
+	repo := t.TempDir()
+	runGitTest(t, repo, "init")
+	runGitTest(t, repo, "config", "user.name", "Test User")
+	runGitTest(t, repo, "config", "user.email", "test@example.com")
+	for name, content := range map[string]string{
+		"package.json":         `{"scripts":{"test":"node test.js"}}`,
+		"src/index.js":         "module.exports = 1\n",
+		"src/deep/Makefile":    "all:\n",
+		".github/workflows/ci": "on: push\n",
+	} {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+	}
+	runGitTest(t, repo, "add", ".")
+	runGitTest(t, repo, "commit", "-m", "initial")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+
+	request := filepath.Join(t.TempDir(), "request.json")
+	script := filepath.Join(t.TempDir(), "agent.sh")
+	body := `#!/bin/sh
+cat > "$SETUP_REQUEST"
+echo '{"version":1,"status":"completed","checks":[{"name":"test","command":"test -f package.json"},{"name":"lint","command":"exit 3"}]}'
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write agent failed: %v", err)
+	}
+	t.Setenv("JUL_AGENT_CMD", script)
+	t.Setenv("SETUP_REQUEST", request)
+
+	out, err := setupCIWithAgent(nil, true)
+	if err != nil {
+		t.Fatalf("setupCIWithAgent failed: %v", err)
+	}
+	if len(out.Commands) != 1 || len(out.Rejected) != 1 || !strings.Contains(out.Rejected[0], "exit 3") {
+		t.Fatalf("expected one accepted and one rejected check, got %+v", out)
+	}
+	cfg, ok, err := cicmd.LoadConfig()
+	if err != nil || !ok {
+		t.Fatalf("expected .jul/ci.toml, got ok=%v err=%v", ok, err)
+	}
+	if len(cfg.Commands) != 1 || cfg.Commands[0].Name != "test" || cfg.Commands[0].Command != "test -f package.json" {
+		t.Fatalf("unexpected checks config: %+v", cfg.Commands)
+	}
+
+	data, err := os.ReadFile(request)
+	if err != nil {
+		t.Fatalf("read agent request failed: %v", err)
+	}
+	payload := string(data)
+	if !strings.Contains(payload, `"action":"setup_ci"`) || !strings.Contains(payload, `"src/index.js"`) {
+		t.Fatalf("expected setup_ci request with layout, got %s", payload)
+	}
+	if !strings.Contains(payload, `node test.js`) || !strings.Contains(payload, `on: push`) {
+		t.Fatalf("expected manifest contents in request, got %s", payload)
+	}
+	if strings.Contains(payload, `"path":"src/deep/Makefile"`) {
+		t.Fatalf("expected nested manifests to be skipped, got %s", payload)
+	}
+}
+
+func TestSetupCIWithAgentRefusesUnconfinedDryRun(t *testing.T) {
+	repo := t.TempDir()
+	runGitTest(t, repo, "init")
+	runGitTest(t, repo, "config", "user.name", "Test User")
+	runGitTest(t, repo, "config", "user.email", "test@example.com")
+	writeFilePath(t, repo, "package.json", `{"scripts":{"test":"node test.js"}}`)
+	runGitTest(t, repo, "add", ".")
+	runGitTest(t, repo, "commit", "-m", "initial")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+
+	marker := filepath.Join(t.TempDir(), "ran")
+	script := filepath.Join(t.TempDir(), "agent.sh")
+	body := `#!/bin/sh
+touch "$SETUP_MARKER"
+echo '{"version":1,"status":"completed","checks":[{"name":"test","command":"true"}]}'
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write agent failed: %v", err)
+	}
+	t.Setenv("JUL_AGENT_CMD", script)
+	t.Setenv("SETUP_MARKER", marker)
+
+	if _, err := setupCIWithAgent(nil, false); err == nil || !strings.Contains(err.Error(), "--unconfined") {
+		t.Fatalf("expected setup to refuse unconfined dry runs, got %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatalf("expected the agent not to run")
+	}
+	if _, ok, _ := cicmd.LoadConfig(); ok {
+		t.Fatalf("expected no .jul/ci.toml")
+	}
+
+	maybeSetupCIOnCheckpoint(true, nil)
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatalf("expected checkpoint setup to stay off by default")
+	}
+}
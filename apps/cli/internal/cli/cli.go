@@ -55,7 +55,10 @@ func (a *App) Run(args []string) int {
 					}
 				}
 			}
-			return cmd.Run(cmdArgs)
+			op := beginOperation(cmd.Name, cmdArgs)
+			code := cmd.Run(cmdArgs)
+			op.finish()
+			return code
 		}
 	}
 
@@ -15,11 +15,13 @@ import (
 	"github.com/lydakis/jul/cli/internal/hooks"
 	"github.com/lydakis/jul/cli/internal/metadata"
 	"github.com/lydakis/jul/cli/internal/metrics"
+	"github.com/lydakis/jul/cli/internal/notes"
 	"github.com/lydakis/jul/cli/internal/output"
 	"github.com/lydakis/jul/cli/internal/policy"
 	remotesel "github.com/lydakis/jul/cli/internal/remote"
 	"github.com/lydakis/jul/cli/internal/syncer"
 	wsconfig "github.com/lydakis/jul/cli/internal/workspace"
+	"github.com/lydakis/jul/cli/pkg/promote"
 )
 
 func Commands(version string) []Command {
@@ -33,10 +35,13 @@ func Commands(version string) []Command {
 		newLocalCommand(),
 		newCheckpointCommand(),
 		newDraftCommand(),
+		newHandoffCommand(),
 		newReviewCommand(),
+		newFixCommand(),
 		newSubmitCommand(),
 		newTraceCommand(),
 		newMergeCommand(),
+		newTransplantCommand(),
 		newSyncCommand(),
 		newStatusCommand(),
 		newLogCommand(),
@@ -46,6 +51,9 @@ func Commands(version string) []Command {
 		newBlameCommand(),
 		newApplyCommand(),
 		newReflogCommand(),
+		newOpCommand(),
+		newUndoCommand(),
+		newRedoCommand(),
 		newPromoteCommand(),
 		newChangesCommand(),
 		newQueryCommand(),
@@ -256,15 +264,7 @@ type promoteResult struct {
 	Timings       metrics.Timings
 }
 
-type promoteError struct {
-	Code    string
-	Message string
-	Next    []output.NextAction
-}
-
-func (e promoteError) Error() string {
-	return e.Message
-}
+type promoteError = promote.Error
 
 func newPromoteCommand() Command {
 	return Command{
@@ -279,6 +279,8 @@ func newPromoteCommand() Command {
 			squash := fs.Bool("squash", false, "Squash checkpoints into single commit")
 			merge := fs.Bool("merge", false, "Create merge commit on target")
 			confirmRewrite := fs.Bool("confirm-rewrite", false, "Confirm publishing to rewritten target")
+			cont := fs.Bool("continue", false, "Continue a stacked promote that stopped mid-stack")
+			abort := fs.Bool("abort", false, "Abort a stacked promote that stopped mid-stack and restore the workspaces")
 			jsonRequested := hasJSONFlag(args)
 			if jsonRequested {
 				fs.SetOutput(io.Discard)
@@ -290,6 +292,29 @@ func newPromoteCommand() Command {
 				return 1
 			}
 
+			if *cont && *abort {
+				if *jsonOut {
+					_ = output.EncodeError(os.Stdout, "promote_invalid_args", "choose only one of --continue or --abort", nil)
+				} else {
+					fmt.Fprintln(os.Stderr, "choose only one of --continue or --abort")
+				}
+				return 1
+			}
+			if *abort {
+				return runPromoteAbort(*jsonOut)
+			}
+			if *cont {
+				timings := metrics.NewTimings()
+				promoteStart := time.Now()
+				res, err := continuePromote(promoteOptions{
+					ForceTarget:    *forceTarget,
+					NoPolicy:       *noPolicy,
+					ConfirmRewrite: *confirmRewrite,
+					Timings:        &timings,
+				})
+				return writePromoteResult(res, err, timings, promoteStart, *jsonOut)
+			}
+
 			if *toBranch == "" {
 				if *jsonOut {
 					_ = output.EncodeError(os.Stdout, "promote_missing_target", "missing --to <branch>", nil)
@@ -367,53 +392,111 @@ func newPromoteCommand() Command {
 				ConfirmRewrite: *confirmRewrite,
 				Timings:        &timings,
 			})
-			if err != nil {
-				var perr promoteError
-				if errors.As(err, &perr) {
-					if *jsonOut {
-						_ = output.EncodeError(os.Stdout, perr.Code, perr.Message, perr.Next)
-					} else {
-						fmt.Fprintln(os.Stderr, perr.Message)
+			return writePromoteResult(res, err, timings, promoteStart, *jsonOut)
+		},
+	}
+}
+
+func writePromoteResult(res promoteResult, err error, timings metrics.Timings, promoteStart time.Time, jsonOut bool) int {
+	if err != nil {
+		var perr promoteError
+		if errors.As(err, &perr) {
+			if jsonOut {
+				_ = output.EncodeError(os.Stdout, perr.Code, perr.Message, outputNextActions(perr.Next))
+			} else {
+				fmt.Fprintln(os.Stderr, perr.Message)
+				for _, next := range perr.Next {
+					if next.Action == "continue" || next.Action == "abort" {
+						fmt.Fprintf(os.Stderr, "  %s\n", next.Command)
 					}
-					return 1
 				}
-				if *jsonOut {
-					_ = output.EncodeError(os.Stdout, "promote_failed", err.Error(), nil)
-				} else {
-					fmt.Fprintf(os.Stderr, "promote failed: %v\n", err)
-				}
-				return 1
 			}
-			if res.Timings.PhaseMs == nil {
-				res.Timings = timings
+			return 1
+		}
+		if errors.Is(err, errPromoteNotInProgress) {
+			if jsonOut {
+				_ = output.EncodeError(os.Stdout, "promote_not_in_progress", err.Error(), nil)
+			} else {
+				fmt.Fprintln(os.Stderr, err.Error())
 			}
-			res.Timings.TotalMs = time.Since(promoteStart).Milliseconds()
+			return 1
+		}
+		if jsonOut {
+			_ = output.EncodeError(os.Stdout, "promote_failed", err.Error(), nil)
+		} else {
+			fmt.Fprintf(os.Stderr, "promote failed: %v\n", err)
+		}
+		return 1
+	}
+	if res.Timings.PhaseMs == nil {
+		res.Timings = timings
+	}
+	res.Timings.TotalMs = time.Since(promoteStart).Milliseconds()
 
-			commitSHA := strings.TrimSpace(res.PublishedTip)
-			if commitSHA == "" && len(res.Published) > 0 {
-				commitSHA = res.Published[len(res.Published)-1]
-			}
-			if commitSHA == "" {
-				commitSHA = res.TargetSHA
-			}
-			out := promoteOutput{
-				Status:      "ok",
-				Branch:      res.Branch,
-				CommitSHA:   commitSHA,
-				Strategy:    res.Strategy,
-				Published:   res.Published,
-				BaseMarker:  res.BaseMarkerSHA,
-				ForceTarget: res.ForceTarget,
-				NoPolicy:    res.NoPolicy,
-				Timings:     res.Timings,
-			}
-			if *jsonOut {
-				return writeJSON(out)
-			}
-			renderPromoteOutput(out)
-			return 0
-		},
+	commitSHA := strings.TrimSpace(res.PublishedTip)
+	if commitSHA == "" && len(res.Published) > 0 {
+		commitSHA = res.Published[len(res.Published)-1]
+	}
+	if commitSHA == "" {
+		commitSHA = res.TargetSHA
+	}
+	out := promoteOutput{
+		Status:      "ok",
+		Branch:      res.Branch,
+		CommitSHA:   commitSHA,
+		Strategy:    res.Strategy,
+		Published:   res.Published,
+		BaseMarker:  res.BaseMarkerSHA,
+		ForceTarget: res.ForceTarget,
+		NoPolicy:    res.NoPolicy,
+		Timings:     res.Timings,
+	}
+	if jsonOut {
+		return writeJSON(out)
+	}
+	renderPromoteOutput(out)
+	return 0
+}
+
+type promoteAbortOutput struct {
+	Status     string   `json:"status"`
+	Action     string   `json:"action"`
+	Branch     string   `json:"branch"`
+	Workspaces []string `json:"workspaces"`
+	Published  []string `json:"published,omitempty"`
+}
+
+func runPromoteAbort(jsonOut bool) int {
+	state, err := abortPromote()
+	if err != nil {
+		code := "promote_abort_failed"
+		if errors.Is(err, errPromoteNotInProgress) {
+			code = "promote_not_in_progress"
+		}
+		if jsonOut {
+			_ = output.EncodeError(os.Stdout, code, err.Error(), nil)
+		} else {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+		return 1
+	}
+	out := promoteAbortOutput{
+		Status:    "ok",
+		Action:    "promote_abort",
+		Branch:    state.Branch,
+		Published: state.Published,
+	}
+	for _, entry := range state.Stack {
+		out.Workspaces = append(out.Workspaces, entry.User+"/"+entry.Name)
 	}
+	if jsonOut {
+		return writeJSON(out)
+	}
+	fmt.Fprintf(os.Stdout, "Promote aborted; restored %s and %d workspaces\n", out.Branch, len(out.Workspaces))
+	if remote, err := remotesel.Resolve(); err == nil && strings.TrimSpace(remote.Name) != "" && len(out.Published) > 0 {
+		fmt.Fprintf(os.Stdout, "%d commits already pushed to %s stay published.\n", len(out.Published), remote.Name)
+	}
+	return 0
 }
 
 func renderPromoteOutput(out promoteOutput) {
@@ -501,7 +584,7 @@ func promoteLocal(opts promoteOptions) (promoteResult, error) {
 	if err != nil {
 		return promoteResult{}, err
 	}
-	strategy, err := resolvePromoteStrategy(opts.Strategy, policyCfg.Strategy, policyOK)
+	strategy, err := promote.ResolveStrategy(opts.Strategy, policyCfg.Strategy, policyOK)
 	if err != nil {
 		return promoteResult{}, err
 	}
@@ -534,7 +617,7 @@ func promoteLocal(opts promoteOptions) (promoteResult, error) {
 			return promoteResult{}, promoteError{
 				Code:    "promote_target_rewritten",
 				Message: fmt.Sprintf("promote blocked: target %s was rewritten; use --confirm-rewrite after restacking", branch),
-				Next: []output.NextAction{
+				Next: []promote.NextAction{
 					{Action: "restack", Command: fmt.Sprintf("jul ws restack --onto %s", branch)},
 					{Action: "confirm", Command: fmt.Sprintf("jul promote --to %s --confirm-rewrite", branch)},
 				},
@@ -542,54 +625,20 @@ func promoteLocal(opts promoteOptions) (promoteResult, error) {
 		}
 	}
 
-	published := []string{}
-	publishedTip := ""
-	var mergeCommitSHA *string
-	var mainline *int
-
+	repo := promote.Repo{WorkDir: repoRoot}
 	rewriteStart := time.Now()
-	switch strategy {
-	case "rebase":
-		if remoteTip == "" || gitutil.IsAncestor(remoteTip, sha) || opts.ForceTarget {
-			published = append(published, checkpointSHAs...)
-			publishedTip = published[len(published)-1]
-		} else {
-			published, err = promoteRebase(repoRoot, remoteTip, checkpointSHAs)
-			if err != nil {
-				return promoteResult{}, err
-			}
-			if len(published) == 0 {
-				return promoteResult{}, fmt.Errorf("rebase produced no published commits")
-			}
-			publishedTip = published[len(published)-1]
-		}
-	case "squash":
-		baseTip := resolvePromoteBaseTip(remoteTip, localTip, checkpoints)
-		msg, _ := gitutil.CommitMessage(checkpoints[len(checkpoints)-1].SHA)
-		msg = ensureChangeID(msg, changeID)
-		publishedTip, err = promoteSquash(repoRoot, baseTip, checkpointSHAs, msg)
-		if err != nil {
-			return promoteResult{}, err
-		}
-		published = []string{publishedTip}
-	case "merge":
-		baseTip := resolvePromoteBaseTip(remoteTip, localTip, checkpoints)
-		msg, _ := gitutil.CommitMessage(checkpoints[len(checkpoints)-1].SHA)
-		msg = ensureChangeID(msg, changeID)
-		publishedTip, err = promoteMerge(repoRoot, baseTip, checkpointSHAs[len(checkpointSHAs)-1], msg)
-		if err != nil {
-			return promoteResult{}, err
-		}
-		published = []string{publishedTip}
-		mergeCommitSHA = &publishedTip
-		mainlineVal := 1
-		mainline = &mainlineVal
-	default:
-		return promoteResult{}, promoteError{
-			Code:    "promote_invalid_strategy",
-			Message: fmt.Sprintf("unsupported promote strategy %q", strategy),
-		}
+	res, err := promote.Apply(repo, promote.Plan{
+		Strategy:    strategy,
+		ChangeID:    changeID,
+		Checkpoints: promoteCheckpoints(checkpoints),
+		TargetTip:   remoteTip,
+		LocalTip:    localTip,
+		Force:       opts.ForceTarget,
+	})
+	if err != nil {
+		return promoteResult{}, err
 	}
+	published, publishedTip := res.Published, res.PublishedTip
 	timings.Add("rewrite", time.Since(rewriteStart))
 
 	guardTip := strings.TrimSpace(remoteTip)
@@ -601,7 +650,7 @@ func promoteLocal(opts promoteOptions) (promoteResult, error) {
 			return promoteResult{}, promoteError{
 				Code:    "promote_non_fast_forward",
 				Message: "promote would not be fast-forward; use --force-target to override",
-				Next: []output.NextAction{
+				Next: []promote.NextAction{
 					{Action: "force", Command: fmt.Sprintf("jul promote --to %s --force-target", branch)},
 				},
 			}
@@ -619,21 +668,17 @@ func promoteLocal(opts promoteOptions) (promoteResult, error) {
 		return promoteResult{}, err
 	}
 
-	eventID, err := recordPromoteMeta(promoteMetaInput{
+	if _, err := promote.RecordEvent(repo, cliNotes{}, promote.Event{
 		Branch:         branch,
 		Strategy:       strategy,
 		ChangeID:       changeID,
 		AnchorSHA:      anchorSHA,
-		Checkpoints:    checkpoints,
+		Checkpoints:    promoteCheckpoints(checkpoints),
 		CheckpointSHAs: checkpointSHAs,
 		PublishedSHAs:  published,
-		MergeCommitSHA: mergeCommitSHA,
-		Mainline:       mainline,
-	})
-	if err != nil {
-		return promoteResult{}, err
-	}
-	if err := writePromoteChangeIDNotes(changeID, eventID, strategy, checkpoints, published); err != nil {
+		MergeCommitSHA: res.MergeCommitSHA,
+		Mainline:       res.Mainline,
+	}); err != nil {
 		return promoteResult{}, err
 	}
 
@@ -660,8 +705,8 @@ func promoteLocal(opts promoteOptions) (promoteResult, error) {
 }
 
 type stackWorkspace struct {
-	User string
-	Name string
+	User string `json:"user"`
+	Name string `json:"name"`
 }
 
 func promoteWithStack(opts promoteOptions) (promoteResult, error) {
@@ -669,6 +714,13 @@ func promoteWithStack(opts promoteOptions) (promoteResult, error) {
 	if err != nil {
 		return promoteResult{}, err
 	}
+	if _, ok := readPromoteState(repoRoot); ok {
+		return promoteResult{}, promoteError{
+			Code:    "promote_in_progress",
+			Message: "promote already in progress; run 'jul promote --continue' or 'jul promote --abort'",
+			Next:    promoteResumeActions,
+		}
+	}
 	user, workspace := workspaceParts()
 	stack, baseBranch, err := resolvePromoteStack(repoRoot, user, workspace)
 	if err != nil {
@@ -681,30 +733,28 @@ func promoteWithStack(opts promoteOptions) (promoteResult, error) {
 		return promoteResult{}, fmt.Errorf("stacked workspace targets %s; use --to %s", baseBranch, baseBranch)
 	}
 
-	originalEnv := os.Getenv(config.EnvWorkspace)
-	defer restoreWorkspaceEnv(originalEnv)
-
-	var result promoteResult
 	// Promote bottom-up (base workspace first).
+	state := promoteState{
+		Branch:         opts.Branch,
+		TargetSHA:      opts.TargetSHA,
+		Strategy:       opts.Strategy,
+		ForceTarget:    opts.ForceTarget,
+		NoPolicy:       opts.NoPolicy,
+		ConfirmRewrite: opts.ConfirmRewrite,
+	}
 	for i := len(stack) - 1; i >= 0; i-- {
 		entry := stack[i]
-		wsID := entry.User + "/" + entry.Name
-		if err := withWorkspaceEnv(wsID); err != nil {
-			return promoteResult{}, err
-		}
-		localOpts := opts
-		if i != 0 {
-			localOpts.TargetSHA = ""
-		}
-		res, err := promoteLocal(localOpts)
+		snap, err := wsconfig.TakeSnapshot(repoRoot, entry.User, entry.Name)
 		if err != nil {
 			return promoteResult{}, err
 		}
-		if i == 0 {
-			result = res
-		}
+		state.Stack = append(state.Stack, entry)
+		state.Originals = append(state.Originals, snap)
 	}
-	return result, nil
+	if tip, err := gitutil.ResolveRef("refs/heads/" + strings.TrimSpace(opts.Branch)); err == nil {
+		state.BranchTip = strings.TrimSpace(tip)
+	}
+	return runPromoteStack(repoRoot, &state, opts)
 }
 
 func resolvePromoteStack(repoRoot, user, workspace string) ([]stackWorkspace, string, error) {
@@ -814,74 +864,15 @@ func restoreWorkspaceEnv(prev string) {
 	_ = os.Setenv(config.EnvWorkspace, prev)
 }
 
-type promoteMetaInput struct {
-	Branch         string
-	Strategy       string
-	ChangeID       string
-	AnchorSHA      string
-	Checkpoints    []metadata.ChangeCheckpoint
-	CheckpointSHAs []string
-	PublishedSHAs  []string
-	MergeCommitSHA *string
-	Mainline       *int
-}
+// cliNotes routes promote event notes through the cached notes package.
+type cliNotes struct{}
 
-func recordPromoteMeta(input promoteMetaInput) (int, error) {
-	if strings.TrimSpace(input.ChangeID) == "" {
-		return 0, nil
-	}
-	anchorSHA := strings.TrimSpace(input.AnchorSHA)
-	checkpoints := input.Checkpoints
-	if len(checkpoints) == 0 {
-		var err error
-		anchorSHA, checkpoints, err = changeMetaFromCheckpoints(input.ChangeID)
-		if err != nil {
-			return 0, err
-		}
-	}
-	if anchorSHA == "" {
-		if len(checkpoints) > 0 {
-			anchorSHA = checkpoints[0].SHA
-		} else if len(input.PublishedSHAs) > 0 {
-			anchorSHA = input.PublishedSHAs[0]
-		}
-	}
-	if anchorSHA == "" {
-		return 0, nil
-	}
+func (cliNotes) ReadJSON(ref, objectSHA string, target any) (bool, error) {
+	return notes.ReadJSON(ref, objectSHA, target)
+}
 
-	meta, ok, err := metadata.ReadChangeMeta(anchorSHA)
-	if err != nil {
-		return 0, err
-	}
-	if !ok {
-		meta = metadata.ChangeMeta{}
-	}
-	if meta.ChangeID == "" {
-		meta.ChangeID = input.ChangeID
-	}
-	if meta.AnchorSHA == "" {
-		meta.AnchorSHA = anchorSHA
-	}
-	if len(checkpoints) > 0 {
-		meta.Checkpoints = checkpoints
-	}
-	eventID := len(meta.PromoteEvents) + 1
-	strategy := strings.TrimSpace(input.Strategy)
-	if strategy == "" {
-		strategy = "rebase"
-	}
-	meta.PromoteEvents = append(meta.PromoteEvents, metadata.PromoteEvent{
-		Target:         strings.TrimSpace(input.Branch),
-		Strategy:       strategy,
-		Timestamp:      time.Now().UTC(),
-		Published:      input.PublishedSHAs,
-		CheckpointSHAs: input.CheckpointSHAs,
-		PublishedSHAs:  input.PublishedSHAs,
-		MergeCommitSHA: input.MergeCommitSHA,
-		Mainline:       input.Mainline,
-	})
-	return eventID, metadata.WriteChangeMeta(meta)
+func (cliNotes) AddJSON(ref, objectSHA string, payload any) error {
+	return notes.AddJSON(ref, objectSHA, payload)
 }
 
 func startNewDraftAfterPromote(repoRoot, checkpointSHA, publishedSHA, branch, trackTip string) (string, error) {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/lydakis/jul/cli/internal/client"
 	"github.com/lydakis/jul/cli/internal/gitutil"
 	"github.com/lydakis/jul/cli/internal/metadata"
 	"github.com/lydakis/jul/cli/internal/output"
@@ -41,12 +42,15 @@ func newDiffCommand() Command {
 				return 1
 			}
 
+			payload := output.DiffResult{From: from, To: to, Diff: diffOut}
+			if !*stat && !*nameOnly {
+				payload.Findings, payload.FindingsOn = diffFindings(from, to)
+			}
 			if *jsonOut {
-				payload := output.DiffResult{From: from, To: to, Diff: diffOut}
 				return writeJSON(payload)
 			}
 
-			output.RenderDiff(os.Stdout, output.DiffResult{From: from, To: to, Diff: diffOut})
+			output.RenderDiff(os.Stdout, payload)
 			return 0
 		},
 	}
@@ -129,6 +133,19 @@ func resolveDiffTargets(args []string) (string, string, bool, error) {
 	}
 }
 
+// diffFindings returns the review findings to anchor in a diff: those of the
+// new side when it was reviewed, else those of the old side, as when diffing
+// a suggestion against the checkpoint it fixes.
+func diffFindings(from, to string) ([]client.Finding, string) {
+	if note, err := metadata.GetFindings(to); err == nil && note != nil && len(note.Findings) > 0 {
+		return note.Findings, "to"
+	}
+	if note, err := metadata.GetFindings(from); err == nil && note != nil && len(note.Findings) > 0 {
+		return note.Findings, "from"
+	}
+	return nil, ""
+}
+
 func runDiff(from, to string, stat, nameOnly bool, root bool) (string, error) {
 	args := []string{"diff"}
 	if stat {
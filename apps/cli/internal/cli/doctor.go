@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/lydakis/jul/cli/internal/agent"
 	"github.com/lydakis/jul/cli/internal/config"
 	"github.com/lydakis/jul/cli/internal/gitutil"
 	"github.com/lydakis/jul/cli/internal/output"
@@ -16,6 +17,7 @@ type doctorOutput struct {
 	RemoteName     string `json:"remote_name,omitempty"`
 	CheckpointSync string `json:"checkpoint_sync"`
 	DraftSync      string `json:"draft_sync"`
+	AgentSandbox   string `json:"agent_sandbox"`
 	Message        string `json:"message,omitempty"`
 }
 
@@ -46,7 +48,7 @@ func newDoctorCommand() Command {
 }
 
 func runDoctor() (doctorOutput, error) {
-	out := doctorOutput{Status: "ok"}
+	out := doctorOutput{Status: "ok", AgentSandbox: agent.SandboxStatus()}
 	remote, err := remotesel.Resolve()
 	if err != nil {
 		switch err {
@@ -126,6 +128,9 @@ func renderDoctorOutput(out doctorOutput) {
 	if out.DraftSync != "" {
 		fmt.Fprintf(os.Stdout, "draft_sync: %s\n", out.DraftSync)
 	}
+	if out.AgentSandbox != "" {
+		fmt.Fprintf(os.Stdout, "agent_sandbox: %s\n", out.AgentSandbox)
+	}
 }
 
 func probeSyncCapabilities(remoteName, headSHA, ref, noteRef string) (bool, bool, error) {
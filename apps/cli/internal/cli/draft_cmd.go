@@ -500,10 +500,12 @@ func resolveRefSHA(ref string) (string, error) {
 }
 
 func rebaseDraftOnto(repoRoot, baseSHA, draftSHA, changeID string) (string, error) {
-	worktree, err := agent.EnsureWorktree(repoRoot, baseSHA, agent.WorktreeOptions{})
+	lease, err := agent.AcquireWorktree(repoRoot, baseSHA, "rebase_draft")
 	if err != nil {
 		return "", err
 	}
+	defer lease.Release()
+	worktree := lease.Worktree
 	if err := gitDir(worktree, nil, "cherry-pick", "--no-commit", draftSHA); err != nil {
 		conflicts := mergeConflictFiles(worktree)
 		_ = gitDir(worktree, nil, "cherry-pick", "--abort")
@@ -522,7 +524,12 @@ func rebaseDraftOnto(repoRoot, baseSHA, draftSHA, changeID string) (string, erro
 }
 
 func mergeDrafts(repoRoot, baseSHA, oursSHA, theirsSHA, changeID string, stream io.Writer) (string, error) {
-	worktree, err := agent.EnsureWorktree(repoRoot, oursSHA, agent.WorktreeOptions{})
+	lease, err := agent.AcquireMergeWorktree(repoRoot, "adopt_draft")
+	if err != nil {
+		return "", err
+	}
+	defer lease.Release()
+	worktree, err := agent.EnsureWorktree(repoRoot, oursSHA, agent.WorktreeOptions{Name: agent.MergeWorktree})
 	if err != nil {
 		return "", err
 	}
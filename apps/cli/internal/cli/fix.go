@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/agent"
+	cicmd "github.com/lydakis/jul/cli/internal/ci"
+	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/metadata"
+	"github.com/lydakis/jul/cli/internal/output"
+)
+
+const fixAction = "fix_failing_test"
+
+func newFixCommand() Command {
+	return Command{
+		Name:    "fix",
+		Summary: "Let the agent fix failing checks and suggest the result",
+		Run: func(args []string) int {
+			fs, jsonOut := newFlagSet("fix")
+			var commands stringList
+			fs.Var(&commands, "cmd", "Check to run (repeatable). Default: .jul/ci.toml or inferred")
+			maxIterations := fs.Int("max-iterations", 0, "Agent attempts before giving up (default: provider max_iterations)")
+			_ = fs.Parse(args)
+
+			progress := io.Writer(os.Stderr)
+			if *jsonOut {
+				progress = io.Discard
+			}
+			stream := watchStream(*jsonOut, os.Stdout, os.Stderr)
+			res, err := runFix([]string(commands), *maxIterations, progress, stream)
+			if err != nil {
+				return commandError(*jsonOut, "fix_failed", fmt.Sprintf("fix failed: %v", err), nil)
+			}
+			if res.Suggestion != nil {
+				res.NextActions = buildSuggestionActions([]client.Suggestion{*res.Suggestion})
+			}
+			if *jsonOut {
+				if code := writeJSON(res); code != 0 {
+					return code
+				}
+			} else {
+				output.RenderFix(os.Stdout, res, output.DefaultOptions())
+			}
+			if res.Status == "gave_up" {
+				return 1
+			}
+			return 0
+		},
+	}
+}
+
+// runFix runs the checks in the agent worktree, confined like the agent
+// itself since they execute code it wrote, and while they fail hands
+// the results to the agent and re-runs them against its commit, up to limit
+// times. A passing run becomes one suggestion carrying the ci attestation.
+func runFix(cmds []string, limit int, progress, stream io.Writer) (output.FixOutput, error) {
+	baseSHA, changeID, err := reviewBase()
+	if err != nil {
+		return output.FixOutput{}, err
+	}
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return output.FixOutput{}, err
+	}
+	lease, err := agent.AcquireWorktree(repoRoot, baseSHA, fixAction)
+	if err != nil {
+		return output.FixOutput{}, err
+	}
+	defer lease.Release()
+	worktree := lease.Worktree
+	if len(cmds) == 0 {
+		cmds = ciCommands(worktree)
+	}
+
+	res := output.FixOutput{BaseSHA: baseSHA, ChangeID: changeID, Commands: cmds}
+	provider, err := agent.ResolveProvider()
+	if err != nil {
+		return res, err
+	}
+	fmt.Fprintf(progress, "Running checks on %s...\n", shortSHA(baseSHA))
+	result, err := runFixChecks(provider, worktree, cmds)
+	if err != nil {
+		return res, err
+	}
+	if result.Status == "pass" {
+		res.Status = "passing"
+		return res, nil
+	}
+
+	if limit <= 0 {
+		limit = provider.MaxIterations
+	}
+	res.MaxIterations = limit
+
+	diff := reviewDiff("", baseSHA)
+	files := reviewFiles("", baseSHA)
+	head := baseSHA
+	summary := ""
+	for i := 1; i <= limit; i++ {
+		signals, err := json.Marshal(result)
+		if err != nil {
+			return res, err
+		}
+		fmt.Fprintf(progress, "Iteration %d/%d: %s failing, running agent...\n", i, limit, strings.Join(failedChecks(result), ", "))
+		req := agent.ReviewRequest{
+			Version:       1,
+			Action:        fixAction,
+			WorkspacePath: worktree,
+			Context: agent.ReviewContext{
+				Checkpoint: baseSHA,
+				ChangeID:   changeID,
+				Diff:       diff,
+				Files:      files,
+				CIResults:  signals,
+			},
+		}
+		resp, err := agent.RunReviewWithStream(context.Background(), provider, req, stream)
+		if err != nil {
+			return res, err
+		}
+		if _, err := autoCommitWorktree(worktree, "agent: fix failing checks"); err != nil {
+			return res, err
+		}
+		next, err := gitOutputDir(worktree, "rev-parse", "HEAD")
+		if err != nil {
+			return res, err
+		}
+		iteration := output.FixIteration{
+			Iteration: i,
+			CommitSHA: next,
+			Summary:   strings.TrimSpace(resp.Summary),
+		}
+		if iteration.Summary != "" {
+			summary = iteration.Summary
+		}
+		if next == head {
+			iteration.Status = "no_changes"
+			iteration.CommitSHA = ""
+			res.Iterations = append(res.Iterations, iteration)
+			continue
+		}
+		head = next
+		result, err = runFixChecks(provider, worktree, cmds)
+		if err != nil {
+			return res, err
+		}
+		iteration.Status = result.Status
+		iteration.Failed = failedChecks(result)
+		res.Iterations = append(res.Iterations, iteration)
+		if result.Status == "pass" {
+			return finishFix(res, worktree, head, summary, result)
+		}
+	}
+
+	res.Status = "gave_up"
+	res.Reason = fixGiveUpReason(res, result)
+	return res, nil
+}
+
+func runFixChecks(provider agent.Provider, worktree string, cmds []string) (cicmd.Result, error) {
+	return cicmd.RunCommandsWith(cmds, func(command string) ([]byte, error) {
+		return agent.RunInWorktree(context.Background(), provider, worktree, command)
+	})
+}
+
+// finishFix folds the agent's commits into one on top of the base, records
+// the passing checks as its ci attestation and creates the suggestion.
+func finishFix(res output.FixOutput, worktree, head, summary string, result cicmd.Result) (output.FixOutput, error) {
+	message := summary
+	if message == "" {
+		message = "fix: make failing checks pass"
+	}
+	commit, err := squashWorktreeCommits(worktree, res.BaseSHA, head, message)
+	if err != nil {
+		return res, err
+	}
+	deviceID, err := config.DeviceID()
+	if err != nil {
+		return res, err
+	}
+	signals, err := json.Marshal(result)
+	if err != nil {
+		return res, err
+	}
+	testStatus, compileStatus := inferCIStatuses(res.Commands, result.Status)
+	att, err := metadata.WriteAttestation(client.Attestation{
+		CommitSHA:     commit,
+		DeviceID:      strings.TrimSpace(deviceID),
+		ChangeID:      res.ChangeID,
+		Type:          "ci",
+		Status:        result.Status,
+		TestStatus:    testStatus,
+		CompileStatus: compileStatus,
+		StartedAt:     result.StartedAt,
+		FinishedAt:    result.FinishedAt,
+		SignalsJSON:   string(signals),
+	})
+	if err != nil {
+		return res, err
+	}
+	suggestion, err := metadata.CreateSuggestion(metadata.SuggestionCreate{
+		ChangeID:           res.ChangeID,
+		BaseCommitSHA:      res.BaseSHA,
+		SuggestedCommitSHA: commit,
+		CreatedBy:          "agent",
+		Reason:             fixAction,
+		Description:        firstLine(message),
+	})
+	if err != nil {
+		return res, err
+	}
+	res.Status = "fixed"
+	res.Attestation = &att
+	res.Suggestion = &suggestion
+	return res, nil
+}
+
+// squashWorktreeCommits returns head when it is a single commit on base,
+// otherwise a new commit with head's tree whose only parent is base.
+func squashWorktreeCommits(worktree, base, head, message string) (string, error) {
+	parent, err := gitOutputDir(worktree, "rev-parse", head+"^")
+	if err == nil && parent == base {
+		return head, nil
+	}
+	cmd := exec.Command("git", "commit-tree", head+"^{tree}", "-p", base, "-m", message)
+	cmd.Dir = worktree
+	cmd.Env = append(os.Environ(), flattenEnv(agentCommitEnv)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git commit-tree: %s", strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func failedChecks(result cicmd.Result) []string {
+	var failed []string
+	for _, cmd := range result.Commands {
+		if cmd.Status != "pass" {
+			failed = append(failed, output.LabelForCommand(cmd.Command))
+		}
+	}
+	return failed
+}
+
+func fixGiveUpReason(res output.FixOutput, result cicmd.Result) string {
+	changed := false
+	for _, iteration := range res.Iterations {
+		if iteration.Status != "no_changes" {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return fmt.Sprintf("agent made no changes in %d iteration(s)", len(res.Iterations))
+	}
+	return fmt.Sprintf("checks still failing after %d iteration(s): %s", len(res.Iterations), strings.Join(failedChecks(result), ", "))
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/agent"
+	"github.com/lydakis/jul/cli/internal/metadata"
+)
+
+func TestRunFixIteratesUntilChecksPass(t *testing.T) {
+	repo := t.TempDir()
+	runGitTest(t, repo, "init")
+	runGitTest(t, repo, "config", "user.name", "Test User")
+	runGitTest(t, repo, "config", "user.email", "test@example.com")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+	runGitTest(t, repo, "add", "README.md")
+	runGitTest(t, repo, "commit", "-m", "initial")
+	baseSHA := runGitOutputTest(t, repo, "rev-parse", "HEAD")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+
+	// The agent only gets it right on its second attempt.
+	counter := filepath.Join(t.TempDir(), "count")
+	script := filepath.Join(t.TempDir(), "agent.sh")
+	body := `#!/bin/sh
+cat >/dev/null
+n=$(cat "$FIX_COUNTER" 2>/dev/null || echo 0)
+n=$((n+1))
+echo "$n" > "$FIX_COUNTER"
+if [ "$n" -ge 2 ]; then
+  echo fixed > "$JUL_AGENT_WORKSPACE/fixed.txt"
+else
+  echo attempt > "$JUL_AGENT_WORKSPACE/attempt.txt"
+fi
+echo '{"version":1,"status":"completed","summary":"fix: add fixed.txt"}'
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write agent failed: %v", err)
+	}
+	t.Setenv("JUL_AGENT_CMD", script)
+	t.Setenv("FIX_COUNTER", counter)
+
+	checks := []string{"test -f fixed.txt"}
+	res, err := runFix(checks, 0, io.Discard, nil)
+	if err != nil {
+		t.Fatalf("runFix failed: %v", err)
+	}
+	if res.Status != "fixed" || len(res.Iterations) != 2 || res.MaxIterations != 5 {
+		t.Fatalf("expected fix on the second of 5 iterations, got %+v", res)
+	}
+	if res.Iterations[0].Status != "fail" || res.Iterations[1].Status != "pass" {
+		t.Fatalf("unexpected iterations: %+v", res.Iterations)
+	}
+	if res.Suggestion == nil || res.Suggestion.Reason != "fix_failing_test" || res.Suggestion.BaseCommitSHA != baseSHA {
+		t.Fatalf("unexpected suggestion: %+v", res.Suggestion)
+	}
+	commit := res.Suggestion.SuggestedCommitSHA
+	if parent := runGitOutputTest(t, repo, "rev-parse", commit+"^"); parent != baseSHA {
+		t.Fatalf("expected one suggestion commit on %s, got parent %s", baseSHA, parent)
+	}
+	files := runGitOutputTest(t, repo, "diff", "--name-only", baseSHA, commit)
+	if !strings.Contains(files, "fixed.txt") || !strings.Contains(files, "attempt.txt") {
+		t.Fatalf("expected both attempts in the suggestion, got %q", files)
+	}
+	att, err := metadata.GetAttestation(commit)
+	if err != nil || att == nil || att.Status != "pass" || att.Type != "ci" {
+		t.Fatalf("expected passing ci attestation on %s, got %+v (%v)", commit, att, err)
+	}
+
+	if err := os.WriteFile(counter, []byte("0\n"), 0o644); err != nil {
+		t.Fatalf("reset counter failed: %v", err)
+	}
+	res, err = runFix(checks, 1, io.Discard, nil)
+	if err != nil {
+		t.Fatalf("runFix failed: %v", err)
+	}
+	if res.Status != "gave_up" || res.Suggestion != nil || !strings.Contains(res.Reason, "still failing after 1 iteration") {
+		t.Fatalf("expected give-up report, got %+v", res)
+	}
+}
+
+func TestRunFixRunsChecksThroughAgentSandbox(t *testing.T) {
+	repo := t.TempDir()
+	runGitTest(t, repo, "init")
+	runGitTest(t, repo, "config", "user.name", "Test User")
+	runGitTest(t, repo, "config", "user.email", "test@example.com")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+	runGitTest(t, repo, "add", "README.md")
+	runGitTest(t, repo, "commit", "-m", "initial")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_CMD", "true")
+
+	// Checks run as agent commands: they see the agent environment...
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+	res, err := runFix([]string{`test "$JUL_NO_SYNC" = 1`}, 1, io.Discard, nil)
+	if err != nil || res.Status != "passing" {
+		t.Fatalf("expected check to run as an agent command, got %+v (%v)", res, err)
+	}
+
+	// ...and are confined when the sandbox is on. Test binaries cannot start
+	// the sandbox helper, so a confined run reports it unavailable.
+	t.Setenv("JUL_AGENT_SANDBOX", "on")
+	marker := filepath.Join(t.TempDir(), "ran")
+	_, err = runFix([]string{"touch " + marker}, 1, io.Discard, nil)
+	if !errors.Is(err, agent.ErrSandboxUnavailable) {
+		t.Fatalf("expected checks to go through the sandbox, got %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatalf("check ran unconfined on the host")
+	}
+}
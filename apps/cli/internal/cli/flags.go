@@ -100,3 +100,35 @@ func newFlagSetWithOutput(name string, out io.Writer) (*flag.FlagSet, *bool) {
 	jsonOut := fs.Bool("json", false, "Output JSON")
 	return fs, jsonOut
 }
+
+// flagsFirst moves flags ahead of positional arguments so that
+// "cmd <id> --flag value" parses like "cmd --flag value <id>". valueFlags
+// names the flags that take a separate value argument.
+func flagsFirst(args []string, valueFlags ...string) []string {
+	takesValue := make(map[string]bool, len(valueFlags))
+	for _, name := range valueFlags {
+		takesValue["-"+name] = true
+		takesValue["--"+name] = true
+	}
+	opts := make([]string, 0, len(args))
+	positionals := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positionals = append(positionals, args[i+1:]...)
+			i = len(args)
+		case takesValue[arg]:
+			opts = append(opts, arg)
+			if i+1 < len(args) {
+				opts = append(opts, args[i+1])
+				i++
+			}
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			opts = append(opts, arg)
+		default:
+			positionals = append(positionals, arg)
+		}
+	}
+	return append(opts, positionals...)
+}
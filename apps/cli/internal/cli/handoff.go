@@ -0,0 +1,404 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/handoff"
+	"github.com/lydakis/jul/cli/internal/output"
+	remotesel "github.com/lydakis/jul/cli/internal/remote"
+	"github.com/lydakis/jul/cli/internal/syncer"
+	wsconfig "github.com/lydakis/jul/cli/internal/workspace"
+)
+
+func newHandoffCommand() Command {
+	return Command{
+		Name:    "handoff",
+		Summary: "Move the current workspace to another device",
+		Run: func(args []string) int {
+			jsonOut, args := stripJSONFlag(args)
+			if len(args) == 0 {
+				return commandError(jsonOut, "handoff_missing_subcommand", "missing handoff subcommand (push|pull)", nil)
+			}
+			sub := args[0]
+			subArgs := args[1:]
+			if jsonOut {
+				subArgs = ensureJSONFlag(subArgs)
+			}
+			switch sub {
+			case "push":
+				return runHandoffPush(subArgs)
+			case "pull":
+				return runHandoffPull(subArgs)
+			default:
+				return commandError(jsonOut, "handoff_unknown_subcommand", fmt.Sprintf("unknown subcommand %q", sub), nil)
+			}
+		},
+	}
+}
+
+func runHandoffPush(args []string) int {
+	fs, jsonOut := newFlagSet("handoff push")
+	prompts := fs.Bool("prompts", false, "Include trace prompts and summaries whatever traces.sync_prompt_* says")
+	_ = fs.Parse(args)
+
+	out, err := handoffPush(*prompts)
+	if err != nil {
+		return commandError(*jsonOut, "handoff_push_failed", fmt.Sprintf("handoff push failed: %v", err), nil)
+	}
+	if *jsonOut {
+		return writeJSON(out)
+	}
+	output.RenderHandoff(os.Stdout, out, output.DefaultOptions())
+	return 0
+}
+
+func runHandoffPull(args []string) int {
+	fs, jsonOut := newFlagSet("handoff pull")
+	from := fs.String("from", "", "Take the handoff pushed by this device")
+	force := fs.Bool("force", false, "Replace local draft changes in the workspace")
+	_ = fs.Parse(args)
+
+	out, err := handoffPull(strings.TrimSpace(*from), strings.TrimSpace(fs.Arg(0)), *force)
+	if err != nil {
+		if _, ok := err.(workspaceOpenError); ok {
+			return writeWorkspaceOpenError(err, *jsonOut)
+		}
+		var next []output.NextAction
+		if strings.Contains(err.Error(), "local draft changes") {
+			next = []output.NextAction{{Action: "force", Command: "jul handoff pull --force"}}
+		}
+		return commandError(*jsonOut, "handoff_pull_failed", fmt.Sprintf("handoff pull failed: %v", err), next)
+	}
+	if repoRoot, err := gitutil.RepoTopLevel(); err == nil {
+		_, _ = refreshStatusCache(repoRoot)
+	}
+	if *jsonOut {
+		return writeJSON(out)
+	}
+	output.RenderHandoff(os.Stdout, out, output.DefaultOptions())
+	return 0
+}
+
+// handoffPush syncs the draft and publishes it under refs/jul/handoff together
+// with the local workspace config, which no sync carries, and the prompts of
+// the workspace's pending traces that traces.sync_prompt_* (or prompts)
+// allows.
+func handoffPush(prompts bool) (output.HandoffOutput, error) {
+	if !config.DraftSyncEnabled() {
+		return output.HandoffOutput{}, fmt.Errorf("draft sync unavailable; run 'jul doctor'")
+	}
+	remote, err := remotesel.Resolve()
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	syncRes, err := syncer.Sync()
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	draftSHA := strings.TrimSpace(syncRes.DraftSHA)
+	if draftSHA == "" {
+		return output.HandoffOutput{}, fmt.Errorf("no draft to hand off")
+	}
+	user, workspace := workspaceParts()
+	if workspace == "" {
+		workspace = "@"
+	}
+	baseSHA := ""
+	if parent, err := gitutil.ParentOf(draftSHA); err == nil {
+		baseSHA = strings.TrimSpace(parent)
+	}
+	ok, reason, err := syncer.DraftPushAllowed(repoRoot, baseSHA, draftSHA, config.AllowDraftSecrets())
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	if !ok {
+		return output.HandoffOutput{}, fmt.Errorf("%s", reason)
+	}
+	deviceID, err := config.DeviceID()
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	manifest := handoff.Manifest{
+		Device:    deviceID,
+		Workspace: workspace,
+		DraftSHA:  draftSHA,
+	}
+	if sha, err := gitutil.ResolveRef(workspaceRef(user, workspace)); err == nil {
+		manifest.WorkspaceSHA = strings.TrimSpace(sha)
+	}
+	if cfg, ok, err := wsconfig.ReadConfig(repoRoot, workspace); err == nil && ok {
+		manifest.BaseRef = cfg.BaseRef
+		manifest.BaseSHA = cfg.BaseSHA
+		manifest.TrackRef = cfg.TrackRef
+		manifest.TrackTip = cfg.TrackTip
+	}
+	traces, err := handoffTraces(repoRoot, user, workspace, deviceID, prompts)
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	sha, err := handoff.Build(repoRoot, manifest, traces)
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	ref := handoff.Ref(user, workspace)
+	if err := gitutil.UpdateRef(ref, sha); err != nil {
+		return output.HandoffOutput{}, err
+	}
+	if err := pushRef(remote.Name, sha, ref, true); err != nil {
+		return output.HandoffOutput{}, err
+	}
+	stored, err := handoff.Read(repoRoot, sha)
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	return handoffOutput("push", remote.Name, ref, stored), nil
+}
+
+// handoffTraces returns the trace files to hand off: the prompts and summaries
+// of the traces this device recorded for workspace since its last checkpoint.
+// Files that trip the secret scan block the handoff unless sync.allow_secrets
+// is set.
+func handoffTraces(repoRoot, user, workspace, deviceID string, prompts bool) (map[string][]byte, error) {
+	full := prompts || config.TraceSyncPromptFull()
+	summary := prompts || config.TraceSyncPromptSummary()
+	if !full && !summary {
+		return nil, nil
+	}
+	traceSyncRef := fmt.Sprintf("refs/jul/trace-sync/%s/%s/%s", user, deviceID, workspace)
+	if !gitutil.RefExists(traceSyncRef) {
+		return nil, nil
+	}
+	args := []string{"rev-list", traceSyncRef}
+	if traceRef := fmt.Sprintf("refs/jul/traces/%s/%s", user, workspace); gitutil.RefExists(traceRef) {
+		args = append(args, "^"+traceRef)
+	}
+	out, err := gitutil.Git(args...)
+	if err != nil {
+		return nil, err
+	}
+	files, err := handoff.TraceFiles(repoRoot, strings.Fields(out), full, summary)
+	if err != nil {
+		return nil, err
+	}
+	if config.AllowDraftSecrets() {
+		return files, nil
+	}
+	for rel, content := range files {
+		if syncer.ContainsSecret(string(content)) {
+			return nil, fmt.Errorf("handoff blocked: potential secret in trace %s (set sync.allow_secrets to override)", rel)
+		}
+	}
+	return files, nil
+}
+
+// handoffPull finds the newest handoff pushed by another device (or the one
+// for workspace, or from device), switches to its workspace and materialises
+// the draft, config and trace prompts it carries.
+func handoffPull(device, workspace string, force bool) (output.HandoffOutput, error) {
+	remote, err := remotesel.Resolve()
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	deviceID, err := config.DeviceID()
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	user, currentWorkspace := workspaceParts()
+	if currentWorkspace == "" {
+		currentWorkspace = "@"
+	}
+	// The last handoff seen for this workspace tells pulling again apart from
+	// replacing work done since.
+	previousDraft := ""
+	if sha, err := gitutil.ResolveRef(handoff.Ref(user, currentWorkspace)); err == nil {
+		if previous, err := handoff.Read(repoRoot, strings.TrimSpace(sha)); err == nil {
+			previousDraft = previous.DraftSHA
+		}
+	}
+	ref, manifest, err := findHandoff(repoRoot, remote.Name, user, workspace, device, deviceID)
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	target := manifest.Workspace
+	if err := ensureNotOpenElsewhere(user, target); err != nil {
+		return output.HandoffOutput{}, err
+	}
+
+	if target == currentWorkspace {
+		if !force && handoffWouldDiscard(manifest.DraftSHA, previousDraft) {
+			return output.HandoffOutput{}, fmt.Errorf("workspace %s has local draft changes; use --force to replace them", target)
+		}
+	} else {
+		if err := saveWorkspaceState(currentWorkspace); err != nil {
+			return output.HandoffOutput{}, fmt.Errorf("failed to save workspace: %w", err)
+		}
+		if _, err := syncer.Sync(); err != nil {
+			return output.HandoffOutput{}, fmt.Errorf("failed to sync current workspace: %w", err)
+		}
+	}
+
+	cfg, _, err := wsconfig.ReadConfig(repoRoot, target)
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	if manifest.BaseRef != "" {
+		cfg.BaseRef = manifest.BaseRef
+	}
+	if manifest.BaseSHA != "" {
+		cfg.BaseSHA = manifest.BaseSHA
+	}
+	if manifest.TrackRef != "" {
+		cfg.TrackRef = manifest.TrackRef
+	}
+	if manifest.TrackTip != "" {
+		cfg.TrackTip = manifest.TrackTip
+	}
+	if err := wsconfig.WriteConfig(repoRoot, target, cfg); err != nil {
+		return output.HandoffOutput{}, err
+	}
+	wsRef := workspaceRef(user, target)
+	if manifest.WorkspaceSHA != "" && !gitutil.RefExists(wsRef) {
+		if err := gitutil.UpdateRef(wsRef, manifest.WorkspaceSHA); err != nil {
+			return output.HandoffOutput{}, err
+		}
+	}
+	if target != currentWorkspace {
+		if err := switchToWorkspace(user, target); err != nil {
+			return output.HandoffOutput{}, err
+		}
+		if err := setCurrentWorkspace(user + "/" + target); err != nil {
+			return output.HandoffOutput{}, err
+		}
+	}
+
+	draftSHA := strings.TrimSpace(manifest.DraftSHA)
+	baseSHA := draftSHA
+	if parent, err := gitutil.ParentOf(draftSHA); err == nil && strings.TrimSpace(parent) != "" {
+		baseSHA = strings.TrimSpace(parent)
+	}
+	localSyncRef, err := syncRef(user, target)
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	if err := gitutil.UpdateRef(localSyncRef, draftSHA); err != nil {
+		return output.HandoffOutput{}, err
+	}
+	if err := writeWorkspaceLease(repoRoot, target, baseSHA); err != nil {
+		return output.HandoffOutput{}, err
+	}
+	if err := gitutil.EnsureHeadRef(repoRoot, workspaceHeadRef(target), baseSHA); err != nil {
+		return output.HandoffOutput{}, err
+	}
+	if err := updateWorktreeLocal(repoRoot, draftSHA); err != nil {
+		return output.HandoffOutput{}, err
+	}
+	if config.DraftSyncEnabled() {
+		_ = pushRef(remote.Name, draftSHA, localSyncRef, true)
+	}
+	traces, err := handoff.RestoreTraces(repoRoot, ref)
+	if err != nil {
+		return output.HandoffOutput{}, err
+	}
+	out := handoffOutput("pull", remote.Name, ref, manifest)
+	out.Traces = traces
+	return out, nil
+}
+
+// findHandoff fetches the handoff refs of user from the remote and picks the
+// newest one that another device pushed, narrowed to workspace or device
+// when given.
+func findHandoff(repoRoot, remoteName, user, workspace, device, selfDevice string) (string, handoff.Manifest, error) {
+	pattern := handoff.Prefix(user) + "*"
+	if workspace != "" {
+		pattern = handoff.Ref(user, workspace)
+	}
+	out, err := gitutil.Git("ls-remote", remoteName, pattern)
+	if err != nil {
+		return "", handoff.Manifest{}, err
+	}
+	var bestRef string
+	var best handoff.Manifest
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		ref := fields[1]
+		if err := fetchRef(remoteName, ref); err != nil {
+			return "", handoff.Manifest{}, err
+		}
+		manifest, err := handoff.Read(repoRoot, fields[0])
+		if err != nil {
+			continue
+		}
+		if device != "" && manifest.Device != device {
+			continue
+		}
+		if device == "" && manifest.Device == selfDevice {
+			continue
+		}
+		if bestRef == "" || manifest.CreatedAt.After(best.CreatedAt) {
+			bestRef, best = ref, manifest
+		}
+	}
+	if bestRef == "" {
+		switch {
+		case device != "":
+			return "", handoff.Manifest{}, fmt.Errorf("no handoff from %s", device)
+		case workspace != "":
+			return "", handoff.Manifest{}, fmt.Errorf("no handoff for workspace %s", workspace)
+		}
+		return "", handoff.Manifest{}, fmt.Errorf("no handoff from another device; run 'jul handoff push' there first")
+	}
+	return bestRef, best, nil
+}
+
+// handoffWouldDiscard reports whether the working tree has changes that
+// replacing it with incoming would lose: changes that are neither empty nor
+// the content of incoming or of the previously pulled handoff.
+func handoffWouldDiscard(incoming, previous string) bool {
+	_, base, err := currentDraftAndBase()
+	if err != nil {
+		return false
+	}
+	currentTree, err := gitutil.DraftTree()
+	if err != nil {
+		return false
+	}
+	for _, sha := range []string{base, incoming, previous} {
+		if strings.TrimSpace(sha) == "" {
+			continue
+		}
+		if tree, err := gitutil.TreeOf(sha); err == nil && tree == currentTree {
+			return false
+		}
+	}
+	return true
+}
+
+func handoffOutput(action, remoteName, ref string, m handoff.Manifest) output.HandoffOutput {
+	return output.HandoffOutput{
+		Status:    "ok",
+		Action:    action,
+		Workspace: m.Workspace,
+		Device:    m.Device,
+		Remote:    remoteName,
+		Ref:       ref,
+		DraftSHA:  m.DraftSHA,
+		BaseRef:   m.BaseRef,
+		TrackRef:  m.TrackRef,
+		Traces:    m.Traces,
+	}
+}
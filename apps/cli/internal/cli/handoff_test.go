@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/config"
+	wsconfig "github.com/lydakis/jul/cli/internal/workspace"
+)
+
+func TestHandoffPushPull(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_NO_SYNC", "1")
+
+	remoteDir := filepath.Join(tmp, "remote.git")
+	if err := os.MkdirAll(remoteDir, 0o755); err != nil {
+		t.Fatalf("mkdir remote failed: %v", err)
+	}
+	runGitTestCmd(t, remoteDir, "init", "--bare")
+
+	repoA := filepath.Join(tmp, "a")
+	if err := os.MkdirAll(repoA, 0o755); err != nil {
+		t.Fatalf("mkdir repo failed: %v", err)
+	}
+	runGitTestCmd(t, repoA, "init")
+	runGitTestCmd(t, repoA, "config", "user.name", "Test User")
+	runGitTestCmd(t, repoA, "config", "user.email", "test@example.com")
+	writeFilePath(t, repoA, "base.txt", "base\n")
+	runGitTestCmd(t, repoA, "add", "base.txt")
+	runGitTestCmd(t, repoA, "commit", "-m", "base")
+	runGitTestCmd(t, repoA, "remote", "add", "origin", remoteDir)
+	runGitTestCmd(t, repoA, "push", "origin", "HEAD:refs/heads/main")
+	baseSHA := strings.TrimSpace(runGitCmd(t, repoA, "rev-parse", "HEAD"))
+	runGitTestCmd(t, repoA, "update-ref", "refs/jul/workspaces/tester/@", baseSHA)
+
+	cwd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	// Device A: a draft in progress, local workspace config and a trace prompt.
+	t.Setenv("HOME", filepath.Join(tmp, "home-a"))
+	if err := os.Chdir(repoA); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	for key, value := range map[string]string{"name": "origin", "draft_sync": "enabled"} {
+		if err := config.SetRepoConfigValue("remote", key, value); err != nil {
+			t.Fatalf("set remote config failed: %v", err)
+		}
+	}
+	if err := wsconfig.WriteConfig(repoA, "@", wsconfig.Config{BaseRef: "refs/heads/main", TrackRef: "refs/heads/main"}); err != nil {
+		t.Fatalf("write workspace config failed: %v", err)
+	}
+	writeFilePath(t, repoA, "wip.txt", "in progress\n")
+	if err := os.MkdirAll(filepath.Join(repoA, ".jul", "traces", "prompts"), 0o755); err != nil {
+		t.Fatalf("mkdir traces failed: %v", err)
+	}
+	// Two pending traces on this workspace, and a prompt from elsewhere.
+	deviceID, err := config.DeviceID()
+	if err != nil {
+		t.Fatalf("device id failed: %v", err)
+	}
+	tree := strings.TrimSpace(runGitCmd(t, repoA, "rev-parse", "HEAD^{tree}"))
+	first := strings.TrimSpace(runGitCmd(t, repoA, "commit-tree", tree, "-m", "[trace]"))
+	second := strings.TrimSpace(runGitCmd(t, repoA, "commit-tree", tree, "-p", first, "-m", "[trace]"))
+	runGitTestCmd(t, repoA, "update-ref", "refs/jul/trace-sync/tester/"+deviceID+"/@", second)
+	writeFilePath(t, repoA, ".jul/traces/prompts/"+first+".txt", "fix the bug\n\n")
+	writeFilePath(t, repoA, ".jul/traces/prompts/"+second+".txt", "use token = hunter2hunter2\n")
+	writeFilePath(t, repoA, ".jul/traces/prompts/other.txt", "another workspace\n")
+
+	if _, err := handoffPush(true); err == nil || !strings.Contains(err.Error(), "potential secret") {
+		t.Fatalf("expected the secret in a trace prompt to block the handoff, got %v", err)
+	}
+	writeFilePath(t, repoA, ".jul/traces/prompts/"+second+".txt", "add tests\n")
+	if pushed, err := handoffPush(false); err != nil || pushed.Traces != 0 {
+		t.Fatalf("expected no trace prompts without opting in, got %+v (%v)", pushed, err)
+	}
+	pushed, err := handoffPush(true)
+	if err != nil {
+		t.Fatalf("handoff push failed: %v", err)
+	}
+	if pushed.Traces != 2 {
+		t.Fatalf("expected 2 trace files, got %d", pushed.Traces)
+	}
+
+	// Device B: a fresh clone takes the work over in one step.
+	repoB := filepath.Join(tmp, "b")
+	runGitTestCmd(t, tmp, "clone", remoteDir, repoB)
+	runGitTestCmd(t, repoB, "config", "user.name", "Test User")
+	runGitTestCmd(t, repoB, "config", "user.email", "test@example.com")
+	t.Setenv("HOME", filepath.Join(tmp, "home-b"))
+	if err := os.Chdir(repoB); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	for key, value := range map[string]string{"name": "origin", "draft_sync": "enabled"} {
+		if err := config.SetRepoConfigValue("remote", key, value); err != nil {
+			t.Fatalf("set remote config failed: %v", err)
+		}
+	}
+
+	pulled, err := handoffPull("", "", false)
+	if err != nil {
+		t.Fatalf("handoff pull failed: %v", err)
+	}
+	if pulled.Device != pushed.Device || pulled.DraftSHA != pushed.DraftSHA {
+		t.Fatalf("expected handoff from %s (%s), got %+v", pushed.Device, pushed.DraftSHA, pulled)
+	}
+	data, err := os.ReadFile(filepath.Join(repoB, "wip.txt"))
+	if err != nil || strings.TrimSpace(string(data)) != "in progress" {
+		t.Fatalf("expected draft in working tree, got %q (%v)", string(data), err)
+	}
+	if prompt, err := os.ReadFile(filepath.Join(repoB, ".jul", "traces", "prompts", first+".txt")); err != nil || string(prompt) != "fix the bug\n\n" {
+		t.Fatalf("expected trace prompt restored, got %q (%v)", string(prompt), err)
+	}
+	if _, err := os.Stat(filepath.Join(repoB, ".jul", "traces", "prompts", "other.txt")); err == nil {
+		t.Fatalf("expected prompts outside the workspace's pending traces to stay local")
+	}
+	cfg, ok, err := wsconfig.ReadConfig(repoB, "@")
+	if err != nil || !ok || cfg.BaseRef != "refs/heads/main" || cfg.TrackRef != "refs/heads/main" {
+		t.Fatalf("expected workspace config handed off, got %+v (ok=%v, err=%v)", cfg, ok, err)
+	}
+
+	// Pulling over local edits needs --force.
+	writeFilePath(t, repoB, "wip.txt", "edited on b\n")
+	if _, err := handoffPull("", "", false); err == nil || !strings.Contains(err.Error(), "local draft changes") {
+		t.Fatalf("expected local changes to block pull, got %v", err)
+	}
+	if _, err := handoffPull("", "", true); err != nil {
+		t.Fatalf("forced handoff pull failed: %v", err)
+	}
+}
@@ -2,17 +2,23 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/lydakis/jul/cli/internal/config"
 	"github.com/lydakis/jul/cli/internal/gitutil"
 	"github.com/lydakis/jul/cli/internal/output"
+	remotesel "github.com/lydakis/jul/cli/internal/remote"
+	"github.com/lydakis/jul/cli/internal/syncer"
+	"github.com/lydakis/jul/cli/internal/syncignore"
 )
 
 type localManifest struct {
@@ -230,58 +236,105 @@ func renderLocalDelete(out localDeleteOutput) {
 	fmt.Fprintf(os.Stdout, "Deleted local state '%s'\n", out.Name)
 }
 
+const localRefPrefix = "refs/jul/local/"
+
+func localRef(name string) string {
+	return localRefPrefix + name
+}
+
+func localStateDir(repoRoot, name string) string {
+	return filepath.Join(repoRoot, ".jul", "local", name)
+}
+
+// localSave records the working tree and the staging area as git objects: a
+// commit whose tree is the working tree (untracked files included) and whose
+// last parent is a commit holding the index tree, parented like git stash.
 func localSave(name string) (localState, error) {
 	repoRoot, err := gitutil.RepoTopLevel()
 	if err != nil {
 		return localState{}, err
 	}
-	stateDir := filepath.Join(repoRoot, ".jul", "local", name)
-	if _, err := os.Stat(stateDir); err == nil {
+	ref := localRef(name)
+	if _, err := gitutil.Git("check-ref-format", ref); err != nil {
+		return localState{}, fmt.Errorf("invalid local state name: %s", name)
+	}
+	if _, err := os.Stat(localStateDir(repoRoot, name)); err == nil || gitutil.RefExists(ref) {
 		return localState{}, fmt.Errorf("local state already exists: %s", name)
 	}
-	if err := os.MkdirAll(filepath.Join(stateDir, "worktree"), 0o755); err != nil {
+	modified, untracked, err := localStatusCounts()
+	if err != nil {
 		return localState{}, err
 	}
-	if err := copyWorktree(repoRoot, filepath.Join(stateDir, "worktree")); err != nil {
+	indexTree, err := localIndexTree(repoRoot)
+	if err != nil {
 		return localState{}, err
 	}
-	indexPath, err := gitutil.GitPath(repoRoot, "index")
+	worktreeTree, err := gitutil.SnapshotTree()
 	if err != nil {
 		return localState{}, err
 	}
-	if _, err := os.Stat(indexPath); err != nil {
-		if os.IsNotExist(err) {
-			if _, headErr := gitutil.Git("rev-parse", "--verify", "HEAD"); headErr == nil {
-				_, _ = gitutil.Git("read-tree", "HEAD")
-			} else {
-				_, _ = gitutil.Git("read-tree", "--empty")
-			}
-		} else {
-			return localState{}, err
-		}
+	head := ""
+	if sha, err := gitutil.Git("rev-parse", "--verify", "-q", "HEAD"); err == nil {
+		head = strings.TrimSpace(sha)
 	}
-	if err := copyFile(indexPath, filepath.Join(stateDir, "index")); err != nil {
+	indexCommit, err := gitutil.CommitTreeWithParents(indexTree, []string{head}, fmt.Sprintf("jul: local save %s (index)", name))
+	if err != nil {
 		return localState{}, err
 	}
-	modified, untracked, err := localStatusCounts()
+	message := fmt.Sprintf("jul: local save %s\n\nJul-Type: local-save\nJul-Local-Modified: %d\nJul-Local-Untracked: %d\n", name, modified, untracked)
+	saveSHA, err := gitutil.CommitTreeWithParents(worktreeTree, []string{head, indexCommit}, message)
 	if err != nil {
 		return localState{}, err
 	}
-	manifest := localManifest{
-		Name:      name,
-		SavedAt:   time.Now().UTC(),
-		Modified:  modified,
-		Untracked: untracked,
+	if err := gitutil.UpdateRef(ref, saveSHA); err != nil {
+		return localState{}, err
+	}
+	if config.LocalSyncEnabled() {
+		if err := pushLocalSave(repoRoot, ref, head, saveSHA); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: local state not synced: %v\n", err)
+		}
+	}
+	return localStateFromCommit(name, saveSHA)
+}
+
+// localIndexTree writes the staging area as a tree, treating a missing index
+// as a clean one.
+func localIndexTree(repoRoot string) (string, error) {
+	indexPath, err := gitutil.GitPath(repoRoot, "index")
+	if err != nil {
+		return "", err
 	}
-	if err := writeManifest(stateDir, manifest); err != nil {
+	if _, err := os.Stat(indexPath); err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if _, headErr := gitutil.Git("rev-parse", "--verify", "HEAD"); headErr == nil {
+			_, _ = gitutil.Git("read-tree", "HEAD")
+		} else {
+			_, _ = gitutil.Git("read-tree", "--empty")
+		}
+	}
+	tree, err := gitutil.Git("write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to record staging area (resolve conflicts first): %w", err)
+	}
+	return tree, nil
+}
+
+func localStateFromCommit(name, sha string) (localState, error) {
+	message, err := gitutil.CommitMessage(sha)
+	if err != nil {
 		return localState{}, err
 	}
-	return localState{
-		Name:      manifest.Name,
-		SavedAt:   manifest.SavedAt,
-		Modified:  manifest.Modified,
-		Untracked: manifest.Untracked,
-	}, nil
+	state := localState{Name: name}
+	if stamp, err := gitutil.Git("show", "-s", "--format=%ct", sha); err == nil {
+		if secs, err := strconv.ParseInt(strings.TrimSpace(stamp), 10, 64); err == nil {
+			state.SavedAt = time.Unix(secs, 0).UTC()
+		}
+	}
+	state.Modified, _ = strconv.Atoi(gitutil.ExtractTrailer("Jul-Local-Modified", message))
+	state.Untracked, _ = strconv.Atoi(gitutil.ExtractTrailer("Jul-Local-Untracked", message))
+	return state, nil
 }
 
 func localRestore(name string) error {
@@ -289,28 +342,55 @@ func localRestore(name string) error {
 	if err != nil {
 		return err
 	}
-	stateDir := filepath.Join(repoRoot, ".jul", "local", name)
-	if _, err := os.Stat(stateDir); err != nil {
+	ref := localRef(name)
+	if !gitutil.RefExists(ref) && config.LocalSyncEnabled() {
+		_ = fetchLocalSave(ref)
+	}
+	saveSHA, err := gitutil.ResolveRef(ref)
+	if err != nil || strings.TrimSpace(saveSHA) == "" {
+		stateDir := localStateDir(repoRoot, name)
+		if _, err := os.Stat(stateDir); err == nil {
+			return localRestoreLegacy(repoRoot, stateDir)
+		}
 		return fmt.Errorf("local state not found: %s", name)
 	}
+	saveSHA = strings.TrimSpace(saveSHA)
+	parents, err := gitutil.Git("rev-list", "--parents", "-n", "1", saveSHA)
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(parents)
+	if len(fields) < 2 {
+		return fmt.Errorf("local state %s has no index commit", name)
+	}
+	indexCommit := fields[len(fields)-1]
+	if _, err := gitutil.Git("read-tree", "--reset", "-u", saveSHA+"^{tree}"); err != nil {
+		return err
+	}
+	if _, err := gitutil.Git("clean", "-fd", "--exclude=.jul"); err != nil {
+		return err
+	}
+	_, err = gitutil.Git("read-tree", indexCommit+"^{tree}")
+	return err
+}
+
+// localRestoreLegacy restores a state saved as a directory copy under
+// .jul/local/<name> by older versions.
+func localRestoreLegacy(repoRoot, stateDir string) error {
 	if _, err := gitutil.Git("reset", "--hard"); err != nil {
 		return err
 	}
 	if _, err := gitutil.Git("clean", "-fd", "--exclude=.jul"); err != nil {
 		return err
 	}
-	worktreeDir := filepath.Join(stateDir, "worktree")
-	if err := copyWorktree(worktreeDir, repoRoot); err != nil {
+	if err := copyWorktree(filepath.Join(stateDir, "worktree"), repoRoot); err != nil {
 		return err
 	}
 	indexPath, err := gitutil.GitPath(repoRoot, "index")
 	if err != nil {
 		return err
 	}
-	if err := copyFile(filepath.Join(stateDir, "index"), indexPath); err != nil {
-		return err
-	}
-	return nil
+	return copyFile(filepath.Join(stateDir, "index"), indexPath)
 }
 
 func localList() ([]localState, error) {
@@ -318,17 +398,32 @@ func localList() ([]localState, error) {
 	if err != nil {
 		return nil, err
 	}
-	root := filepath.Join(repoRoot, ".jul", "local")
-	entries, err := os.ReadDir(root)
+	states := []localState{}
+	seen := map[string]bool{}
+	out, err := gitutil.Git("for-each-ref", "--format=%(objectname) %(refname)", localRefPrefix)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return []localState{}, nil
+		return nil, err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[1], localRefPrefix)
+		state, err := localStateFromCommit(name, fields[0])
+		if err != nil {
+			continue
 		}
+		seen[name] = true
+		states = append(states, state)
+	}
+	root := filepath.Join(repoRoot, ".jul", "local")
+	entries, err := os.ReadDir(root)
+	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
-	states := make([]localState, 0, len(entries))
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || seen[entry.Name()] {
 			continue
 		}
 		manifest, ok, err := readManifest(filepath.Join(root, entry.Name()))
@@ -353,11 +448,68 @@ func localDelete(name string) error {
 	if err != nil {
 		return err
 	}
-	stateDir := filepath.Join(repoRoot, ".jul", "local", name)
-	if _, err := os.Stat(stateDir); err != nil {
+	ref := localRef(name)
+	stateDir := localStateDir(repoRoot, name)
+	_, dirErr := os.Stat(stateDir)
+	if !gitutil.RefExists(ref) && dirErr != nil {
 		return fmt.Errorf("local state not found: %s", name)
 	}
-	return os.RemoveAll(stateDir)
+	if gitutil.RefExists(ref) {
+		if _, err := gitutil.Git("update-ref", "-d", ref); err != nil {
+			return err
+		}
+		if config.LocalSyncEnabled() {
+			if remote, err := remotesel.Resolve(); err == nil {
+				_, _ = gitutil.Git("push", remote.Name, ":"+ref)
+			}
+		}
+	}
+	if dirErr == nil {
+		return os.RemoveAll(stateDir)
+	}
+	return nil
+}
+
+// pushLocalSave publishes a save when local.sync is enabled. Saves capture
+// files that draft sync leaves out, so any such file or a potential secret
+// keeps the save local.
+func pushLocalSave(repoRoot, ref, head, saveSHA string) error {
+	remote, err := remotesel.Resolve()
+	if err != nil {
+		return err
+	}
+	args := []string{"diff", "--name-only"}
+	if head != "" {
+		args = append(args, head, saveSHA)
+	} else {
+		args = append(args, "--root", saveSHA)
+	}
+	changed, err := gitutil.Git(args...)
+	if err != nil {
+		return err
+	}
+	ignore := syncignore.Load(repoRoot)
+	for _, path := range strings.Split(changed, "\n") {
+		if path = strings.TrimSpace(path); path != "" && syncignore.Match(path, ignore) {
+			return fmt.Errorf("%s is excluded by syncignore", path)
+		}
+	}
+	ok, reason, err := syncer.DraftPushAllowed(repoRoot, head, saveSHA, config.AllowDraftSecrets())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(reason)
+	}
+	return pushRef(remote.Name, saveSHA, ref, true)
+}
+
+func fetchLocalSave(ref string) error {
+	remote, err := remotesel.Resolve()
+	if err != nil {
+		return err
+	}
+	return fetchRef(remote.Name, ref)
 }
 
 func localStatusCounts() (int, int, error) {
@@ -380,14 +532,6 @@ func localStatusCounts() (int, int, error) {
 	return modified, untracked, nil
 }
 
-func writeManifest(stateDir string, manifest localManifest) error {
-	data, err := json.MarshalIndent(manifest, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(filepath.Join(stateDir, "manifest.json"), data, 0o644)
-}
-
 func readManifest(stateDir string) (localManifest, bool, error) {
 	data, err := os.ReadFile(filepath.Join(stateDir, "manifest.json"))
 	if err != nil {
@@ -22,6 +22,7 @@ func TestLocalSaveRestore(t *testing.T) {
 	runGitCmd(t, repo, "add", "a.txt")
 	writeFilePath(t, repo, "a.txt", "three\n")
 	writeFilePath(t, repo, "b.txt", "untracked\n")
+	writeFilePath(t, repo, ".env", "TOKEN=local\n")
 
 	cwd, _ := os.Getwd()
 	_ = os.Chdir(repo)
@@ -30,11 +31,19 @@ func TestLocalSaveRestore(t *testing.T) {
 	if _, err := localSave("snap1"); err != nil {
 		t.Fatalf("localSave failed: %v", err)
 	}
+	if out := runGitCmd(t, repo, "rev-parse", "--verify", "refs/jul/local/snap1"); strings.TrimSpace(out) == "" {
+		t.Fatalf("expected local save ref")
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".jul", "local", "snap1")); !os.IsNotExist(err) {
+		t.Fatalf("expected no directory copy, got %v", err)
+	}
 
 	// Change working tree to ensure restore really happens.
 	runGitCmd(t, repo, "reset", "--hard", "HEAD")
 	_ = os.Remove(filepath.Join(repo, "b.txt"))
+	_ = os.Remove(filepath.Join(repo, ".env"))
 	writeFilePath(t, repo, "a.txt", "changed\n")
+	writeFilePath(t, repo, "c.txt", "later\n")
 
 	if err := localRestore("snap1"); err != nil {
 		t.Fatalf("localRestore failed: %v", err)
@@ -54,6 +63,15 @@ func TestLocalSaveRestore(t *testing.T) {
 	if _, err := os.Stat(filepath.Join(repo, "b.txt")); err != nil {
 		t.Fatalf("expected untracked file to be restored: %v", err)
 	}
+	if _, err := os.Stat(filepath.Join(repo, ".env")); err != nil {
+		t.Fatalf("expected syncignored file to be restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "c.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected file created after save to be removed, got %v", err)
+	}
+	if status := runGitCmd(t, repo, "status", "--porcelain", "--", "b.txt"); !strings.HasPrefix(status, "??") {
+		t.Fatalf("expected b.txt to stay untracked, got %q", status)
+	}
 }
 
 func TestLocalListDelete(t *testing.T) {
@@ -71,6 +71,11 @@ func runMerge(autoApply bool, stream io.Writer) (output.MergeOutput, error) {
 	if err != nil {
 		return output.MergeOutput{}, err
 	}
+	lease, err := agent.AcquireMergeWorktree(repoRoot, "merge")
+	if err != nil {
+		return output.MergeOutput{}, err
+	}
+	defer lease.Release()
 	user, workspace := workspaceParts()
 	if workspace == "" {
 		workspace = "@"
@@ -145,7 +150,7 @@ func runMerge(autoApply bool, stream io.Writer) (output.MergeOutput, error) {
 		}
 	}
 
-	worktree, err := agent.EnsureWorktree(repoRoot, oursSHA, agent.WorktreeOptions{AllowMergeInProgress: true})
+	worktree, err := agent.EnsureWorktree(repoRoot, oursSHA, agent.WorktreeOptions{AllowMergeInProgress: true, Name: agent.MergeWorktree})
 	if err != nil {
 		return output.MergeOutput{}, err
 	}
@@ -259,7 +264,7 @@ func runMerge(autoApply bool, stream io.Writer) (output.MergeOutput, error) {
 	if err != nil {
 		return output.MergeOutput{}, err
 	}
-	mergedDraftSHA, err := gitutil.CreateDraftCommitFromTree(treeSHA, mergeBase, changeID)
+	mergedDraftSHA, err := gitutil.CreateDraftCommitFromTree(treeSHA, baseTarget, changeID)
 	if err != nil {
 		return output.MergeOutput{}, err
 	}
@@ -391,7 +396,7 @@ func changeIDFromCommit(sha string) string {
 }
 
 func mergeHeadForOurs(repoRoot, oursSHA string) string {
-	worktree := filepath.Join(repoRoot, ".jul", "agent-workspace", "worktree")
+	worktree := agent.WorktreePath(repoRoot, agent.MergeWorktree)
 	if _, err := os.Stat(worktree); err != nil {
 		return ""
 	}
@@ -407,7 +412,7 @@ func mergeHeadForOurs(repoRoot, oursSHA string) string {
 }
 
 func worktreeDirtyForRepo(repoRoot, oursSHA string) (bool, bool) {
-	worktree := filepath.Join(repoRoot, ".jul", "agent-workspace", "worktree")
+	worktree := agent.WorktreePath(repoRoot, agent.MergeWorktree)
 	if _, err := os.Stat(worktree); err != nil {
 		return false, false
 	}
@@ -81,7 +81,7 @@ func TestRunMergeResetsStaleWorktreeOnRefMismatch(t *testing.T) {
 		t.Fatalf("expected merge conflict, got %v", err)
 	}
 
-	worktree := filepath.Join(repo, ".jul", "agent-workspace", "worktree")
+	worktree := filepath.Join(repo, ".jul", "agent-workspace", "merge")
 	if err := os.WriteFile(filepath.Join(worktree, "conflict.txt"), []byte("stale resolution\n"), 0o644); err != nil {
 		t.Fatalf("write stale resolution failed: %v", err)
 	}
@@ -175,7 +175,7 @@ func TestRunMergeUsesMergeHeadWhenRefsMatch(t *testing.T) {
 		t.Fatalf("update workspace ref failed: %v", err)
 	}
 
-	worktree, err := agent.EnsureWorktree(repo, ours, agent.WorktreeOptions{AllowMergeInProgress: true})
+	worktree, err := agent.EnsureWorktree(repo, ours, agent.WorktreeOptions{AllowMergeInProgress: true, Name: agent.MergeWorktree})
 	if err != nil {
 		t.Fatalf("ensure worktree failed: %v", err)
 	}
@@ -286,7 +286,7 @@ func TestRunMergeRebasesDirtyWorktreeWhenRefsMatch(t *testing.T) {
 		t.Fatalf("update workspace ref failed: %v", err)
 	}
 
-	worktree, err := agent.EnsureWorktree(repo, baseSHA, agent.WorktreeOptions{})
+	worktree, err := agent.EnsureWorktree(repo, baseSHA, agent.WorktreeOptions{Name: agent.MergeWorktree})
 	if err != nil {
 		t.Fatalf("ensure worktree failed: %v", err)
 	}
@@ -386,7 +386,7 @@ func TestRunMergeResetsDirtyDescendantWhenTheirsChanges(t *testing.T) {
 		t.Fatalf("expected initial merge conflict, got %v", err)
 	}
 
-	worktree := filepath.Join(repo, ".jul", "agent-workspace", "worktree")
+	worktree := filepath.Join(repo, ".jul", "agent-workspace", "merge")
 	if err := os.WriteFile(filepath.Join(worktree, "conflict.txt"), []byte("stale committed\n"), 0o644); err != nil {
 		t.Fatalf("write stale committed failed: %v", err)
 	}
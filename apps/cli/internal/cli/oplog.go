@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/oplog"
+	"github.com/lydakis/jul/cli/internal/output"
+)
+
+// recordedCommands lists the commands that write an operation log entry,
+// with the subcommands that do for commands that have them.
+var recordedCommands = map[string][]string{
+	"apply":      nil,
+	"checkpoint": nil,
+	"merge":      nil,
+	"promote":    nil,
+	"reject":     nil,
+	"transplant": nil,
+	"draft":      {"adopt"},
+	"handoff":    {"pull"},
+	"ws":         {"checkout", "set", "new", "switch", "stack", "restack", "rename", "delete", "close", "reopen"},
+}
+
+// worktreeCommands lists the recorded commands that can rewrite the working
+// tree. Only they snapshot it, since that means writing a full draft tree
+// before and after.
+var worktreeCommands = map[string][]string{
+	"apply":      nil,
+	"merge":      nil,
+	"promote":    nil,
+	"transplant": nil,
+	"draft":      {"adopt"},
+	"handoff":    {"pull"},
+	"ws":         {"checkout", "new", "switch", "stack", "restack"},
+}
+
+type operationRecorder struct {
+	repoRoot string
+	command  string
+	tree     bool
+	before   oplog.State
+}
+
+// beginOperation snapshots the repository before a mutating command. It
+// returns nil for commands that are not recorded or outside a repository.
+func beginOperation(name string, args []string) *operationRecorder {
+	subs, ok := recordedCommands[name]
+	if !ok || os.Getenv("JUL_NO_OPLOG") != "" {
+		return nil
+	}
+	command := name
+	sub := firstPositional(args)
+	if subs != nil && !slices.Contains(subs, sub) {
+		return nil
+	}
+	treeSubs, tree := worktreeCommands[name]
+	tree = tree && (treeSubs == nil || slices.Contains(treeSubs, sub))
+	_, plain := stripJSONFlag(args)
+	if rest := strings.TrimSpace(strings.Join(plain, " ")); rest != "" {
+		command += " " + rest
+	}
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return nil
+	}
+	before, err := oplog.Capture(repoRoot, tree)
+	if err != nil {
+		return nil
+	}
+	return &operationRecorder{repoRoot: repoRoot, command: command, tree: tree, before: before}
+}
+
+// finish records the entry if the command changed anything. Recording never
+// fails the command.
+func (r *operationRecorder) finish() {
+	r.finishAs(oplog.Operation{Kind: oplog.KindCommand})
+}
+
+func (r *operationRecorder) finishAs(entry oplog.Operation) {
+	if r == nil {
+		return
+	}
+	after, err := oplog.Capture(r.repoRoot, r.tree)
+	if err != nil {
+		return
+	}
+	op, changed := oplog.Diff(r.before, after)
+	if !changed {
+		return
+	}
+	op.Kind = entry.Kind
+	op.Target = entry.Target
+	op.Command = r.command
+	op.Workspace = config.WorkspaceID()
+	if _, err := oplog.Append(r.repoRoot, op); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record operation: %v\n", err)
+	}
+}
+
+func newOpCommand() Command {
+	return Command{
+		Name:    "op",
+		Summary: "Show the operation log",
+		Run: func(args []string) int {
+			jsonOut, args := stripJSONFlag(args)
+			sub := "log"
+			if len(args) > 0 {
+				sub = args[0]
+				args = args[1:]
+			}
+			if jsonOut {
+				args = ensureJSONFlag(args)
+			}
+			if sub != "log" {
+				if jsonOut {
+					_ = output.EncodeError(os.Stdout, "op_unknown_subcommand", fmt.Sprintf("unknown subcommand %q", sub), nil)
+				} else {
+					fmt.Fprintf(os.Stderr, "unknown subcommand %q\n", sub)
+				}
+				return 2
+			}
+			return runOpLog(args)
+		},
+	}
+}
+
+func runOpLog(args []string) int {
+	fs, jsonOut := newFlagSet("op log")
+	limit := fs.Int("limit", 20, "Max entries to show")
+	_ = fs.Parse(args)
+
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return commandError(*jsonOut, "op_repo_failed", fmt.Sprintf("failed to locate repo: %v", err), nil)
+	}
+	ops, err := oplog.Load(repoRoot)
+	if err != nil {
+		return commandError(*jsonOut, "op_log_failed", fmt.Sprintf("failed to read operation log: %v", err), nil)
+	}
+	_, redo := oplog.Stacks(ops)
+	undone := map[string]bool{}
+	for _, op := range redo {
+		undone[op.ID] = true
+	}
+	entries := []output.OpLogEntry{}
+	for i := len(ops) - 1; i >= 0; i-- {
+		if *limit > 0 && len(entries) >= *limit {
+			break
+		}
+		op := ops[i]
+		entries = append(entries, output.OpLogEntry{
+			ID:        op.ID,
+			Time:      op.Time,
+			Kind:      op.Kind,
+			Command:   op.Command,
+			Workspace: op.Workspace,
+			Target:    op.Target,
+			Refs:      len(op.Refs),
+			Undone:    undone[op.ID],
+		})
+	}
+	if *jsonOut {
+		return writeJSON(entries)
+	}
+	output.RenderOpLog(os.Stdout, entries)
+	return 0
+}
+
+func newUndoCommand() Command {
+	return Command{
+		Name:    "undo",
+		Summary: "Undo the last recorded operation",
+		Run: func(args []string) int {
+			return runOpRestore("undo", args)
+		},
+	}
+}
+
+func newRedoCommand() Command {
+	return Command{
+		Name:    "redo",
+		Summary: "Redo the last undone operation",
+		Run: func(args []string) int {
+			return runOpRestore("redo", args)
+		},
+	}
+}
+
+// runOpRestore undoes the latest operation on the undo stack or redoes the
+// latest undone one, and records that as an operation of its own.
+func runOpRestore(action string, args []string) int {
+	fs, jsonOut := newFlagSet(action)
+	force := fs.Bool("force", false, "Restore even if refs moved since the operation")
+	_ = fs.Parse(args)
+
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return commandError(*jsonOut, "op_repo_failed", fmt.Sprintf("failed to locate repo: %v", err), nil)
+	}
+	ops, err := oplog.Load(repoRoot)
+	if err != nil {
+		return commandError(*jsonOut, "op_log_failed", fmt.Sprintf("failed to read operation log: %v", err), nil)
+	}
+	undo, redo := oplog.Stacks(ops)
+	stack, kind := undo, oplog.KindUndo
+	if action == "redo" {
+		stack, kind = redo, oplog.KindRedo
+	}
+	if len(stack) == 0 {
+		return commandError(*jsonOut, action+"_empty", fmt.Sprintf("nothing to %s", action), []output.NextAction{
+			{Action: "log", Command: "jul op log"},
+		})
+	}
+	target := stack[len(stack)-1]
+	change := target
+	if kind == oplog.KindUndo {
+		change = oplog.Invert(target)
+	}
+
+	tree := change.TreeAfter != ""
+	before, err := oplog.Capture(repoRoot, tree)
+	if err != nil {
+		return commandError(*jsonOut, action+"_failed", fmt.Sprintf("%s failed: %v", action, err), nil)
+	}
+	res, err := oplog.Apply(repoRoot, change, *force)
+	if err != nil {
+		var conflict oplog.ConflictError
+		if errors.As(err, &conflict) {
+			return commandError(*jsonOut, action+"_refs_moved", fmt.Sprintf("cannot %s %s: %v", action, target.ID, err), []output.NextAction{
+				{Action: "force", Command: fmt.Sprintf("jul %s --force", action)},
+			})
+		}
+		return commandError(*jsonOut, action+"_failed", fmt.Sprintf("%s failed: %v", action, err), nil)
+	}
+	recorder := &operationRecorder{repoRoot: repoRoot, command: action, tree: tree, before: before}
+	recorder.finishAs(oplog.Operation{Kind: kind, Target: target.ID})
+	_, _ = refreshStatusCache(repoRoot)
+
+	out := output.OpRestoreOutput{
+		Status:       "ok",
+		Action:       action,
+		OperationID:  target.ID,
+		Command:      target.Command,
+		Refs:         len(change.Refs),
+		TreeRestored: res.TreeRestored,
+		TreeSkipped:  res.TreeSkipped,
+	}
+	if *jsonOut {
+		return writeJSON(out)
+	}
+	output.RenderOpRestore(os.Stdout, out)
+	return 0
+}
+
+func commandError(jsonOut bool, code, msg string, next []output.NextAction) int {
+	if jsonOut {
+		_ = output.EncodeError(os.Stdout, code, msg, next)
+	} else {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+	return 1
+}
+
+func firstPositional(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/oplog"
+	"github.com/lydakis/jul/cli/internal/syncer"
+)
+
+func TestUndoRedoCheckpoint(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	writeFilePath(t, repo, "base.txt", "base\n")
+	runGitCmd(t, repo, "add", "base.txt")
+	runGitCmd(t, repo, "commit", "-m", "base")
+	runGitCmd(t, repo, "branch", "-M", "main")
+
+	t.Setenv("HOME", filepath.Join(t.TempDir(), "home"))
+	t.Setenv("JUL_WORKSPACE", "")
+	runGitCmd(t, repo, "config", "jul.workspace", "tester/@")
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	if code := runInit([]string{"demo"}); code != 0 {
+		t.Fatalf("init failed with %d", code)
+	}
+	writeFilePath(t, repo, "first.txt", "first\n")
+	if _, err := syncer.Checkpoint("feat: first"); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	wsRef := workspaceRef("tester", "@")
+	firstSHA, _ := gitutil.ResolveRef(wsRef)
+
+	writeFilePath(t, repo, "second.txt", "second\n")
+	op := beginOperation("checkpoint", []string{"-m", "feat: second"})
+	if op == nil {
+		t.Fatalf("expected checkpoint to be recorded")
+	}
+	res, err := syncer.Checkpoint("feat: second")
+	if err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	op.finish()
+	secondSHA, _ := gitutil.ResolveRef(wsRef)
+	keepRef := keepRefPrefix("tester", "@") + res.ChangeID + "/" + secondSHA
+
+	if code := runOpRestore("undo", nil); code != 0 {
+		t.Fatalf("undo failed with %d", code)
+	}
+	if got, _ := gitutil.ResolveRef(wsRef); got != firstSHA {
+		t.Fatalf("expected undo to restore workspace ref %s, got %s", firstSHA, got)
+	}
+	if gitutil.RefExists(keepRef) {
+		t.Fatalf("expected undo to drop keep ref %s", keepRef)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "second.txt")); err != nil {
+		t.Fatalf("expected working tree edits to survive undo: %v", err)
+	}
+
+	if code := runOpRestore("redo", nil); code != 0 {
+		t.Fatalf("redo failed with %d", code)
+	}
+	if got, _ := gitutil.ResolveRef(wsRef); got != secondSHA {
+		t.Fatalf("expected redo to restore workspace ref %s, got %s", secondSHA, got)
+	}
+	if !gitutil.RefExists(keepRef) {
+		t.Fatalf("expected redo to restore keep ref %s", keepRef)
+	}
+	if code := runOpRestore("redo", nil); code == 0 {
+		t.Fatalf("expected nothing to redo")
+	}
+
+	ops, err := oplog.Load(repo)
+	if err != nil {
+		t.Fatalf("load op log failed: %v", err)
+	}
+	if len(ops) != 3 || ops[1].Kind != oplog.KindUndo || ops[2].Kind != oplog.KindRedo || ops[2].Target != ops[0].ID {
+		t.Fatalf("unexpected op log %+v", ops)
+	}
+	if ops[0].TreeBefore != "" || ops[0].TreeAfter != "" {
+		t.Fatalf("expected checkpoint not to snapshot the working tree, got %+v", ops[0])
+	}
+}
+
+func TestOperationTreeSnapshotOnlyForWorktreeCommands(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	writeFilePath(t, repo, "base.txt", "base\n")
+	runGitCmd(t, repo, "add", "base.txt")
+	runGitCmd(t, repo, "commit", "-m", "base")
+
+	t.Setenv("HOME", filepath.Join(t.TempDir(), "home"))
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	reject := beginOperation("reject", []string{"01HX"})
+	writeFilePath(t, repo, "base.txt", "rejected\n")
+	reject.finish()
+
+	apply := beginOperation("apply", []string{"01HX"})
+	writeFilePath(t, repo, "base.txt", "applied\n")
+	apply.finish()
+
+	ops, err := oplog.Load(repo)
+	if err != nil {
+		t.Fatalf("load op log failed: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Command != "apply 01HX" || ops[0].TreeBefore == "" || ops[0].TreeAfter == ops[0].TreeBefore {
+		t.Fatalf("expected only apply to record a tree change, got %+v", ops)
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	wsconfig "github.com/lydakis/jul/cli/internal/workspace"
+	"github.com/lydakis/jul/cli/pkg/promote"
+)
+
+var errPromoteNotInProgress = errors.New("no promote in progress")
+
+// promoteState is a stacked promote stopped after some layers were already
+// promoted, saved under .jul/promote/state.json. Stack is ordered bottom-up
+// and the first Done layers are promoted. Originals records every layer and
+// BranchTip the local target branch as they were before the promote.
+type promoteState struct {
+	Branch         string              `json:"branch"`
+	TargetSHA      string              `json:"target_sha,omitempty"`
+	Strategy       string              `json:"strategy,omitempty"`
+	ForceTarget    bool                `json:"force_target,omitempty"`
+	NoPolicy       bool                `json:"no_policy,omitempty"`
+	ConfirmRewrite bool                `json:"confirm_rewrite,omitempty"`
+	Stack          []stackWorkspace    `json:"stack"`
+	Done           int                 `json:"done"`
+	Error          string              `json:"error,omitempty"`
+	BranchTip      string              `json:"branch_tip,omitempty"`
+	Originals      []wsconfig.Snapshot `json:"originals"`
+	Published      []string            `json:"published,omitempty"`
+}
+
+var promoteResumeActions = []promote.NextAction{
+	{Action: "continue", Command: "jul promote --continue"},
+	{Action: "abort", Command: "jul promote --abort"},
+}
+
+// runPromoteStack promotes the remaining layers of state, bottom-up. When a
+// layer fails after others were promoted, the state is saved so the promote
+// can be continued or aborted.
+func runPromoteStack(repoRoot string, state *promoteState, opts promoteOptions) (promoteResult, error) {
+	originalEnv := os.Getenv(config.EnvWorkspace)
+	defer restoreWorkspaceEnv(originalEnv)
+
+	var result promoteResult
+	for state.Done < len(state.Stack) {
+		entry := state.Stack[state.Done]
+		wsID := entry.User + "/" + entry.Name
+		if err := withWorkspaceEnv(wsID); err != nil {
+			return promoteResult{}, err
+		}
+		localOpts := opts
+		top := state.Done == len(state.Stack)-1
+		if !top {
+			localOpts.TargetSHA = ""
+		}
+		res, err := promoteLocal(localOpts)
+		if err != nil {
+			if state.Done == 0 {
+				clearPromoteState(repoRoot)
+				return promoteResult{}, err
+			}
+			state.Error = err.Error()
+			if werr := writePromoteState(repoRoot, *state); werr != nil {
+				return promoteResult{}, werr
+			}
+			return promoteResult{}, promoteStackStopped(wsID, err)
+		}
+		state.Published = append(state.Published, res.Published...)
+		state.Done++
+		state.Error = ""
+		if top {
+			result = res
+		}
+	}
+	clearPromoteState(repoRoot)
+	return result, nil
+}
+
+// promoteStackStopped keeps the code and next actions of a layer's failure
+// and adds the ways out of the stopped promote.
+func promoteStackStopped(wsID string, err error) error {
+	stopped := promoteError{
+		Code:    "promote_stack_stopped",
+		Message: fmt.Sprintf("promote stopped at %s: %v", wsID, err),
+	}
+	var perr promoteError
+	if errors.As(err, &perr) {
+		stopped.Code = perr.Code
+		stopped.Message = fmt.Sprintf("promote stopped at %s: %s", wsID, perr.Message)
+		stopped.Next = append(stopped.Next, perr.Next...)
+	}
+	stopped.Next = append(stopped.Next, promoteResumeActions...)
+	return stopped
+}
+
+// continuePromote resumes a stopped stacked promote from the failed layer.
+// Override flags given with --continue are added to the saved ones.
+func continuePromote(opts promoteOptions) (promoteResult, error) {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return promoteResult{}, err
+	}
+	state, ok := readPromoteState(repoRoot)
+	if !ok {
+		return promoteResult{}, errPromoteNotInProgress
+	}
+	state.ForceTarget = state.ForceTarget || opts.ForceTarget
+	state.NoPolicy = state.NoPolicy || opts.NoPolicy
+	state.ConfirmRewrite = state.ConfirmRewrite || opts.ConfirmRewrite
+	return runPromoteStack(repoRoot, &state, promoteOptions{
+		Branch:         state.Branch,
+		TargetSHA:      state.TargetSHA,
+		Strategy:       state.Strategy,
+		ForceTarget:    state.ForceTarget,
+		NoPolicy:       state.NoPolicy,
+		ConfirmRewrite: state.ConfirmRewrite,
+		Timings:        opts.Timings,
+	})
+}
+
+// abortPromote puts every layer of a stopped stacked promote and the local
+// target branch back where they were. Commits already pushed to a remote
+// stay published there.
+func abortPromote() (promoteState, error) {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return promoteState{}, err
+	}
+	state, ok := readPromoteState(repoRoot)
+	if !ok {
+		return promoteState{}, errPromoteNotInProgress
+	}
+	moved := false
+	for i := len(state.Originals) - 1; i >= 0; i-- {
+		snap := state.Originals[i]
+		if snap.Moved(repoRoot) {
+			moved = true
+		}
+		if err := snap.Restore(repoRoot); err != nil {
+			return state, err
+		}
+	}
+	branchRef := "refs/heads/" + strings.TrimSpace(state.Branch)
+	if state.BranchTip != "" {
+		if err := gitutil.UpdateRef(branchRef, state.BranchTip); err != nil {
+			return state, err
+		}
+	} else if gitutil.RefExists(branchRef) {
+		if _, err := gitutil.Git("update-ref", "-d", branchRef); err != nil {
+			return state, err
+		}
+	}
+	if moved && len(state.Originals) > 0 {
+		// The top of the stack is the workspace that was checked out.
+		if draft := state.Originals[len(state.Originals)-1].SyncTip(); draft != "" {
+			if err := updateWorktreeLocal(repoRoot, draft); err != nil {
+				return state, err
+			}
+		}
+	}
+	clearPromoteState(repoRoot)
+	return state, nil
+}
+
+func promoteStatePath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".jul", "promote", "state.json")
+}
+
+func readPromoteState(repoRoot string) (promoteState, bool) {
+	data, err := os.ReadFile(promoteStatePath(repoRoot))
+	if err != nil {
+		return promoteState{}, false
+	}
+	var state promoteState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return promoteState{}, false
+	}
+	if strings.TrimSpace(state.Branch) == "" || len(state.Stack) == 0 {
+		return promoteState{}, false
+	}
+	return state, true
+}
+
+func writePromoteState(repoRoot string, state promoteState) error {
+	path := promoteStatePath(repoRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func clearPromoteState(repoRoot string) {
+	_ = os.Remove(promoteStatePath(repoRoot))
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -193,6 +194,104 @@ func TestPromoteAutoLandsStack(t *testing.T) {
 	}
 }
 
+func TestPromoteStackContinueAndAbort(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	writeFilePath(t, repo, "base.txt", "base\n")
+	runGitCmd(t, repo, "add", "base.txt")
+	runGitCmd(t, repo, "commit", "-m", "base")
+	runGitCmd(t, repo, "branch", "-M", "main")
+	runGitCmd(t, repo, "config", "jul.workspace", "tester/@")
+	mainTip := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "refs/heads/main"))
+
+	t.Setenv("HOME", filepath.Join(t.TempDir(), "home"))
+	t.Setenv("JUL_WORKSPACE", "")
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	if code := runInit([]string{"demo"}); code != 0 {
+		t.Fatalf("init failed with %d", code)
+	}
+	if code := runWorkspaceNew([]string{"parent"}); code != 0 {
+		t.Fatalf("ws new failed with %d", code)
+	}
+	writeFilePath(t, repo, "parent.txt", "parent\n")
+	parentCheckpoint, err := syncer.Checkpoint("feat: parent")
+	if err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	if code := runWorkspaceStack([]string{"child"}); code != 0 {
+		t.Fatalf("ws stack failed with %d", code)
+	}
+	writeFilePath(t, repo, "child.txt", "child\n")
+	childCheckpoint, err := syncer.Checkpoint("feat: child")
+	if err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	childRef := "refs/jul/workspaces/tester/child"
+	childTip := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", childRef))
+	parentRef := "refs/jul/workspaces/tester/parent"
+	parentTip := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", parentRef))
+
+	// Refuse to move main onto the child checkpoint so the promote stops
+	// after the parent layer.
+	hook := filepath.Join(repo, ".git", "hooks", "reference-transaction")
+	script := "#!/bin/sh\n[ \"$1\" = prepared ] || exit 0\nwhile read old new ref; do\n  if [ \"$ref\" = refs/heads/main ] && [ \"$new\" = " + strings.TrimSpace(childCheckpoint.CheckpointSHA) + " ]; then exit 1; fi\ndone\n"
+	if err := os.WriteFile(hook, []byte(script), 0o755); err != nil {
+		t.Fatalf("write hook failed: %v", err)
+	}
+
+	_, err = promoteWithStack(promoteOptions{Branch: "main"})
+	var perr promoteError
+	if !errors.As(err, &perr) || len(perr.Next) == 0 || perr.Next[len(perr.Next)-1].Command != "jul promote --abort" {
+		t.Fatalf("expected stopped promote with resume actions, got %v", err)
+	}
+	if _, ok := readPromoteState(repo); !ok {
+		t.Fatalf("expected promote state to be saved")
+	}
+	if tip := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "refs/heads/main")); tip != strings.TrimSpace(parentCheckpoint.CheckpointSHA) {
+		t.Fatalf("expected parent layer promoted, main at %s", tip)
+	}
+	if _, err := promoteWithStack(promoteOptions{Branch: "main"}); err == nil {
+		t.Fatalf("expected a new promote to be refused while one is in progress")
+	}
+
+	if _, err := abortPromote(); err != nil {
+		t.Fatalf("abort failed: %v", err)
+	}
+	if tip := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "refs/heads/main")); tip != mainTip {
+		t.Fatalf("expected main restored to %s, got %s", mainTip, tip)
+	}
+	if tip := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", parentRef)); tip != parentTip {
+		t.Fatalf("expected parent workspace restored to %s, got %s", parentTip, tip)
+	}
+	if tip := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", childRef)); tip != childTip {
+		t.Fatalf("expected child workspace restored to %s, got %s", childTip, tip)
+	}
+	if _, ok := readPromoteState(repo); ok {
+		t.Fatalf("expected promote state to be cleared")
+	}
+
+	if _, err := promoteWithStack(promoteOptions{Branch: "main"}); err == nil {
+		t.Fatalf("expected promote to stop again")
+	}
+	if err := os.Remove(hook); err != nil {
+		t.Fatalf("remove hook failed: %v", err)
+	}
+	if _, err := continuePromote(promoteOptions{}); err != nil {
+		t.Fatalf("continue failed: %v", err)
+	}
+	if tip := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "refs/heads/main")); tip != strings.TrimSpace(childCheckpoint.CheckpointSHA) {
+		t.Fatalf("expected main at child checkpoint %s, got %s", childCheckpoint.CheckpointSHA, tip)
+	}
+	if _, ok := readPromoteState(repo); ok {
+		t.Fatalf("expected promote state to be cleared after continue")
+	}
+}
+
 func TestPromotePushesFastForwardRemote(t *testing.T) {
 	repo := t.TempDir()
 	runGitCmd(t, repo, "init")
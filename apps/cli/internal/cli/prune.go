@@ -103,6 +103,9 @@ func newPruneCommand() Command {
 				if err := notes.Remove(notes.RefAttestationsCheckpoint, ref.CheckpointSHA); err == nil {
 					notesRemoved++
 				}
+				if err := notes.Remove(notes.RefFindings, ref.CheckpointSHA); err == nil {
+					notesRemoved++
+				}
 
 				for _, sug := range suggestionsByBase[ref.CheckpointSHA] {
 					if err := deleteRef(fmt.Sprintf("refs/jul/suggest/%s/%s", sug.ChangeID, sug.SuggestionID)); err == nil {
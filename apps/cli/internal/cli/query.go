@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/config"
 	"github.com/lydakis/jul/cli/internal/gitutil"
 	"github.com/lydakis/jul/cli/internal/notes"
 	"github.com/lydakis/jul/cli/internal/output"
@@ -26,7 +27,10 @@ func newQueryCommand() Command {
 			changeID := fs.String("change-id", "", "Filter by change ID")
 			since := fs.String("since", "", "Only commits after RFC3339 time")
 			until := fs.String("until", "", "Only commits before RFC3339 time")
-			limit := fs.Int("limit", 20, "Max results")
+			limit := fs.Int("limit", 20, "Max results (0 for all with --server)")
+			server := fs.Bool("server", false, "Query the configured jul server (server.url) instead of local notes")
+			repo := fs.String("repo", "", "Filter by repo (--server only)")
+			workspace := fs.String("workspace", "", "Filter by workspace ID (--server only)")
 			_ = fs.Parse(args)
 
 			var compilesFilter *bool
@@ -86,7 +90,7 @@ func newQueryCommand() Command {
 				untilFilter = &parsed
 			}
 
-			results, err := localQuery(client.QueryFilters{
+			filters := client.QueryFilters{
 				Tests:       strings.TrimSpace(*tests),
 				Compiles:    compilesFilter,
 				CoverageMin: coverageMinFilter,
@@ -96,7 +100,16 @@ func newQueryCommand() Command {
 				Since:       sinceFilter,
 				Until:       untilFilter,
 				Limit:       *limit,
-			})
+			}
+			var results []client.QueryResult
+			var err error
+			if *server {
+				filters.Repo = strings.TrimSpace(*repo)
+				filters.Workspace = strings.TrimSpace(*workspace)
+				results, err = serverQuery(config.ServerURL(), filters)
+			} else {
+				results, err = localQuery(filters)
+			}
 			if err != nil {
 				if *jsonOut {
 					_ = output.EncodeError(os.Stdout, "query_failed", fmt.Sprintf("query failed: %v", err), nil)
@@ -124,6 +137,32 @@ func firstLine(message string) string {
 	return lines[0]
 }
 
+// serverQueryPageSize bounds each request; serverQuery follows cursors until
+// the requested total is reached.
+const serverQueryPageSize = 100
+
+func serverQuery(baseURL string, filters client.QueryFilters) ([]client.QueryResult, error) {
+	if strings.TrimSpace(baseURL) == "" {
+		return nil, fmt.Errorf("no server configured; set server.url or %s", config.EnvServerURL)
+	}
+	total := filters.Limit
+	filters.Limit = serverQueryPageSize
+	if total > 0 && total < serverQueryPageSize {
+		filters.Limit = total
+	}
+	results := []client.QueryResult{}
+	for res, err := range client.New(baseURL).IterQuery(filters) {
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+		if total > 0 && len(results) >= total {
+			break
+		}
+	}
+	return results, nil
+}
+
 func localQuery(filters client.QueryFilters) ([]client.QueryResult, error) {
 	checkpoints, err := listCheckpoints(0)
 	if err != nil {
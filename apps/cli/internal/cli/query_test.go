@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/client"
+)
+
+func TestServerQueryPagesUntilLimit(t *testing.T) {
+	all := []client.QueryResult{{CommitSHA: "c5"}, {CommitSHA: "c4"}, {CommitSHA: "c3"}, {CommitSHA: "c2"}, {CommitSHA: "c1"}}
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		start, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(start+size, len(all))
+		if end < len(all) {
+			w.Header().Set(client.NextCursorHeader, strconv.Itoa(end))
+		}
+		_ = json.NewEncoder(w).Encode(all[start:end])
+	}))
+	defer srv.Close()
+
+	results, err := serverQuery(srv.URL, client.QueryFilters{Limit: 3})
+	if err != nil {
+		t.Fatalf("server query: %v", err)
+	}
+	if len(results) != 3 || results[2].CommitSHA != "c3" || requests != 1 {
+		t.Fatalf("expected first 3 results in one request, got %v after %d requests", results, requests)
+	}
+
+	requests = 0
+	results, err = serverQuery(srv.URL, client.QueryFilters{Limit: 0})
+	if err != nil {
+		t.Fatalf("server query: %v", err)
+	}
+	if len(results) != len(all) {
+		t.Fatalf("expected all %d results, got %d", len(all), len(results))
+	}
+
+	if _, err := serverQuery("", client.QueryFilters{}); err == nil {
+		t.Fatalf("expected error without a server URL")
+	}
+}
@@ -28,6 +28,7 @@ func ensureJulRefspecs(repoRoot, remoteName string) error {
 		"+refs/jul/trace-sync/*:refs/jul/trace-sync/*",
 		"+refs/jul/suggest/*:refs/jul/suggest/*",
 		"+refs/jul/keep/*:refs/jul/keep/*",
+		"+refs/jul/archive/*:refs/jul/archive/*",
 		"+refs/notes/jul/*:refs/notes/jul/*",
 	}
 	for _, refspec := range required {
@@ -17,6 +17,9 @@ import (
 func runWorkspaceRestack(args []string) int {
 	fs, jsonOut := newFlagSet("ws restack")
 	onto := fs.String("onto", "", "Retarget base to ref (e.g. main)")
+	stack := fs.Bool("stack", false, "Also restack every workspace stacked on this one")
+	cont := fs.Bool("continue", false, "Continue a restack stopped on a conflict")
+	abort := fs.Bool("abort", false, "Abort a restack stopped on a conflict and restore the workspace")
 	_ = fs.Parse(args)
 
 	repoRoot, err := gitutil.RepoTopLevel()
@@ -32,6 +35,20 @@ func runWorkspaceRestack(args []string) int {
 	if ws == "" {
 		ws = "@"
 	}
+	if *cont && *abort {
+		if *jsonOut {
+			_ = output.EncodeError(os.Stdout, "restack_invalid_args", "choose only one of --continue or --abort", nil)
+		} else {
+			fmt.Fprintln(os.Stderr, "choose only one of --continue or --abort")
+		}
+		return 1
+	}
+	if *cont {
+		return runWorkspaceRestackContinue(repoRoot, user, ws, *jsonOut)
+	}
+	if *abort {
+		return runWorkspaceRestackAbort(repoRoot, user, ws, *jsonOut)
+	}
 
 	cfg, _, err := workspace.ReadConfig(repoRoot, ws)
 	if err != nil {
@@ -84,6 +101,10 @@ func runWorkspaceRestack(args []string) int {
 		return 1
 	}
 
+	if *stack {
+		return runWorkspaceRestackStack(repoRoot, user, ws, baseRef, baseTip, *jsonOut)
+	}
+
 	res, err := restack.Run(restack.Options{
 		RepoRoot:  repoRoot,
 		User:      user,
@@ -92,44 +113,68 @@ func runWorkspaceRestack(args []string) int {
 		BaseTip:   baseTip,
 	})
 	if err != nil {
-		if errors.Is(err, agent.ErrMergeInProgress) {
-			if *jsonOut {
-				_ = output.EncodeError(os.Stdout, "restack_merge_in_progress", "restack blocked: merge in progress; run 'jul merge' first", nil)
-			} else {
-				fmt.Fprintln(os.Stderr, "restack blocked: merge in progress; run 'jul merge' first")
-			}
-			return 1
+		return writeRestackError(err, *jsonOut)
+	}
+	return finishWorkspaceRestack(user+"/"+ws, res, *jsonOut)
+}
+
+func runWorkspaceRestackContinue(repoRoot, user, ws string, jsonOut bool) int {
+	if restack.StackInProgress(repoRoot) {
+		res, err := restack.ContinueStack(repoRoot)
+		return finishStackRestack(user, ws, res, err, jsonOut)
+	}
+	state, ok := restack.ReadState(repoRoot)
+	if !ok {
+		return writeRestackError(restack.ErrNotInProgress, jsonOut)
+	}
+	res, err := restack.Continue(repoRoot)
+	if err != nil {
+		return writeRestackError(err, jsonOut)
+	}
+	return finishWorkspaceRestack(state.User+"/"+state.Workspace, res, jsonOut)
+}
+
+func runWorkspaceRestackAbort(repoRoot, user, ws string, jsonOut bool) int {
+	out := workspaceActionOutput{
+		Status:    "ok",
+		Action:    "restack_abort",
+		Workspace: user + "/" + ws,
+	}
+	if restack.StackInProgress(repoRoot) {
+		res, err := restack.AbortStack(repoRoot)
+		if err != nil {
+			return writeRestackError(err, jsonOut)
 		}
-		var conflict restack.ConflictError
-		if errors.As(err, &conflict) {
-			if *jsonOut {
-				_ = output.EncodeError(os.Stdout, "restack_conflict", "restack conflict; run 'jul merge' to resolve", nil)
-			} else {
-				fmt.Fprintf(os.Stderr, "Restack conflict on checkpoint %s\n", strings.TrimSpace(conflict.CheckpointSHA))
-				if len(conflict.Conflicts) > 0 {
-					fmt.Fprintln(os.Stderr, "Conflicts in:")
-					for _, file := range conflict.Conflicts {
-						fmt.Fprintf(os.Stderr, "  - %s\n", file)
-					}
-				}
-				fmt.Fprintln(os.Stderr, "Run 'jul merge' to resolve.")
+		restored := 0
+		for _, layer := range res.Layers {
+			if layer.Status == restack.LayerRestacked || layer.Status == restack.LayerConflict {
+				restored++
 			}
-			return 1
 		}
-		if *jsonOut {
-			_ = output.EncodeError(os.Stdout, "restack_failed", fmt.Sprintf("restack failed: %v", err), nil)
-		} else {
-			fmt.Fprintf(os.Stderr, "restack failed: %v\n", err)
+		out.Message = fmt.Sprintf("Stack restack aborted; %d workspaces restored", restored)
+	} else {
+		state, err := restack.Abort(repoRoot)
+		if err != nil {
+			return writeRestackError(err, jsonOut)
 		}
-		return 1
+		out.Workspace = state.User + "/" + state.Workspace
+		out.Message = fmt.Sprintf("Restack aborted; %s restored", out.Workspace)
 	}
+	if jsonOut {
+		return writeJSON(out)
+	}
+	renderWorkspaceAction(out)
+	return 0
+}
+
+func finishWorkspaceRestack(workspaceID string, res restack.Result, jsonOut bool) int {
 	out := workspaceActionOutput{
 		Status:    "ok",
 		Action:    "restack",
-		Workspace: user + "/" + ws,
-		Message:   fmt.Sprintf("Restacked %d checkpoints onto %s", len(res.NewCheckpoints), strings.TrimSpace(baseTip)),
+		Workspace: workspaceID,
+		Message:   fmt.Sprintf("Restacked %d checkpoints onto %s", len(res.NewCheckpoints), strings.TrimSpace(res.BaseTip)),
 	}
-	if *jsonOut {
+	if jsonOut {
 		if code := writeJSON(out); code != 0 {
 			return code
 		}
@@ -137,36 +182,194 @@ func runWorkspaceRestack(args []string) int {
 		renderWorkspaceAction(out)
 	}
 
-	if len(res.NewCheckpoints) > 0 {
-		remote, rerr := remotesel.Resolve()
-		if rerr == nil && strings.TrimSpace(remote.Name) != "" {
-			ref := changeRef(res.ChangeID)
-			remoteTip, _ := remoteRefTip(remote.Name, ref)
-			if err := pushWorkspace(remote.Name, res.NewCheckpoints[len(res.NewCheckpoints)-1], ref, remoteTip); err != nil {
-				if *jsonOut {
-					_ = output.EncodeError(os.Stdout, "restack_push_failed", fmt.Sprintf("failed to push change ref: %v", err), nil)
-				} else {
-					fmt.Fprintf(os.Stderr, "failed to push change ref: %v\n", err)
+	if err := pushRestackedRefs(res.ChangeID, res.NewCheckpoints); err != nil {
+		if jsonOut {
+			_ = output.EncodeError(os.Stdout, "restack_push_failed", err.Error(), nil)
+		} else {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+		return 1
+	}
+	retargetAfterRestack(jsonOut)
+	return 0
+}
+
+func retargetAfterRestack(jsonOut bool) {
+	retargeted, err := retargetStaleSuggestions()
+	if jsonOut {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to rebase stale suggestions: %v\n", err)
+		return
+	}
+	renderRetargetedSuggestions(retargeted)
+}
+
+var restackResumeActions = []output.NextAction{
+	{Action: "continue", Command: "jul ws restack --continue"},
+	{Action: "abort", Command: "jul ws restack --abort"},
+}
+
+func writeRestackError(err error, jsonOut bool) int {
+	var conflict restack.ConflictError
+	switch {
+	case errors.Is(err, agent.ErrMergeInProgress):
+		if jsonOut {
+			_ = output.EncodeError(os.Stdout, "restack_merge_in_progress", "restack blocked: merge in progress; run 'jul merge' first", nil)
+		} else {
+			fmt.Fprintln(os.Stderr, "restack blocked: merge in progress; run 'jul merge' first")
+		}
+	case errors.Is(err, restack.ErrInProgress):
+		if jsonOut {
+			_ = output.EncodeError(os.Stdout, "restack_in_progress", err.Error(), restackResumeActions)
+		} else {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+	case errors.Is(err, restack.ErrNotInProgress):
+		if jsonOut {
+			_ = output.EncodeError(os.Stdout, "restack_not_in_progress", err.Error(), nil)
+		} else {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+	case errors.As(err, &conflict):
+		if jsonOut {
+			msg := fmt.Sprintf("restack conflict on checkpoint %s; resolve it in %s and run 'jul ws restack --continue'", shortSHA(conflict.CheckpointSHA), conflict.Worktree)
+			_ = output.EncodeError(os.Stdout, "restack_conflict", msg, restackResumeActions)
+		} else {
+			fmt.Fprintf(os.Stderr, "Restack conflict on checkpoint %s\n", strings.TrimSpace(conflict.CheckpointSHA))
+			if len(conflict.Conflicts) > 0 {
+				fmt.Fprintln(os.Stderr, "Conflicts in:")
+				for _, file := range conflict.Conflicts {
+					fmt.Fprintf(os.Stderr, "  - %s\n", file)
 				}
-				return 1
 			}
-			anchor := anchorRef(res.ChangeID)
-			anchorTip, _ := remoteRefTip(remote.Name, anchor)
-			if anchorTip == "" {
-				if err := pushRef(remote.Name, res.NewCheckpoints[0], anchor, false); err != nil {
-					if *jsonOut {
-						_ = output.EncodeError(os.Stdout, "restack_push_failed", fmt.Sprintf("failed to push anchor ref: %v", err), nil)
-					} else {
-						fmt.Fprintf(os.Stderr, "failed to push anchor ref: %v\n", err)
-					}
-					return 1
-				}
+			fmt.Fprintf(os.Stderr, "Resolve them in %s, then run 'jul ws restack --continue' (or 'jul ws restack --abort').\n", conflict.Worktree)
+		}
+	default:
+		if jsonOut {
+			_ = output.EncodeError(os.Stdout, "restack_failed", fmt.Sprintf("restack failed: %v", err), nil)
+		} else {
+			fmt.Fprintf(os.Stderr, "restack failed: %v\n", err)
+		}
+	}
+	return 1
+}
+
+type stackRestackOutput struct {
+	Status      string              `json:"status"`
+	Action      string              `json:"action"`
+	Workspace   string              `json:"workspace_id"`
+	Layers      []restack.Layer     `json:"layers"`
+	Worktree    string              `json:"worktree,omitempty"`
+	NextActions []output.NextAction `json:"next_actions,omitempty"`
+}
+
+func runWorkspaceRestackStack(repoRoot, user, ws, baseRef, baseTip string, jsonOut bool) int {
+	res, err := restack.RunStack(restack.StackOptions{
+		RepoRoot:  repoRoot,
+		User:      user,
+		Workspace: ws,
+		Current:   ws,
+		BaseRef:   baseRef,
+		BaseTip:   baseTip,
+	})
+	return finishStackRestack(user, ws, res, err, jsonOut)
+}
+
+func finishStackRestack(user, ws string, res restack.StackResult, err error, jsonOut bool) int {
+	out := stackRestackOutput{
+		Status:    "ok",
+		Action:    "restack",
+		Workspace: user + "/" + ws,
+		Layers:    res.Layers,
+	}
+	var conflict restack.ConflictError
+	if err != nil && !errors.As(err, &conflict) {
+		return writeRestackError(err, jsonOut)
+	}
+	if err != nil {
+		out.Status = "conflict"
+		out.Worktree = conflict.Worktree
+		out.NextActions = restackResumeActions
+	}
+
+	for _, layer := range res.Layers {
+		if layer.Status != restack.LayerRestacked {
+			continue
+		}
+		if err := pushRestackedRefs(layer.ChangeID, layer.NewCheckpoints); err != nil {
+			if jsonOut {
+				_ = output.EncodeError(os.Stdout, "restack_push_failed", err.Error(), nil)
+			} else {
+				fmt.Fprintln(os.Stderr, err.Error())
 			}
+			return 1
 		}
 	}
+
+	if jsonOut {
+		if code := writeJSON(out); code != 0 {
+			return code
+		}
+	} else {
+		renderStackRestack(out)
+	}
+	if out.Status != "ok" {
+		return 1
+	}
+	retargetAfterRestack(jsonOut)
 	return 0
 }
 
+func renderStackRestack(out stackRestackOutput) {
+	for _, layer := range out.Layers {
+		line := fmt.Sprintf("%s: %s", layer.Workspace, strings.ReplaceAll(layer.Status, "_", " "))
+		switch {
+		case layer.Status == restack.LayerRestacked && len(layer.NewCheckpoints) > 0:
+			line += fmt.Sprintf(" (%d checkpoints, %s → %s)", len(layer.NewCheckpoints),
+				shortSHA(layer.OldCheckpoints[len(layer.OldCheckpoints)-1]), shortSHA(layer.NewCheckpoints[len(layer.NewCheckpoints)-1]))
+		case layer.Reason != "":
+			line += " (" + layer.Reason + ")"
+		}
+		fmt.Fprintln(os.Stdout, line)
+		for _, file := range layer.Conflicts {
+			fmt.Fprintf(os.Stdout, "  - %s\n", file)
+		}
+	}
+	if len(out.NextActions) > 0 {
+		fmt.Fprintf(os.Stdout, "Resolve the conflict in %s, then run one of:\n", out.Worktree)
+		for _, action := range out.NextActions {
+			fmt.Fprintf(os.Stdout, "  %s\n", action.Command)
+		}
+	}
+}
+
+// pushRestackedRefs publishes a restacked change and, the first time, its
+// anchor. It is a no-op without a configured remote.
+func pushRestackedRefs(changeID string, checkpoints []string) error {
+	if len(checkpoints) == 0 {
+		return nil
+	}
+	remote, rerr := remotesel.Resolve()
+	if rerr != nil || strings.TrimSpace(remote.Name) == "" {
+		return nil
+	}
+	ref := changeRef(changeID)
+	remoteTip, _ := remoteRefTip(remote.Name, ref)
+	if err := pushWorkspace(remote.Name, checkpoints[len(checkpoints)-1], ref, remoteTip); err != nil {
+		return fmt.Errorf("failed to push change ref: %v", err)
+	}
+	anchor := anchorRef(changeID)
+	anchorTip, _ := remoteRefTip(remote.Name, anchor)
+	if anchorTip == "" {
+		if err := pushRef(remote.Name, checkpoints[0], anchor, false); err != nil {
+			return fmt.Errorf("failed to push anchor ref: %v", err)
+		}
+	}
+	return nil
+}
+
 func isEmptyCherryPick(err error) bool {
 	if err == nil {
 		return false
@@ -232,28 +435,7 @@ func normalizeBaseRef(repoRoot, value string) (string, error) {
 }
 
 func resolveBaseTip(repoRoot, baseRef string) (string, error) {
-	if strings.TrimSpace(baseRef) == "" {
-		return "", fmt.Errorf("base ref required")
-	}
-	sha, err := gitutil.Git("-C", repoRoot, "rev-parse", baseRef)
-	if err != nil {
-		return "", err
-	}
-	sha = strings.TrimSpace(sha)
-	if strings.HasPrefix(baseRef, "refs/jul/workspaces/") {
-		parent, err := gitutil.ParentOf(sha)
-		if err == nil && strings.TrimSpace(parent) != "" {
-			return strings.TrimSpace(parent), nil
-		}
-		return "", fmt.Errorf("base workspace has no checkpoint")
-	}
-	if strings.HasPrefix(baseRef, "refs/jul/changes/") {
-		if sha == "" {
-			return "", fmt.Errorf("change ref missing")
-		}
-		return sha, nil
-	}
-	return sha, nil
+	return restack.ResolveBaseTip(repoRoot, baseRef)
 }
 
 func updateWorktreeLocal(repoRoot, ref string) error {
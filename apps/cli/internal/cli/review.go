@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -36,10 +35,15 @@ func newReviewCommand() Command {
 			fs, jsonOut := newFlagSet("review")
 			suggest := fs.Bool("suggest", false, "Create suggestions instead of summary")
 			from := fs.String("from", "", "Reuse prior review summary (requires --suggest)")
+			var profiles stringList
+			fs.Var(&profiles, "profile", "Review profile to run (repeatable). Default: all configured profiles")
+			full := fs.Bool("full", false, "Review the whole change instead of only what changed since the last review")
+			regenerate := fs.String("regenerate", "", "Ask the agent to redo a conflicted suggestion against the current checkpoint (implies --suggest)")
 			_ = fs.Parse(args)
 
 			mode := reviewModeSummary
-			if *suggest {
+			regenerateID := strings.TrimSpace(*regenerate)
+			if *suggest || regenerateID != "" {
 				mode = reviewModeSuggest
 			}
 			fromID := strings.TrimSpace(*from)
@@ -53,10 +57,10 @@ func newReviewCommand() Command {
 			}
 
 			if reviewInternalEnv() {
-				return runReviewInternalCommand(mode, fromID, *jsonOut)
+				return runReviewInternalCommand(mode, fromID, regenerateID, profiles, *full, *jsonOut)
 			}
 
-			run, err := startBackgroundReview(mode, fromID)
+			run, err := startBackgroundReview(mode, fromID, regenerateID, profiles, *full)
 			if err != nil {
 				if *jsonOut {
 					_ = output.EncodeError(os.Stdout, "review_failed", fmt.Sprintf("review failed: %v", err), nil)
@@ -124,9 +128,9 @@ func isDetached(ch <-chan struct{}) bool {
 	}
 }
 
-func runReviewInternalCommand(mode reviewMode, fromReviewID string, jsonOut bool) int {
+func runReviewInternalCommand(mode reviewMode, fromReviewID, regenerateID string, profiles []string, full, jsonOut bool) int {
 	started := time.Now().UTC()
-	result, err := runReviewInternal(mode, fromReviewID, os.Stdout)
+	result, err := runReviewInternal(mode, fromReviewID, regenerateID, profiles, full, os.Stdout)
 	result.Mode = mode
 	if result.StartedAt.IsZero() {
 		result.StartedAt = started
@@ -153,13 +157,17 @@ func runReviewInternalCommand(mode reviewMode, fromReviewID string, jsonOut bool
 	return renderReviewResult(result, jsonOut)
 }
 
-func runReviewInternal(mode reviewMode, fromReviewID string, stream io.Writer) (reviewRunResult, error) {
+func runReviewInternal(mode reviewMode, fromReviewID, regenerateID string, profileNames []string, full bool, stream io.Writer) (reviewRunResult, error) {
 	if mode == "" {
 		mode = reviewModeSummary
 	}
 	if mode != reviewModeSuggest && strings.TrimSpace(fromReviewID) != "" {
 		return reviewRunResult{}, fmt.Errorf("--from requires --suggest")
 	}
+	profiles, err := selectReviewProfiles(profileNames)
+	if err != nil {
+		return reviewRunResult{}, err
+	}
 	baseSHA, changeID, err := reviewBase()
 	if err != nil {
 		return reviewRunResult{}, err
@@ -170,24 +178,72 @@ func runReviewInternal(mode reviewMode, fromReviewID string, stream io.Writer) (
 		return reviewRunResult{}, err
 	}
 
-	worktree, err := agent.EnsureWorktree(repoRoot, baseSHA, agent.WorktreeOptions{})
-	if err != nil {
-		if errors.Is(err, agent.ErrMergeInProgress) {
-			return reviewRunResult{}, fmt.Errorf("merge in progress; run 'jul merge' first")
+	result := reviewRunResult{
+		Mode:     mode,
+		BaseSHA:  baseSHA,
+		ChangeID: changeID,
+	}
+	fromSHA := reviewChangeBase(baseSHA, changeID)
+	var prior *metadata.FindingsNote
+	if !full {
+		prior = priorReview(baseSHA, changeID)
+	}
+	var keptFindings []client.Finding
+	if prior != nil {
+		fromSHA = prior.CheckpointSHA
+		result.ReviewedFrom = prior.CheckpointSHA
+		interdiff := reviewHunks(prior.CheckpointSHA, baseSHA)
+		keptFindings = carryOverFindings(prior.Findings, interdiff)
+		if mode == reviewModeSuggest {
+			result.CarriedOver = carryOverSuggestions(prior.CheckpointSHA, baseSHA, changeID, interdiff)
 		}
-		return reviewRunResult{}, err
 	}
 
-	diff := reviewDiff(baseSHA)
-	files := reviewFiles(baseSHA)
-
 	ctx := agent.ReviewContext{
 		Checkpoint: baseSHA,
 		ChangeID:   changeID,
-		Diff:       diff,
-		Files:      files,
+		Diff:       reviewDiff(fromSHA, baseSHA),
+		Files:      reviewFiles(fromSHA, baseSHA),
 		CIResults:  reviewCIResults(baseSHA),
 	}
+	if prior != nil {
+		ctx.ReviewedCheckpoint = prior.CheckpointSHA
+		ctx.PriorFindings = agentFindings(keptFindings)
+		ctx.OpenSuggestions = agentOpenSuggestions(changeID, baseSHA)
+		if strings.TrimSpace(ctx.Diff) == "" && regenerateID == "" {
+			result.Status = "completed"
+			result.Findings, err = storeReviewFindings(baseSHA, changeID, "", prior.CheckpointSHA, keptFindings, nil)
+			if err != nil {
+				return result, err
+			}
+			_, _ = refreshStatusCache(repoRoot)
+			return result, nil
+		}
+	}
+	if regenerateID != "" {
+		sug, ok, err := metadata.GetSuggestionByID(regenerateID)
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			return result, fmt.Errorf("suggestion %s not found", regenerateID)
+		}
+		if sug.ChangeID != changeID {
+			return result, fmt.Errorf("suggestion %s belongs to change %s, not %s", regenerateID, sug.ChangeID, changeID)
+		}
+		patch, err := suggestionPatch(sug.BaseCommitSHA, sug.SuggestedCommitSHA)
+		if err != nil {
+			return result, err
+		}
+		ctx.Regenerate = &agent.ReviewSuggestion{
+			ID:          sug.SuggestionID,
+			Commit:      sug.SuggestedCommitSHA,
+			Reason:      sug.Reason,
+			Description: sug.Description,
+			Confidence:  sug.Confidence,
+		}
+		ctx.RegeneratePatch = patch
+	}
 	if mode == reviewModeSuggest && strings.TrimSpace(fromReviewID) != "" {
 		note, err := metadata.GetAgentReviewByID(strings.TrimSpace(fromReviewID))
 		if err != nil {
@@ -203,33 +259,32 @@ func runReviewInternal(mode reviewMode, fromReviewID string, stream io.Writer) (
 	if mode == reviewModeSuggest {
 		action = "review_suggest"
 	}
-	req := agent.ReviewRequest{
-		Version:       1,
-		Action:        action,
-		WorkspacePath: worktree,
-		Context:       ctx,
-	}
 
-	provider, err := agent.ResolveProvider()
+	jobs, err := prepareReviewJobs(profiles)
 	if err != nil {
 		return reviewRunResult{}, err
 	}
-
-	resp, err := agent.RunReviewWithStream(context.Background(), provider, req, stream)
-	if err != nil {
+	if err := runReviewJobs(repoRoot, baseSHA, jobs, action, ctx, stream); err != nil {
 		return reviewRunResult{}, err
 	}
 
-	status := strings.TrimSpace(resp.Status)
-	if status == "" {
-		status = "completed"
+	var done []*reviewJob
+	for _, job := range jobs {
+		if job.err != nil {
+			if len(jobs) == 1 {
+				return result, job.err
+			}
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s review failed: %v", job.profile.Name, job.err))
+			continue
+		}
+		done = append(done, job)
 	}
-	result := reviewRunResult{
-		Mode:     mode,
-		Status:   status,
-		BaseSHA:  baseSHA,
-		ChangeID: changeID,
+	if len(done) == 0 {
+		return result, fmt.Errorf("all review profiles failed")
 	}
+
+	resp := mergeReviewResponses(done)
+	result.Status = resp.Status
 	if mode == reviewModeSummary {
 		result.Summary = strings.TrimSpace(resp.Summary)
 		note, err := writeReviewNote(baseSHA, changeID, resp)
@@ -237,25 +292,58 @@ func runReviewInternal(mode reviewMode, fromReviewID string, stream io.Writer) (
 			return result, err
 		}
 		result.ReviewID = note.ReviewID
+		result.Findings, err = storeReviewFindings(baseSHA, changeID, note.ReviewID, result.ReviewedFrom, keptFindings, done)
+		if err != nil {
+			return result, err
+		}
+		_, _ = refreshStatusCache(repoRoot)
 		return result, nil
 	}
-
-	seeds, err := collectReviewSuggestions(worktree, baseSHA, resp)
+	result.Findings, err = storeReviewFindings(baseSHA, changeID, "", result.ReviewedFrom, keptFindings, done)
 	if err != nil {
 		return result, err
 	}
 
+	var seeds []reviewSeed
+	for i, job := range done {
+		for _, sug := range job.seeds {
+			seed := reviewSeed{ReviewSuggestion: sug, job: i}
+			if job.profile.Name != "" {
+				seed.Profiles = []string{job.profile.Name}
+			}
+			seeds = append(seeds, seed)
+		}
+	}
+	if len(done) > 1 {
+		seeds = dedupeReviewSeeds(baseSHA, seeds)
+	}
+
 	created, err := storeReviewSuggestions(baseSHA, changeID, seeds)
 	if err != nil {
 		return result, err
 	}
 	result.Suggestions = created
+	if regenerateID != "" && len(created) > 0 {
+		ids := make([]string, 0, len(created))
+		for _, sug := range created {
+			ids = append(ids, sug.SuggestionID)
+		}
+		if _, err := metadata.UpdateSuggestionStatus(regenerateID, "rejected", "regenerated as "+strings.Join(ids, ", ")); err != nil {
+			return result, err
+		}
+		result.Regenerated = regenerateID
+	}
+	_, _ = refreshStatusCache(repoRoot)
 	return result, nil
 }
 
 func renderReviewResult(result reviewRunResult, jsonOut bool) int {
 	if jsonOut {
-		out := output.ReviewOutput{}
+		out := output.ReviewOutput{Warnings: result.Warnings}
+		out.Findings = result.Findings
+		out.ReviewedFrom = result.ReviewedFrom
+		out.CarriedOver = result.CarriedOver
+		out.Regenerated = result.Regenerated
 		if result.Mode == reviewModeSummary {
 			out.Review = &output.ReviewSummary{
 				ReviewID:  strings.TrimSpace(result.ReviewID),
@@ -274,6 +362,12 @@ func renderReviewResult(result reviewRunResult, jsonOut bool) int {
 		return writeJSON(out)
 	}
 
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if result.ReviewedFrom != "" {
+		fmt.Fprintf(os.Stdout, "Incremental review since %s (run with --full for a complete pass).\n", shortSHA(result.ReviewedFrom))
+	}
 	if result.Mode == reviewModeSummary {
 		summary := output.ReviewSummary{
 			ReviewID:  strings.TrimSpace(result.ReviewID),
@@ -284,9 +378,17 @@ func renderReviewResult(result reviewRunResult, jsonOut bool) int {
 			Timestamp: result.FinishedAt.UTC().Format(time.RFC3339),
 		}
 		output.RenderReview(os.Stdout, summary)
+		renderFindingsCount(result.BaseSHA, result.Findings)
 		return 0
 	}
 
+	renderFindingsCount(result.BaseSHA, result.Findings)
+	if len(result.CarriedOver) > 0 {
+		fmt.Fprintf(os.Stdout, "%d suggestion(s) carried over.\n", len(result.CarriedOver))
+	}
+	if result.Regenerated != "" {
+		fmt.Fprintf(os.Stdout, "Suggestion %s regenerated and marked rejected.\n", result.Regenerated)
+	}
 	if len(result.Suggestions) == 0 {
 		fmt.Fprintln(os.Stdout, "No suggestions created.")
 		return 0
@@ -296,6 +398,13 @@ func renderReviewResult(result reviewRunResult, jsonOut bool) int {
 	return 0
 }
 
+func renderFindingsCount(baseSHA string, findings []client.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stdout, "%d finding(s) recorded (worst: %s). Run 'jul show %s' to see them.\n", len(findings), metadata.WorstSeverity(findings), shortSHA(baseSHA))
+}
+
 func reviewBase() (string, string, error) {
 	if checkpoint, _ := latestCheckpoint(); checkpoint != nil {
 		return checkpoint.SHA, checkpoint.ChangeID, nil
@@ -312,30 +421,30 @@ func reviewBase() (string, string, error) {
 	return draftSHA, changeID, nil
 }
 
-func reviewDiff(baseSHA string) string {
+// reviewDiff is the diff a review covers, fromSHA..baseSHA. An empty fromSHA
+// means baseSHA's own changes.
+func reviewDiff(fromSHA, baseSHA string) string {
 	if strings.TrimSpace(baseSHA) == "" {
 		return ""
 	}
-	parent, err := gitutil.ParentOf(baseSHA)
-	if err != nil || parent == "" {
-		out, err := gitutil.Git("show", "--format=", "--root", baseSHA)
-		if err != nil {
-			return ""
-		}
-		return out
+	if strings.TrimSpace(fromSHA) == "" {
+		fromSHA = reviewParent(baseSHA)
 	}
-	out, err := gitutil.Git("diff", parent, baseSHA)
+	out, err := gitutil.Git("diff", fromSHA, baseSHA)
 	if err != nil {
 		return ""
 	}
 	return out
 }
 
-func reviewFiles(baseSHA string) []agent.ReviewFile {
+func reviewFiles(fromSHA, baseSHA string) []agent.ReviewFile {
 	if strings.TrimSpace(baseSHA) == "" {
 		return nil
 	}
-	out, err := gitutil.Git("diff-tree", "--no-commit-id", "--name-only", "-r", "--root", baseSHA)
+	if strings.TrimSpace(fromSHA) == "" {
+		fromSHA = reviewParent(baseSHA)
+	}
+	out, err := gitutil.Git("diff", "--name-only", "--no-renames", fromSHA, baseSHA)
 	if err != nil {
 		return nil
 	}
@@ -392,7 +501,7 @@ func writeReviewNote(baseSHA, changeID string, resp agent.ReviewResponse) (metad
 	})
 }
 
-func storeReviewSuggestions(baseSHA, changeID string, suggestions []agent.ReviewSuggestion) ([]client.Suggestion, error) {
+func storeReviewSuggestions(baseSHA, changeID string, suggestions []reviewSeed) ([]client.Suggestion, error) {
 	minConfidence := config.ReviewMinConfidence()
 	created := make([]client.Suggestion, 0, len(suggestions))
 	for _, sug := range suggestions {
@@ -410,6 +519,7 @@ func storeReviewSuggestions(baseSHA, changeID string, suggestions []agent.Review
 			Reason:             strings.TrimSpace(sug.Reason),
 			Description:        strings.TrimSpace(sug.Description),
 			Confidence:         sug.Confidence,
+			Profiles:           sug.Profiles,
 		})
 		if err != nil {
 			return nil, err
@@ -419,6 +529,52 @@ func storeReviewSuggestions(baseSHA, changeID string, suggestions []agent.Review
 	return created, nil
 }
 
+// storeReviewFindings replaces the checkpoint's findings with those carried
+// over from an earlier review plus those reported by this one, crediting each
+// to the profiles that raised it.
+func storeReviewFindings(baseSHA, changeID, reviewID, reviewedFrom string, kept []client.Finding, jobs []*reviewJob) ([]client.Finding, error) {
+	findings := append([]client.Finding(nil), kept...)
+	index := map[string]int{}
+	for i, finding := range findings {
+		index[fmt.Sprintf("%s:%d:%d:%s", finding.Path, finding.StartLine, finding.EndLine, finding.Message)] = i
+	}
+	for _, job := range jobs {
+		for _, raw := range job.resp.Findings {
+			finding := client.Finding{
+				Path:      raw.Path,
+				StartLine: raw.StartLine,
+				EndLine:   raw.EndLine,
+				Severity:  raw.Severity,
+				Category:  raw.Category,
+				Message:   raw.Message,
+			}
+			key := fmt.Sprintf("%s:%d:%d:%s", strings.TrimSpace(raw.Path), raw.StartLine, raw.EndLine, strings.TrimSpace(raw.Message))
+			if i, ok := index[key]; ok {
+				if job.profile.Name != "" {
+					findings[i].Profiles = appendUnique(findings[i].Profiles, job.profile.Name)
+				}
+				continue
+			}
+			if job.profile.Name != "" {
+				finding.Profiles = []string{job.profile.Name}
+			}
+			index[key] = len(findings)
+			findings = append(findings, finding)
+		}
+	}
+	note, err := metadata.WriteFindings(metadata.FindingsNote{
+		CheckpointSHA: baseSHA,
+		ChangeID:      changeID,
+		ReviewID:      reviewID,
+		Findings:      findings,
+		ReviewedFrom:  reviewedFrom,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return note.Findings, nil
+}
+
 func collectReviewSuggestions(worktree, baseSHA string, resp agent.ReviewResponse) ([]agent.ReviewSuggestion, error) {
 	seeds := make(map[string]agent.ReviewSuggestion)
 	for _, sug := range resp.Suggestions {
@@ -466,6 +622,15 @@ func collectReviewSuggestions(worktree, baseSHA string, resp agent.ReviewRespons
 	return out, nil
 }
 
+var agentCommitEnv = map[string]string{
+	"GIT_AUTHOR_NAME":     "Jul Agent",
+	"GIT_AUTHOR_EMAIL":    "agent@jul.local",
+	"GIT_COMMITTER_NAME":  "Jul Agent",
+	"GIT_COMMITTER_EMAIL": "agent@jul.local",
+	// Keep the shared post-commit hook from syncing an agent worktree.
+	"JUL_NO_SYNC": "1",
+}
+
 func autoCommitWorktree(worktree, message string) (string, error) {
 	dirty, err := worktreeDirty(worktree)
 	if err != nil || !dirty {
@@ -475,13 +640,7 @@ func autoCommitWorktree(worktree, message string) (string, error) {
 	if err := gitDir(worktree, nil, "add", "-A", "--", ".", ":(exclude)jul-review-*.txt"); err != nil {
 		return "", err
 	}
-	env := map[string]string{
-		"GIT_AUTHOR_NAME":     "Jul Agent",
-		"GIT_AUTHOR_EMAIL":    "agent@jul.local",
-		"GIT_COMMITTER_NAME":  "Jul Agent",
-		"GIT_COMMITTER_EMAIL": "agent@jul.local",
-	}
-	if err := gitDir(worktree, env, "commit", "-m", message, "--no-gpg-sign"); err != nil {
+	if err := gitDir(worktree, agentCommitEnv, "commit", "-m", message, "--no-gpg-sign"); err != nil {
 		return "", err
 	}
 	return gitOutputDir(worktree, "rev-parse", "HEAD")
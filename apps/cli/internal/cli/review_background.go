@@ -30,12 +30,20 @@ type reviewRunResult struct {
 	ChangeID    string              `json:"change_id,omitempty"`
 	Summary     string              `json:"summary,omitempty"`
 	Suggestions []client.Suggestion `json:"suggestions,omitempty"`
-	Error       string              `json:"error,omitempty"`
-	StartedAt   time.Time           `json:"started_at,omitempty"`
-	FinishedAt  time.Time           `json:"finished_at,omitempty"`
+	Findings    []client.Finding    `json:"findings,omitempty"`
+	// ReviewedFrom is the earlier checkpoint an incremental review diffed
+	// against; CarriedOver are its suggestions moved onto BaseSHA.
+	ReviewedFrom string              `json:"reviewed_from,omitempty"`
+	CarriedOver  []client.Suggestion `json:"carried_over,omitempty"`
+	// Regenerated is the conflicted suggestion this run replaced.
+	Regenerated string    `json:"regenerated,omitempty"`
+	Warnings    []string  `json:"warnings,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	StartedAt   time.Time `json:"started_at,omitempty"`
+	FinishedAt  time.Time `json:"finished_at,omitempty"`
 }
 
-func startBackgroundReview(mode reviewMode, fromReviewID string) (*reviewRun, error) {
+func startBackgroundReview(mode reviewMode, fromReviewID, regenerateID string, profiles []string, full bool) (*reviewRun, error) {
 	exe, err := os.Executable()
 	if err != nil {
 		return nil, err
@@ -58,6 +66,15 @@ func startBackgroundReview(mode reviewMode, fromReviewID string) (*reviewRun, er
 	if strings.TrimSpace(fromReviewID) != "" {
 		args = append(args, "--from", strings.TrimSpace(fromReviewID))
 	}
+	if strings.TrimSpace(regenerateID) != "" {
+		args = append(args, "--regenerate", strings.TrimSpace(regenerateID))
+	}
+	for _, profile := range profiles {
+		args = append(args, "--profile", profile)
+	}
+	if full {
+		args = append(args, "--full")
+	}
 
 	cmd := exec.Command(exe, args...)
 	cmd.Dir = root
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lydakis/jul/cli/internal/agent"
+	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/metadata"
+)
+
+// reviewChangeBase is where a full review of the change starts: the parent
+// of its first checkpoint, or baseSHA's own parent when the change has no
+// anchor leading to baseSHA.
+func reviewChangeBase(baseSHA, changeID string) string {
+	if strings.TrimSpace(changeID) != "" {
+		if anchor, err := gitutil.ResolveRef(anchorRef(changeID)); err == nil {
+			anchor = strings.TrimSpace(anchor)
+			if anchor == baseSHA || gitutil.IsAncestor(anchor, baseSHA) {
+				return reviewParent(anchor)
+			}
+		}
+	}
+	return reviewParent(baseSHA)
+}
+
+// reviewParent is sha's parent, or the empty tree for a root commit.
+func reviewParent(sha string) string {
+	if parent, err := gitutil.ParentOf(sha); err == nil && strings.TrimSpace(parent) != "" {
+		return strings.TrimSpace(parent)
+	}
+	tree, _ := gitutil.Git("mktree")
+	return strings.TrimSpace(tree)
+}
+
+// priorReview finds the latest review of an earlier checkpoint of the change
+// that baseSHA builds on. Reviews of baseSHA itself do not count: reviewing
+// the same checkpoint again is a full pass.
+func priorReview(baseSHA, changeID string) *metadata.FindingsNote {
+	if strings.TrimSpace(changeID) == "" {
+		return nil
+	}
+	reviews, err := metadata.ListFindings()
+	if err != nil {
+		return nil
+	}
+	var prior *metadata.FindingsNote
+	var priorAt time.Time
+	for i := range reviews {
+		note := reviews[i]
+		if note.ChangeID != changeID || note.CheckpointSHA == baseSHA {
+			continue
+		}
+		if !gitutil.IsAncestor(note.CheckpointSHA, baseSHA) {
+			continue
+		}
+		if prior == nil || note.CreatedAt.After(priorAt) {
+			prior = &reviews[i]
+			priorAt = note.CreatedAt
+		}
+	}
+	return prior
+}
+
+// carryOverFindings keeps the findings whose lines the interdiff leaves
+// alone, shifted to where those lines now sit. Findings on changed lines, and
+// file-level findings on changed files, are dropped: the incremental review
+// looks at them again.
+func carryOverFindings(findings []client.Finding, interdiff []diffHunk) []client.Finding {
+	out := make([]client.Finding, 0, len(findings))
+	for _, finding := range findings {
+		last := finding.EndLine
+		if last < finding.StartLine {
+			last = finding.StartLine
+		}
+		offset := 0
+		touched := false
+		for _, hunk := range interdiff {
+			if hunk.path != finding.Path {
+				continue
+			}
+			if hunk.oldCount == 0 && finding.StartLine > 0 {
+				// Pure insertion after line hunk.start.
+				if hunk.start < finding.StartLine {
+					offset += hunk.newCount
+				}
+				continue
+			}
+			if finding.StartLine == 0 || (hunk.start <= last && finding.StartLine < hunk.end) {
+				touched = true
+				break
+			}
+			if hunk.end <= finding.StartLine {
+				offset += hunk.newCount - hunk.oldCount
+			}
+		}
+		if touched {
+			continue
+		}
+		if finding.StartLine > 0 {
+			finding.StartLine += offset
+			finding.EndLine += offset
+		}
+		out = append(out, finding)
+	}
+	return out
+}
+
+// carryOverSuggestions moves the prior checkpoint's pending suggestions onto
+// baseSHA when the interdiff does not touch what they change, so the agent is
+// not asked to produce them again.
+func carryOverSuggestions(priorSHA, baseSHA, changeID string, interdiff []diffHunk) []client.Suggestion {
+	pending, err := metadata.ListSuggestions(changeID, "pending", 0)
+	if err != nil {
+		return nil
+	}
+	var candidates []client.Suggestion
+	for _, sug := range pending {
+		if sug.BaseCommitSHA != priorSHA {
+			continue
+		}
+		if hunksOverlap(reviewHunks(priorSHA, sug.SuggestedCommitSHA), interdiff) {
+			continue
+		}
+		candidates = append(candidates, sug)
+	}
+	res, _ := retargetSuggestions(baseSHA, candidates)
+	return res.Retargeted
+}
+
+func agentFindings(findings []client.Finding) []agent.ReviewFinding {
+	out := make([]agent.ReviewFinding, 0, len(findings))
+	for _, finding := range findings {
+		out = append(out, agent.ReviewFinding{
+			Path:      finding.Path,
+			StartLine: finding.StartLine,
+			EndLine:   finding.EndLine,
+			Severity:  finding.Severity,
+			Category:  finding.Category,
+			Message:   finding.Message,
+		})
+	}
+	return out
+}
+
+func agentOpenSuggestions(changeID, baseSHA string) []agent.ReviewSuggestion {
+	pending, err := metadata.ListSuggestions(changeID, "pending", 0)
+	if err != nil {
+		return nil
+	}
+	var out []agent.ReviewSuggestion
+	for _, sug := range pending {
+		if sug.BaseCommitSHA != baseSHA {
+			continue
+		}
+		files, _ := gitutil.Git("diff", "--name-only", sug.BaseCommitSHA, sug.SuggestedCommitSHA)
+		out = append(out, agent.ReviewSuggestion{
+			ID:           sug.SuggestionID,
+			Commit:       sug.SuggestedCommitSHA,
+			Reason:       sug.Reason,
+			Description:  sug.Description,
+			Confidence:   sug.Confidence,
+			FilesChanged: strings.Fields(files),
+		})
+	}
+	return out
+}
@@ -0,0 +1,336 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lydakis/jul/cli/internal/agent"
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+var reviewProfileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// reviewJob is one reviewer persona's run in a pool worktree. The zero
+// profile is the plain, unfocused review.
+type reviewJob struct {
+	profile  config.ReviewProfile
+	provider agent.Provider
+	resp     agent.ReviewResponse
+	seeds    []agent.ReviewSuggestion
+	err      error
+}
+
+// reviewSeed is a suggestion candidate tagged with the personas behind it.
+type reviewSeed struct {
+	agent.ReviewSuggestion
+	Profiles []string
+	job      int
+}
+
+// selectReviewProfiles returns the named profiles, or every configured one
+// when names is empty.
+func selectReviewProfiles(names []string) ([]config.ReviewProfile, error) {
+	configured := config.ReviewProfiles()
+	for _, profile := range configured {
+		if !reviewProfileNamePattern.MatchString(profile.Name) {
+			return nil, fmt.Errorf("invalid review profile name %q", profile.Name)
+		}
+	}
+	if len(names) == 0 {
+		return configured, nil
+	}
+	selected := make([]config.ReviewProfile, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		found := false
+		for _, profile := range configured {
+			if profile.Name == name {
+				selected = append(selected, profile)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("review profile %q not configured", name)
+		}
+	}
+	return selected, nil
+}
+
+// prepareReviewJobs resolves each profile's provider.
+func prepareReviewJobs(profiles []config.ReviewProfile) ([]*reviewJob, error) {
+	if len(profiles) == 0 {
+		profiles = []config.ReviewProfile{{}}
+	}
+	jobs := make([]*reviewJob, 0, len(profiles))
+	for _, profile := range profiles {
+		provider, err := agent.ResolveNamedProvider(profile.Provider)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &reviewJob{profile: profile, provider: provider})
+	}
+	return jobs, nil
+}
+
+// leaseReviewWorktrees leases up to n pool worktrees, one after the other
+// since git worktree bookkeeping is not concurrency safe. Members held by
+// fixes, merges or other reviews are skipped; it fails only when none is free.
+func leaseReviewWorktrees(repoRoot, baseSHA string, n int) ([]*agent.Lease, error) {
+	var leases []*agent.Lease
+	for len(leases) < n {
+		lease, err := agent.AcquireWorktree(repoRoot, baseSHA, "review")
+		if err != nil {
+			if len(leases) > 0 && errors.Is(err, agent.ErrPoolExhausted) {
+				break
+			}
+			for _, held := range leases {
+				held.Release()
+			}
+			return nil, err
+		}
+		leases = append(leases, lease)
+	}
+	return leases, nil
+}
+
+// runReviewJobs runs the jobs' agents concurrently on as many pool worktrees
+// as are free, queueing the rest, and for suggest reviews collects the
+// commits each one left in its worktree. A worktree is reset to baseSHA
+// before it takes the next queued job.
+func runReviewJobs(repoRoot, baseSHA string, jobs []*reviewJob, action string, reviewCtx agent.ReviewContext, stream io.Writer) error {
+	leases, err := leaseReviewWorktrees(repoRoot, baseSHA, len(jobs))
+	if err != nil {
+		return err
+	}
+	queue := make(chan *reviewJob, len(jobs))
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	var wg sync.WaitGroup
+	var streamMu sync.Mutex
+	for _, lease := range leases {
+		wg.Add(1)
+		go func(lease *agent.Lease) {
+			defer wg.Done()
+			defer lease.Release()
+			used := false
+			for job := range queue {
+				if used {
+					if err := resetReviewWorktree(lease.Worktree, baseSHA); err != nil {
+						job.err = err
+						continue
+					}
+				}
+				used = true
+				req := agent.ReviewRequest{
+					Version:       1,
+					Action:        action,
+					WorkspacePath: lease.Worktree,
+					Context:       reviewCtx,
+				}
+				req.Context.Profile = job.profile.Name
+				req.Context.Focus = job.profile.Prompt
+				jobStream := stream
+				if stream != nil && len(jobs) > 1 {
+					jobStream = &prefixWriter{mu: &streamMu, w: stream, prefix: "[" + job.profile.Name + "] "}
+				}
+				job.resp, job.err = agent.RunReviewWithStream(context.Background(), job.provider, req, jobStream)
+				if job.err != nil || action != "review_suggest" {
+					continue
+				}
+				job.seeds, job.err = collectReviewSuggestions(lease.Worktree, reviewCtx.Checkpoint, job.resp)
+			}
+		}(lease)
+	}
+	wg.Wait()
+	return nil
+}
+
+func resetReviewWorktree(worktree, baseSHA string) error {
+	if err := gitDir(worktree, nil, "reset", "--hard", baseSHA); err != nil {
+		return err
+	}
+	return gitDir(worktree, nil, "clean", "-fd")
+}
+
+// mergeReviewResponses folds per-profile summaries into one response with a
+// heading per profile.
+func mergeReviewResponses(jobs []*reviewJob) agent.ReviewResponse {
+	if len(jobs) == 1 {
+		resp := jobs[0].resp
+		resp.Status = strings.TrimSpace(resp.Status)
+		if resp.Status == "" {
+			resp.Status = "completed"
+		}
+		return resp
+	}
+	var summary strings.Builder
+	for _, job := range jobs {
+		text := strings.TrimSpace(job.resp.Summary)
+		if text == "" {
+			continue
+		}
+		if summary.Len() > 0 {
+			summary.WriteString("\n\n")
+		}
+		summary.WriteString("## " + job.profile.Name + "\n")
+		summary.WriteString(text)
+	}
+	return agent.ReviewResponse{Version: 1, Status: "completed", Summary: summary.String()}
+}
+
+// diffHunk is a changed region: start/end bound the pre-image lines it
+// touches, oldCount/newCount are the raw line counts from the header.
+type diffHunk struct {
+	path               string
+	start, end         int
+	oldCount, newCount int
+}
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+// dedupeReviewSeeds drops suggestions whose hunks overlap a more confident
+// suggestion from another profile, crediting that profile on the one kept.
+// Seeds from the same profile are never merged: its commits stack.
+func dedupeReviewSeeds(baseSHA string, seeds []reviewSeed) []reviewSeed {
+	hunks := make([][]diffHunk, len(seeds))
+	order := make([]int, len(seeds))
+	for i, seed := range seeds {
+		hunks[i] = reviewHunks(baseSHA, seed.Commit)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return seeds[order[a]].Confidence > seeds[order[b]].Confidence
+	})
+	var kept []int
+	for _, i := range order {
+		merged := false
+		for _, k := range kept {
+			if seeds[k].job == seeds[i].job || !hunksOverlap(hunks[i], hunks[k]) {
+				continue
+			}
+			seeds[k].Profiles = appendUnique(seeds[k].Profiles, seeds[i].Profiles...)
+			merged = true
+			break
+		}
+		if !merged {
+			kept = append(kept, i)
+		}
+	}
+	sort.Ints(kept)
+	out := make([]reviewSeed, 0, len(kept))
+	for _, k := range kept {
+		out = append(out, seeds[k])
+	}
+	return out
+}
+
+// reviewHunks lists the base-side line ranges commit changes. Pure
+// insertions count as touching the line they follow.
+func reviewHunks(baseSHA, commit string) []diffHunk {
+	out, err := gitutil.Git("diff", "-U0", "--no-color", "--no-renames", baseSHA, commit)
+	if err != nil {
+		return nil
+	}
+	var hunks []diffHunk
+	oldPath, path := "", ""
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			oldPath = strings.TrimPrefix(strings.TrimPrefix(line, "--- "), "a/")
+		case strings.HasPrefix(line, "+++ "):
+			path = oldPath
+			if path == "/dev/null" {
+				path = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			}
+		case strings.HasPrefix(line, "@@ "):
+			match := hunkHeaderPattern.FindStringSubmatch(line)
+			if match == nil || path == "" {
+				continue
+			}
+			start, _ := strconv.Atoi(match[1])
+			oldCount, newCount := 1, 1
+			if match[2] != "" {
+				oldCount, _ = strconv.Atoi(match[2])
+			}
+			if match[3] != "" {
+				newCount, _ = strconv.Atoi(match[3])
+			}
+			span := oldCount
+			if span == 0 {
+				span = 1
+			}
+			hunks = append(hunks, diffHunk{path: path, start: start, end: start + span, oldCount: oldCount, newCount: newCount})
+		}
+	}
+	return hunks
+}
+
+func hunksOverlap(a, b []diffHunk) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.path == y.path && x.start < y.end && y.start < x.end {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// prefixWriter tags each line written to w so concurrent agents' output
+// stays attributable. Writers sharing mu never interleave within a line.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf = append(p.buf, b...)
+	for {
+		idx := bytes.IndexByte(p.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf[:idx]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[idx+1:]
+	}
+	return len(b), nil
+}
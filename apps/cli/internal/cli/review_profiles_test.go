@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/agent"
+)
+
+// setupReviewProfilesRepo writes three review profiles and an agent whose
+// security and tests reviews rewrite the same README line while docs adds a
+// file. extraConfig is appended to .jul/config.toml.
+func setupReviewProfilesRepo(t *testing.T, extraConfig string) string {
+	t.Helper()
+	repo := t.TempDir()
+	runGitTest(t, repo, "init")
+	runGitTest(t, repo, "config", "user.name", "Test User")
+	runGitTest(t, repo, "config", "user.email", "test@example.com")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+	runGitTest(t, repo, "add", "README.md")
+	runGitTest(t, repo, "commit", "-m", "initial")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+
+	cfg := `[review.profiles.security]
+prompt = "Look for injection bugs."
+
+[review.profiles.tests]
+prompt = "Look for missing tests."
+
+[review.profiles.docs]
+prompt = "Look for stale docs."
+` + extraConfig
+	if err := os.MkdirAll(filepath.Join(repo, ".jul"), 0o755); err != nil {
+		t.Fatalf("mkdir failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".jul", "config.toml"), []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+
+	// security and tests both rewrite the first README line; docs adds a file.
+	script := filepath.Join(t.TempDir(), "agent.sh")
+	body := `#!/bin/sh
+req=$(cat)
+cd "$JUL_AGENT_WORKSPACE"
+case "$req" in
+*'"profile":"security"'*) sed -i.bak 1s/one/ONE/ README.md; rm -f README.md.bak; conf=0.9 ;;
+*'"profile":"tests"'*) sed -i.bak 1s/one/uno/ README.md; rm -f README.md.bak; conf=0.5 ;;
+*) echo docs > docs.txt; conf=0.7 ;;
+esac
+case "$req" in
+*'"focus":"Look for '*) ;;
+*) echo missing focus >&2; exit 1 ;;
+esac
+git add -A && git commit -q -m review
+echo "{\"version\":1,\"status\":\"completed\",\"suggestions\":[{\"commit\":\"$(git rev-parse HEAD)\",\"reason\":\"review\",\"confidence\":$conf}]}"
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write agent failed: %v", err)
+	}
+	t.Setenv("JUL_AGENT_CMD", script)
+	return repo
+}
+
+func TestReviewProfilesRunInParallelAndDedupe(t *testing.T) {
+	repo := setupReviewProfilesRepo(t, "")
+
+	res, err := runReviewInternal(reviewModeSuggest, "", "", nil, false, nil)
+	if err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	if len(res.Warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", res.Warnings)
+	}
+	if len(res.Suggestions) != 2 {
+		t.Fatalf("expected overlapping suggestions to be merged, got %+v", res.Suggestions)
+	}
+	var tags []string
+	for _, sug := range res.Suggestions {
+		tags = append(tags, strings.Join(sug.Profiles, "+"))
+		if len(sug.Profiles) == 2 && sug.Confidence != 0.9 {
+			t.Fatalf("expected the more confident suggestion to be kept, got %+v", sug)
+		}
+	}
+	sort.Strings(tags)
+	if strings.Join(tags, " ") != "docs security+tests" {
+		t.Fatalf("unexpected profile tags: %v", tags)
+	}
+	for _, name := range []string{"worktree", "worktree-2", "worktree-3"} {
+		if _, err := os.Stat(filepath.Join(repo, ".jul", "agent-workspace", name)); err != nil {
+			t.Fatalf("expected pool worktree %s: %v", name, err)
+		}
+	}
+	leases, _ := filepath.Glob(filepath.Join(repo, ".jul", "agent-workspace", "leases", "*.json"))
+	if len(leases) != 0 {
+		t.Fatalf("expected leases to be released, got %v", leases)
+	}
+
+	if _, err := runReviewInternal(reviewModeSuggest, "", "", []string{"perf"}, false, nil); err == nil || !strings.Contains(err.Error(), `"perf" not configured`) {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestReviewProfilesQueueWhenPoolIsBusy(t *testing.T) {
+	repo := setupReviewProfilesRepo(t, "\n[agent]\npool_size = 2\n")
+	head := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+	held, err := agent.AcquireWorktree(repo, head, "fix")
+	if err != nil {
+		t.Fatalf("AcquireWorktree failed: %v", err)
+	}
+	defer held.Release()
+
+	res, err := runReviewInternal(reviewModeSuggest, "", "", nil, false, nil)
+	if err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	if len(res.Warnings) != 0 || len(res.Suggestions) != 2 {
+		t.Fatalf("expected all three profiles to run on the one free worktree, got %+v (warnings %v)", res.Suggestions, res.Warnings)
+	}
+	for _, sug := range res.Suggestions {
+		files := strings.Fields(runGitCmd(t, repo, "diff", "--name-only", head, sug.SuggestedCommitSHA))
+		if len(files) != 1 {
+			t.Fatalf("expected each queued profile to start from a clean worktree, got %v for %+v", files, sug)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".jul", "agent-workspace", "worktree-3")); !os.IsNotExist(err) {
+		t.Fatalf("expected the review to stay within pool_size, got %v", err)
+	}
+}
@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"bytes"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/metadata"
+	"github.com/lydakis/jul/cli/internal/output"
+	"github.com/lydakis/jul/cli/internal/policy"
 )
 
 func TestReviewRootCommitDiff(t *testing.T) {
@@ -30,11 +36,11 @@ func TestReviewRootCommitDiff(t *testing.T) {
 		_ = os.Chdir(cwd)
 	})
 
-	diff := reviewDiff(strings.TrimSpace(sha))
+	diff := reviewDiff("", strings.TrimSpace(sha))
 	if strings.TrimSpace(diff) == "" {
 		t.Fatalf("expected non-empty diff for root commit")
 	}
-	files := reviewFiles(strings.TrimSpace(sha))
+	files := reviewFiles("", strings.TrimSpace(sha))
 	if len(files) == 0 {
 		t.Fatalf("expected files for root commit")
 	}
@@ -100,3 +106,209 @@ func runGitOutputTest(t *testing.T, dir string, args ...string) string {
 	}
 	return strings.TrimSpace(string(output))
 }
+
+func TestReviewStoresFindingsForShowDiffAndPolicy(t *testing.T) {
+	repo := t.TempDir()
+	runGitTest(t, repo, "init")
+	runGitTest(t, repo, "config", "user.name", "Test User")
+	runGitTest(t, repo, "config", "user.email", "test@example.com")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+	runGitTest(t, repo, "add", "README.md")
+	runGitTest(t, repo, "commit", "-m", "initial")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("one\nTWO\nthree\n"), 0o644); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+	runGitTest(t, repo, "commit", "-am", "shout")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+
+	script := filepath.Join(t.TempDir(), "agent.sh")
+	body := `#!/bin/sh
+cat >/dev/null
+echo '{"version":1,"status":"completed","summary":"shouting","findings":[{"path":"README.md","start_line":2,"end_line":2,"severity":"error","category":"style","message":"do not shout"},{"path":"README.md","start_line":3,"severity":"info","message":"fine"}]}'
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write agent failed: %v", err)
+	}
+	t.Setenv("JUL_AGENT_CMD", script)
+
+	res, err := runReviewInternal(reviewModeSummary, "", "", nil, false, nil)
+	if err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	if len(res.Findings) != 2 || res.Findings[0].Severity != "high" {
+		t.Fatalf("expected normalized findings, got %+v", res.Findings)
+	}
+	note, err := metadata.GetFindings(res.BaseSHA)
+	if err != nil || note == nil || note.ReviewID != res.ReviewID {
+		t.Fatalf("expected findings note for review %s, got %+v (%v)", res.ReviewID, note, err)
+	}
+
+	show, err := buildShowPayload(res.BaseSHA)
+	if err != nil {
+		t.Fatalf("show failed: %v", err)
+	}
+	if len(show.Findings) != 2 {
+		t.Fatalf("expected findings in show payload, got %+v", show.Findings)
+	}
+
+	from, to, _, err := resolveDiffTargets([]string{res.BaseSHA})
+	if err != nil {
+		t.Fatalf("resolve diff failed: %v", err)
+	}
+	diffOut, err := runDiff(from, to, false, false, false)
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	findings, side := diffFindings(from, to)
+	var buf bytes.Buffer
+	output.RenderDiff(&buf, output.DiffResult{Diff: diffOut, Findings: findings, FindingsOn: side})
+	rendered := buf.String()
+	hunk := strings.Index(rendered, "+TWO")
+	anchored := strings.Index(rendered, ">> [high] README.md:2 style: do not shout")
+	if hunk < 0 || anchored < hunk {
+		t.Fatalf("expected finding after its hunk, got:\n%s", rendered)
+	}
+
+	err = enforcePromotePolicy(policy.PromotePolicy{MaxSeverity: "medium"}, res.BaseSHA, "")
+	if err == nil || !strings.Contains(err.Error(), "1 review finding(s) above max severity medium") {
+		t.Fatalf("expected max_severity gate, got %v", err)
+	}
+	if err := enforcePromotePolicy(policy.PromotePolicy{MaxSeverity: "high"}, res.BaseSHA, ""); err != nil {
+		t.Fatalf("expected findings within max severity to pass, got %v", err)
+	}
+}
+
+func TestReviewIsIncrementalSinceLastReviewedCheckpoint(t *testing.T) {
+	repo := t.TempDir()
+	runGitTest(t, repo, "init")
+	runGitTest(t, repo, "config", "user.name", "Test User")
+	runGitTest(t, repo, "config", "user.email", "test@example.com")
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+	}
+	writeFile("a.txt", "one\ntwo\nthree\n")
+	writeFile("b.txt", "bee\n")
+	runGitTest(t, repo, "add", ".")
+	runGitTest(t, repo, "commit", "-m", "initial")
+	changeID := "Iabc0000000000000000000000000000000000001"
+	writeFile("a.txt", "one\nTWO\nthree\n")
+	runGitTest(t, repo, "commit", "-am", "shout\n\nChange-Id: "+changeID)
+	runGitTest(t, repo, "update-ref", anchorRef(changeID), "HEAD")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+
+	seen := filepath.Join(t.TempDir(), "seen.txt")
+	script := filepath.Join(t.TempDir(), "agent.sh")
+	body := `#!/bin/sh
+cat > "` + seen + `"
+if grep -q "reviewed_checkpoint" "` + seen + `"; then
+  echo '{"version":1,"status":"completed","summary":"bees","findings":[{"path":"b.txt","start_line":1,"severity":"low","message":"too many bees"}]}'
+else
+  echo '{"version":1,"status":"completed","summary":"shouting","findings":[{"path":"a.txt","start_line":2,"severity":"high","message":"do not shout"}]}'
+fi
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write agent failed: %v", err)
+	}
+	t.Setenv("JUL_AGENT_CMD", script)
+
+	first, err := runReviewInternal(reviewModeSummary, "", "", nil, false, nil)
+	if err != nil {
+		t.Fatalf("first review failed: %v", err)
+	}
+	if first.ReviewedFrom != "" || len(first.Findings) != 1 {
+		t.Fatalf("expected full first review, got %+v", first)
+	}
+
+	writeFile("a.txt", "zero\none\nTWO\nthree\n")
+	writeFile("b.txt", "bee\nbee\n")
+	runGitTest(t, repo, "commit", "-am", "more\n\nChange-Id: "+changeID)
+
+	second, err := runReviewInternal(reviewModeSummary, "", "", nil, false, nil)
+	if err != nil {
+		t.Fatalf("second review failed: %v", err)
+	}
+	if second.ReviewedFrom != first.BaseSHA {
+		t.Fatalf("expected review since %s, got %q", first.BaseSHA, second.ReviewedFrom)
+	}
+	request, _ := os.ReadFile(seen)
+	if strings.Contains(string(request), "-two") || !strings.Contains(string(request), "+zero") {
+		t.Fatalf("expected only the interdiff, got:\n%s", request)
+	}
+	if !strings.Contains(string(request), "do not shout") {
+		t.Fatalf("expected prior findings as context, got:\n%s", request)
+	}
+	if len(second.Findings) != 2 {
+		t.Fatalf("expected carried and new findings, got %+v", second.Findings)
+	}
+	if second.Findings[0].Path != "a.txt" || second.Findings[0].StartLine != 3 {
+		t.Fatalf("expected carried finding shifted to line 3, got %+v", second.Findings[0])
+	}
+	note, err := metadata.GetFindings(second.BaseSHA)
+	if err != nil || note == nil || note.ReviewedFrom != first.BaseSHA {
+		t.Fatalf("expected coverage recorded on findings note, got %+v (%v)", note, err)
+	}
+
+	full, err := runReviewInternal(reviewModeSummary, "", "", nil, true, nil)
+	if err != nil {
+		t.Fatalf("full review failed: %v", err)
+	}
+	request, _ = os.ReadFile(seen)
+	if full.ReviewedFrom != "" || !strings.Contains(string(request), "-two") {
+		t.Fatalf("expected --full to review the whole change, got %+v:\n%s", full, request)
+	}
+}
+
+func TestCarryOverFindingsShiftsAndDrops(t *testing.T) {
+	findings := []client.Finding{
+		{Path: "a.go", StartLine: 10, EndLine: 12, Message: "below edit"},
+		{Path: "a.go", StartLine: 4, EndLine: 4, Message: "on edit"},
+		{Path: "a.go", StartLine: 1, EndLine: 1, Message: "above edit"},
+		{Path: "b.go", StartLine: 0, Message: "file level"},
+		{Path: "c.go", StartLine: 7, Message: "before insertion"},
+		{Path: "c.go", StartLine: 9, Message: "after insertion"},
+	}
+	interdiff := []diffHunk{
+		{path: "a.go", start: 3, end: 5, oldCount: 2, newCount: 3},
+		{path: "c.go", start: 7, end: 8, oldCount: 0, newCount: 2},
+		{path: "b.go", start: 1, end: 2, oldCount: 1, newCount: 1},
+	}
+	kept := carryOverFindings(findings, interdiff)
+	got := map[string]int{}
+	for _, f := range kept {
+		got[f.Message] = f.StartLine
+	}
+	want := map[string]int{"below edit": 11, "above edit": 1, "before insertion": 7, "after insertion": 11}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for message, line := range want {
+		if got[message] != line {
+			t.Fatalf("expected %q at line %d, got %v", message, line, got)
+		}
+	}
+}
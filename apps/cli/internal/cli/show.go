@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/lydakis/jul/cli/internal/client"
 	"github.com/lydakis/jul/cli/internal/gitutil"
 	"github.com/lydakis/jul/cli/internal/metadata"
 	"github.com/lydakis/jul/cli/internal/output"
@@ -16,7 +17,8 @@ func newShowCommand() Command {
 		Summary: "Show details of a checkpoint or suggestion",
 		Run: func(args []string) int {
 			fs, jsonOut := newFlagSet("show")
-			_ = fs.Parse(args)
+			hunks := fs.Bool("hunks", false, "Number a suggestion's hunks for 'jul apply <id> --hunks'")
+			_ = fs.Parse(flagsFirst(args))
 
 			id := strings.TrimSpace(fs.Arg(0))
 			if id == "" {
@@ -37,6 +39,17 @@ func newShowCommand() Command {
 				}
 				return 1
 			}
+			if *hunks && payload.Suggestion != nil {
+				payload.Hunks, err = suggestionHunks(payload.Suggestion.BaseCommitSHA, payload.Suggestion.SuggestedCommitSHA)
+				if err != nil {
+					if *jsonOut {
+						_ = output.EncodeError(os.Stdout, "show_failed", fmt.Sprintf("show failed: %v", err), nil)
+					} else {
+						fmt.Fprintf(os.Stderr, "show failed: %v\n", err)
+					}
+					return 1
+				}
+			}
 
 			if *jsonOut {
 				return writeJSON(payload)
@@ -74,6 +87,10 @@ func buildShowPayload(id string) (output.ShowResult, error) {
 
 	attView, _ := resolveAttestationView(sha)
 	diffstat := diffStatParent(sha)
+	var findings []client.Finding
+	if note, err := metadata.GetFindings(sha); err == nil && note != nil {
+		findings = note.Findings
+	}
 	return output.ShowResult{
 		Type:                     "checkpoint",
 		CommitSHA:                sha,
@@ -85,6 +102,7 @@ func buildShowPayload(id string) (output.ShowResult, error) {
 		AttestationStale:         attView.Stale,
 		AttestationInheritedFrom: attView.InheritedFrom,
 		DiffStat:                 diffstat,
+		Findings:                 findings,
 	}, nil
 }
 
@@ -78,6 +78,7 @@ func refreshStatusCache(repoRoot string) (*statusCache, error) {
 	if pendingCounts == nil {
 		pendingCounts = map[string]int{}
 	}
+	findingSummaries, _ := metadata.FindingSummaries()
 
 	summaries := make([]output.CheckpointSummary, 0, len(checkpoints))
 	for _, cp := range checkpoints {
@@ -95,6 +96,8 @@ func refreshStatusCache(repoRoot string) (*statusCache, error) {
 			CIStale:            ciView.Stale,
 			CIInheritedFrom:    ciView.InheritedFrom,
 			SuggestionsPending: count,
+			Findings:           findingSummaries[cp.SHA].Count,
+			FindingsWorst:      findingSummaries[cp.SHA].Worst,
 		})
 	}
 
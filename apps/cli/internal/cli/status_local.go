@@ -286,6 +286,7 @@ func buildLocalStatus() (output.Status, error) {
 			return output.Status{}, err
 		}
 		pendingCounts, _ := metadata.PendingSuggestionCounts()
+		findingSummaries, _ := metadata.FindingSummaries()
 		summaries := make([]output.CheckpointSummary, 0, len(checkpoints))
 		for _, cp := range checkpoints {
 			ciView, _ := resolveAttestationView(cp.SHA)
@@ -302,6 +303,8 @@ func buildLocalStatus() (output.Status, error) {
 				CIStale:            ciView.Stale,
 				CIInheritedFrom:    ciView.InheritedFrom,
 				SuggestionsPending: count,
+				Findings:           findingSummaries[cp.SHA].Count,
+				FindingsWorst:      findingSummaries[cp.SHA].Worst,
 			})
 		}
 		status.Checkpoints = summaries
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/agent"
+	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/metadata"
+)
+
+type retargetResult struct {
+	Target     string
+	Retargeted []client.Suggestion
+	Conflicted []client.Suggestion
+}
+
+// retargetStaleSuggestions moves the current change's stale suggestions onto
+// the draft's base, so they survive checkpoints and restacks. Conflicted
+// suggestions are retried too: a later base may take them cleanly. Partially
+// applied ones carry over only the hunks not yet taken.
+func retargetStaleSuggestions() (retargetResult, error) {
+	draftSHA, parentSHA, err := currentDraftAndBase()
+	if err != nil {
+		return retargetResult{}, err
+	}
+	changeID := changeIDForCommit(draftSHA)
+	var stale []client.Suggestion
+	for _, status := range []string{"pending", "partially_applied", "conflicted"} {
+		suggestions, err := metadata.ListSuggestions(changeID, status, 0)
+		if err != nil {
+			return retargetResult{}, err
+		}
+		for _, sug := range suggestions {
+			if suggestionIsStale(sug.BaseCommitSHA, draftSHA, parentSHA) {
+				stale = append(stale, sug)
+			}
+		}
+	}
+	return retargetSuggestions(parentSHA, stale)
+}
+
+// retargetSuggestions three-way merges each suggestion's changes onto
+// targetSHA in an agent worktree. Clean merges get a new suggested commit and
+// return to pending; the rest are marked conflicted.
+func retargetSuggestions(targetSHA string, suggestions []client.Suggestion) (retargetResult, error) {
+	res := retargetResult{Target: targetSHA}
+	if len(suggestions) == 0 {
+		return res, nil
+	}
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return res, err
+	}
+	lease, err := agent.AcquireWorktree(repoRoot, targetSHA, "retarget_suggestions")
+	if err != nil {
+		return res, err
+	}
+	defer lease.Release()
+	for _, sug := range suggestions {
+		suggested, conflicts, err := rebaseSuggestionOnto(lease.Worktree, sug, targetSHA)
+		if err != nil {
+			return res, err
+		}
+		switch {
+		case len(conflicts) > 0:
+			message := fmt.Sprintf("conflicts in %s when rebasing onto %s", strings.Join(conflicts, ", "), shortSHA(targetSHA))
+			updated, err := metadata.UpdateSuggestionStatus(sug.SuggestionID, "conflicted", message)
+			if err != nil {
+				return res, err
+			}
+			res.Conflicted = append(res.Conflicted, updated)
+		case suggested == "":
+			if _, err := metadata.UpdateSuggestionStatus(sug.SuggestionID, "applied", "already in "+shortSHA(targetSHA)); err != nil {
+				return res, err
+			}
+		default:
+			moved, err := metadata.RetargetSuggestion(sug.SuggestionID, targetSHA, suggested)
+			if err != nil {
+				return res, err
+			}
+			res.Retargeted = append(res.Retargeted, moved)
+		}
+	}
+	_ = gitDir(lease.Worktree, nil, "reset", "--hard", targetSHA)
+	_ = gitDir(lease.Worktree, nil, "clean", "-fd")
+	return res, nil
+}
+
+// rebaseSuggestionOnto replays BaseCommitSHA..SuggestedCommitSHA onto
+// targetSHA with a three-way apply. It returns the new suggested commit, or
+// the files that conflict. An empty commit with no conflicts means targetSHA
+// already contains the change. For a suggestion that was partially applied,
+// only the remaining hunks are replayed; the taken ones are already in the
+// draft and may since have been edited.
+func rebaseSuggestionOnto(worktree string, sug client.Suggestion, targetSHA string) (string, []string, error) {
+	if err := gitDir(worktree, nil, "reset", "--hard", targetSHA); err != nil {
+		return "", nil, err
+	}
+	if err := gitDir(worktree, nil, "clean", "-fd"); err != nil {
+		return "", nil, err
+	}
+	patch, err := suggestionPatch(sug.BaseCommitSHA, sug.SuggestedCommitSHA)
+	if err != nil {
+		return "", nil, err
+	}
+	files, _ := diffNameOnly(sug.BaseCommitSHA, sug.SuggestedCommitSHA)
+	if len(sug.AppliedPaths) > 0 || len(sug.AppliedHunks) > 0 {
+		remaining, err := remainingSuggestionSubset(sug, patch)
+		if err != nil {
+			// Everything was taken; there is nothing left to carry over.
+			return "", nil, nil
+		}
+		patch, files = remaining.patch, remaining.paths
+	}
+	cmd := exec.Command("git", "apply", "--3way", "--index")
+	cmd.Dir = worktree
+	cmd.Stdin = strings.NewReader(patch)
+	if _, err := cmd.CombinedOutput(); err != nil {
+		conflicts := mergeConflictFiles(worktree)
+		if len(conflicts) == 0 {
+			conflicts = files
+		}
+		return "", conflicts, nil
+	}
+	tree, err := gitOutputDir(worktree, "write-tree")
+	if err != nil {
+		return "", nil, err
+	}
+	targetTree, err := gitutil.Git("rev-parse", targetSHA+"^{tree}")
+	if err != nil {
+		return "", nil, err
+	}
+	if strings.TrimSpace(tree) == strings.TrimSpace(targetTree) {
+		return "", nil, nil
+	}
+	message, err := gitutil.CommitMessage(sug.SuggestedCommitSHA)
+	if err != nil {
+		return "", nil, err
+	}
+	sha, err := gitutil.CommitTree(strings.TrimSpace(tree), targetSHA, strings.TrimSpace(message))
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(sha), nil, nil
+}
+
+func renderRetargetedSuggestions(res retargetResult) {
+	if len(res.Retargeted) > 0 {
+		fmt.Fprintf(os.Stdout, "  ↻ Rebased %d stale suggestion(s) onto %s\n", len(res.Retargeted), shortSHA(res.Target))
+	}
+	for _, sug := range res.Conflicted {
+		fmt.Fprintf(os.Stdout, "  ✗ Suggestion %s conflicts with %s (jul review --regenerate %s)\n", sug.SuggestionID, shortSHA(res.Target), sug.SuggestionID)
+	}
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/metadata"
+)
+
+func TestRetargetStaleSuggestionsAfterCheckpoint(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+
+	changeID := "I5555555555555555555555555555555555555555"
+	long := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	writeFilePath(t, repo, "a.txt", long)
+	writeFilePath(t, repo, "b.txt", "bee\n")
+	runGitCmd(t, repo, "add", ".")
+	runGitCmd(t, repo, "commit", "-m", "first\n\nChange-Id: "+changeID)
+	oldCheckpoint := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+
+	suggest := func(name, content string) string {
+		t.Helper()
+		writeFilePath(t, repo, name, content)
+		runGitCmd(t, repo, "commit", "-am", "suggestion for "+name)
+		sha := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+		runGitCmd(t, repo, "reset", "-q", "--hard", oldCheckpoint)
+		return sha
+	}
+	cleanSHA := suggest("a.txt", strings.Replace(long, "2\n", "two\n", 1))
+	conflictSHA := suggest("b.txt", "BEE\n")
+
+	writeFilePath(t, repo, "a.txt", strings.Replace(long, "11\n", "eleven\n", 1))
+	writeFilePath(t, repo, "b.txt", "buzz\n")
+	runGitCmd(t, repo, "commit", "-am", "second\n\nChange-Id: "+changeID)
+	newCheckpoint := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+	runGitCmd(t, repo, "commit", "--allow-empty", "-m", "[draft] wip\n\nChange-Id: "+changeID)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+
+	create := func(suggested string) string {
+		t.Helper()
+		sug, err := metadata.CreateSuggestion(metadata.SuggestionCreate{
+			ChangeID:           changeID,
+			BaseCommitSHA:      oldCheckpoint,
+			SuggestedCommitSHA: suggested,
+			CreatedBy:          "tester",
+			Reason:             "review",
+		})
+		if err != nil {
+			t.Fatalf("CreateSuggestion failed: %v", err)
+		}
+		return sug.SuggestionID
+	}
+	cleanID := create(cleanSHA)
+	conflictID := create(conflictSHA)
+
+	res, err := retargetStaleSuggestions()
+	if err != nil {
+		t.Fatalf("retarget failed: %v", err)
+	}
+	if len(res.Retargeted) != 1 || len(res.Conflicted) != 1 || res.Target != newCheckpoint {
+		t.Fatalf("expected one retargeted and one conflicted suggestion onto %s, got %+v", newCheckpoint, res)
+	}
+
+	moved, _, _ := metadata.GetSuggestionByID(cleanID)
+	if moved.Status != "pending" || moved.BaseCommitSHA != newCheckpoint || moved.SuggestedCommitSHA == cleanSHA {
+		t.Fatalf("expected clean suggestion rebased and pending, got %+v", moved)
+	}
+	content := runGitCmd(t, repo, "show", moved.SuggestedCommitSHA+":a.txt")
+	if !strings.Contains(content, "two") || !strings.Contains(content, "eleven") {
+		t.Fatalf("expected rebased suggestion to keep both changes, got:\n%s", content)
+	}
+	if parent := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", moved.SuggestedCommitSHA+"^")); parent != newCheckpoint {
+		t.Fatalf("expected rebased suggestion on %s, got parent %s", newCheckpoint, parent)
+	}
+
+	conflicted, _, _ := metadata.GetSuggestionByID(conflictID)
+	if conflicted.Status != "conflicted" || !strings.Contains(conflicted.ResolutionMessage, "b.txt") {
+		t.Fatalf("expected conflicted suggestion naming b.txt, got %+v", conflicted)
+	}
+	if status := strings.TrimSpace(runGitCmd(t, repo, "status", "--porcelain", "--untracked-files=no")); status != "" {
+		t.Fatalf("expected user worktree untouched, got %q", status)
+	}
+
+	seen := filepath.Join(t.TempDir(), "seen.json")
+	script := filepath.Join(t.TempDir(), "agent.sh")
+	body := `#!/bin/sh
+cat > "` + seen + `"
+cd "$JUL_AGENT_WORKSPACE"
+echo BUZZ > b.txt
+git add -A && git commit -q -m regenerate
+echo "{\"version\":1,\"status\":\"completed\",\"suggestions\":[{\"commit\":\"$(git rev-parse HEAD)\",\"reason\":\"review\",\"confidence\":0.8}]}"
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write agent failed: %v", err)
+	}
+	t.Setenv("JUL_AGENT_CMD", script)
+
+	review, err := runReviewInternal(reviewModeSuggest, "", conflictID, nil, false, nil)
+	if err != nil {
+		t.Fatalf("regenerate failed: %v", err)
+	}
+	if review.Regenerated != conflictID || len(review.Suggestions) != 1 {
+		t.Fatalf("expected a regenerated suggestion, got %+v", review)
+	}
+	request, _ := os.ReadFile(seen)
+	if !strings.Contains(string(request), `"regenerate"`) || !strings.Contains(string(request), "+BEE") {
+		t.Fatalf("expected the stale patch in the request, got:\n%s", request)
+	}
+	replaced, _, _ := metadata.GetSuggestionByID(conflictID)
+	if replaced.Status != "rejected" || !strings.Contains(replaced.ResolutionMessage, review.Suggestions[0].SuggestionID) {
+		t.Fatalf("expected the conflicted suggestion to be replaced, got %+v", replaced)
+	}
+}
+
+func TestRetargetPartiallyAppliedSuggestionKeepsRemainingHunks(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+
+	changeID := "I6666666666666666666666666666666666666666"
+	long := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	writeFilePath(t, repo, "a.txt", long)
+	runGitCmd(t, repo, "add", ".")
+	runGitCmd(t, repo, "commit", "-m", "first\n\nChange-Id: "+changeID)
+	oldCheckpoint := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+
+	writeFilePath(t, repo, "a.txt", strings.Replace(strings.Replace(long, "2\n", "two\n", 1), "11\n", "eleven\n", 1))
+	runGitCmd(t, repo, "commit", "-am", "suggestion")
+	suggestedSHA := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+	runGitCmd(t, repo, "reset", "-q", "--hard", oldCheckpoint)
+
+	// Hunk 1 was taken and then reworded before the next checkpoint, so
+	// replaying the whole suggestion would conflict.
+	writeFilePath(t, repo, "a.txt", strings.Replace(long, "2\n", "TWO\n", 1))
+	runGitCmd(t, repo, "commit", "-am", "second\n\nChange-Id: "+changeID)
+	newCheckpoint := strings.TrimSpace(runGitCmd(t, repo, "rev-parse", "HEAD"))
+	runGitCmd(t, repo, "commit", "--allow-empty", "-m", "[draft] wip\n\nChange-Id: "+changeID)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	t.Setenv("HOME", filepath.Join(repo, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+	t.Setenv("JUL_AGENT_SANDBOX", "off")
+
+	sug, err := metadata.CreateSuggestion(metadata.SuggestionCreate{
+		ChangeID:           changeID,
+		BaseCommitSHA:      oldCheckpoint,
+		SuggestedCommitSHA: suggestedSHA,
+		CreatedBy:          "tester",
+		Reason:             "review",
+	})
+	if err != nil {
+		t.Fatalf("CreateSuggestion failed: %v", err)
+	}
+	if _, err := metadata.RecordSuggestionApply(sug.SuggestionID, "partially_applied", []string{"a.txt"}, []int{1}); err != nil {
+		t.Fatalf("RecordSuggestionApply failed: %v", err)
+	}
+
+	res, err := retargetStaleSuggestions()
+	if err != nil {
+		t.Fatalf("retarget failed: %v", err)
+	}
+	if len(res.Retargeted) != 1 || len(res.Conflicted) != 0 {
+		t.Fatalf("expected the partially applied suggestion retargeted, got %+v", res)
+	}
+	moved, _, _ := metadata.GetSuggestionByID(sug.SuggestionID)
+	if moved.Status != "pending" || moved.BaseCommitSHA != newCheckpoint || len(moved.AppliedHunks) != 0 || len(moved.AppliedPaths) != 0 {
+		t.Fatalf("expected a pending suggestion with no applied subset on %s, got %+v", newCheckpoint, moved)
+	}
+	diff := runGitCmd(t, repo, "diff", newCheckpoint, moved.SuggestedCommitSHA)
+	if !strings.Contains(diff, "+eleven") || strings.Contains(diff, "+two") || strings.Contains(diff, "-TWO") {
+		t.Fatalf("expected only the remaining hunk, got:\n%s", diff)
+	}
+}
@@ -23,8 +23,9 @@ func newSuggestionsCommand() Command {
 			timings := metrics.NewTimings()
 			fs, jsonOut := newFlagSet("suggestions")
 			changeID := fs.String("change-id", "", "Filter by change ID")
-			status := fs.String("status", "pending", "Filter by status (pending|applied|rejected|stale|all)")
+			status := fs.String("status", "pending", "Filter by status (pending|applied|partially_applied|conflicted|rejected|stale|all)")
 			limit := fs.Int("limit", 20, "Max results")
+			profile := fs.String("profile", "", "Filter by review profile")
 			_ = fs.Parse(args)
 
 			contextStart := time.Now()
@@ -121,7 +122,12 @@ func newSuggestionsCommand() Command {
 			if statusFilter == "stale" {
 				listStatus = "pending"
 			}
-			results, err := metadata.ListSuggestions(currentChangeID, listStatus, *limit)
+			profileFilter := strings.TrimSpace(*profile)
+			listLimit := *limit
+			if profileFilter != "" {
+				listLimit = 0
+			}
+			results, err := metadata.ListSuggestions(currentChangeID, listStatus, listLimit)
 			timings.Add("list", time.Since(listStart))
 			if err != nil {
 				if *jsonOut {
@@ -132,6 +138,9 @@ func newSuggestionsCommand() Command {
 				return 1
 			}
 			filterStart := time.Now()
+			if profileFilter != "" {
+				results = filterSuggestionsByProfile(results, profileFilter, *limit)
+			}
 			if statusFilter == "stale" || statusFilter == "pending" {
 				staleOnly := make([]client.Suggestion, 0, len(results))
 				freshOnly := make([]client.Suggestion, 0, len(results))
@@ -168,6 +177,22 @@ func newSuggestionsCommand() Command {
 	}
 }
 
+func filterSuggestionsByProfile(suggestions []client.Suggestion, profile string, limit int) []client.Suggestion {
+	filtered := make([]client.Suggestion, 0, len(suggestions))
+	for _, sug := range suggestions {
+		for _, name := range sug.Profiles {
+			if name == profile {
+				filtered = append(filtered, sug)
+				break
+			}
+		}
+		if limit > 0 && len(filtered) >= limit {
+			break
+		}
+	}
+	return filtered
+}
+
 func newSuggestionActionCommand(name, action string) Command {
 	return Command{
 		Name:    name,
@@ -28,7 +28,12 @@ func runSyncDaemon(opts syncer.SyncOptions) int {
 		return 1
 	}
 
-	pidPath := filepath.Join(repoRoot, ".jul", "sync-daemon.pid")
+	// One daemon serves the main worktree and every linked workspace worktree.
+	mainRoot := repoRoot
+	if root, err := gitutil.MainWorktree(repoRoot); err == nil && root != "" {
+		mainRoot = root
+	}
+	pidPath := filepath.Join(mainRoot, ".jul", "sync-daemon.pid")
 	if err := os.MkdirAll(filepath.Dir(pidPath), 0o755); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to prepare daemon state: %v\n", err)
 		return 1
@@ -64,7 +69,8 @@ func runSyncDaemon(opts syncer.SyncOptions) int {
 	}
 	defer watcher.Close()
 
-	if err := watchRepo(watcher, repoRoot); err != nil {
+	roots := &daemonRoots{watched: map[string]bool{}}
+	if err := roots.refresh(watcher, mainRoot); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to watch repo: %v\n", err)
 		return 1
 	}
@@ -77,25 +83,34 @@ func runSyncDaemon(opts syncer.SyncOptions) int {
 	var syncInFlight int64
 	var attemptSeq int64
 
-	runSync := func() {
+	runSync := func(root string) {
 		syncMu.Lock()
 		defer syncMu.Unlock()
 		attemptID := atomic.AddInt64(&attemptSeq, 1)
 		inFlight := atomic.AddInt64(&syncInFlight, 1)
 		startedAt := time.Now()
-		logDaemonSyncStart(attemptID, inFlight, startedAt)
+		worktree := ""
+		if root != mainRoot {
+			worktree = root
+		}
+		logDaemonSyncStart(attemptID, inFlight, startedAt, worktree)
 		var syncErr error
 		var res syncer.Result
 		defer func() {
 			inFlightDone := atomic.AddInt64(&syncInFlight, -1)
-			logDaemonSyncDone(attemptID, inFlightDone, startedAt, time.Now(), res, syncErr)
+			logDaemonSyncDone(attemptID, inFlightDone, startedAt, time.Now(), worktree, res, syncErr)
 		}()
 		if minInterval > 0 && !lastSync.IsZero() {
 			if since := time.Since(lastSync); since < minInterval {
 				time.Sleep(minInterval - since)
 			}
 		}
-		res, syncErr = syncer.SyncWithOptions(opts)
+		// The syncer works on the repository of the current directory.
+		syncErr = inDir(root, func() error {
+			var err error
+			res, err = syncer.SyncWithOptions(opts)
+			return err
+		})
 		if syncErr != nil {
 			fmt.Fprintf(os.Stderr, "sync failed: %v\n", syncErr)
 		}
@@ -104,13 +119,16 @@ func runSyncDaemon(opts syncer.SyncOptions) int {
 
 	go func() {
 		for range syncCh {
-			runSync()
+			for _, root := range roots.takePending() {
+				runSync(root)
+			}
 		}
 	}()
 
 	var timerMu sync.Mutex
 	var timer *time.Timer
-	schedule := func() {
+	schedule := func(root string) {
+		roots.markPending(root)
 		timerMu.Lock()
 		defer timerMu.Unlock()
 		if timer != nil {
@@ -124,8 +142,14 @@ func runSyncDaemon(opts syncer.SyncOptions) int {
 		})
 	}
 
-	// Run an initial sync.
-	schedule()
+	// Run an initial sync of every worktree.
+	for _, root := range roots.list() {
+		schedule(root)
+	}
+
+	// Pick up worktrees opened or removed while the daemon runs.
+	rescan := time.NewTicker(daemonRescanInterval)
+	defer rescan.Stop()
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
@@ -135,13 +159,28 @@ func runSyncDaemon(opts syncer.SyncOptions) int {
 		case <-sigCh:
 			fmt.Fprintln(os.Stdout, "Sync daemon stopped.")
 			return 0
+		case <-rescan.C:
+			before := map[string]bool{}
+			for _, root := range roots.list() {
+				before[root] = true
+			}
+			_ = roots.refresh(watcher, mainRoot)
+			for _, root := range roots.list() {
+				if !before[root] {
+					schedule(root)
+				}
+			}
 		case event, ok := <-watcher.Events:
 			if !ok {
 				return 0
 			}
+			root := roots.owner(event.Name)
+			if root == "" {
+				continue
+			}
 			if shouldIgnorePath(event.Name) {
 				if isJulPath(event.Name) {
-					_ = ensureJulDir(repoRoot)
+					_ = ensureJulDir(root)
 				}
 				continue
 			}
@@ -153,7 +192,7 @@ func runSyncDaemon(opts syncer.SyncOptions) int {
 					_ = watchRepo(watcher, event.Name)
 				}
 			}
-			schedule()
+			schedule(root)
 		case err, ok := <-watcher.Errors:
 			if !ok {
 				return 0
@@ -163,6 +202,101 @@ func runSyncDaemon(opts syncer.SyncOptions) int {
 	}
 }
 
+const daemonRescanInterval = 10 * time.Second
+
+// daemonRoots tracks the worktrees the sync daemon watches and which of them
+// have changes waiting to be synced.
+type daemonRoots struct {
+	mu      sync.Mutex
+	watched map[string]bool
+	pending map[string]bool
+}
+
+func (r *daemonRoots) refresh(watcher *fsnotify.Watcher, mainRoot string) error {
+	current, err := julWorktrees(mainRoot)
+	if err != nil {
+		current = []string{mainRoot}
+	}
+	keep := map[string]bool{}
+	for _, root := range current {
+		if _, err := os.Stat(root); err != nil {
+			continue
+		}
+		keep[root] = true
+		r.mu.Lock()
+		seen := r.watched[root]
+		r.mu.Unlock()
+		if seen {
+			continue
+		}
+		if err := watchRepo(watcher, root); err != nil {
+			if root == mainRoot {
+				return err
+			}
+			continue
+		}
+		r.mu.Lock()
+		r.watched[root] = true
+		r.mu.Unlock()
+	}
+	r.mu.Lock()
+	for root := range r.watched {
+		if !keep[root] {
+			delete(r.watched, root)
+		}
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *daemonRoots) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]string, 0, len(r.watched))
+	for root := range r.watched {
+		out = append(out, root)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// owner returns the watched worktree containing path, preferring the deepest
+// one when worktrees are nested.
+func (r *daemonRoots) owner(path string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	best := ""
+	for root := range r.watched {
+		if path == root || strings.HasPrefix(path, root+string(os.PathSeparator)) {
+			if len(root) > len(best) {
+				best = root
+			}
+		}
+	}
+	return best
+}
+
+func (r *daemonRoots) markPending(root string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		r.pending = map[string]bool{}
+	}
+	r.pending[root] = true
+}
+
+func (r *daemonRoots) takePending() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]string, 0, len(r.pending))
+	for root := range r.pending {
+		out = append(out, root)
+	}
+	r.pending = nil
+	sort.Strings(out)
+	return out
+}
+
 func readDaemonPID(path string) (int, bool) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -223,6 +357,7 @@ func isJulPath(path string) bool {
 type daemonSyncLog struct {
 	Event      string           `json:"event"`
 	AttemptID  int64            `json:"attempt_id"`
+	Worktree   string           `json:"worktree,omitempty"`
 	AtUnixMs   int64            `json:"at_unix_ms"`
 	InFlight   int64            `json:"in_flight"`
 	Status     string           `json:"status,omitempty"`
@@ -236,19 +371,21 @@ type daemonSyncPhase struct {
 	Phase map[string]int64 `json:"phase,omitempty"`
 }
 
-func logDaemonSyncStart(attemptID int64, inFlight int64, at time.Time) {
+func logDaemonSyncStart(attemptID int64, inFlight int64, at time.Time, worktree string) {
 	logDaemonSyncEvent(daemonSyncLog{
 		Event:     "daemon_sync_start",
 		AttemptID: attemptID,
+		Worktree:  worktree,
 		AtUnixMs:  at.UnixMilli(),
 		InFlight:  inFlight,
 	})
 }
 
-func logDaemonSyncDone(attemptID int64, inFlight int64, startedAt, finishedAt time.Time, res syncer.Result, err error) {
+func logDaemonSyncDone(attemptID int64, inFlight int64, startedAt, finishedAt time.Time, worktree string, res syncer.Result, err error) {
 	entry := daemonSyncLog{
 		Event:      "daemon_sync_done",
 		AttemptID:  attemptID,
+		Worktree:   worktree,
 		AtUnixMs:   finishedAt.UnixMilli(),
 		InFlight:   inFlight,
 		DurationMs: finishedAt.Sub(startedAt).Milliseconds(),
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/output"
+	"github.com/lydakis/jul/cli/internal/syncer"
+)
+
+func newTransplantCommand() Command {
+	return Command{
+		Name:    "transplant",
+		Summary: "Carry the draft onto the workspace's advanced base",
+		Run: func(args []string) int {
+			fs, jsonOut := newFlagSet("transplant")
+			_ = fs.Parse(args)
+
+			res, err := syncer.Transplant()
+			if err != nil {
+				if *jsonOut {
+					_ = output.EncodeError(os.Stdout, "transplant_failed", err.Error(), []output.NextAction{
+						{Action: "checkout", Command: "jul ws checkout @"},
+					})
+				} else {
+					fmt.Fprintf(os.Stderr, "transplant failed: %v\n", err)
+				}
+				return 1
+			}
+			if repoRoot, err := gitutil.RepoTopLevel(); err == nil {
+				_, _ = refreshStatusCache(repoRoot)
+			}
+			if *jsonOut {
+				if code := writeJSON(res); code != 0 {
+					return code
+				}
+			} else {
+				output.RenderTransplant(os.Stdout, res, output.DefaultOptions())
+			}
+			if res.Status == "conflicts" {
+				return 1
+			}
+			return 0
+		},
+	}
+}
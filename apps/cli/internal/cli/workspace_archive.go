@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/output"
+	remotesel "github.com/lydakis/jul/cli/internal/remote"
+	wsconfig "github.com/lydakis/jul/cli/internal/workspace"
+)
+
+func runWorkspaceClose(args []string) int {
+	fs, jsonOut := newFlagSet("ws close")
+	_ = fs.Parse(args)
+	user, name, ok := archiveTarget(fs.Arg(0), *jsonOut)
+	if !ok {
+		return 1
+	}
+	target := user + "/" + name
+	if target == config.WorkspaceID() {
+		return archiveError(*jsonOut, "workspace_close_current", "cannot close current workspace", []output.NextAction{
+			{Action: "switch", Command: "jul ws switch <other>"},
+		})
+	}
+	if err := ensureNotOpenElsewhere(user, name); err != nil {
+		return writeWorkspaceOpenError(err, *jsonOut)
+	}
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return archiveError(*jsonOut, "workspace_repo_failed", fmt.Sprintf("failed to locate repo: %v", err), nil)
+	}
+	if !gitutil.RefExists(workspaceRef(user, name)) {
+		return archiveError(*jsonOut, "workspace_not_found", fmt.Sprintf("workspace not found: %s", target), nil)
+	}
+	if gitutil.RefExists(wsconfig.ArchivePrefix(user, name) + "workspace") {
+		return archiveError(*jsonOut, "workspace_archive_exists", fmt.Sprintf("an archived workspace %s already exists", target), []output.NextAction{
+			{Action: "reopen", Command: "jul ws reopen " + name},
+		})
+	}
+	if stack, err := wsconfig.LoadStack(repoRoot, user); err == nil {
+		if node, ok := stack.Nodes[name]; ok && len(node.Children) > 0 {
+			return archiveError(*jsonOut, "workspace_close_has_dependents", fmt.Sprintf("workspaces stacked on %s: %s", target, strings.Join(node.Children, ", ")), []output.NextAction{
+				{Action: "tree", Command: "jul ws tree"},
+			})
+		}
+	}
+
+	moves, err := wsconfig.PlanClose(repoRoot, user, name)
+	if err != nil {
+		return archiveError(*jsonOut, "workspace_close_failed", fmt.Sprintf("failed to close workspace: %v", err), nil)
+	}
+	synced, err := syncArchiveMoves(repoRoot, moves)
+	if err != nil {
+		return archiveError(*jsonOut, "workspace_close_sync_failed", fmt.Sprintf("failed to sync archive: %v", err), nil)
+	}
+	if err := wsconfig.ApplyClose(repoRoot, name, moves); err != nil {
+		return archiveError(*jsonOut, "workspace_close_failed", fmt.Sprintf("failed to close workspace: %v", err), nil)
+	}
+
+	out := workspaceActionOutput{
+		Status:       "ok",
+		Action:       "close",
+		Workspace:    target,
+		Message:      fmt.Sprintf("Closed workspace %s (archived under %s)", target, strings.TrimSuffix(wsconfig.ArchivePrefix(user, name), "/")),
+		WorkspaceRef: wsconfig.ArchivePrefix(user, name) + "workspace",
+	}
+	if synced != "" {
+		out.Message += fmt.Sprintf("; archive synced to %s", synced)
+	}
+	if *jsonOut {
+		return writeJSON(out)
+	}
+	renderWorkspaceAction(out)
+	return 0
+}
+
+func runWorkspaceReopen(args []string) int {
+	fs, jsonOut := newFlagSet("ws reopen")
+	_ = fs.Parse(args)
+	user, name, ok := archiveTarget(fs.Arg(0), *jsonOut)
+	if !ok {
+		return 1
+	}
+	target := user + "/" + name
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return archiveError(*jsonOut, "workspace_repo_failed", fmt.Sprintf("failed to locate repo: %v", err), nil)
+	}
+	// The archive may have been closed on another device.
+	if remote, rerr := remotesel.Resolve(); rerr == nil {
+		if err := ensureJulRefspecs(repoRoot, remote.Name); err != nil {
+			return archiveError(*jsonOut, "workspace_remote_failed", fmt.Sprintf("failed to configure remote: %v", err), nil)
+		}
+		if _, err := gitutil.Git("-C", repoRoot, "fetch", remote.Name); err != nil {
+			return archiveError(*jsonOut, "workspace_fetch_failed", fmt.Sprintf("fetch failed: %v", err), nil)
+		}
+	}
+	if gitutil.RefExists(workspaceRef(user, name)) {
+		return archiveError(*jsonOut, "workspace_exists", fmt.Sprintf("workspace %s already exists", target), nil)
+	}
+	if !gitutil.RefExists(wsconfig.ArchivePrefix(user, name) + "workspace") {
+		return archiveError(*jsonOut, "workspace_archive_missing", fmt.Sprintf("no archived workspace %s", target), []output.NextAction{
+			{Action: "list", Command: "jul ws list --archived"},
+		})
+	}
+
+	moves, err := wsconfig.PlanReopen(repoRoot, user, name)
+	if err != nil {
+		return archiveError(*jsonOut, "workspace_reopen_failed", fmt.Sprintf("failed to reopen workspace: %v", err), nil)
+	}
+	synced, err := syncArchiveMoves(repoRoot, moves)
+	if err != nil {
+		return archiveError(*jsonOut, "workspace_reopen_sync_failed", fmt.Sprintf("failed to sync workspace: %v", err), nil)
+	}
+	if err := wsconfig.ApplyReopen(repoRoot, user, name, moves); err != nil {
+		return archiveError(*jsonOut, "workspace_reopen_failed", fmt.Sprintf("failed to reopen workspace: %v", err), nil)
+	}
+
+	out := workspaceActionOutput{
+		Status:       "ok",
+		Action:       "reopen",
+		Workspace:    target,
+		Message:      fmt.Sprintf("Reopened workspace %s", target),
+		WorkspaceRef: workspaceRef(user, name),
+	}
+	if synced != "" {
+		out.Message += fmt.Sprintf("; synced to %s", synced)
+	}
+	if *jsonOut {
+		return writeJSON(out)
+	}
+	renderWorkspaceAction(out)
+	fmt.Fprintf(os.Stdout, "Switch to it with 'jul ws switch %s'.\n", name)
+	return 0
+}
+
+func archivedWorkspaces() ([]client.Workspace, error) {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return nil, err
+	}
+	user, _ := workspaceParts()
+	archived, err := wsconfig.ListArchived(repoRoot, user)
+	if err != nil {
+		return nil, err
+	}
+	workspaces := make([]client.Workspace, 0, len(archived))
+	for _, ws := range archived {
+		workspaces = append(workspaces, client.Workspace{
+			WorkspaceID:   user + "/" + ws.Name,
+			Repo:          config.RepoName(),
+			Branch:        ws.Name,
+			LastCommitSHA: ws.SHA,
+		})
+	}
+	return workspaces, nil
+}
+
+// syncArchiveMoves mirrors moves on the remote before they are applied
+// locally, so a failed push leaves both sides untouched. Local branches are
+// not pushed, and only refs the remote has are deleted there. It returns the
+// remote name, or "" when there is no remote.
+func syncArchiveMoves(repoRoot string, moves []wsconfig.RefMove) (string, error) {
+	remote, rerr := remotesel.Resolve()
+	if rerr != nil {
+		if rerr == remotesel.ErrNoRemote || rerr == remotesel.ErrMultipleRemote || rerr == remotesel.ErrRemoteMissing {
+			return "", nil
+		}
+		return "", rerr
+	}
+	if err := ensureJulRefspecs(repoRoot, remote.Name); err != nil {
+		return "", err
+	}
+	var deletes []string
+	for _, move := range moves {
+		if strings.HasPrefix(move.From, "refs/jul/") {
+			deletes = append(deletes, move.From)
+		}
+	}
+	onRemote := map[string]bool{}
+	if len(deletes) > 0 {
+		out, err := gitutil.Git(append([]string{"-C", repoRoot, "ls-remote", remote.Name}, deletes...)...)
+		if err != nil {
+			return "", err
+		}
+		for _, line := range strings.Split(out, "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 {
+				onRemote[fields[1]] = true
+			}
+		}
+	}
+	specs := []string{}
+	for _, move := range moves {
+		if strings.HasPrefix(move.To, "refs/jul/") {
+			specs = append(specs, "+"+move.SHA+":"+move.To)
+		}
+	}
+	for _, ref := range deletes {
+		if onRemote[ref] {
+			specs = append(specs, ":"+ref)
+		}
+	}
+	if len(specs) == 0 {
+		return remote.Name, nil
+	}
+	if _, err := gitutil.Git(append([]string{"-C", repoRoot, "push", remote.Name}, specs...)...); err != nil {
+		return "", fmt.Errorf("push to %s: %w", remote.Name, err)
+	}
+	return remote.Name, nil
+}
+
+func archiveTarget(arg string, jsonOut bool) (string, string, bool) {
+	name := strings.TrimSpace(arg)
+	if name == "" {
+		archiveError(jsonOut, "workspace_missing_name", "workspace name required", nil)
+		return "", "", false
+	}
+	user, _ := workspaceParts()
+	if parts := strings.SplitN(name, "/", 2); len(parts) == 2 {
+		user, name = parts[0], parts[1]
+	}
+	if user == "" || name == "" || strings.Contains(name, "/") {
+		archiveError(jsonOut, "workspace_invalid_name", fmt.Sprintf("invalid workspace %q", arg), nil)
+		return "", "", false
+	}
+	return user, name, true
+}
+
+func archiveError(jsonOut bool, code, msg string, next []output.NextAction) int {
+	if jsonOut {
+		_ = output.EncodeError(os.Stdout, code, msg, next)
+	} else {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+	return 1
+}
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/metadata"
+	"github.com/lydakis/jul/cli/internal/output"
+	"github.com/lydakis/jul/cli/internal/restack"
+	"github.com/lydakis/jul/cli/internal/workspace"
+)
+
+const (
+	baseStateUpToDate = "up_to_date"
+	baseStateAdvanced = "base_advanced"
+	baseStateDiverged = "diverged"
+	baseStateUnknown  = "unknown"
+)
+
+type workspaceTreeCheckpoint struct {
+	CommitSHA string `json:"commit_sha"`
+	Message   string `json:"message"`
+	When      string `json:"when,omitempty"`
+	Count     int    `json:"count"`
+}
+
+type workspaceTreeNode struct {
+	WorkspaceID        string                   `json:"workspace_id"`
+	Name               string                   `json:"name"`
+	Parent             string                   `json:"parent,omitempty"`
+	Current            bool                     `json:"current,omitempty"`
+	BaseRef            string                   `json:"base_ref,omitempty"`
+	BaseSHA            string                   `json:"base_sha,omitempty"`
+	BaseTip            string                   `json:"base_tip,omitempty"`
+	BaseState          string                   `json:"base_state,omitempty"`
+	ChangeID           string                   `json:"change_id,omitempty"`
+	Checkpoint         *workspaceTreeCheckpoint `json:"checkpoint,omitempty"`
+	CIStatus           string                   `json:"ci_status,omitempty"`
+	CIStale            bool                     `json:"ci_stale,omitempty"`
+	SuggestionsPending int                      `json:"suggestions_pending"`
+	CRStatus           string                   `json:"cr_status,omitempty"`
+	Children           []*workspaceTreeNode     `json:"children,omitempty"`
+}
+
+type workspaceTreeOutput struct {
+	Roots []*workspaceTreeNode `json:"roots"`
+}
+
+func runWorkspaceTree(args []string) int {
+	fs, jsonOut := newFlagSet("ws tree")
+	_ = fs.Parse(args)
+
+	out, err := buildWorkspaceTree()
+	if err != nil {
+		if *jsonOut {
+			_ = output.EncodeError(os.Stdout, "workspace_tree_failed", fmt.Sprintf("failed to build workspace tree: %v", err), nil)
+		} else {
+			fmt.Fprintf(os.Stderr, "failed to build workspace tree: %v\n", err)
+		}
+		return 1
+	}
+	if *jsonOut {
+		return writeJSON(out)
+	}
+	renderWorkspaceTree(out)
+	return 0
+}
+
+func buildWorkspaceTree() (workspaceTreeOutput, error) {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return workspaceTreeOutput{}, err
+	}
+	user, current := workspaceParts()
+	if current == "" {
+		current = "@"
+	}
+	stack, err := workspace.LoadStack(repoRoot, user)
+	if err != nil {
+		return workspaceTreeOutput{}, err
+	}
+	pending, _ := metadata.PendingSuggestionCounts()
+
+	var build func(name string) *workspaceTreeNode
+	build = func(name string) *workspaceTreeNode {
+		node := stack.Nodes[name]
+		out := workspaceTreeNodeFor(repoRoot, user, *node, pending)
+		out.Current = name == current
+		for _, child := range node.Children {
+			out.Children = append(out.Children, build(child))
+		}
+		return out
+	}
+	tree := workspaceTreeOutput{Roots: []*workspaceTreeNode{}}
+	for _, root := range stack.Roots {
+		tree.Roots = append(tree.Roots, build(root))
+	}
+	return tree, nil
+}
+
+func workspaceTreeNodeFor(repoRoot, user string, node workspace.Node, pending map[string]int) *workspaceTreeNode {
+	out := &workspaceTreeNode{
+		WorkspaceID: user + "/" + node.Name,
+		Name:        node.Name,
+		Parent:      node.Parent,
+		BaseRef:     strings.TrimSpace(node.Config.BaseRef),
+		BaseSHA:     strings.TrimSpace(node.Config.BaseSHA),
+		ChangeID:    node.ChangeID,
+	}
+	if pending != nil && node.ChangeID != "" {
+		out.SuggestionsPending = pending[node.ChangeID]
+	}
+
+	// The workspace ref points at the latest checkpoint once there is one;
+	// before that it still points at the base it was created from.
+	var chain []string
+	if msg, err := gitutil.CommitMessage(node.Tip); err == nil && node.ChangeID != "" && gitutil.ExtractChangeID(msg) == node.ChangeID {
+		chain, _ = checkpointChain(node.Tip, node.ChangeID)
+		out.Checkpoint = &workspaceTreeCheckpoint{
+			CommitSHA: node.Tip,
+			Message:   firstLine(msg),
+			Count:     len(chain),
+		}
+		if when, err := gitutil.Git("log", "-1", "--format=%cd", "--date=format:%Y-%m-%d %H:%M:%S", node.Tip); err == nil {
+			out.Checkpoint.When = strings.TrimSpace(when)
+		}
+		if view, err := resolveAttestationView(node.Tip); err == nil {
+			out.CIStatus = view.Status
+			out.CIStale = view.Stale
+		}
+	}
+	if len(chain) > 0 {
+		if parent, err := gitutil.ParentOf(chain[0]); err == nil {
+			out.BaseSHA = strings.TrimSpace(parent)
+		}
+		anchor := chain[0]
+		if sha, err := gitutil.ResolveRef(anchorRef(node.ChangeID)); err == nil && strings.TrimSpace(sha) != "" {
+			anchor = strings.TrimSpace(sha)
+		}
+		if state, ok, err := metadata.ReadChangeRequestState(anchor); err == nil && ok {
+			out.CRStatus = state.Status
+		}
+	}
+
+	if out.BaseRef != "" {
+		if tip, err := restack.ResolveBaseTip(repoRoot, out.BaseRef); err == nil {
+			out.BaseTip = tip
+		}
+		out.BaseState = workspaceBaseState(out.BaseSHA, out.BaseTip)
+	}
+	return out
+}
+
+// workspaceBaseState compares the commit a workspace sits on with the current
+// tip of its base ref. A workspace-base marker left by promote stands in for
+// the published tip it was created from.
+func workspaceBaseState(baseSHA, baseTip string) string {
+	if baseSHA == "" || baseTip == "" {
+		return baseStateUnknown
+	}
+	if baseSHA == baseTip {
+		return baseStateUpToDate
+	}
+	if msg, err := gitutil.CommitMessage(baseSHA); err == nil && strings.Contains(msg, "Jul-Type: workspace-base") {
+		markerTree, _ := gitutil.TreeOf(baseSHA)
+		tipTree, _ := gitutil.TreeOf(baseTip)
+		if markerTree != "" && strings.TrimSpace(markerTree) == strings.TrimSpace(tipTree) {
+			return baseStateUpToDate
+		}
+		if parent, err := gitutil.ParentOf(baseSHA); err == nil && strings.TrimSpace(parent) != "" {
+			baseSHA = strings.TrimSpace(parent)
+		}
+	}
+	if gitutil.IsAncestor(baseSHA, baseTip) {
+		return baseStateAdvanced
+	}
+	return baseStateDiverged
+}
+
+func renderWorkspaceTree(out workspaceTreeOutput) {
+	if len(out.Roots) == 0 {
+		fmt.Fprintln(os.Stdout, "No workspaces.")
+		return
+	}
+	var walk func(node *workspaceTreeNode, prefix string, last, root bool)
+	walk = func(node *workspaceTreeNode, prefix string, last, root bool) {
+		branch, childPrefix := "", ""
+		if !root {
+			branch = "├── "
+			childPrefix = prefix + "│   "
+			if last {
+				branch = "└── "
+				childPrefix = prefix + "    "
+			}
+		}
+		fmt.Fprintf(os.Stdout, "%s%s%s\n", prefix, branch, workspaceTreeLine(node))
+		for i, child := range node.Children {
+			walk(child, childPrefix, i == len(node.Children)-1, false)
+		}
+	}
+	for _, root := range out.Roots {
+		walk(root, "", true, true)
+	}
+}
+
+func workspaceTreeLine(node *workspaceTreeNode) string {
+	parts := []string{node.Name}
+	if node.Current {
+		parts[0] = "* " + node.Name
+	}
+	if node.Parent == "" && strings.HasPrefix(node.BaseRef, "refs/heads/") {
+		parts = append(parts, "("+strings.TrimPrefix(node.BaseRef, "refs/heads/")+")")
+	}
+	if node.Checkpoint != nil {
+		parts = append(parts, shortSHA(node.Checkpoint.CommitSHA), fmt.Sprintf("%q", node.Checkpoint.Message))
+	} else {
+		parts = append(parts, "(no checkpoints)")
+	}
+	switch node.BaseState {
+	case baseStateAdvanced:
+		parts = append(parts, "[base advanced]")
+	case baseStateDiverged:
+		parts = append(parts, "[diverged]")
+	}
+	if node.CIStatus != "" {
+		ci := "ci:" + node.CIStatus
+		if node.CIStale {
+			ci += " (stale)"
+		}
+		parts = append(parts, ci)
+	}
+	if node.SuggestionsPending > 0 {
+		parts = append(parts, fmt.Sprintf("suggestions:%d", node.SuggestionsPending))
+	}
+	if node.CRStatus != "" {
+		parts = append(parts, "cr:"+node.CRStatus)
+	}
+	return strings.Join(parts, " ")
+}
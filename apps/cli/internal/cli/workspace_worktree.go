@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/output"
+)
+
+// sharedJulState lists the .jul entries a linked worktree shares with the
+// main worktree: repo config, the per-workspace config and lease, which stay
+// keyed by workspace name, and legacy directory local saves. Everything else
+// under .jul (draft index, sync and CI state) is per worktree.
+var sharedJulState = []struct {
+	name string
+	dir  bool
+}{
+	{"config.toml", false},
+	{"policy.toml", false},
+	{"ci.toml", false},
+	{"syncignore", false},
+	{"workspaces", true},
+	{"local", true},
+}
+
+type workspaceOpenError struct {
+	Workspace string
+	Worktree  string
+}
+
+func (e workspaceOpenError) Error() string {
+	return fmt.Sprintf("workspace %s is open in worktree %s", e.Workspace, e.Worktree)
+}
+
+func runWorkspaceOpen(args []string) int {
+	fs, jsonOut := newFlagSet("ws open")
+	_ = fs.Parse(args)
+	name := strings.TrimSpace(fs.Arg(0))
+	if name == "" {
+		if *jsonOut {
+			_ = output.EncodeError(os.Stdout, "workspace_missing_name", "workspace name required", nil)
+		} else {
+			fmt.Fprintln(os.Stderr, "workspace name required")
+		}
+		return 1
+	}
+	wsID, wsUser, wsName, err := resolveWorkspaceID(name, "")
+	if err != nil {
+		if *jsonOut {
+			_ = output.EncodeError(os.Stdout, "workspace_resolve_failed", err.Error(), nil)
+		} else {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+		return 1
+	}
+	path, err := openWorkspaceWorktree(wsUser, wsName, fs.Arg(1))
+	if err != nil {
+		return writeWorkspaceOpenError(err, *jsonOut)
+	}
+	out := workspaceActionOutput{
+		Status:    "ok",
+		Action:    "open",
+		Workspace: wsID,
+		Worktree:  path,
+		Message:   fmt.Sprintf("Opened workspace '%s' in %s", wsName, path),
+	}
+	if *jsonOut {
+		return writeJSON(out)
+	}
+	renderWorkspaceAction(out)
+	return 0
+}
+
+// runWorkspaceNewWorktree creates a workspace on the current base and opens
+// it in its own worktree, leaving the current worktree and workspace alone.
+func runWorkspaceNewWorktree(wsID, wsUser, wsName, path string, jsonOut bool) int {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return writeWorkspaceOpenError(fmt.Errorf("failed to locate repo root: %w", err), jsonOut)
+	}
+	baseSHA, err := currentBaseSHA()
+	if err != nil {
+		if head, err := gitutil.Git("rev-parse", "HEAD"); err == nil {
+			baseSHA = strings.TrimSpace(head)
+		}
+	}
+	treeSHA, err := gitutil.TreeOf(baseSHA)
+	if err != nil {
+		return writeWorkspaceOpenError(fmt.Errorf("failed to read base tree: %w", err), jsonOut)
+	}
+	if _, err := createWorkspaceRefs(wsUser, wsName, detectBaseRef(repoRoot), baseSHA, strings.TrimSpace(treeSHA)); err != nil {
+		if jsonOut {
+			_ = output.EncodeError(os.Stdout, "workspace_create_failed", fmt.Sprintf("failed to create workspace: %v", err), nil)
+		} else {
+			fmt.Fprintf(os.Stderr, "failed to create workspace: %v\n", err)
+		}
+		return 1
+	}
+	opened, err := openWorkspaceWorktree(wsUser, wsName, path)
+	if err != nil {
+		return writeWorkspaceOpenError(err, jsonOut)
+	}
+	out := workspaceActionOutput{
+		Status:    "ok",
+		Action:    "new",
+		Workspace: wsID,
+		Worktree:  opened,
+		Message:   fmt.Sprintf("Created workspace '%s' in %s", wsName, opened),
+	}
+	if jsonOut {
+		return writeJSON(out)
+	}
+	renderWorkspaceAction(out)
+	return 0
+}
+
+func writeWorkspaceOpenError(err error, jsonOut bool) int {
+	code := "workspace_open_failed"
+	var next []output.NextAction
+	if open, ok := err.(workspaceOpenError); ok {
+		code = "workspace_open_in_worktree"
+		next = []output.NextAction{{Action: "cd", Command: "cd " + open.Worktree}}
+	}
+	if jsonOut {
+		_ = output.EncodeError(os.Stdout, code, err.Error(), next)
+	} else {
+		fmt.Fprintln(os.Stderr, err.Error())
+	}
+	return 1
+}
+
+// openWorkspaceWorktree materialises an existing workspace in a new linked
+// worktree at path (by default a sibling of the main worktree named
+// <repo>-<workspace>). The worktree gets its own HEAD on refs/heads/jul/<ws>,
+// its own jul.workspace in per-worktree git config and its own draft, so jul
+// commands run there act on that workspace.
+func openWorkspaceWorktree(user, name, path string) (string, error) {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return "", err
+	}
+	mainRoot, err := gitutil.MainWorktree(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	if err := ensureNotOpenElsewhere(user, name); err != nil {
+		return "", err
+	}
+	_, current := workspaceParts()
+	if current == name {
+		return "", fmt.Errorf("workspace %s/%s is checked out here; switch away first", user, name)
+	}
+	ref := workspaceRef(user, name)
+	tip, err := gitutil.ResolveRef(ref)
+	if err != nil || strings.TrimSpace(tip) == "" {
+		return "", fmt.Errorf("workspace ref not found: %s", ref)
+	}
+
+	if strings.TrimSpace(path) == "" {
+		path = filepath.Join(filepath.Dir(mainRoot), filepath.Base(mainRoot)+"-"+strings.ReplaceAll(name, "@", "default"))
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if entries, err := os.ReadDir(path); err == nil && len(entries) > 0 {
+		return "", fmt.Errorf("worktree path %s is not empty", path)
+	}
+
+	headRef := workspaceHeadRef(name)
+	if !gitutil.RefExists(headRef) {
+		if err := gitutil.UpdateRef(headRef, strings.TrimSpace(tip)); err != nil {
+			return "", err
+		}
+	}
+	if _, err := gitutil.Git("-C", repoRoot, "worktree", "add", "--no-checkout", path, strings.TrimPrefix(headRef, "refs/heads/")); err != nil {
+		return "", err
+	}
+	if _, err := gitutil.Git("-C", repoRoot, "config", "extensions.worktreeConfig", "true"); err != nil {
+		return "", err
+	}
+	if _, err := gitutil.Git("-C", path, "config", "--worktree", "jul.workspace", user+"/"+name); err != nil {
+		return "", err
+	}
+	if err := linkSharedJulState(mainRoot, path); err != nil {
+		return "", err
+	}
+	if err := inDir(path, func() error {
+		return switchToWorkspaceLocal(user, name)
+	}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// linkSharedJulState points the entries of sharedJulState in the worktree's
+// .jul at the main worktree's.
+func linkSharedJulState(mainRoot, worktree string) error {
+	mainJul := filepath.Join(mainRoot, ".jul")
+	julDir := filepath.Join(worktree, ".jul")
+	if err := os.MkdirAll(julDir, 0o755); err != nil {
+		return err
+	}
+	for _, entry := range sharedJulState {
+		target := filepath.Join(mainJul, entry.name)
+		if entry.dir {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		}
+		link := filepath.Join(julDir, entry.name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(target, link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workspaceWorktree returns the worktree other than repoRoot whose HEAD is on
+// the workspace's head branch.
+func workspaceWorktree(repoRoot, name string) (string, bool) {
+	worktrees, err := gitutil.ListWorktrees(repoRoot)
+	if err != nil {
+		return "", false
+	}
+	headRef := workspaceHeadRef(name)
+	self := filepath.Clean(repoRoot)
+	for _, wt := range worktrees {
+		if wt.Branch == headRef && !samePath(wt.Path, self) {
+			return wt.Path, true
+		}
+	}
+	return "", false
+}
+
+// ensureNotOpenElsewhere fails with a workspaceOpenError when the workspace
+// is checked out in another worktree.
+func ensureNotOpenElsewhere(user, name string) error {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return nil
+	}
+	if open, ok := workspaceWorktree(repoRoot, name); ok {
+		return workspaceOpenError{Workspace: user + "/" + name, Worktree: open}
+	}
+	return nil
+}
+
+// julWorktrees returns the main worktree and every linked worktree checked
+// out on a workspace head branch.
+func julWorktrees(repoRoot string) ([]string, error) {
+	worktrees, err := gitutil.ListWorktrees(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	var roots []string
+	for i, wt := range worktrees {
+		if i == 0 || strings.HasPrefix(wt.Branch, "refs/heads/jul/") {
+			roots = append(roots, wt.Path)
+		}
+	}
+	return roots, nil
+}
+
+// setCurrentWorkspace records the current workspace. A linked worktree keeps
+// its own in per-worktree git config.
+func setCurrentWorkspace(wsID string) error {
+	if root, err := gitutil.RepoTopLevel(); err == nil && gitutil.IsLinkedWorktree(root) {
+		if _, err := gitutil.Git("-C", root, "config", "--worktree", "jul.workspace", wsID); err != nil {
+			return fmt.Errorf("git config --worktree jul.workspace: %w", err)
+		}
+		return nil
+	}
+	return runGitConfig("jul.workspace", wsID)
+}
+
+// inDir runs fn with dir as the working directory; jul's git helpers work on
+// the repository of the current directory.
+func inDir(dir string, fn func() error) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	defer func() { _ = os.Chdir(cwd) }()
+	return fn()
+}
+
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
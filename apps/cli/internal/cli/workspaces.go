@@ -26,6 +26,7 @@ type workspaceActionOutput struct {
 	WorkspaceRef string `json:"workspace_ref,omitempty"`
 	SyncRef      string `json:"sync_ref,omitempty"`
 	WorkspaceSHA string `json:"workspace_sha,omitempty"`
+	Worktree     string `json:"worktree,omitempty"`
 }
 
 type workspaceListOutput struct {
@@ -56,6 +57,8 @@ func newWorkspaceCommand() Command {
 			switch sub {
 			case "list":
 				return runWorkspaceList(subArgs)
+			case "tree":
+				return runWorkspaceTree(subArgs)
 			case "checkout":
 				return runWorkspaceCheckout(subArgs)
 			case "set":
@@ -64,6 +67,8 @@ func newWorkspaceCommand() Command {
 				return runWorkspaceNew(subArgs)
 			case "switch":
 				return runWorkspaceSwitch(subArgs)
+			case "open":
+				return runWorkspaceOpen(subArgs)
 			case "stack":
 				return runWorkspaceStack(subArgs)
 			case "restack":
@@ -72,6 +77,10 @@ func newWorkspaceCommand() Command {
 				return runWorkspaceRename(subArgs)
 			case "delete":
 				return runWorkspaceDelete(subArgs)
+			case "close":
+				return runWorkspaceClose(subArgs)
+			case "reopen":
+				return runWorkspaceReopen(subArgs)
 			default:
 				if jsonOut {
 					_ = output.EncodeError(os.Stdout, "workspace_unknown_subcommand", fmt.Sprintf("unknown subcommand %q", sub), nil)
@@ -103,8 +112,13 @@ func runWorkspaceCurrent(args []string) int {
 
 func runWorkspaceList(args []string) int {
 	fs, jsonOut := newFlagSet("ws list")
+	archived := fs.Bool("archived", false, "List closed workspaces")
 	_ = fs.Parse(args)
-	workspaces, err := localWorkspaces()
+	list := localWorkspaces
+	if *archived {
+		list = archivedWorkspaces
+	}
+	workspaces, err := list()
 	if err != nil {
 		if *jsonOut {
 			_ = output.EncodeError(os.Stdout, "workspace_list_failed", fmt.Sprintf("failed to list workspaces: %v", err), nil)
@@ -146,7 +160,7 @@ func runWorkspaceSet(args []string) int {
 		return 1
 	}
 
-	if err := runGitConfig("jul.workspace", wsID); err != nil {
+	if err := setCurrentWorkspace(wsID); err != nil {
 		if *jsonOut {
 			_ = output.EncodeError(os.Stdout, "workspace_set_failed", fmt.Sprintf("failed to set workspace: %v", err), nil)
 		} else {
@@ -170,6 +184,7 @@ func runWorkspaceSet(args []string) int {
 func runWorkspaceNew(args []string) int {
 	fs, jsonOut := newFlagSet("ws new")
 	user := fs.String("user", "", "Override user for workspace id")
+	worktree := fs.String("worktree", "", "Create the workspace in its own git worktree at this path")
 	_ = fs.Parse(args)
 
 	name := strings.TrimSpace(fs.Arg(0))
@@ -190,6 +205,9 @@ func runWorkspaceNew(args []string) int {
 		}
 		return 1
 	}
+	if strings.TrimSpace(*worktree) != "" {
+		return runWorkspaceNewWorktree(wsID, wsUser, wsName, *worktree, *jsonOut)
+	}
 	_, currentWorkspace := workspaceParts()
 	if err := saveWorkspaceState(currentWorkspace); err != nil {
 		if *jsonOut {
@@ -242,7 +260,7 @@ func runWorkspaceNew(args []string) int {
 		}
 		return 1
 	}
-	if err := runGitConfig("jul.workspace", wsID); err != nil {
+	if err := setCurrentWorkspace(wsID); err != nil {
 		if *jsonOut {
 			_ = output.EncodeError(os.Stdout, "workspace_set_failed", fmt.Sprintf("failed to set workspace: %v", err), nil)
 		} else {
@@ -276,6 +294,11 @@ func runWorkspaceSwitch(args []string) int {
 		}
 		return 1
 	}
+	if _, targetUser, targetName, err := resolveWorkspaceID(name, ""); err == nil {
+		if err := ensureNotOpenElsewhere(targetUser, targetName); err != nil {
+			return writeWorkspaceOpenError(err, *jsonOut)
+		}
+	}
 	currentUser, currentWorkspace := workspaceParts()
 	if err := saveWorkspaceState(currentWorkspace); err != nil {
 		if *jsonOut {
@@ -311,7 +334,7 @@ func runWorkspaceSwitch(args []string) int {
 		}
 		return 1
 	}
-	if err := runGitConfig("jul.workspace", wsID); err != nil {
+	if err := setCurrentWorkspace(wsID); err != nil {
 		rollbackErr := switchToWorkspaceLocal(currentUser, currentWorkspace)
 		if rollbackErr != nil {
 			if *jsonOut {
@@ -430,7 +453,7 @@ func runWorkspaceStack(args []string) int {
 		}
 		return 1
 	}
-	if err := runGitConfig("jul.workspace", wsID); err != nil {
+	if err := setCurrentWorkspace(wsID); err != nil {
 		rollbackErr := switchToWorkspaceLocal(currentUser, currentName)
 		if rollbackErr != nil {
 			if *jsonOut {
@@ -503,6 +526,9 @@ func runWorkspaceCheckout(args []string) int {
 		return 1
 	}
 	wsID := user + "/" + targetName
+	if err := ensureNotOpenElsewhere(user, targetName); err != nil {
+		return writeWorkspaceOpenError(err, *jsonOut)
+	}
 
 	repoRoot, err := gitutil.RepoTopLevel()
 	if err != nil {
@@ -613,7 +639,7 @@ func runWorkspaceCheckout(args []string) int {
 	}
 	refreshWorkspaceTrackTip(repoRoot, targetName)
 
-	if err := runGitConfig("jul.workspace", wsID); err != nil {
+	if err := setCurrentWorkspace(wsID); err != nil {
 		if *jsonOut {
 			_ = output.EncodeError(os.Stdout, "workspace_set_failed", fmt.Sprintf("failed to set workspace: %v", err), nil)
 		} else {
@@ -657,7 +683,7 @@ func runWorkspaceRename(args []string) int {
 	if !strings.Contains(newName, "/") {
 		newID = user + "/" + newName
 	}
-	if err := runGitConfig("jul.workspace", newID); err != nil {
+	if err := setCurrentWorkspace(newID); err != nil {
 		if *jsonOut {
 			_ = output.EncodeError(os.Stdout, "workspace_rename_failed", fmt.Sprintf("failed to rename workspace: %v", err), nil)
 		} else {
@@ -705,6 +731,11 @@ func runWorkspaceDelete(args []string) int {
 		}
 		return 1
 	}
+	if parts := strings.SplitN(target, "/", 2); len(parts) == 2 {
+		if err := ensureNotOpenElsewhere(parts[0], parts[1]); err != nil {
+			return writeWorkspaceOpenError(err, *jsonOut)
+		}
+	}
 	if err := deleteWorkspaceLocal(target); err != nil {
 		if *jsonOut {
 			_ = output.EncodeError(os.Stdout, "workspace_delete_failed", fmt.Sprintf("failed to delete workspace: %v", err), nil)
@@ -827,6 +858,9 @@ func switchToWorkspaceLocal(user, workspace string) error {
 	if err != nil {
 		return err
 	}
+	if open, ok := workspaceWorktree(repoRoot, workspace); ok {
+		return workspaceOpenError{Workspace: user + "/" + workspace, Worktree: open}
+	}
 	ref := workspaceRef(user, workspace)
 	if !gitutil.RefExists(ref) {
 		if cfg, ok, err := wsconfig.ReadConfig(repoRoot, workspace); err == nil && ok {
@@ -911,6 +945,23 @@ func switchToWorkspaceLocal(user, workspace string) error {
 }
 
 func createWorkspaceDraft(user, workspace, baseRef, baseSHA, treeSHA string) (string, error) {
+	draftSHA, err := createWorkspaceRefs(user, workspace, baseRef, baseSHA, treeSHA)
+	if err != nil {
+		return "", err
+	}
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return "", err
+	}
+	if err := gitutil.EnsureHeadRef(repoRoot, workspaceHeadRef(workspace), baseSHA); err != nil {
+		return "", err
+	}
+	return draftSHA, nil
+}
+
+// createWorkspaceRefs creates the workspace, its draft, lease and config
+// without moving the current worktree's HEAD.
+func createWorkspaceRefs(user, workspace, baseRef, baseSHA, treeSHA string) (string, error) {
 	if strings.TrimSpace(baseSHA) == "" {
 		return "", fmt.Errorf("base commit required")
 	}
@@ -953,9 +1004,6 @@ func createWorkspaceDraft(user, workspace, baseRef, baseSHA, treeSHA string) (st
 	if err := ensureWorkspaceConfig(repoRoot, workspace, baseRef, baseSHA); err != nil {
 		return "", err
 	}
-	if err := gitutil.EnsureHeadRef(repoRoot, workspaceHeadRef(workspace), baseSHA); err != nil {
-		return "", err
-	}
 	return draftSHA, nil
 }
 
@@ -1060,5 +1108,5 @@ func runGitConfig(key, value string) error {
 }
 
 func printWorkspaceUsage() {
-	fmt.Fprintln(os.Stdout, "Usage: jul ws [list|checkout|set|new|stack|restack|switch|rename|delete|current]")
+	fmt.Fprintln(os.Stdout, "Usage: jul ws [list|tree|checkout|set|new|stack|restack|switch|open|rename|close|reopen|delete|current]")
 }
@@ -557,3 +557,265 @@ func readFileContents(t *testing.T, dir, name string) string {
 	}
 	return string(data)
 }
+
+func TestWorkspaceTreeShowsStackAndBaseState(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	writeFilePath(t, repo, "base.txt", "base\n")
+	runGitCmd(t, repo, "add", "base.txt")
+	runGitCmd(t, repo, "commit", "-m", "base")
+	runGitCmd(t, repo, "branch", "-M", "main")
+
+	t.Setenv("HOME", filepath.Join(t.TempDir(), "home"))
+	t.Setenv("JUL_WORKSPACE", "")
+	runGitCmd(t, repo, "config", "jul.workspace", "tester/@")
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	if code := runInit([]string{"demo"}); code != 0 {
+		t.Fatalf("init failed with %d", code)
+	}
+	writeFilePath(t, repo, "parent.txt", "parent\n")
+	if _, err := syncer.Checkpoint("feat: parent"); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	if code := runWorkspaceStack([]string{"child"}); code != 0 {
+		t.Fatalf("ws stack failed with %d", code)
+	}
+	writeFilePath(t, repo, "child.txt", "child\n")
+	if _, err := syncer.Checkpoint("feat: child"); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+
+	tree, err := buildWorkspaceTree()
+	if err != nil {
+		t.Fatalf("buildWorkspaceTree failed: %v", err)
+	}
+	if len(tree.Roots) != 1 || tree.Roots[0].Name != "@" || len(tree.Roots[0].Children) != 1 {
+		t.Fatalf("expected @ with one child, got %+v", tree.Roots)
+	}
+	child := tree.Roots[0].Children[0]
+	if child.Name != "child" || child.Parent != "@" || !child.Current {
+		t.Fatalf("expected current child stacked on @, got %+v", child)
+	}
+	if child.Checkpoint == nil || child.Checkpoint.Message != "feat: child" || child.BaseState != baseStateUpToDate {
+		t.Fatalf("expected child checkpoint on an up-to-date base, got %+v", child)
+	}
+
+	if code := runWorkspaceSwitch([]string{"@"}); code != 0 {
+		t.Fatalf("ws switch failed with %d", code)
+	}
+	writeFilePath(t, repo, "parent.txt", "parent two\n")
+	if _, err := syncer.Checkpoint("feat: parent two"); err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+
+	tree, err = buildWorkspaceTree()
+	if err != nil {
+		t.Fatalf("buildWorkspaceTree failed: %v", err)
+	}
+	child = tree.Roots[0].Children[0]
+	if child.BaseState != baseStateAdvanced {
+		t.Fatalf("expected child base advanced after parent checkpoint, got %+v", child)
+	}
+	if !tree.Roots[0].Current || tree.Roots[0].Checkpoint == nil || tree.Roots[0].Checkpoint.Count != 2 {
+		t.Fatalf("expected current @ with two checkpoints, got %+v", tree.Roots[0])
+	}
+}
+
+func TestWorkspaceCloseArchivesAndReopenRestores(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	writeFilePath(t, repo, "base.txt", "base\n")
+	runGitCmd(t, repo, "add", "base.txt")
+	runGitCmd(t, repo, "commit", "-m", "base")
+	runGitCmd(t, repo, "branch", "-M", "main")
+
+	t.Setenv("HOME", filepath.Join(t.TempDir(), "home"))
+	t.Setenv("JUL_WORKSPACE", "")
+	runGitCmd(t, repo, "config", "jul.workspace", "tester/@")
+
+	remoteDir := filepath.Join(t.TempDir(), "remote")
+	if err := os.MkdirAll(remoteDir, 0o755); err != nil {
+		t.Fatalf("failed to create remote dir: %v", err)
+	}
+	runGitCmd(t, remoteDir, "init", "--bare")
+	runGitCmd(t, repo, "remote", "add", "origin", remoteDir)
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	if code := runInit([]string{"demo"}); code != 0 {
+		t.Fatalf("init failed with %d", code)
+	}
+	if code := runWorkspaceNew([]string{"feature"}); code != 0 {
+		t.Fatalf("ws new failed with %d", code)
+	}
+	writeFilePath(t, repo, "feature.txt", "feature\n")
+	checkpoint, err := syncer.Checkpoint("feat: feature")
+	if err != nil {
+		t.Fatalf("checkpoint failed: %v", err)
+	}
+	if code := runWorkspaceClose([]string{"feature"}); code == 0 {
+		t.Fatalf("expected closing the current workspace to fail")
+	}
+	if code := runWorkspaceSwitch([]string{"@"}); code != 0 {
+		t.Fatalf("ws switch failed with %d", code)
+	}
+
+	wsRef := workspaceRef("tester", "feature")
+	wsSHA, err := gitutil.ResolveRef(wsRef)
+	if err != nil {
+		t.Fatalf("resolve workspace ref failed: %v", err)
+	}
+	cfg, ok, err := wsconfig.ReadConfig(repo, "feature")
+	if err != nil || !ok {
+		t.Fatalf("expected feature config, got ok=%v err=%v", ok, err)
+	}
+	keepPrefix := keepRefPrefix("tester", "feature") + checkpoint.ChangeID + "/"
+	rawConfig, err := os.ReadFile(wsconfig.ConfigPath(repo, "feature"))
+	if err != nil {
+		t.Fatalf("read feature config failed: %v", err)
+	}
+
+	if code := runWorkspaceClose([]string{"feature"}); code != 0 {
+		t.Fatalf("ws close failed with %d", code)
+	}
+	archive := wsconfig.ArchivePrefix("tester", "feature")
+	if gitutil.RefExists(wsRef) {
+		t.Fatalf("expected workspace ref to be moved")
+	}
+	if got, _ := gitutil.ResolveRef(archive + "workspace"); got != wsSHA {
+		t.Fatalf("expected archived workspace ref at %s, got %s", wsSHA, got)
+	}
+	if out := strings.TrimSpace(runGitCmd(t, repo, "for-each-ref", keepPrefix)); out != "" {
+		t.Fatalf("expected keep refs to be moved, got %s", out)
+	}
+	if out := strings.TrimSpace(runGitCmd(t, repo, "for-each-ref", archive+"keep/")); out == "" {
+		t.Fatalf("expected archived keep refs")
+	}
+	if _, ok, _ := wsconfig.ReadConfig(repo, "feature"); ok {
+		t.Fatalf("expected feature config to be removed")
+	}
+	if out := runGitCmd(t, repo, "ls-remote", "origin", archive+"workspace"); !strings.HasPrefix(out, wsSHA) {
+		t.Fatalf("expected archive on remote, got %q", out)
+	}
+	if out := strings.TrimSpace(runGitCmd(t, repo, "ls-remote", "origin", wsRef)); out != "" {
+		t.Fatalf("expected remote workspace ref to be moved, got %q", out)
+	}
+
+	archived, err := archivedWorkspaces()
+	if err != nil {
+		t.Fatalf("archivedWorkspaces failed: %v", err)
+	}
+	if len(archived) != 1 || archived[0].WorkspaceID != "tester/feature" || archived[0].LastCommitSHA != wsSHA {
+		t.Fatalf("expected archived tester/feature, got %+v", archived)
+	}
+	live, err := localWorkspaces()
+	if err != nil {
+		t.Fatalf("localWorkspaces failed: %v", err)
+	}
+	for _, ws := range live {
+		if ws.WorkspaceID == "tester/feature" {
+			t.Fatalf("expected closed workspace to be hidden from ws list")
+		}
+	}
+
+	if code := runWorkspaceReopen([]string{"feature"}); code != 0 {
+		t.Fatalf("ws reopen failed with %d", code)
+	}
+	if got, _ := gitutil.ResolveRef(wsRef); got != wsSHA {
+		t.Fatalf("expected reopened workspace ref at %s, got %s", wsSHA, got)
+	}
+	if out := strings.TrimSpace(runGitCmd(t, repo, "for-each-ref", archive)); out != "" {
+		t.Fatalf("expected archive to be emptied, got %s", out)
+	}
+	if out := strings.TrimSpace(runGitCmd(t, repo, "for-each-ref", keepPrefix)); out == "" {
+		t.Fatalf("expected keep refs to be restored")
+	}
+	restored, ok, err := wsconfig.ReadConfig(repo, "feature")
+	if err != nil || !ok || restored != cfg {
+		t.Fatalf("expected config %+v restored, got %+v ok=%v err=%v", cfg, restored, ok, err)
+	}
+	if data, _ := os.ReadFile(wsconfig.ConfigPath(repo, "feature")); string(data) != string(rawConfig) {
+		t.Fatalf("expected config restored byte for byte, got %q want %q", data, rawConfig)
+	}
+	if code := runWorkspaceSwitch([]string{"feature"}); code != 0 {
+		t.Fatalf("switch to reopened workspace failed with %d", code)
+	}
+	if data, err := os.ReadFile(filepath.Join(repo, "feature.txt")); err != nil || string(data) != "feature\n" {
+		t.Fatalf("expected feature.txt after reopening, got %q err=%v", string(data), err)
+	}
+}
+
+func TestWorkspaceNewWorktreeIsolatesWorkspaces(t *testing.T) {
+	repo := t.TempDir()
+	runGitCmd(t, repo, "init")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	writeFilePath(t, repo, "base.txt", "base\n")
+	runGitCmd(t, repo, "add", "base.txt")
+	runGitCmd(t, repo, "commit", "-m", "base")
+	runGitCmd(t, repo, "branch", "-M", "main")
+
+	t.Setenv("HOME", filepath.Join(t.TempDir(), "home"))
+	t.Setenv("JUL_WORKSPACE", "")
+	runGitCmd(t, repo, "config", "jul.workspace", "tester/@")
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(repo)
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	if code := runInit([]string{"demo"}); code != 0 {
+		t.Fatalf("init failed with %d", code)
+	}
+	writeFilePath(t, repo, "wip.txt", "main wip\n")
+
+	worktree := filepath.Join(t.TempDir(), "feature")
+	if code := runWorkspaceNew([]string{"--worktree", worktree, "feature"}); code != 0 {
+		t.Fatalf("ws new --worktree failed with %d", code)
+	}
+	if got := strings.TrimSpace(runGitCmd(t, repo, "config", "jul.workspace")); got != "tester/@" {
+		t.Fatalf("expected main worktree to stay on tester/@, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "wip.txt")); err != nil {
+		t.Fatalf("expected main worktree changes to be left alone: %v", err)
+	}
+	if got := strings.TrimSpace(runGitCmd(t, worktree, "config", "jul.workspace")); got != "tester/feature" {
+		t.Fatalf("expected worktree workspace tester/feature, got %q", got)
+	}
+	if got := strings.TrimSpace(runGitCmd(t, worktree, "symbolic-ref", "HEAD")); got != workspaceHeadRef("feature") {
+		t.Fatalf("expected worktree HEAD on %s, got %q", workspaceHeadRef("feature"), got)
+	}
+
+	if code := runWorkspaceSwitch([]string{"feature"}); code == 0 {
+		t.Fatalf("expected switching to a workspace open in another worktree to fail")
+	}
+
+	_ = os.Chdir(worktree)
+	// Shared refs live in the common git dir and must resolve from here.
+	if !gitutil.RefExists(workspaceRef("tester", "feature")) {
+		t.Fatalf("expected workspace ref to resolve in linked worktree")
+	}
+	writeFilePath(t, worktree, "feature.txt", "feature\n")
+	if _, err := syncer.Checkpoint("feat: feature"); err != nil {
+		t.Fatalf("checkpoint in worktree failed: %v", err)
+	}
+	featureTip, err := gitutil.ResolveRef(workspaceRef("tester", "feature"))
+	if err != nil {
+		t.Fatalf("resolve feature workspace failed: %v", err)
+	}
+	if _, err := gitutil.Git("cat-file", "-e", strings.TrimSpace(featureTip)+":feature.txt"); err != nil {
+		t.Fatalf("expected feature checkpoint to contain feature.txt: %v", err)
+	}
+	if _, err := gitutil.Git("cat-file", "-e", strings.TrimSpace(featureTip)+":wip.txt"); err == nil {
+		t.Fatalf("expected feature checkpoint to exclude main worktree changes")
+	}
+}
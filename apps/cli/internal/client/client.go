@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// NextCursorHeader carries the server's opaque cursor for the next page.
+const NextCursorHeader = "X-Next-Cursor"
+
 type Client struct {
 	baseURL string
 	http    *http.Client
@@ -108,14 +113,63 @@ type QueryFilters struct {
 	CoverageMax *float64
 	ChangeID    string
 	Author      string
+	Repo        string
+	Workspace   string
 	Since       *time.Time
 	Until       *time.Time
+	Cursor      string
 	Limit       int
 }
 
+// ListOptions filters and pages the list endpoints. Limit is the page size;
+// the Iter* helpers follow cursors until the server runs out of rows.
+type ListOptions struct {
+	Owner     string
+	Repo      string
+	Workspace string
+	Status    string
+	ChangeID  string
+	CommitSHA string
+	Since     *time.Time
+	Until     *time.Time
+	Cursor    string
+	Limit     int
+}
+
+func (o ListOptions) values() url.Values {
+	values := url.Values{}
+	setIf := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	setIf("owner", o.Owner)
+	setIf("repo", o.Repo)
+	setIf("workspace", o.Workspace)
+	setIf("status", o.Status)
+	setIf("change_id", o.ChangeID)
+	setIf("commit_sha", o.CommitSHA)
+	if o.Since != nil {
+		values.Set("since", o.Since.UTC().Format(time.RFC3339))
+	}
+	if o.Until != nil {
+		values.Set("until", o.Until.UTC().Format(time.RFC3339))
+	}
+	setIf("cursor", o.Cursor)
+	if o.Limit > 0 {
+		values.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return values
+}
+
 type RepoInfo struct {
-	Name     string `json:"name"`
-	CloneURL string `json:"clone_url"`
+	Name          string    `json:"name"`
+	Owner         string    `json:"owner"`
+	Description   string    `json:"description"`
+	DefaultBranch string    `json:"default_branch"`
+	CloneURL      string    `json:"clone_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type Suggestion struct {
@@ -127,11 +181,27 @@ type Suggestion struct {
 	Reason             string    `json:"reason"`
 	Description        string    `json:"description"`
 	Confidence         float64   `json:"confidence"`
+	Profiles           []string  `json:"profiles,omitempty"`
 	Status             string    `json:"status"`
 	ResolutionMessage  string    `json:"resolution_message,omitempty"`
 	DiffstatJSON       string    `json:"diffstat_json"`
 	CreatedAt          time.Time `json:"created_at"`
 	ResolvedAt         time.Time `json:"resolved_at,omitempty"`
+	// AppliedPaths and AppliedHunks record the subset taken by partial
+	// applies; hunks are numbered as in "jul show <id> --hunks".
+	AppliedPaths []string `json:"applied_paths,omitempty"`
+	AppliedHunks []int    `json:"applied_hunks,omitempty"`
+}
+
+// Finding is a line-anchored review finding on a checkpoint.
+type Finding struct {
+	Path      string   `json:"path"`
+	StartLine int      `json:"start_line,omitempty"`
+	EndLine   int      `json:"end_line,omitempty"`
+	Severity  string   `json:"severity"`
+	Category  string   `json:"category,omitempty"`
+	Message   string   `json:"message"`
+	Profiles  []string `json:"profiles,omitempty"`
 }
 
 type SyncPayload struct {
@@ -240,48 +310,135 @@ func (c *Client) Reflog(workspaceID string, limit int) ([]ReflogEntry, error) {
 }
 
 func (c *Client) Query(filters QueryFilters) ([]QueryResult, error) {
-	var results []QueryResult
-	path := "/api/v1/query"
-	query := make([]string, 0, 4)
+	results, _, err := c.QueryPage(filters)
+	return results, err
+}
+
+// QueryPage returns one page of query results and the cursor for the next.
+func (c *Client) QueryPage(filters QueryFilters) ([]QueryResult, string, error) {
+	values := ListOptions{
+		Repo:      filters.Repo,
+		Workspace: filters.Workspace,
+		ChangeID:  filters.ChangeID,
+		Since:     filters.Since,
+		Until:     filters.Until,
+		Cursor:    filters.Cursor,
+		Limit:     filters.Limit,
+	}.values()
 	if filters.Tests != "" {
-		query = append(query, "tests="+urlQueryEscape(filters.Tests))
+		values.Set("tests", filters.Tests)
 	}
 	if filters.Compiles != nil {
-		value := "false"
-		if *filters.Compiles {
-			value = "true"
-		}
-		query = append(query, "compiles="+value)
+		values.Set("compiles", strconv.FormatBool(*filters.Compiles))
 	}
 	if filters.CoverageMin != nil {
-		query = append(query, fmt.Sprintf("coverage_min=%g", *filters.CoverageMin))
+		values.Set("coverage_min", fmt.Sprintf("%g", *filters.CoverageMin))
 	}
 	if filters.CoverageMax != nil {
-		query = append(query, fmt.Sprintf("coverage_max=%g", *filters.CoverageMax))
-	}
-	if filters.ChangeID != "" {
-		query = append(query, "change_id="+urlQueryEscape(filters.ChangeID))
+		values.Set("coverage_max", fmt.Sprintf("%g", *filters.CoverageMax))
 	}
 	if filters.Author != "" {
-		query = append(query, "author="+urlQueryEscape(filters.Author))
-	}
-	if filters.Since != nil {
-		query = append(query, "since="+urlQueryEscape(filters.Since.Format(time.RFC3339)))
-	}
-	if filters.Until != nil {
-		query = append(query, "until="+urlQueryEscape(filters.Until.Format(time.RFC3339)))
-	}
-	if filters.Limit > 0 {
-		query = append(query, fmt.Sprintf("limit=%d", filters.Limit))
+		values.Set("author", filters.Author)
 	}
-	if len(query) > 0 {
-		path = path + "?" + strings.Join(query, "&")
+	var results []QueryResult
+	next, err := c.getPage("/api/v1/query", values, &results)
+	return results, next, err
+}
+
+// IterQuery walks every query result across pages. filters.Limit is the page
+// size, not a total.
+func (c *Client) IterQuery(filters QueryFilters) iter.Seq2[QueryResult, error] {
+	return paginate(filters.Cursor, func(cursor string) ([]QueryResult, string, error) {
+		filters.Cursor = cursor
+		return c.QueryPage(filters)
+	})
+}
+
+func (c *Client) ListChangesPage(opts ListOptions) ([]Change, string, error) {
+	var out []Change
+	next, err := c.getPage("/api/v1/changes", opts.values(), &out)
+	return out, next, err
+}
+
+func (c *Client) IterChanges(opts ListOptions) iter.Seq2[Change, error] {
+	return paginate(opts.Cursor, func(cursor string) ([]Change, string, error) {
+		opts.Cursor = cursor
+		return c.ListChangesPage(opts)
+	})
+}
+
+func (c *Client) ListWorkspacesPage(opts ListOptions) ([]Workspace, string, error) {
+	var out []Workspace
+	next, err := c.getPage("/api/v1/workspaces", opts.values(), &out)
+	return out, next, err
+}
+
+func (c *Client) IterWorkspaces(opts ListOptions) iter.Seq2[Workspace, error] {
+	return paginate(opts.Cursor, func(cursor string) ([]Workspace, string, error) {
+		opts.Cursor = cursor
+		return c.ListWorkspacesPage(opts)
+	})
+}
+
+func (c *Client) ListAttestationsPage(opts ListOptions) ([]Attestation, string, error) {
+	var out []Attestation
+	next, err := c.getPage("/api/v1/attestations", opts.values(), &out)
+	return out, next, err
+}
+
+func (c *Client) IterAttestations(opts ListOptions) iter.Seq2[Attestation, error] {
+	return paginate(opts.Cursor, func(cursor string) ([]Attestation, string, error) {
+		opts.Cursor = cursor
+		return c.ListAttestationsPage(opts)
+	})
+}
+
+func (c *Client) ListSuggestionsPage(opts ListOptions) ([]Suggestion, string, error) {
+	var out []Suggestion
+	next, err := c.getPage("/api/v1/suggestions", opts.values(), &out)
+	return out, next, err
+}
+
+func (c *Client) IterSuggestions(opts ListOptions) iter.Seq2[Suggestion, error] {
+	return paginate(opts.Cursor, func(cursor string) ([]Suggestion, string, error) {
+		opts.Cursor = cursor
+		return c.ListSuggestionsPage(opts)
+	})
+}
+
+// paginate turns a page fetcher into an iterator starting at cursor. A fetch
+// error is yielded once and ends the sequence.
+func paginate[T any](cursor string, fetch func(cursor string) ([]T, string, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			items, next, err := fetch(cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" || next == cursor {
+				return
+			}
+			cursor = next
+		}
 	}
+}
 
-	if err := c.doJSON(http.MethodGet, path, nil, &results); err != nil {
-		return nil, err
+func (c *Client) getPage(path string, values url.Values, out any) (string, error) {
+	if encoded := values.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	header, err := c.do(http.MethodGet, path, nil, out)
+	if err != nil {
+		return "", err
 	}
-	return results, nil
+	return header.Get(NextCursorHeader), nil
 }
 
 func (c *Client) CreateRepo(name string) (RepoInfo, error) {
@@ -293,26 +450,30 @@ func (c *Client) CreateRepo(name string) (RepoInfo, error) {
 	return repo, nil
 }
 
-func (c *Client) ListSuggestions(changeID, status string, limit int) ([]Suggestion, error) {
-	var out []Suggestion
-	path := "/api/v1/suggestions"
-	query := make([]string, 0, 3)
-	if changeID != "" {
-		query = append(query, "change_id="+urlQueryEscape(changeID))
-	}
-	if status != "" {
-		query = append(query, "status="+urlQueryEscape(status))
-	}
-	if limit > 0 {
-		query = append(query, fmt.Sprintf("limit=%d", limit))
-	}
-	if len(query) > 0 {
-		path = path + "?" + strings.Join(query, "&")
-	}
-	if err := c.doJSON(http.MethodGet, path, nil, &out); err != nil {
-		return nil, err
+func (c *Client) GetRepo(name string) (RepoInfo, error) {
+	var repo RepoInfo
+	if err := c.doJSON(http.MethodGet, "/api/v1/repos/"+strings.Trim(name, "/"), nil, &repo); err != nil {
+		return RepoInfo{}, err
 	}
-	return out, nil
+	return repo, nil
+}
+
+func (c *Client) ListReposPage(opts ListOptions) ([]RepoInfo, string, error) {
+	var out []RepoInfo
+	next, err := c.getPage("/api/v1/repos", opts.values(), &out)
+	return out, next, err
+}
+
+func (c *Client) IterRepos(opts ListOptions) iter.Seq2[RepoInfo, error] {
+	return paginate(opts.Cursor, func(cursor string) ([]RepoInfo, string, error) {
+		opts.Cursor = cursor
+		return c.ListReposPage(opts)
+	})
+}
+
+func (c *Client) ListSuggestions(changeID, status string, limit int) ([]Suggestion, error) {
+	out, _, err := c.ListSuggestionsPage(ListOptions{ChangeID: changeID, Status: status, Limit: limit})
+	return out, err
 }
 
 func (c *Client) GetSuggestion(id string) (Suggestion, error) {
@@ -353,19 +514,24 @@ type SuggestionCreateRequest struct {
 }
 
 func (c *Client) doJSON(method, path string, body any, out any) error {
+	_, err := c.do(method, path, body, out)
+	return err
+}
+
+func (c *Client) do(method, path string, body any, out any) (http.Header, error) {
 	var reader io.Reader
 	if body != nil {
 		buf := &bytes.Buffer{}
 		enc := json.NewEncoder(buf)
 		if err := enc.Encode(body); err != nil {
-			return err
+			return nil, err
 		}
 		reader = buf
 	}
 
 	req, err := http.NewRequest(method, c.baseURL+path, reader)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -373,19 +539,19 @@ func (c *Client) doJSON(method, path string, body any, out any) error {
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		payload, _ := io.ReadAll(resp.Body)
-		return &HTTPError{Status: resp.StatusCode, Body: strings.TrimSpace(string(payload))}
+		return nil, &HTTPError{Status: resp.StatusCode, Body: strings.TrimSpace(string(payload))}
 	}
 
 	if out == nil {
-		return nil
+		return resp.Header, nil
 	}
-	return json.NewDecoder(resp.Body).Decode(out)
+	return resp.Header, json.NewDecoder(resp.Body).Decode(out)
 }
 
 func isNotFound(err error) bool {
@@ -397,7 +563,3 @@ func isNotFound(err error) bool {
 	}
 	return strings.Contains(err.Error(), "not found")
 }
-
-func urlQueryEscape(value string) string {
-	return url.QueryEscape(value)
-}
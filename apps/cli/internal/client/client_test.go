@@ -0,0 +1,68 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIterQueryFollowsCursors(t *testing.T) {
+	pages := map[string]struct {
+		results []QueryResult
+		next    string
+	}{
+		"":   {results: []QueryResult{{CommitSHA: "c3"}, {CommitSHA: "c2"}}, next: "p2"},
+		"p2": {results: []QueryResult{{CommitSHA: "c1"}}},
+	}
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.URL.Query().Get("repo"); got != "demo" {
+			t.Errorf("expected repo filter demo, got %q", got)
+		}
+		cursor := r.URL.Query().Get("cursor")
+		requests = append(requests, cursor)
+		page := pages[cursor]
+		if page.next != "" {
+			w.Header().Set(NextCursorHeader, page.next)
+		}
+		_ = json.NewEncoder(w).Encode(page.results)
+	}))
+	defer srv.Close()
+
+	var got []string
+	for res, err := range New(srv.URL).IterQuery(QueryFilters{Repo: "demo", Limit: 2}) {
+		if err != nil {
+			t.Fatalf("iterate query: %v", err)
+		}
+		got = append(got, res.CommitSHA)
+	}
+	if len(got) != 3 || got[0] != "c3" || got[2] != "c1" {
+		t.Fatalf("unexpected results: %v", got)
+	}
+	if len(requests) != 2 || requests[1] != "p2" {
+		t.Fatalf("unexpected cursor sequence: %v", requests)
+	}
+}
+
+func TestIterChangesStopsOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid cursor"}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	count := 0
+	for _, err := range New(srv.URL).IterChanges(ListOptions{Cursor: "bad"}) {
+		count++
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected a single error yield, got %d", count)
+	}
+}
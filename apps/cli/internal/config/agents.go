@@ -17,19 +17,33 @@ type AgentProvider struct {
 	Timeout       time.Duration
 	Bundled       bool
 	MaxIterations int
-	EnableNetwork bool
+	// EnableNetwork overrides sandbox.enable_network for this provider when set.
+	EnableNetwork *bool
 	Actions       map[string]string
 }
 
+// AgentSandbox is the [sandbox] section of agents.toml.
+type AgentSandbox struct {
+	Enabled       bool
+	EnableNetwork bool
+	CPUSeconds    int
+	MemoryMB      int
+	// Writable lists extra paths the agent may write to, such as its own
+	// state directory under $HOME.
+	Writable []string
+}
+
 type AgentConfig struct {
 	DefaultProvider string
 	Providers       map[string]AgentProvider
+	Sandbox         AgentSandbox
 }
 
 func LoadAgentConfig() AgentConfig {
 	cfg := AgentConfig{
 		DefaultProvider: "opencode",
 		Providers:       map[string]AgentProvider{},
+		Sandbox:         defaultAgentSandbox(),
 	}
 	data, err := os.ReadFile(agentConfigPath())
 	if err != nil {
@@ -40,6 +54,7 @@ func LoadAgentConfig() AgentConfig {
 	if provider := strings.TrimSpace(parsed["default.provider"]); provider != "" {
 		cfg.DefaultProvider = provider
 	}
+	applySandboxConfig(&cfg.Sandbox, parsed)
 	for key, value := range parsed {
 		if !strings.HasPrefix(key, "providers.") {
 			continue
@@ -88,7 +103,8 @@ func LoadAgentConfig() AgentConfig {
 				provider.MaxIterations = iter
 			}
 		case "enable_network":
-			provider.EnableNetwork = parseBool(value)
+			enabled := parseBool(value)
+			provider.EnableNetwork = &enabled
 		}
 		cfg.Providers[name] = provider
 	}
@@ -143,6 +159,11 @@ func WriteAgentConfig(defaultProvider string) error {
 	content += "mode = \"prompt\"\n"
 	content += "headless = \"claude -p $PROMPT --output-format json --permission-mode acceptEdits\"\n"
 	content += "timeout_seconds = 300\n"
+	content += "\n[sandbox]\n"
+	content += "enabled = false\n"
+	content += "enable_network = true\n"
+	content += "cpu_seconds = 900\n"
+	content += "memory_mb = 8192\n"
 	return os.WriteFile(path, []byte(content), 0o644)
 }
 
@@ -167,6 +188,46 @@ func ensureDefaultProvider(cfg *AgentConfig) {
 	}
 }
 
+// defaultAgentSandbox leaves confinement opt-in, since many hosts lack
+// unprivileged user namespaces; once enabled, network access stays on because
+// hosted models need it.
+func defaultAgentSandbox() AgentSandbox {
+	return AgentSandbox{
+		Enabled:       false,
+		EnableNetwork: true,
+		CPUSeconds:    900,
+		MemoryMB:      8192,
+		Writable: []string{
+			"~/.local/share/opencode",
+			"~/.local/state/opencode",
+			"~/.cache/opencode",
+			"~/.claude",
+			"~/.claude.json",
+			"~/.codex",
+		},
+	}
+}
+
+func applySandboxConfig(sandbox *AgentSandbox, parsed map[string]string) {
+	if value, ok := parsed["sandbox.enabled"]; ok {
+		sandbox.Enabled = parseBool(value)
+	}
+	if value, ok := parsed["sandbox.enable_network"]; ok {
+		sandbox.EnableNetwork = parseBool(value)
+	}
+	for key, target := range map[string]*int{
+		"sandbox.cpu_seconds": &sandbox.CPUSeconds,
+		"sandbox.memory_mb":   &sandbox.MemoryMB,
+	} {
+		if n, err := strconv.Atoi(strings.TrimSpace(parsed[key])); err == nil && n >= 0 {
+			*target = n
+		}
+	}
+	if value, ok := parsed["sandbox.writable"]; ok {
+		sandbox.Writable = append(sandbox.Writable, parseList(value)...)
+	}
+}
+
 func defaultBundledProvider() AgentProvider {
 	return AgentProvider{
 		Name:     "opencode",
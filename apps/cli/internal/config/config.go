@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"os/user"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -13,6 +14,7 @@ import (
 
 const (
 	EnvWorkspace = "JUL_WORKSPACE"
+	EnvServerURL = "JUL_SERVER_URL"
 )
 
 type cachedConfig struct {
@@ -114,6 +116,15 @@ func ServerUser() string {
 	return ""
 }
 
+// ServerURL is the base URL of a jul server, used by commands that can read
+// from it instead of local notes.
+func ServerURL() string {
+	if value := strings.TrimSpace(os.Getenv(EnvServerURL)); value != "" {
+		return value
+	}
+	return configValue("server.url")
+}
+
 func UserName() string {
 	if cfg := configValue("user.name"); cfg != "" {
 		return cfg
@@ -212,10 +223,21 @@ func SyncMinIntervalSeconds() int {
 	return configInt("sync.min_interval_seconds", 5)
 }
 
+func LocalSyncEnabled() bool {
+	return configBool("local.sync", false)
+}
+
 func CIRunOnCheckpoint() bool {
 	return configBool("ci.run_on_checkpoint", true)
 }
 
+// CISetupOnCheckpoint asks the agent to propose .jul/ci.toml on the first
+// checkpoint that finds none. Off by default: it blocks the checkpoint on an
+// agent run.
+func CISetupOnCheckpoint() bool {
+	return configBool("ci.setup_on_checkpoint", false)
+}
+
 func CIRunOnDraft() bool {
 	return configBool("ci.run_on_draft", true)
 }
@@ -236,6 +258,61 @@ func ReviewRunOnCheckpoint() bool {
 	return configBool("review.run_on_checkpoint", true)
 }
 
+// AgentPoolSize bounds how many agent worktrees can be leased at once.
+func AgentPoolSize() int {
+	if size := configInt("agent.pool_size", 4); size > 0 {
+		return size
+	}
+	return 1
+}
+
+// ReviewProfile is a named reviewer persona from [review.profiles.<name>].
+type ReviewProfile struct {
+	Name string
+	// Prompt is added to the review prompt to focus the agent.
+	Prompt string
+	// Provider overrides the default agent provider when set.
+	Provider string
+}
+
+// ReviewProfiles returns the configured review personas sorted by name.
+// Repo config overrides user config field by field.
+func ReviewProfiles() []ReviewProfile {
+	byName := map[string]*ReviewProfile{}
+	for _, values := range []map[string]string{userConfigValues(), repoConfigValues()} {
+		for key, value := range values {
+			rest, ok := strings.CutPrefix(key, "review.profiles.")
+			if !ok {
+				continue
+			}
+			idx := strings.LastIndex(rest, ".")
+			if idx <= 0 {
+				continue
+			}
+			name := rest[:idx]
+			profile := byName[name]
+			if profile == nil {
+				profile = &ReviewProfile{Name: name}
+				byName[name] = profile
+			}
+			switch rest[idx+1:] {
+			case "prompt":
+				profile.Prompt = strings.TrimSpace(value)
+			case "provider":
+				profile.Provider = strings.TrimSpace(value)
+			}
+		}
+	}
+	profiles := make([]ReviewProfile, 0, len(byName))
+	for _, profile := range byName {
+		profiles = append(profiles, *profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
 func PromoteTarget() string {
 	if cfg := configValue("promote.default_target"); cfg != "" {
 		return cfg
@@ -276,11 +353,19 @@ func CheckpointAdoptOnCommit() bool {
 }
 
 func configList(key string, def []string) []string {
-	raw := strings.TrimSpace(configValue(key))
-	if raw == "" {
+	out := parseList(configValue(key))
+	if len(out) == 0 {
 		return def
 	}
+	return out
+}
+
+// parseList reads a `["a", "b"]` or `a, b` value.
+func parseList(raw string) []string {
 	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return nil
+	}
 	if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
 		trimmed = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(trimmed, "["), "]"))
 	}
@@ -299,9 +384,6 @@ func configList(key string, def []string) []string {
 		}
 		out = append(out, value)
 	}
-	if len(out) == 0 {
-		return def
-	}
 	return out
 }
 
@@ -407,6 +489,22 @@ func userConfigValue(key string) string {
 	return config[key]
 }
 
+func userConfigValues() map[string]string {
+	path, err := userConfigPath()
+	if err != nil {
+		return map[string]string{}
+	}
+	return readConfigCached(path)
+}
+
+func repoConfigValues() map[string]string {
+	path, err := repoConfigPath()
+	if err != nil {
+		return map[string]string{}
+	}
+	return readConfigCached(path)
+}
+
 func repoConfigValue(key string) string {
 	path, err := repoConfigPath()
 	if err != nil {
@@ -58,16 +58,38 @@ func DraftTree() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	return DraftTreeAt(repoRoot)
+}
+
+// DraftTreeAt writes repoRoot's working tree as a draft tree, without
+// resolving the repository from the current directory.
+func DraftTreeAt(repoRoot string) (string, error) {
 	julDir := filepath.Join(repoRoot, ".jul")
 	if err := os.MkdirAll(julDir, 0o755); err != nil {
 		return "", err
 	}
 	indexPath := filepath.Join(julDir, "draft-index")
-	return writeTree(repoRoot, indexPath)
+	return writeTree(repoRoot, indexPath, syncignore.Load(repoRoot))
+}
+
+// SnapshotTree writes the working tree, untracked files included, through the
+// shadow index at .jul/local-index. Unlike DraftTree it only leaves out .jul/
+// and gitignored files, so it also captures files kept out of draft sync.
+func SnapshotTree() (string, error) {
+	repoRoot, err := RepoTopLevel()
+	if err != nil {
+		return "", err
+	}
+	julDir := filepath.Join(repoRoot, ".jul")
+	if err := os.MkdirAll(julDir, 0o755); err != nil {
+		return "", err
+	}
+	indexPath := filepath.Join(julDir, "local-index")
+	return writeTree(repoRoot, indexPath, []string{".jul/"})
 }
 
-func writeTree(repoRoot, indexPath string) (string, error) {
-	excludePath, err := writeTempExcludes(repoRoot)
+func writeTree(repoRoot, indexPath string, patterns []string) (string, error) {
+	excludePath, err := writeExcludes(patterns)
 	if err != nil {
 		return "", err
 	}
@@ -154,11 +176,14 @@ func collectChangedPathsFromStatus(statusOutput string) []string {
 }
 
 func writeTempExcludes(repoRoot string) (string, error) {
+	return writeExcludes(syncignore.Load(repoRoot))
+}
+
+func writeExcludes(patterns []string) (string, error) {
 	file, err := os.CreateTemp("", "jul-exclude-")
 	if err != nil {
 		return "", err
 	}
-	patterns := syncignore.Load(repoRoot)
 	for _, pattern := range patterns {
 		if _, err := file.WriteString(pattern + "\n"); err != nil {
 			_ = file.Close()
@@ -95,6 +95,27 @@ func gitDirForRoot(repoRoot string) (string, error) {
 	return path, nil
 }
 
+// commonDirFor returns the directory holding the shared refs of gitDir. The
+// git dir of a linked worktree names it in its commondir file.
+func commonDirFor(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	path := strings.TrimSpace(string(data))
+	if path == "" {
+		return gitDir
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(gitDir, path)
+	}
+	return filepath.Clean(path)
+}
+
+func perWorktreeRef(ref string) bool {
+	return strings.HasPrefix(ref, "refs/worktree/") || strings.HasPrefix(ref, "refs/bisect/") || strings.HasPrefix(ref, "refs/rewritten/")
+}
+
 func resolveRefFast(ref string) (string, bool) {
 	ref = strings.TrimSpace(ref)
 	if ref == "" {
@@ -143,14 +164,18 @@ func readRefFromGitDir(gitDir, ref string) (string, bool) {
 	if !strings.HasPrefix(ref, "refs/") {
 		return "", false
 	}
-	refPath := filepath.Join(gitDir, filepath.FromSlash(ref))
+	refsDir := gitDir
+	if !perWorktreeRef(ref) {
+		refsDir = commonDirFor(gitDir)
+	}
+	refPath := filepath.Join(refsDir, filepath.FromSlash(ref))
 	if data, err := os.ReadFile(refPath); err == nil {
 		sha := strings.TrimSpace(string(data))
 		if sha != "" {
 			return sha, true
 		}
 	}
-	packedPath := filepath.Join(gitDir, "packed-refs")
+	packedPath := filepath.Join(commonDirFor(gitDir), "packed-refs")
 	file, err := os.Open(packedPath)
 	if err != nil {
 		return "", false
@@ -216,6 +241,10 @@ func ListRefsFast(prefix string) ([]string, bool, error) {
 	if err != nil {
 		return nil, false, nil
 	}
+	if perWorktreeRef(normalizedPrefix + "/") {
+		return nil, false, nil
+	}
+	gitDir = commonDirFor(gitDir)
 
 	seen := map[string]struct{}{}
 	matchesPrefix := func(ref string) bool {
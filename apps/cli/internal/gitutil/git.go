@@ -192,6 +192,16 @@ func Git(args ...string) (string, error) {
 	return git(args...)
 }
 
+// ReadBlob returns the exact bytes of a blob in repoRoot. Unlike Git, it does
+// not trim the output, so restored files round-trip byte for byte.
+func ReadBlob(repoRoot, object string) ([]byte, error) {
+	out, err := gitWithEnvRaw(repoRoot, nil, "cat-file", "blob", object)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
 func gitWithDir(dir string, args ...string) (string, error) {
 	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
 	var out bytes.Buffer
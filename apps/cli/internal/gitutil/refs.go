@@ -6,9 +6,14 @@ import (
 	"strings"
 )
 
+// RefUpdate is one update in an UpdateRefs transaction. A non-empty OldSHA
+// makes the update conditional on the ref's current value; Delete removes
+// the ref instead of setting it to SHA.
 type RefUpdate struct {
-	Ref string
-	SHA string
+	Ref    string
+	SHA    string
+	OldSHA string
+	Delete bool
 }
 
 func RefExists(ref string) bool {
@@ -52,10 +57,21 @@ func UpdateRefs(updates []RefUpdate) error {
 	for _, update := range updates {
 		ref := strings.TrimSpace(update.Ref)
 		sha := strings.TrimSpace(update.SHA)
+		old := strings.TrimSpace(update.OldSHA)
+		if old != "" {
+			old = " " + old
+		}
+		if update.Delete {
+			if ref == "" {
+				return fmt.Errorf("ref required")
+			}
+			fmt.Fprintf(&input, "delete %s%s\n", ref, old)
+			continue
+		}
 		if ref == "" || sha == "" {
 			return fmt.Errorf("ref and sha required")
 		}
-		fmt.Fprintf(&input, "update %s %s\n", ref, sha)
+		fmt.Fprintf(&input, "update %s %s%s\n", ref, sha, old)
 	}
 	cmd := exec.Command("git", "-C", repoRoot, "update-ref", "--stdin")
 	cmd.Stdin = strings.NewReader(input.String())
@@ -88,6 +104,10 @@ func ExtractTraceBase(message string) string {
 	return extractTrailer("Trace-Base", message)
 }
 
+func ExtractTrailer(key, message string) string {
+	return extractTrailer(key, message)
+}
+
 func extractTrailer(key, message string) string {
 	if strings.TrimSpace(message) == "" || strings.TrimSpace(key) == "" {
 		return ""
@@ -0,0 +1,58 @@
+package gitutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// HashBlob writes data as a blob object and returns its SHA.
+func HashBlob(repoRoot string, data []byte) (string, error) {
+	cmd := exec.Command("git", "-C", repoRoot, "hash-object", "-w", "--stdin")
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git hash-object failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// TreeFromBlobs writes a tree holding each path with its blob SHA, going
+// through a throwaway index so nested paths become subtrees.
+func TreeFromBlobs(repoRoot string, blobs map[string]string) (string, error) {
+	file, err := os.CreateTemp("", "jul-tree-index-")
+	if err != nil {
+		return "", err
+	}
+	indexPath := file.Name()
+	_ = file.Close()
+	// git refuses an empty file as an index; it only needs the path.
+	_ = os.Remove(indexPath)
+	defer os.Remove(indexPath)
+
+	paths := make([]string, 0, len(blobs))
+	for path := range blobs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var info bytes.Buffer
+	for _, path := range paths {
+		fmt.Fprintf(&info, "100644 %s\t%s\n", blobs[path], path)
+	}
+	cmd := exec.Command("git", "-C", repoRoot, "update-index", "--add", "--index-info")
+	cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+indexPath)
+	cmd.Stdin = &info
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git update-index failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return gitWithEnv(repoRoot, map[string]string{
+		"GIT_INDEX_FILE": indexPath,
+	}, "write-tree")
+}
@@ -0,0 +1,62 @@
+package gitutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree is one entry of `git worktree list`. Branch is the full ref HEAD
+// points at, empty when HEAD is detached.
+type Worktree struct {
+	Path   string
+	Head   string
+	Branch string
+}
+
+// ListWorktrees returns the main worktree first, then the linked ones.
+func ListWorktrees(repoRoot string) ([]Worktree, error) {
+	out, err := Git("-C", repoRoot, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var worktrees []Worktree
+	var current *Worktree
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			worktrees = append(worktrees, Worktree{Path: filepath.Clean(strings.TrimPrefix(line, "worktree "))})
+			current = &worktrees[len(worktrees)-1]
+		case current == nil:
+		case strings.HasPrefix(line, "HEAD "):
+			current.Head = strings.TrimPrefix(line, "HEAD ")
+		case strings.HasPrefix(line, "branch "):
+			current.Branch = strings.TrimPrefix(line, "branch ")
+		}
+	}
+	return worktrees, nil
+}
+
+// MainWorktree returns the root of the repository's main worktree.
+func MainWorktree(repoRoot string) (string, error) {
+	worktrees, err := ListWorktrees(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	if len(worktrees) == 0 {
+		return repoRoot, nil
+	}
+	return worktrees[0].Path, nil
+}
+
+// IsLinkedWorktree reports whether repoRoot is a worktree added with
+// `git worktree add` rather than the main one.
+func IsLinkedWorktree(repoRoot string) bool {
+	gitDir, err := gitDirForRoot(repoRoot)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(gitDir, "commondir"))
+	return err == nil
+}
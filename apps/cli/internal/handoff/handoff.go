@@ -0,0 +1,170 @@
+package handoff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+const (
+	manifestPath = "handoff.json"
+	tracesPath   = "traces"
+)
+
+// Manifest describes a handed-off workspace: the draft to materialise, the
+// workspace tip and the local workspace config the device had at push time.
+type Manifest struct {
+	Device       string    `json:"device"`
+	Workspace    string    `json:"workspace"`
+	DraftSHA     string    `json:"draft_sha"`
+	WorkspaceSHA string    `json:"workspace_sha,omitempty"`
+	BaseRef      string    `json:"base_ref,omitempty"`
+	BaseSHA      string    `json:"base_sha,omitempty"`
+	TrackRef     string    `json:"track_ref,omitempty"`
+	TrackTip     string    `json:"track_tip,omitempty"`
+	Traces       int       `json:"traces"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Prefix is the ref namespace holding user's handoffs, one per workspace.
+func Prefix(user string) string {
+	return fmt.Sprintf("refs/jul/handoff/%s/", user)
+}
+
+func Ref(user, workspace string) string {
+	return Prefix(user) + workspace
+}
+
+// Build records a handoff as a commit whose tree holds the manifest and the
+// trace files (see TraceFiles) under traces/. The draft and workspace tip are
+// its parents, so pushing the handoff ref carries them along.
+func Build(repoRoot string, m Manifest, traces map[string][]byte) (string, error) {
+	if strings.TrimSpace(m.DraftSHA) == "" {
+		return "", fmt.Errorf("draft sha required")
+	}
+	blobs := map[string]string{}
+	for rel, content := range traces {
+		blob, err := gitutil.HashBlob(repoRoot, content)
+		if err != nil {
+			return "", err
+		}
+		blobs[tracesPath+"/"+rel] = blob
+	}
+	m.Traces = len(blobs)
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC()
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	blob, err := gitutil.HashBlob(repoRoot, append(data, '\n'))
+	if err != nil {
+		return "", err
+	}
+	blobs[manifestPath] = blob
+	tree, err := gitutil.TreeFromBlobs(repoRoot, blobs)
+	if err != nil {
+		return "", err
+	}
+	parents := []string{m.DraftSHA}
+	if m.WorkspaceSHA != "" && m.WorkspaceSHA != m.DraftSHA {
+		parents = append(parents, m.WorkspaceSHA)
+	}
+	message := fmt.Sprintf("jul: handoff %s from %s\n\nJul-Type: handoff\n", m.Workspace, m.Device)
+	return gitutil.CommitTreeWithParents(tree, parents, message)
+}
+
+// Read returns the manifest stored in a handoff commit.
+func Read(repoRoot, sha string) (Manifest, error) {
+	data, err := gitutil.Git("-C", repoRoot, "cat-file", "blob", sha+":"+manifestPath)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("not a handoff: %s", sha)
+	}
+	var m Manifest
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return Manifest{}, err
+	}
+	return m, nil
+}
+
+// RestoreTraces writes the handed-off trace files that are missing under
+// .jul/traces and returns how many it wrote. Existing files are kept.
+func RestoreTraces(repoRoot, sha string) (int, error) {
+	out, err := gitutil.Git("-C", repoRoot, "ls-tree", "-r", sha, tracesPath+"/")
+	if err != nil {
+		return 0, err
+	}
+	written := 0
+	for _, line := range strings.Split(out, "\n") {
+		blob, path, ok := parseTreeLine(line)
+		if !ok {
+			continue
+		}
+		rel := strings.TrimPrefix(path, tracesPath+"/")
+		if rel == path || strings.Contains(rel, "..") {
+			continue
+		}
+		target := filepath.Join(repoRoot, ".jul", "traces", filepath.FromSlash(rel))
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		content, err := gitutil.ReadBlob(repoRoot, blob)
+		if err != nil {
+			return written, err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// parseTreeLine splits a "<mode> blob <sha>\t<path>" ls-tree line.
+func parseTreeLine(line string) (string, string, bool) {
+	meta, path, ok := strings.Cut(strings.TrimSpace(line), "\t")
+	if !ok {
+		return "", "", false
+	}
+	fields := strings.Fields(meta)
+	if len(fields) != 3 || fields[1] != "blob" {
+		return "", "", false
+	}
+	return fields[2], path, true
+}
+
+// TraceFiles reads the local prompt and/or summary files of traceSHAs from
+// .jul/traces, keyed by their slash path there. Missing files are skipped.
+func TraceFiles(repoRoot string, traceSHAs []string, prompts, summaries bool) (map[string][]byte, error) {
+	var dirs []string
+	if prompts {
+		dirs = append(dirs, "prompts")
+	}
+	if summaries {
+		dirs = append(dirs, "summaries")
+	}
+	files := map[string][]byte{}
+	for _, sha := range traceSHAs {
+		for _, dir := range dirs {
+			rel := dir + "/" + sha + ".txt"
+			data, err := os.ReadFile(filepath.Join(repoRoot, ".jul", "traces", filepath.FromSlash(rel)))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, err
+			}
+			files[rel] = data
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/notes"
+	"github.com/lydakis/jul/cli/internal/policy"
+)
+
+// FindingsNote holds the findings of the latest review of a checkpoint. It is
+// stored on the checkpoint commit, so each review replaces the previous set,
+// and it doubles as the record that the checkpoint was reviewed.
+type FindingsNote struct {
+	CheckpointSHA string           `json:"checkpoint_sha"`
+	ChangeID      string           `json:"change_id,omitempty"`
+	ReviewID      string           `json:"review_id,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Findings      []client.Finding `json:"findings"`
+	// ReviewedFrom is the earlier checkpoint an incremental review diffed
+	// against; empty for a full review.
+	ReviewedFrom string `json:"reviewed_from,omitempty"`
+}
+
+// FindingSummary is the per-checkpoint rollup shown by status.
+type FindingSummary struct {
+	Count int
+	Worst string
+}
+
+func WriteFindings(note FindingsNote) (FindingsNote, error) {
+	if strings.TrimSpace(note.CheckpointSHA) == "" {
+		return FindingsNote{}, errors.New("checkpoint sha required")
+	}
+	if note.CreatedAt.IsZero() {
+		note.CreatedAt = time.Now().UTC()
+	}
+	note.Findings = NormalizeFindings(note.Findings)
+	if err := notes.AddJSON(notes.RefFindings, note.CheckpointSHA, note); err != nil {
+		return FindingsNote{}, err
+	}
+	return note, nil
+}
+
+func GetFindings(checkpointSHA string) (*FindingsNote, error) {
+	if strings.TrimSpace(checkpointSHA) == "" {
+		return nil, nil
+	}
+	var note FindingsNote
+	found, err := notes.ReadJSON(notes.RefFindings, checkpointSHA, &note)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return &note, nil
+}
+
+// ListFindings returns the findings notes of every reviewed checkpoint.
+func ListFindings() ([]FindingsNote, error) {
+	entries, err := notes.ReadJSONEntries(notes.RefFindings)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]FindingsNote, 0, len(entries))
+	for _, entry := range entries {
+		var note FindingsNote
+		if err := json.Unmarshal(entry.Payload, &note); err != nil {
+			continue
+		}
+		note.CheckpointSHA = entry.ObjectSHA
+		out = append(out, note)
+	}
+	return out, nil
+}
+
+// FindingSummaries rolls up stored findings by checkpoint SHA.
+func FindingSummaries() (map[string]FindingSummary, error) {
+	entries, err := notes.ReadJSONEntries(notes.RefFindings)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]FindingSummary, len(entries))
+	for _, entry := range entries {
+		var note FindingsNote
+		if err := json.Unmarshal(entry.Payload, &note); err != nil {
+			continue
+		}
+		if len(note.Findings) == 0 {
+			continue
+		}
+		out[entry.ObjectSHA] = FindingSummary{Count: len(note.Findings), Worst: WorstSeverity(note.Findings)}
+	}
+	return out, nil
+}
+
+// NormalizeFindings drops findings without a path or message, maps
+// severities onto the policy scale and orders them by file and line.
+func NormalizeFindings(findings []client.Finding) []client.Finding {
+	out := make([]client.Finding, 0, len(findings))
+	for _, finding := range findings {
+		finding.Path = strings.TrimPrefix(strings.TrimSpace(finding.Path), "./")
+		finding.Message = strings.TrimSpace(finding.Message)
+		if finding.Path == "" || finding.Message == "" {
+			continue
+		}
+		finding.Severity = policy.NormalizeSeverity(finding.Severity)
+		finding.Category = strings.TrimSpace(finding.Category)
+		if finding.StartLine < 0 {
+			finding.StartLine = 0
+		}
+		if finding.EndLine < finding.StartLine {
+			finding.EndLine = finding.StartLine
+		}
+		out = append(out, finding)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].StartLine < out[j].StartLine
+	})
+	return out
+}
+
+func WorstSeverity(findings []client.Finding) string {
+	worst := ""
+	for _, finding := range findings {
+		if policy.SeverityRank(finding.Severity) > policy.SeverityRank(worst) {
+			worst = finding.Severity
+		}
+	}
+	return worst
+}
@@ -370,3 +370,43 @@ func hashObject(t *testing.T, repo, content string) string {
 	}
 	return run(t, repo, "git", "hash-object", "-w", path)
 }
+
+func TestFindingsRoundTrip(t *testing.T) {
+	repo := initRepo(t)
+	commit := commitFile(t, repo, "main.go", "package main\n", "checkpoint")
+
+	withRepo(t, repo, func() {
+		_, err := WriteFindings(FindingsNote{
+			CheckpointSHA: commit,
+			Findings: []client.Finding{
+				{Path: "./main.go", StartLine: 3, Severity: "Warning", Category: "bug", Message: "nil map write"},
+				{Path: "main.go", StartLine: 1, EndLine: 2, Severity: "critical", Message: "sql injection"},
+				{Path: "", Message: "unanchored"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("WriteFindings failed: %v", err)
+		}
+		got, err := GetFindings(commit)
+		if err != nil {
+			t.Fatalf("GetFindings failed: %v", err)
+		}
+		if got == nil || len(got.Findings) != 2 {
+			t.Fatalf("expected 2 findings, got %+v", got)
+		}
+		first, second := got.Findings[0], got.Findings[1]
+		if first.StartLine != 1 || first.Severity != "critical" {
+			t.Fatalf("expected findings ordered by line, got %+v", got.Findings)
+		}
+		if second.Path != "main.go" || second.Severity != "medium" || second.EndLine != 3 {
+			t.Fatalf("expected normalized finding, got %+v", second)
+		}
+		summaries, err := FindingSummaries()
+		if err != nil {
+			t.Fatalf("FindingSummaries failed: %v", err)
+		}
+		if summary := summaries[commit]; summary.Count != 2 || summary.Worst != "critical" {
+			t.Fatalf("unexpected summary %+v", summary)
+		}
+	})
+}
@@ -23,6 +23,8 @@ type SuggestionCreate struct {
 	Reason             string
 	Description        string
 	Confidence         float64
+	// Profiles names the review personas that proposed the change.
+	Profiles []string
 }
 
 func CreateSuggestion(req SuggestionCreate) (client.Suggestion, error) {
@@ -51,6 +53,7 @@ func CreateSuggestion(req SuggestionCreate) (client.Suggestion, error) {
 		Reason:             strings.TrimSpace(req.Reason),
 		Description:        strings.TrimSpace(req.Description),
 		Confidence:         req.Confidence,
+		Profiles:           req.Profiles,
 		Status:             "pending",
 		CreatedAt:          time.Now().UTC(),
 	}
@@ -135,6 +138,74 @@ func UpdateSuggestionStatus(id, status, resolution string) (client.Suggestion, e
 	return client.Suggestion{}, errors.New("suggestion not found")
 }
 
+// RecordSuggestionApply records the paths and hunks taken from a suggestion
+// along with its new status (applied or partially_applied).
+func RecordSuggestionApply(id, status string, paths []string, hunks []int) (client.Suggestion, error) {
+	if strings.TrimSpace(id) == "" {
+		return client.Suggestion{}, errors.New("suggestion id required")
+	}
+	entries, err := loadSuggestionEntries()
+	if err != nil {
+		return client.Suggestion{}, err
+	}
+	for _, entry := range entries {
+		sug := entry.Suggestion
+		if sug.SuggestionID != id {
+			continue
+		}
+		sug.Status = normalizeSuggestionStatus(status)
+		sug.AppliedPaths = paths
+		sug.AppliedHunks = hunks
+		sug.ResolvedAt = time.Now().UTC()
+		if err := notes.AddJSON(notes.RefSuggestions, entry.ObjectSHA, sug); err != nil {
+			return client.Suggestion{}, err
+		}
+		return sug, nil
+	}
+	return client.Suggestion{}, errors.New("suggestion not found")
+}
+
+// RetargetSuggestion moves a suggestion onto a new base and suggested commit
+// and returns it to pending.
+func RetargetSuggestion(id, baseSHA, suggestedSHA string) (client.Suggestion, error) {
+	if strings.TrimSpace(baseSHA) == "" || strings.TrimSpace(suggestedSHA) == "" {
+		return client.Suggestion{}, errors.New("base and suggested commit required")
+	}
+	entries, err := loadSuggestionEntries()
+	if err != nil {
+		return client.Suggestion{}, err
+	}
+	for _, entry := range entries {
+		sug := entry.Suggestion
+		if sug.SuggestionID != id {
+			continue
+		}
+		sug.BaseCommitSHA = strings.TrimSpace(baseSHA)
+		sug.SuggestedCommitSHA = strings.TrimSpace(suggestedSHA)
+		sug.Status = "pending"
+		sug.ResolutionMessage = ""
+		sug.ResolvedAt = time.Time{}
+		// Hunk numbers refer to the old patch; the new one holds only what
+		// was left.
+		sug.AppliedPaths = nil
+		sug.AppliedHunks = nil
+		ref := fmt.Sprintf("refs/jul/suggest/%s/%s", sug.ChangeID, sug.SuggestionID)
+		if err := gitutil.UpdateRef(ref, sug.SuggestedCommitSHA); err != nil {
+			return client.Suggestion{}, err
+		}
+		if err := notes.AddJSON(notes.RefSuggestions, sug.SuggestedCommitSHA, sug); err != nil {
+			return client.Suggestion{}, err
+		}
+		if entry.ObjectSHA != sug.SuggestedCommitSHA {
+			if err := notes.Remove(notes.RefSuggestions, entry.ObjectSHA); err != nil {
+				return client.Suggestion{}, err
+			}
+		}
+		return sug, nil
+	}
+	return client.Suggestion{}, errors.New("suggestion not found")
+}
+
 func GetSuggestionByID(id string) (client.Suggestion, bool, error) {
 	if strings.TrimSpace(id) == "" {
 		return client.Suggestion{}, false, errors.New("suggestion id required")
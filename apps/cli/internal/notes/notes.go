@@ -21,6 +21,7 @@ const (
 	RefTraces                 = "refs/notes/jul/traces"
 	RefSuggestions            = "refs/notes/jul/suggestions"
 	RefAgentReview            = "refs/notes/jul/agent-review"
+	RefFindings               = "refs/notes/jul/findings"
 	RefCRState                = "refs/notes/jul/cr-state"
 	RefCRComments             = "refs/notes/jul/cr-comments"
 	RefMeta                   = "refs/notes/jul/meta"
@@ -0,0 +1,398 @@
+package oplog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+// Kinds of operation entries. Undo and redo entries name the operation they
+// reverted or reapplied in Target.
+const (
+	KindCommand = "command"
+	KindUndo    = "undo"
+	KindRedo    = "redo"
+)
+
+// Change is one value an operation moved. An empty Before or After means the
+// ref or file did not exist.
+type Change struct {
+	Name   string `json:"name"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Operation is one entry of the operation log.
+type Operation struct {
+	ID         string   `json:"id"`
+	Time       string   `json:"time"`
+	Kind       string   `json:"kind"`
+	Command    string   `json:"command"`
+	Workspace  string   `json:"workspace,omitempty"`
+	Target     string   `json:"target,omitempty"`
+	Refs       []Change `json:"refs,omitempty"`
+	Files      []Change `json:"files,omitempty"`
+	Config     []Change `json:"config,omitempty"`
+	TreeBefore string   `json:"tree_before,omitempty"`
+	TreeAfter  string   `json:"tree_after,omitempty"`
+}
+
+// State is the part of the repository jul operations mutate: jul refs, the
+// workspace head branches, jul notes, HEAD, the files under
+// .jul/workspaces (leases, configs, track tips) and the current workspace.
+// Tree is the working tree written as a draft tree.
+type State struct {
+	Refs      map[string]string
+	Files     map[string]string
+	Workspace string
+	Tree      string
+}
+
+const headRef = "HEAD"
+
+var refPrefixes = []string{"refs/jul/", "refs/heads/jul/", "refs/notes/jul/"}
+
+func LogPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".jul", "oplog.jsonl")
+}
+
+// Capture records the current state of repoRoot. Writing the draft tree
+// walks the whole working tree, so Tree is only filled in when tree is set.
+func Capture(repoRoot string, tree bool) (State, error) {
+	state := State{Refs: map[string]string{}, Files: map[string]string{}}
+	args := append([]string{"-C", repoRoot, "for-each-ref", "--format=%(objectname) %(refname)"}, refPrefixes...)
+	out, err := gitutil.Git(args...)
+	if err != nil {
+		return State{}, err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		// Local saves are scratch state owned by 'jul local'; keep them out.
+		if fields := strings.Fields(line); len(fields) == 2 && !strings.HasPrefix(fields[1], "refs/jul/local/") {
+			state.Refs[fields[1]] = fields[0]
+		}
+	}
+	if head := readHead(repoRoot); head != "" {
+		state.Refs[headRef] = head
+	}
+
+	julDir := filepath.Join(repoRoot, ".jul")
+	root := filepath.Join(julDir, "workspaces")
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		_ = filepath.WalkDir(resolved, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(resolved, path)
+			if err != nil {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			state.Files[filepath.ToSlash(filepath.Join("workspaces", rel))] = string(data)
+			return nil
+		})
+	}
+
+	if ws, err := gitutil.Git("-C", repoRoot, "config", "--get", "jul.workspace"); err == nil {
+		state.Workspace = strings.TrimSpace(ws)
+	}
+	if tree {
+		if sha, err := gitutil.DraftTreeAt(repoRoot); err == nil {
+			state.Tree = strings.TrimSpace(sha)
+		}
+	}
+	return state, nil
+}
+
+// Diff builds the entry for an operation that took the repository from
+// before to after. It reports false when nothing changed.
+func Diff(before, after State) (Operation, bool) {
+	op := Operation{
+		Refs:  diffMaps(before.Refs, after.Refs),
+		Files: diffMaps(before.Files, after.Files),
+	}
+	if before.Workspace != after.Workspace {
+		op.Config = []Change{{Name: "jul.workspace", Before: before.Workspace, After: after.Workspace}}
+	}
+	if before.Tree != after.Tree {
+		op.TreeBefore = before.Tree
+		op.TreeAfter = after.Tree
+	}
+	changed := len(op.Refs) > 0 || len(op.Files) > 0 || len(op.Config) > 0 || op.TreeBefore != op.TreeAfter
+	return op, changed
+}
+
+func diffMaps(before, after map[string]string) []Change {
+	var changes []Change
+	for name, value := range before {
+		if after[name] != value {
+			changes = append(changes, Change{Name: name, Before: value, After: after[name]})
+		}
+	}
+	for name, value := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, Change{Name: name, After: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// Append adds op to the log, filling in its id and time.
+func Append(repoRoot string, op Operation) (Operation, error) {
+	if op.ID == "" {
+		op.ID = ulid.Make().String()
+	}
+	if op.Time == "" {
+		op.Time = time.Now().UTC().Format(time.RFC3339)
+	}
+	if op.Kind == "" {
+		op.Kind = KindCommand
+	}
+	path := LogPath(repoRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return op, err
+	}
+	data, err := json.Marshal(op)
+	if err != nil {
+		return op, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return op, err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return op, err
+	}
+	return op, nil
+}
+
+// Load returns the log oldest first.
+func Load(repoRoot string) ([]Operation, error) {
+	f, err := os.Open(LogPath(repoRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var ops []Operation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var op Operation
+		if err := json.Unmarshal([]byte(line), &op); err != nil {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops, scanner.Err()
+}
+
+// Stacks replays the log and returns the operations that can be undone and
+// redone, most recent last. A new command clears the redo stack.
+func Stacks(ops []Operation) (undo []Operation, redo []Operation) {
+	byID := map[string]Operation{}
+	for _, op := range ops {
+		byID[op.ID] = op
+		switch op.Kind {
+		case KindUndo:
+			undo = remove(undo, op.Target)
+			if target, ok := byID[op.Target]; ok {
+				redo = append(redo, target)
+			}
+		case KindRedo:
+			redo = remove(redo, op.Target)
+			if target, ok := byID[op.Target]; ok {
+				undo = append(undo, target)
+			}
+		default:
+			undo = append(undo, op)
+			redo = nil
+		}
+	}
+	return undo, redo
+}
+
+func remove(ops []Operation, id string) []Operation {
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].ID == id {
+			return append(ops[:i:i], ops[i+1:]...)
+		}
+	}
+	return ops
+}
+
+// Invert returns the changes that revert op.
+func Invert(op Operation) Operation {
+	flip := func(changes []Change) []Change {
+		out := make([]Change, len(changes))
+		for i, c := range changes {
+			out[i] = Change{Name: c.Name, Before: c.After, After: c.Before}
+		}
+		return out
+	}
+	return Operation{
+		Refs:       flip(op.Refs),
+		Files:      flip(op.Files),
+		Config:     flip(op.Config),
+		TreeBefore: op.TreeAfter,
+		TreeAfter:  op.TreeBefore,
+	}
+}
+
+// ConflictError lists refs that moved since the operation being reverted or
+// reapplied.
+type ConflictError struct {
+	Refs []string
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("refs changed since the operation: %s", strings.Join(e.Refs, ", "))
+}
+
+// volatileRef reports refs that move on every sync; they are restored without
+// checking their current value.
+func volatileRef(ref string) bool {
+	return strings.HasPrefix(ref, "refs/jul/sync/") || strings.HasPrefix(ref, "refs/jul/trace-sync/")
+}
+
+// ApplyResult reports what Apply did with the working tree.
+type ApplyResult struct {
+	TreeRestored bool
+	TreeSkipped  bool
+}
+
+// Apply moves the repository from each change's Before to its After. Refs
+// are updated in one transaction. Unless force is set, any ref other than
+// sync refs that no longer holds its Before value aborts with a
+// ConflictError before anything changes. The working tree is only reset when
+// it still matches TreeBefore, so edits made since are never discarded.
+func Apply(repoRoot string, op Operation, force bool) (ApplyResult, error) {
+	current, err := Capture(repoRoot, op.TreeAfter != "")
+	if err != nil {
+		return ApplyResult{}, err
+	}
+	if !force {
+		var moved []string
+		for _, c := range op.Refs {
+			if !volatileRef(c.Name) && current.Refs[c.Name] != c.Before {
+				moved = append(moved, c.Name)
+			}
+		}
+		if len(moved) > 0 {
+			return ApplyResult{}, ConflictError{Refs: moved}
+		}
+	}
+
+	var updates []gitutil.RefUpdate
+	head := ""
+	for _, c := range op.Refs {
+		if c.Name == headRef {
+			head = c.After
+			continue
+		}
+		have := current.Refs[c.Name]
+		if have == c.After {
+			continue
+		}
+		updates = append(updates, gitutil.RefUpdate{Ref: c.Name, SHA: c.After, OldSHA: have, Delete: c.After == ""})
+	}
+	if err := gitutil.UpdateRefs(updates); err != nil {
+		return ApplyResult{}, err
+	}
+	if head != "" && head != current.Refs[headRef] {
+		if err := writeHead(repoRoot, head); err != nil {
+			return ApplyResult{}, err
+		}
+	}
+
+	julDir := filepath.Join(repoRoot, ".jul")
+	for _, c := range op.Files {
+		path := filepath.Join(julDir, filepath.FromSlash(c.Name))
+		if c.After == "" {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return ApplyResult{}, err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return ApplyResult{}, err
+		}
+		if err := os.WriteFile(path, []byte(c.After), 0o644); err != nil {
+			return ApplyResult{}, err
+		}
+	}
+
+	for _, c := range op.Config {
+		if c.Name != "jul.workspace" || c.After == "" {
+			continue
+		}
+		args := []string{"-C", repoRoot, "config"}
+		if gitutil.IsLinkedWorktree(repoRoot) {
+			args = append(args, "--worktree")
+		}
+		if _, err := gitutil.Git(append(args, "jul.workspace", c.After)...); err != nil {
+			return ApplyResult{}, err
+		}
+	}
+
+	res := ApplyResult{}
+	if op.TreeAfter != "" && op.TreeAfter != current.Tree {
+		if current.Tree != op.TreeBefore || !objectExists(repoRoot, op.TreeAfter) {
+			res.TreeSkipped = true
+			return res, nil
+		}
+		if _, err := gitutil.Git("-C", repoRoot, "read-tree", "--reset", "-u", op.TreeAfter); err != nil {
+			return res, err
+		}
+		if _, err := gitutil.Git("-C", repoRoot, "clean", "-fd", "--exclude=.jul"); err != nil {
+			return res, err
+		}
+		res.TreeRestored = true
+	}
+	return res, nil
+}
+
+// readHead returns "ref: <branch>" for a symbolic HEAD, else its commit.
+func readHead(repoRoot string) string {
+	if ref, err := gitutil.Git("-C", repoRoot, "symbolic-ref", "-q", "HEAD"); err == nil && strings.TrimSpace(ref) != "" {
+		return "ref: " + strings.TrimSpace(ref)
+	}
+	if sha, err := gitutil.Git("-C", repoRoot, "rev-parse", "-q", "--verify", "HEAD"); err == nil {
+		return strings.TrimSpace(sha)
+	}
+	return ""
+}
+
+func writeHead(repoRoot, head string) error {
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		_, err := gitutil.Git("-C", repoRoot, "symbolic-ref", "HEAD", ref)
+		return err
+	}
+	_, err := gitutil.Git("-C", repoRoot, "update-ref", "--no-deref", "HEAD", head)
+	return err
+}
+
+func objectExists(repoRoot, sha string) bool {
+	_, err := gitutil.Git("-C", repoRoot, "cat-file", "-e", sha)
+	return err == nil
+}
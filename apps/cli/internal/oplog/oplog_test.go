@@ -0,0 +1,67 @@
+package oplog
+
+import "testing"
+
+func TestStacksTrackUndoAndRedo(t *testing.T) {
+	ops := []Operation{
+		{ID: "a", Kind: KindCommand},
+		{ID: "b", Kind: KindCommand},
+		{ID: "u1", Kind: KindUndo, Target: "b"},
+		{ID: "u2", Kind: KindUndo, Target: "a"},
+		{ID: "r1", Kind: KindRedo, Target: "a"},
+	}
+	undo, redo := Stacks(ops)
+	if len(undo) != 1 || undo[0].ID != "a" {
+		t.Fatalf("expected undo stack [a], got %+v", undo)
+	}
+	if len(redo) != 1 || redo[0].ID != "b" {
+		t.Fatalf("expected redo stack [b], got %+v", redo)
+	}
+
+	ops = append(ops, Operation{ID: "c", Kind: KindCommand})
+	undo, redo = Stacks(ops)
+	if len(undo) != 2 || undo[1].ID != "c" {
+		t.Fatalf("expected undo stack [a c], got %+v", undo)
+	}
+	if len(redo) != 0 {
+		t.Fatalf("expected a new command to clear redo, got %+v", redo)
+	}
+}
+
+func TestDiffAndInvert(t *testing.T) {
+	before := State{
+		Refs:      map[string]string{"refs/jul/workspaces/u/@": "aaa", "refs/jul/keep/u/@/I1/aaa": "aaa"},
+		Files:     map[string]string{"workspaces/@/lease": "aaa\n"},
+		Workspace: "u/@",
+		Tree:      "t1",
+	}
+	after := State{
+		Refs:      map[string]string{"refs/jul/workspaces/u/@": "bbb", "refs/jul/changes/I1": "bbb"},
+		Files:     map[string]string{"workspaces/@/lease": "bbb\n"},
+		Workspace: "u/@",
+		Tree:      "t1",
+	}
+	op, changed := Diff(before, after)
+	if !changed {
+		t.Fatalf("expected changes")
+	}
+	if len(op.Refs) != 3 || len(op.Files) != 1 || len(op.Config) != 0 || op.TreeBefore != "" {
+		t.Fatalf("unexpected diff %+v", op)
+	}
+	inverse := Invert(op)
+	for _, c := range inverse.Refs {
+		switch c.Name {
+		case "refs/jul/changes/I1":
+			if c.After != "" {
+				t.Fatalf("expected undo to delete new change ref, got %+v", c)
+			}
+		case "refs/jul/keep/u/@/I1/aaa":
+			if c.After != "aaa" {
+				t.Fatalf("expected undo to restore keep ref, got %+v", c)
+			}
+		}
+	}
+	if _, changed := Diff(after, after); changed {
+		t.Fatalf("expected no changes for identical states")
+	}
+}
@@ -8,7 +8,10 @@ import (
 type ApplyResult struct {
 	SuggestionID string        `json:"suggestion_id"`
 	Applied      bool          `json:"applied"`
+	Status       string        `json:"status,omitempty"`
 	FilesChanged []string      `json:"files_changed,omitempty"`
+	Hunks        []int         `json:"hunks,omitempty"`
+	TotalHunks   int           `json:"total_hunks,omitempty"`
 	Draft        ApplyDraft    `json:"draft,omitempty"`
 	Checkpoint   string        `json:"checkpoint_sha,omitempty"`
 	NextActions  []ApplyAction `json:"next_actions,omitempty"`
@@ -25,7 +28,9 @@ type ApplyAction struct {
 }
 
 func RenderApply(w io.Writer, res ApplyResult) {
-	if res.Applied {
+	if res.Applied && res.Status == "partially_applied" {
+		fmt.Fprintf(w, "Applied %d of %d hunk(s) to draft; the rest of the suggestion stays open.\n", len(res.Hunks), res.TotalHunks)
+	} else if res.Applied {
 		fmt.Fprintln(w, "Applied to draft.")
 	} else {
 		fmt.Fprintln(w, "No changes applied.")
@@ -197,11 +197,17 @@ func LabelForCommand(command string) string {
 		return fmt.Sprintf("%s (%s)", base, path)
 	}
 	switch {
-	case strings.Contains(normalized, "lint"):
+	case strings.Contains(normalized, "lint") || strings.Contains(normalized, "ruff check") ||
+		strings.Contains(normalized, "flake8"):
 		return "lint"
+	case strings.Contains(normalized, "typecheck") || strings.Contains(normalized, "type-check") ||
+		strings.Contains(normalized, "mypy") || strings.Contains(normalized, "run tsc"):
+		return "typecheck"
 	case strings.Contains(normalized, "go test") || strings.Contains(normalized, "pytest") ||
 		strings.Contains(normalized, "npm test") || strings.Contains(normalized, "yarn test") ||
-		strings.Contains(normalized, "pnpm test"):
+		strings.Contains(normalized, "pnpm test") || strings.Contains(normalized, "run test") ||
+		strings.Contains(normalized, "cargo test") || strings.Contains(normalized, "make test") ||
+		normalized == "tox":
 		return "test"
 	case strings.Contains(normalized, "go vet"):
 		return "lint"
@@ -4,15 +4,24 @@ import (
 	"fmt"
 	"io"
 	"strings"
+
+	"github.com/lydakis/jul/cli/internal/client"
 )
 
 type DiffResult struct {
-	From string `json:"from,omitempty"`
-	To   string `json:"to,omitempty"`
-	Diff string `json:"diff,omitempty"`
+	From     string           `json:"from,omitempty"`
+	To       string           `json:"to,omitempty"`
+	Diff     string           `json:"diff,omitempty"`
+	Findings []client.Finding `json:"findings,omitempty"`
+	// FindingsOn is the side the findings' lines refer to: "to" or "from".
+	FindingsOn string `json:"findings_on,omitempty"`
 }
 
 func RenderDiff(w io.Writer, res DiffResult) {
 	out := strings.TrimRight(res.Diff, "\n")
+	if len(res.Findings) > 0 {
+		annotateDiff(w, out, res.Findings, res.FindingsOn == "from")
+		return
+	}
 	fmt.Fprintln(w, out)
 }
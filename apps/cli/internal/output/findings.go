@@ -0,0 +1,115 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/client"
+)
+
+// FindingLine formats a finding as "[high] path:12-14 category: message".
+func FindingLine(f client.Finding) string {
+	location := f.Path
+	if f.StartLine > 0 {
+		location += ":" + strconv.Itoa(f.StartLine)
+		if f.EndLine > f.StartLine {
+			location += "-" + strconv.Itoa(f.EndLine)
+		}
+	}
+	message := f.Message
+	if f.Category != "" {
+		message = f.Category + ": " + message
+	}
+	line := fmt.Sprintf("[%s] %s %s", f.Severity, location, message)
+	if len(f.Profiles) > 0 {
+		line += " {" + strings.Join(f.Profiles, ", ") + "}"
+	}
+	return line
+}
+
+var diffHunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// annotateDiff prints diff with each finding placed after the hunk it falls
+// in. Findings outside every hunk follow the file's last hunk. When oldSide
+// is set, finding lines refer to the pre-image.
+func annotateDiff(w io.Writer, diff string, findings []client.Finding, oldSide bool) {
+	byPath := map[string][]int{}
+	for i, f := range findings {
+		byPath[f.Path] = append(byPath[f.Path], i)
+	}
+	printed := make([]bool, len(findings))
+	path := ""
+	start, end := 0, 0
+	inHunk := false
+	flushHunk := func() {
+		if !inHunk {
+			return
+		}
+		inHunk = false
+		for _, i := range byPath[path] {
+			f := findings[i]
+			last := f.EndLine
+			if last < f.StartLine {
+				last = f.StartLine
+			}
+			if printed[i] || f.StartLine == 0 || f.StartLine >= end || last < start {
+				continue
+			}
+			printed[i] = true
+			fmt.Fprintf(w, ">> %s\n", FindingLine(f))
+		}
+	}
+	flushFile := func() {
+		flushHunk()
+		for _, i := range byPath[path] {
+			if printed[i] {
+				continue
+			}
+			printed[i] = true
+			fmt.Fprintf(w, ">> %s\n", FindingLine(findings[i]))
+		}
+		path = ""
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+		case inHunk:
+			if strings.HasPrefix(line, "@@ ") {
+				flushHunk()
+				start, end, inHunk = parseHunkRange(line, oldSide)
+			}
+		case strings.HasPrefix(line, "--- ") && oldSide:
+			path = strings.TrimPrefix(strings.TrimPrefix(line, "--- "), "a/")
+		case strings.HasPrefix(line, "+++ ") && !oldSide:
+			path = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "@@ "):
+			start, end, inHunk = parseHunkRange(line, oldSide)
+		}
+		fmt.Fprintln(w, line)
+	}
+	flushFile()
+}
+
+func parseHunkRange(header string, oldSide bool) (int, int, bool) {
+	match := diffHunkHeader.FindStringSubmatch(header)
+	if match == nil {
+		return 0, 0, false
+	}
+	first, count := match[3], match[4]
+	if oldSide {
+		first, count = match[1], match[2]
+	}
+	start, _ := strconv.Atoi(first)
+	n := 1
+	if count != "" {
+		n, _ = strconv.Atoi(count)
+	}
+	if n == 0 {
+		n = 1
+	}
+	return start, start + n, true
+}
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/client"
+)
+
+// FixOutput reports a `jul fix` run: passing (nothing to do), fixed (one
+// suggestion with its passing attestation) or gave_up (with the reason).
+type FixOutput struct {
+	Status        string              `json:"status"`
+	BaseSHA       string              `json:"base_sha"`
+	ChangeID      string              `json:"change_id,omitempty"`
+	Commands      []string            `json:"commands"`
+	MaxIterations int                 `json:"max_iterations,omitempty"`
+	Iterations    []FixIteration      `json:"iterations,omitempty"`
+	Suggestion    *client.Suggestion  `json:"suggestion,omitempty"`
+	Attestation   *client.Attestation `json:"attestation,omitempty"`
+	Reason        string              `json:"reason,omitempty"`
+	NextActions   []NextAction        `json:"next_actions,omitempty"`
+}
+
+type FixIteration struct {
+	Iteration int      `json:"iteration"`
+	CommitSHA string   `json:"commit_sha,omitempty"`
+	Status    string   `json:"status"`
+	Failed    []string `json:"failed,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+}
+
+func RenderFix(w io.Writer, out FixOutput, opts Options) {
+	switch out.Status {
+	case "passing":
+		fmt.Fprintf(w, "%s Checks already pass on %s; nothing to fix.\n", fixIcon("pass", opts), shortID(out.BaseSHA, 7))
+		return
+	case "fixed":
+		fmt.Fprintf(w, "%s Checks pass after %d of %d iteration(s).\n", fixIcon("pass", opts), len(out.Iterations), out.MaxIterations)
+	default:
+		fmt.Fprintf(w, "%s Gave up: %s\n", fixIcon("fail", opts), out.Reason)
+	}
+	for _, iteration := range out.Iterations {
+		line := fmt.Sprintf("  %d. %s", iteration.Iteration, iteration.Status)
+		if iteration.CommitSHA != "" {
+			line += " " + shortID(iteration.CommitSHA, 7)
+		}
+		if len(iteration.Failed) > 0 {
+			line += " (failing: " + strings.Join(iteration.Failed, ", ") + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+	if out.Suggestion == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nSuggestion %s (%s) -> %s\n", out.Suggestion.SuggestionID, out.Suggestion.Reason, shortID(out.Suggestion.SuggestedCommitSHA, 7))
+	if out.Attestation != nil {
+		fmt.Fprintf(w, "  ci: %s\n", out.Attestation.Status)
+	}
+	fmt.Fprintf(w, "\nRun 'jul show %s' to review or 'jul apply %s' to apply.\n", out.Suggestion.SuggestionID, out.Suggestion.SuggestionID)
+}
+
+func fixIcon(status string, opts Options) string {
+	if icon := statusIconColored(status, opts); icon != "" {
+		return icon
+	}
+	return statusIcon(status, opts)
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"fmt"
+	"io"
+)
+
+type HandoffOutput struct {
+	Status    string `json:"status"`
+	Action    string `json:"action"`
+	Workspace string `json:"workspace"`
+	Device    string `json:"device"`
+	Remote    string `json:"remote"`
+	Ref       string `json:"ref"`
+	DraftSHA  string `json:"draft_sha"`
+	BaseRef   string `json:"base_ref,omitempty"`
+	TrackRef  string `json:"track_ref,omitempty"`
+	Traces    int    `json:"traces"`
+}
+
+func RenderHandoff(w io.Writer, out HandoffOutput, opts Options) {
+	ok := statusIconColored("pass", opts)
+	if ok == "" {
+		ok = statusIcon("pass", opts)
+	}
+	if out.Action == "push" {
+		fmt.Fprintf(w, "Handing off workspace '%s' from %s...\n", out.Workspace, out.Device)
+		fmt.Fprintf(w, "  %sDraft %s synced\n", ok, shortID(out.DraftSHA, 6))
+		if out.BaseRef != "" || out.TrackRef != "" {
+			fmt.Fprintf(w, "  %sWorkspace config (base %s, track %s)\n", ok, orNone(out.BaseRef), orNone(out.TrackRef))
+		}
+		fmt.Fprintf(w, "  %s%d trace files\n", ok, out.Traces)
+		fmt.Fprintf(w, "  %sPushed %s to %s\n", ok, out.Ref, out.Remote)
+		fmt.Fprintln(w, "\nRun 'jul handoff pull' on the other device.")
+		return
+	}
+	fmt.Fprintf(w, "Taking over workspace '%s' from %s...\n", out.Workspace, out.Device)
+	if out.BaseRef != "" || out.TrackRef != "" {
+		fmt.Fprintf(w, "  %sWorkspace config (base %s, track %s)\n", ok, orNone(out.BaseRef), orNone(out.TrackRef))
+	}
+	fmt.Fprintf(w, "  %sDraft %s materialised\n", ok, shortID(out.DraftSHA, 6))
+	fmt.Fprintf(w, "  %s%d trace files restored\n", ok, out.Traces)
+	fmt.Fprintf(w, "Switched to workspace '%s'\n", out.Workspace)
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"fmt"
+	"io"
+)
+
+type OpLogEntry struct {
+	ID        string `json:"id"`
+	Time      string `json:"time"`
+	Kind      string `json:"kind"`
+	Command   string `json:"command"`
+	Workspace string `json:"workspace,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Refs      int    `json:"refs"`
+	Undone    bool   `json:"undone,omitempty"`
+}
+
+type OpRestoreOutput struct {
+	Status       string `json:"status"`
+	Action       string `json:"action"`
+	OperationID  string `json:"operation_id"`
+	Command      string `json:"command"`
+	Refs         int    `json:"refs"`
+	TreeRestored bool   `json:"tree_restored,omitempty"`
+	TreeSkipped  bool   `json:"tree_skipped,omitempty"`
+}
+
+func RenderOpLog(w io.Writer, entries []OpLogEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No operations recorded.")
+		return
+	}
+	for _, entry := range entries {
+		label := entry.Command
+		switch entry.Kind {
+		case "undo":
+			label = "undo " + shortID(entry.Target, 10)
+		case "redo":
+			label = "redo " + shortID(entry.Target, 10)
+		}
+		line := fmt.Sprintf("%s %s %s (%d refs)", entry.ID, entry.Time, label, entry.Refs)
+		if entry.Workspace != "" {
+			line += " [" + entry.Workspace + "]"
+		}
+		if entry.Undone {
+			line += " (undone)"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func RenderOpRestore(w io.Writer, out OpRestoreOutput) {
+	verb := "Undid"
+	if out.Action == "redo" {
+		verb = "Redid"
+	}
+	fmt.Fprintf(w, "%s %s (%s): %d refs restored\n", verb, out.OperationID, out.Command, out.Refs)
+	if out.TreeRestored {
+		fmt.Fprintln(w, "  Working tree restored")
+	}
+	if out.TreeSkipped {
+		fmt.Fprintln(w, "  Working tree changed since the operation; files left as they are")
+	}
+}
@@ -0,0 +1,33 @@
+package output
+
+import (
+	"fmt"
+	"io"
+)
+
+// CheckpointReshapeOutput reports a squash or split of a change's checkpoints.
+type CheckpointReshapeOutput struct {
+	Status         string   `json:"status"`
+	Action         string   `json:"action"`
+	ChangeID       string   `json:"change_id"`
+	Rewritten      []string `json:"rewritten"`
+	OldCheckpoints []string `json:"old_checkpoints"`
+	NewCheckpoints []string `json:"new_checkpoints"`
+	DraftSHA       string   `json:"draft_sha"`
+}
+
+func RenderCheckpointReshape(w io.Writer, out CheckpointReshapeOutput, opts Options) {
+	ok := statusIconColored("pass", opts)
+	if ok == "" {
+		ok = statusIcon("pass", opts)
+	}
+	verb := "Squashed"
+	if out.Action == "split" {
+		verb = "Split"
+	}
+	fmt.Fprintf(w, "%s %s: %d -> %d checkpoints\n", ok, verb+" "+out.ChangeID, len(out.OldCheckpoints), len(out.NewCheckpoints))
+	for _, sha := range out.Rewritten {
+		fmt.Fprintf(w, "  rewrote %s\n", shortID(sha, 7))
+	}
+	fmt.Fprintf(w, "  draft now on %s\n", shortID(out.NewCheckpoints[len(out.NewCheckpoints)-1], 7))
+}
@@ -11,7 +11,14 @@ import (
 type ReviewOutput struct {
 	Review      *ReviewSummary      `json:"review,omitempty"`
 	Suggestions []client.Suggestion `json:"suggestions,omitempty"`
-	NextActions []NextAction        `json:"next_actions,omitempty"`
+	Findings    []client.Finding    `json:"findings,omitempty"`
+	// ReviewedFrom is set when only the changes since that checkpoint were
+	// reviewed.
+	ReviewedFrom string              `json:"reviewed_from,omitempty"`
+	CarriedOver  []client.Suggestion `json:"carried_over,omitempty"`
+	Regenerated  string              `json:"regenerated,omitempty"`
+	Warnings     []string            `json:"warnings,omitempty"`
+	NextActions  []NextAction        `json:"next_actions,omitempty"`
 }
 
 type ReviewSummary struct {
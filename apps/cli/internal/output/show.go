@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/lydakis/jul/cli/internal/client"
@@ -20,6 +21,16 @@ type ShowResult struct {
 	AttestationInheritedFrom string              `json:"attestation_inherited_from,omitempty"`
 	Suggestion               *client.Suggestion  `json:"suggestion,omitempty"`
 	DiffStat                 string              `json:"diffstat,omitempty"`
+	Findings                 []client.Finding    `json:"findings,omitempty"`
+	Hunks                    []ShowHunk          `json:"hunks,omitempty"`
+}
+
+// ShowHunk is one numbered hunk of a suggestion, as selected by
+// "jul apply <id> --hunks".
+type ShowHunk struct {
+	Index int    `json:"index"`
+	Path  string `json:"path"`
+	Patch string `json:"patch"`
 }
 
 func RenderShow(w io.Writer, payload ShowResult) {
@@ -33,10 +44,20 @@ func RenderShow(w io.Writer, payload ShowResult) {
 		}
 		fmt.Fprintf(w, "Base: %s\n", payload.Suggestion.BaseCommitSHA)
 		fmt.Fprintf(w, "Suggested: %s\n", payload.Suggestion.SuggestedCommitSHA)
+		if len(payload.Suggestion.AppliedHunks) > 0 {
+			fmt.Fprintf(w, "Applied hunks: %s\n", joinInts(payload.Suggestion.AppliedHunks))
+		}
 		if payload.DiffStat != "" {
 			fmt.Fprintln(w, "\nFiles changed:")
 			fmt.Fprintln(w, payload.DiffStat)
 		}
+		if len(payload.Hunks) > 0 {
+			fmt.Fprintln(w, "\nHunks:")
+			for _, hunk := range payload.Hunks {
+				fmt.Fprintf(w, "[%d] %s\n", hunk.Index, hunk.Path)
+				fmt.Fprint(w, hunk.Patch)
+			}
+		}
 		return
 	}
 
@@ -66,6 +87,49 @@ func RenderShow(w io.Writer, payload ShowResult) {
 	}
 	if payload.DiffStat != "" {
 		fmt.Fprintln(w, "\nFiles changed:")
-		fmt.Fprintln(w, payload.DiffStat)
+		renderDiffStatFindings(w, payload.DiffStat, payload.Findings)
+	} else if len(payload.Findings) > 0 {
+		renderDiffStatFindings(w, "", payload.Findings)
+	}
+}
+
+// renderDiffStatFindings lists each file's findings under its diffstat line
+// and collects findings on files outside the diffstat at the end.
+func renderDiffStatFindings(w io.Writer, diffstat string, findings []client.Finding) {
+	printed := make([]bool, len(findings))
+	if diffstat != "" {
+		for _, line := range strings.Split(diffstat, "\n") {
+			fmt.Fprintln(w, line)
+			path, _, ok := strings.Cut(line, "|")
+			if !ok {
+				continue
+			}
+			path = strings.TrimSpace(path)
+			for i, f := range findings {
+				if !printed[i] && f.Path == path {
+					printed[i] = true
+					fmt.Fprintf(w, "    >> %s\n", FindingLine(f))
+				}
+			}
+		}
+	}
+	header := false
+	for i, f := range findings {
+		if printed[i] {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "\nFindings:")
+			header = true
+		}
+		fmt.Fprintf(w, "  %s\n", FindingLine(f))
+	}
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
 	}
+	return strings.Join(parts, ",")
 }
@@ -70,6 +70,8 @@ type CheckpointSummary struct {
 	CIStale            bool   `json:"ci_stale,omitempty"`
 	CIInheritedFrom    string `json:"ci_inherited_from,omitempty"`
 	SuggestionsPending int    `json:"suggestions_pending,omitempty"`
+	Findings           int    `json:"findings,omitempty"`
+	FindingsWorst      string `json:"findings_worst,omitempty"`
 }
 
 type PromoteStatus struct {
@@ -166,6 +168,13 @@ func RenderStatus(w io.Writer, status Status, opts Options) {
 				}
 				fmt.Fprintf(w, "    └─ %s%d suggestion pending\n", warn, cp.SuggestionsPending)
 			}
+			if cp.Findings > 0 {
+				warn := statusIconColored("warning", opts)
+				if warn == "" {
+					warn = statusIcon("warning", opts)
+				}
+				fmt.Fprintf(w, "    └─ %s%d review finding(s), worst %s\n", warn, cp.Findings, cp.FindingsWorst)
+			}
 		}
 		fmt.Fprintln(w, "")
 	}
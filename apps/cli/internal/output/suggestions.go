@@ -49,7 +49,11 @@ func RenderSuggestions(w io.Writer, view SuggestionsView, opts Options) {
 		if stale {
 			staleMark = strings.TrimSpace(warnMark) + " stale"
 		}
-		fmt.Fprintf(w, "[%s] %s %s %s\n", sug.SuggestionID, sug.Reason, confidence, staleMark)
+		profiles := ""
+		if len(sug.Profiles) > 0 {
+			profiles = " {" + strings.Join(sug.Profiles, ", ") + "}"
+		}
+		fmt.Fprintf(w, "[%s] %s%s %s %s\n", sug.SuggestionID, sug.Reason, profiles, confidence, staleMark)
 		if stale && view.CheckpointSHA != "" {
 			fmt.Fprintf(w, "             Created for %s, current is %s\n", sug.BaseCommitSHA, view.CheckpointSHA)
 		} else if sug.BaseCommitSHA != "" {
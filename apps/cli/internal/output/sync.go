@@ -35,7 +35,7 @@ func RenderSync(w io.Writer, res syncer.Result, opts Options) {
 		fmt.Fprintf(w, "  %sBase fast-forwarded (clean)\n", ok)
 	}
 	if res.BaseAdvanced {
-		fmt.Fprintf(w, "  %sBase advanced — run 'jul ws restack' when ready\n", warn)
+		fmt.Fprintf(w, "  %sBase advanced — run 'jul transplant' or 'jul ws restack' when ready\n", warn)
 	}
 	if res.Diverged {
 		fmt.Fprintf(w, "  %sWorkspace lease mismatch — run 'jul ws checkout'\n", warn)
@@ -0,0 +1,41 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lydakis/jul/cli/internal/syncer"
+)
+
+func RenderTransplant(w io.Writer, res syncer.TransplantResult, opts Options) {
+	ok := statusIconColored("pass", opts)
+	if ok == "" {
+		ok = statusIcon("pass", opts)
+	}
+	warn := statusIconColored("warning", opts)
+	if warn == "" {
+		warn = statusIcon("warning", opts)
+	}
+	switch res.Status {
+	case "up_to_date":
+		fmt.Fprintf(w, "%s Draft already on the workspace tip.\n", ok)
+		return
+	case "conflicts":
+		fmt.Fprintf(w, "Rebasing draft from %s onto %s...\n", shortID(res.OldBase, 6), shortID(res.NewBase, 6))
+		for _, file := range res.Conflicts {
+			fmt.Fprintf(w, "  %sConflicts in %s\n", warn, file)
+		}
+		if len(res.Conflicts) == 0 {
+			fmt.Fprintf(w, "  %sConflicts detected\n", warn)
+		}
+		fmt.Fprintln(w, "\nRun 'jul merge' to resolve.")
+		return
+	}
+	fmt.Fprintf(w, "Rebasing draft from %s onto %s...\n", shortID(res.OldBase, 6), shortID(res.NewBase, 6))
+	fmt.Fprintf(w, "  %sDraft transplanted (%s)\n", ok, res.DraftSHA)
+	if res.RemotePushed {
+		fmt.Fprintf(w, "  %sSync ref pushed (%s)\n", ok, res.SyncRef)
+	} else if res.RemoteProblem != "" {
+		fmt.Fprintf(w, "  %s%s\n", warn, res.RemoteProblem)
+	}
+}
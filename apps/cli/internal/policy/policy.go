@@ -13,6 +13,8 @@ type PromotePolicy struct {
 	Strategy                    string
 	RequiredChecks              []string
 	RequireSuggestionsAddressed *bool
+	// MaxSeverity is the worst review finding severity promote allows.
+	MaxSeverity string
 }
 
 func LoadPromotePolicy(repoRoot, target string) (PromotePolicy, bool, error) {
@@ -75,9 +77,52 @@ func applyPromoteSection(parsed map[string]string, section string, policy *Promo
 			updated = true
 		}
 	}
+	if val, ok := parsed[section+".max_severity"]; ok {
+		if SeverityRank(val) >= 0 {
+			policy.MaxSeverity = NormalizeSeverity(val)
+			updated = true
+		}
+	}
 	return updated
 }
 
+// Severities lists review finding severities from least to most severe.
+var Severities = []string{"info", "low", "medium", "high", "critical"}
+
+// SeverityRank returns the position of severity in Severities, or -1 when it
+// is not a known severity.
+func SeverityRank(severity string) int {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	for i, known := range Severities {
+		if severity == known {
+			return i
+		}
+	}
+	return -1
+}
+
+// NormalizeSeverity maps agent-reported severities onto Severities, treating
+// anything unrecognized as medium.
+func NormalizeSeverity(severity string) string {
+	severity = strings.ToLower(strings.TrimSpace(severity))
+	switch severity {
+	case "note", "nit", "suggestion":
+		return "info"
+	case "minor":
+		return "low"
+	case "warning", "warn", "moderate":
+		return "medium"
+	case "error", "major":
+		return "high"
+	case "blocker", "fatal":
+		return "critical"
+	}
+	if SeverityRank(severity) >= 0 {
+		return severity
+	}
+	return "medium"
+}
+
 func parseStringList(raw string) []string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
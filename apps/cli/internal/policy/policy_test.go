@@ -21,6 +21,7 @@ strategy = "rebase"  # rebase | squash | merge
 min_coverage_pct = 92.5 # coverage target
 require_suggestions_addressed = false # warn only
 required_checks = ["ci", "lint"] # checks list
+max_severity = "Medium" # review findings gate
 `
 	if err := os.WriteFile(filepath.Join(policyDir, "policy.toml"), []byte(policy), 0o644); err != nil {
 		t.Fatalf("write policy file: %v", err)
@@ -45,4 +46,7 @@ required_checks = ["ci", "lint"] # checks list
 	if len(parsed.RequiredChecks) != 2 || parsed.RequiredChecks[0] != "ci" || parsed.RequiredChecks[1] != "lint" {
 		t.Fatalf("unexpected required checks: %#v", parsed.RequiredChecks)
 	}
+	if parsed.MaxSeverity != "medium" {
+		t.Fatalf("expected max severity medium, got %q", parsed.MaxSeverity)
+	}
 }
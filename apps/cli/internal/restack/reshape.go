@@ -0,0 +1,414 @@
+package restack
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/metadata"
+)
+
+// ReshapeOptions selects the workspace whose current change is reshaped.
+type ReshapeOptions struct {
+	RepoRoot  string
+	User      string
+	Workspace string
+	// Message replaces the squashed message, or names the first half of a
+	// split.
+	Message string
+}
+
+type ReshapeResult struct {
+	ChangeID       string
+	OldCheckpoints []string
+	NewCheckpoints []string
+	NewDraftSHA    string
+	// Rewritten lists the old checkpoints that were replaced.
+	Rewritten []string
+}
+
+// rewrite is one checkpoint of the new chain: its tree, message without trace
+// trailers, the old checkpoints it stands in for and the trace head it carries
+// over (empty for a new split half).
+type rewrite struct {
+	tree      string
+	message   string
+	sources   []string
+	traceHead string
+}
+
+type reshapeChain struct {
+	repoRoot  string
+	user      string
+	workspace string
+	deviceID  string
+	changeID  string
+	chain     []string
+	draftSHA  string
+}
+
+// Squash folds the checkpoints from..to (inclusive) of the current change into
+// one checkpoint holding to's tree. Later checkpoints are re-parented onto it
+// with their trees unchanged.
+func Squash(opts ReshapeOptions, from, to string) (ReshapeResult, error) {
+	rc, err := loadReshapeChain(opts)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	start, err := rc.index(from)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	end, err := rc.index(to)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	if start >= end {
+		return ReshapeResult{}, fmt.Errorf("%s must be an earlier checkpoint than %s", from, to)
+	}
+
+	message := strings.TrimSpace(opts.Message)
+	if message == "" {
+		parts := make([]string, 0, end-start+1)
+		for _, sha := range rc.chain[start : end+1] {
+			msg, _ := gitutil.CommitMessage(sha)
+			if body := stripReshapeTrailers(msg); body != "" {
+				parts = append(parts, body)
+			}
+		}
+		message = strings.Join(parts, "\n\n")
+	}
+	tree, err := gitutil.TreeOf(rc.chain[end])
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	oldHead := gitutil.ExtractTraceHead(mustMessage(rc.chain[end]))
+	plan := []rewrite{{tree: tree, message: message, sources: rc.chain[start : end+1], traceHead: oldHead}}
+	plan = append(plan, rc.unchanged(end+1)...)
+	return rc.apply(start, plan)
+}
+
+// Split turns checkpoint sha into two: first one holding only its changes to
+// paths, then the checkpoint itself on top.
+func Split(opts ReshapeOptions, sha string, paths []string) (ReshapeResult, error) {
+	if len(paths) == 0 {
+		return ReshapeResult{}, fmt.Errorf("paths required for split")
+	}
+	rc, err := loadReshapeChain(opts)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	idx, err := rc.index(sha)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	target := rc.chain[idx]
+	parent, err := gitutil.ParentOf(target)
+	if err != nil || strings.TrimSpace(parent) == "" {
+		return ReshapeResult{}, fmt.Errorf("checkpoint %s has no parent to split against", shortSHA(target))
+	}
+	parent = strings.TrimSpace(parent)
+	firstTree, err := splitTree(rc.repoRoot, parent, target, paths)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	parentTree, _ := gitutil.TreeOf(parent)
+	targetTree, err := gitutil.TreeOf(target)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	if firstTree == parentTree {
+		return ReshapeResult{}, fmt.Errorf("checkpoint %s does not change %s", shortSHA(target), strings.Join(paths, ", "))
+	}
+	if firstTree == targetTree {
+		return ReshapeResult{}, fmt.Errorf("%s cover every change in checkpoint %s", strings.Join(paths, ", "), shortSHA(target))
+	}
+
+	targetMsg := mustMessage(target)
+	firstMessage := strings.TrimSpace(opts.Message)
+	if firstMessage == "" {
+		subject := firstLine(stripReshapeTrailers(targetMsg))
+		firstMessage = fmt.Sprintf("%s (%s)", subject, strings.Join(paths, ", "))
+	}
+	plan := []rewrite{
+		{tree: firstTree, message: firstMessage, sources: []string{target}},
+		{tree: targetTree, message: stripReshapeTrailers(targetMsg), sources: []string{target}, traceHead: gitutil.ExtractTraceHead(targetMsg)},
+	}
+	plan = append(plan, rc.unchanged(idx+1)...)
+	return rc.apply(idx, plan)
+}
+
+func loadReshapeChain(opts ReshapeOptions) (*reshapeChain, error) {
+	repoRoot := strings.TrimSpace(opts.RepoRoot)
+	if repoRoot == "" {
+		var err error
+		repoRoot, err = gitutil.RepoTopLevel()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := ReadState(repoRoot); ok {
+		return nil, ErrInProgress
+	}
+	workspace := strings.TrimSpace(opts.Workspace)
+	if workspace == "" {
+		workspace = "@"
+	}
+	user := strings.TrimSpace(opts.User)
+	if user == "" {
+		return nil, fmt.Errorf("user required")
+	}
+	deviceID, err := config.DeviceID()
+	if err != nil {
+		return nil, err
+	}
+	syncRef := fmt.Sprintf("refs/jul/sync/%s/%s/%s", user, deviceID, workspace)
+	draftSHA, err := gitutil.ResolveRef(syncRef)
+	if err != nil || strings.TrimSpace(draftSHA) == "" {
+		return nil, fmt.Errorf("draft not found; run 'jul sync' first")
+	}
+	draftSHA = strings.TrimSpace(draftSHA)
+	changeID := gitutil.ExtractChangeID(mustMessage(draftSHA))
+	if changeID == "" {
+		return nil, fmt.Errorf("draft has no Change-Id")
+	}
+	latest, err := latestCheckpointForChange(user, workspace, changeID)
+	if err != nil {
+		return nil, err
+	}
+	if latest == "" {
+		return nil, fmt.Errorf("no checkpoints for change %s", changeID)
+	}
+	workspaceTip, err := gitutil.ResolveRef(fmt.Sprintf("refs/jul/workspaces/%s/%s", user, workspace))
+	if err != nil || strings.TrimSpace(workspaceTip) != latest {
+		return nil, fmt.Errorf("workspace tip is not the latest checkpoint of %s; run 'jul sync' first", changeID)
+	}
+	chain, err := checkpointChain(latest, changeID)
+	if err != nil {
+		return nil, err
+	}
+	return &reshapeChain{
+		repoRoot:  repoRoot,
+		user:      user,
+		workspace: workspace,
+		deviceID:  deviceID,
+		changeID:  changeID,
+		chain:     chain,
+		draftSHA:  draftSHA,
+	}, nil
+}
+
+func (rc *reshapeChain) index(rev string) (int, error) {
+	sha, err := gitutil.Git("rev-parse", "--verify", "-q", strings.TrimSpace(rev)+"^{commit}")
+	if err != nil || strings.TrimSpace(sha) == "" {
+		return 0, fmt.Errorf("unknown checkpoint %s", rev)
+	}
+	sha = strings.TrimSpace(sha)
+	for i, candidate := range rc.chain {
+		if candidate == sha {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%s is not a checkpoint of change %s", rev, rc.changeID)
+}
+
+// unchanged carries the checkpoints from index on over as they are.
+func (rc *reshapeChain) unchanged(index int) []rewrite {
+	var plan []rewrite
+	for _, sha := range rc.chain[index:] {
+		msg := mustMessage(sha)
+		tree, _ := gitutil.TreeOf(sha)
+		plan = append(plan, rewrite{
+			tree:      tree,
+			message:   stripReshapeTrailers(msg),
+			sources:   []string{sha},
+			traceHead: gitutil.ExtractTraceHead(msg),
+		})
+	}
+	return plan
+}
+
+// apply commits plan in place of the checkpoints from start on, emitting a
+// restack trace per checkpoint, and moves the change, workspace, keep refs,
+// draft and change metadata onto the new chain.
+func (rc *reshapeChain) apply(start int, plan []rewrite) (ReshapeResult, error) {
+	parent := ""
+	if start > 0 {
+		parent = rc.chain[start-1]
+	} else if p, err := gitutil.ParentOf(rc.chain[0]); err == nil {
+		parent = strings.TrimSpace(p)
+	}
+	prevTrace := strings.TrimSpace(gitutil.ExtractTraceBase(mustMessage(rc.chain[start])))
+	if start > 0 {
+		prevTrace = strings.TrimSpace(gitutil.ExtractTraceHead(mustMessage(parent)))
+	}
+
+	lastAttested := ""
+	newChain := append([]string{}, rc.chain[:start]...)
+	for _, step := range plan {
+		traceParents := []string{}
+		if prevTrace != "" {
+			traceParents = append(traceParents, prevTrace)
+		}
+		if head := strings.TrimSpace(step.traceHead); head != "" && head != prevTrace {
+			traceParents = append(traceParents, head)
+		}
+		traceSHA, err := createRestackTrace(step.tree, traceParents, rc.deviceID)
+		if err != nil {
+			return ReshapeResult{}, fmt.Errorf("failed to create restack trace: %v", err)
+		}
+		msg := addTrailer(step.message, "Change-Id", rc.changeID)
+		msg = addTrailer(msg, "Trace-Base", prevTrace)
+		msg = addTrailer(msg, "Trace-Head", traceSHA)
+		sha, err := gitutil.CommitTreeWithParents(step.tree, []string{parent}, msg)
+		if err != nil {
+			return ReshapeResult{}, err
+		}
+		for _, source := range step.sources {
+			if from := attestedSource(source); from != "" {
+				lastAttested = from
+			}
+		}
+		if lastAttested != "" {
+			_ = metadata.WriteAttestationInheritance(sha, lastAttested)
+		}
+		newChain = append(newChain, sha)
+		parent = sha
+		prevTrace = traceSHA
+	}
+	newTip := newChain[len(newChain)-1]
+
+	keepPrefix := keepRefPrefix(rc.user, rc.workspace) + rc.changeID + "/"
+	updates := []gitutil.RefUpdate{}
+	for _, sha := range rc.chain[start:] {
+		updates = append(updates, gitutil.RefUpdate{Ref: keepPrefix + sha, Delete: true})
+	}
+	for _, sha := range newChain[start:] {
+		updates = append(updates, gitutil.RefUpdate{Ref: keepPrefix + sha, SHA: sha})
+	}
+	updates = append(updates,
+		gitutil.RefUpdate{Ref: fmt.Sprintf("refs/jul/changes/%s", rc.changeID), SHA: newTip},
+		gitutil.RefUpdate{Ref: fmt.Sprintf("refs/jul/workspaces/%s/%s", rc.user, rc.workspace), SHA: newTip},
+	)
+	if start == 0 {
+		updates = append(updates, gitutil.RefUpdate{Ref: fmt.Sprintf("refs/jul/anchors/%s", rc.changeID), SHA: newChain[0]})
+	}
+
+	draftTree, err := gitutil.TreeOf(rc.draftSHA)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	newDraft, err := gitutil.CreateDraftCommitFromTree(draftTree, newTip, rc.changeID)
+	if err != nil {
+		return ReshapeResult{}, err
+	}
+	updates = append(updates, gitutil.RefUpdate{Ref: fmt.Sprintf("refs/jul/sync/%s/%s/%s", rc.user, rc.deviceID, rc.workspace), SHA: newDraft})
+	if err := gitutil.UpdateRefs(updates); err != nil {
+		return ReshapeResult{}, err
+	}
+	if err := writeWorkspaceLease(rc.repoRoot, rc.workspace, newTip); err != nil {
+		return ReshapeResult{}, err
+	}
+	// The new tip has the old tip's tree, so the working tree stays as is.
+	if err := ensureWorkspaceHead(rc.repoRoot, rc.workspace, newTip); err != nil {
+		return ReshapeResult{}, err
+	}
+	if err := rc.writeChangeMeta(newChain); err != nil {
+		return ReshapeResult{}, err
+	}
+	return ReshapeResult{
+		ChangeID:       rc.changeID,
+		OldCheckpoints: rc.chain,
+		NewCheckpoints: newChain,
+		NewDraftSHA:    newDraft,
+		Rewritten:      rc.chain[start:],
+	}, nil
+}
+
+// writeChangeMeta records the new checkpoint list, keyed by the (possibly
+// new) anchor, keeping promote history.
+func (rc *reshapeChain) writeChangeMeta(chain []string) error {
+	meta, ok, err := metadata.ReadChangeMeta(rc.chain[0])
+	if err != nil {
+		return err
+	}
+	if !ok {
+		meta = metadata.ChangeMeta{}
+	}
+	meta.ChangeID = rc.changeID
+	meta.AnchorSHA = chain[0]
+	meta.Checkpoints = make([]metadata.ChangeCheckpoint, 0, len(chain))
+	for _, sha := range chain {
+		meta.Checkpoints = append(meta.Checkpoints, metadata.ChangeCheckpoint{
+			SHA:     sha,
+			Message: firstLine(mustMessage(sha)),
+		})
+	}
+	return metadata.WriteChangeMeta(meta)
+}
+
+// attestedSource returns the checkpoint whose attestation sha carries: sha
+// itself when attested, or the checkpoint it already inherits from.
+func attestedSource(sha string) string {
+	att, _ := metadata.GetAttestation(sha)
+	if att == nil {
+		return ""
+	}
+	if strings.TrimSpace(att.Status) != "" {
+		return sha
+	}
+	if inheritFrom := strings.TrimSpace(att.AttestationInheritFrom); inheritFrom != "" {
+		if inherited, _ := metadata.GetAttestation(inheritFrom); inherited != nil && strings.TrimSpace(inherited.Status) != "" {
+			return inheritFrom
+		}
+	}
+	return ""
+}
+
+// splitTree returns parent's tree with paths taken from target, removing the
+// ones target deleted.
+func splitTree(repoRoot, parent, target string, paths []string) (string, error) {
+	index, err := os.CreateTemp("", "jul-split-index-")
+	if err != nil {
+		return "", err
+	}
+	indexPath := index.Name()
+	_ = index.Close()
+	_ = os.Remove(indexPath)
+	defer os.Remove(indexPath)
+	env := map[string]string{"GIT_INDEX_FILE": indexPath}
+	if _, err := gitDir(repoRoot, env, "read-tree", parent); err != nil {
+		return "", err
+	}
+	args := append([]string{"restore", "--source=" + target, "--staged", "--"}, paths...)
+	if _, err := gitDir(repoRoot, env, args...); err != nil {
+		return "", err
+	}
+	return gitDir(repoRoot, env, "write-tree")
+}
+
+func stripReshapeTrailers(message string) string {
+	for _, key := range []string{"Change-Id", "Trace-Base", "Trace-Head"} {
+		message = stripTrailer(message, key)
+	}
+	return strings.TrimSpace(message)
+}
+
+func mustMessage(sha string) string {
+	msg, _ := gitutil.CommitMessage(sha)
+	return msg
+}
+
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(line)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
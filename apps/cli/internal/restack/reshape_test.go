@@ -0,0 +1,122 @@
+package restack
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/client"
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/metadata"
+)
+
+func TestSplitAndSquashRewriteChain(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	repo := t.TempDir()
+	runGit(t, repo, nil, "init")
+	runGit(t, repo, nil, "config", "user.name", "Test User")
+	runGit(t, repo, nil, "config", "user.email", "test@example.com")
+	writeFile(t, repo, "base.txt", "base\n")
+	runGit(t, repo, nil, "add", "base.txt")
+	runGit(t, repo, nil, "commit", "-m", "base")
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repo); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	changeID := "I1234567890abcdef1234567890abcdef12345678"
+	keep := keepRefPrefix("tester", "@") + changeID + "/"
+	var chain []string
+	for _, step := range []struct {
+		files []string
+		msg   string
+	}{
+		{[]string{"a.txt"}, "feat: one"},
+		{[]string{"b.txt", "c.txt"}, "feat: two"},
+		{[]string{"d.txt"}, "feat: three"},
+	} {
+		for _, name := range step.files {
+			writeFile(t, repo, name, name+"\n")
+		}
+		runGit(t, repo, nil, append([]string{"add"}, step.files...)...)
+		runGit(t, repo, nil, "commit", "-m", step.msg+"\n\nChange-Id: "+changeID)
+		sha := strings.TrimSpace(runGit(t, repo, nil, "rev-parse", "HEAD"))
+		runGit(t, repo, nil, "update-ref", keep+sha, sha)
+		chain = append(chain, sha)
+	}
+	tip := chain[len(chain)-1]
+	runGit(t, repo, nil, "update-ref", "refs/jul/workspaces/tester/@", tip)
+	runGit(t, repo, nil, "update-ref", "refs/jul/changes/"+changeID, tip)
+	if _, err := metadata.WriteAttestation(client.Attestation{CommitSHA: chain[1], ChangeID: changeID, Type: "checkpoint", Status: "pass"}); err != nil {
+		t.Fatalf("write attestation failed: %v", err)
+	}
+
+	deviceID, err := config.DeviceID()
+	if err != nil {
+		t.Fatalf("device id failed: %v", err)
+	}
+	tipTree := strings.TrimSpace(runGit(t, repo, nil, "rev-parse", tip+"^{tree}"))
+	draft := strings.TrimSpace(runGit(t, repo, nil, "commit-tree", tipTree, "-p", tip, "-m", "[draft] WIP\n\nChange-Id: "+changeID))
+	runGit(t, repo, nil, "update-ref", "refs/jul/sync/tester/"+deviceID+"/@", draft)
+
+	opts := ReshapeOptions{RepoRoot: repo, User: "tester", Workspace: "@"}
+	split, err := Split(opts, chain[1], []string{"c.txt"})
+	if err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if len(split.NewCheckpoints) != 4 || split.NewCheckpoints[0] != chain[0] {
+		t.Fatalf("expected 4 checkpoints keeping the first, got %v", split.NewCheckpoints)
+	}
+	firstHalf := split.NewCheckpoints[1]
+	if files := strings.TrimSpace(runGit(t, repo, nil, "diff", "--name-only", chain[0], firstHalf)); files != "c.txt" {
+		t.Fatalf("expected split checkpoint to hold c.txt, got %q", files)
+	}
+	if !strings.Contains(runGit(t, repo, nil, "log", "-1", "--format=%B", firstHalf), "Trace-Head:") {
+		t.Fatalf("expected restack trace trailer on split checkpoint")
+	}
+	if att, _ := metadata.GetAttestation(firstHalf); att == nil || att.AttestationInheritFrom != chain[1] {
+		t.Fatalf("expected split checkpoint to inherit attestation from %s, got %+v", chain[1], att)
+	}
+	if refs := runGit(t, repo, nil, "for-each-ref", "--format=%(refname)", keep); strings.Contains(refs, chain[1]) || !strings.Contains(refs, firstHalf) {
+		t.Fatalf("expected keep refs to follow the split, got %s", refs)
+	}
+
+	squash, err := Squash(opts, split.NewCheckpoints[0], split.NewCheckpoints[2])
+	if err != nil {
+		t.Fatalf("squash failed: %v", err)
+	}
+	if len(squash.NewCheckpoints) != 2 {
+		t.Fatalf("expected 2 checkpoints after squash, got %v", squash.NewCheckpoints)
+	}
+	newTip := squash.NewCheckpoints[1]
+	if got := strings.TrimSpace(runGit(t, repo, nil, "rev-parse", newTip+"^{tree}")); got != tipTree {
+		t.Fatalf("expected tip tree unchanged, got %s", got)
+	}
+	for _, ref := range []string{"refs/jul/changes/" + changeID, "refs/jul/workspaces/tester/@"} {
+		if got := strings.TrimSpace(runGit(t, repo, nil, "rev-parse", ref)); got != newTip {
+			t.Fatalf("expected %s at %s, got %s", ref, newTip, got)
+		}
+	}
+	if got := strings.TrimSpace(runGit(t, repo, nil, "rev-parse", "refs/jul/anchors/"+changeID)); got != squash.NewCheckpoints[0] {
+		t.Fatalf("expected anchor moved to %s, got %s", squash.NewCheckpoints[0], got)
+	}
+	if got := strings.TrimSpace(runGit(t, repo, nil, "rev-parse", "refs/jul/sync/tester/"+deviceID+"/@^")); got != newTip {
+		t.Fatalf("expected draft re-parented onto %s, got %s", newTip, got)
+	}
+	meta, ok, err := metadata.ReadChangeMeta(squash.NewCheckpoints[0])
+	if err != nil || !ok {
+		t.Fatalf("expected change meta at new anchor, got ok=%v err=%v", ok, err)
+	}
+	if len(meta.Checkpoints) != 2 || meta.Checkpoints[1].SHA != newTip || meta.Checkpoints[1].Message != "feat: three" {
+		t.Fatalf("unexpected change meta checkpoints: %+v", meta.Checkpoints)
+	}
+}
@@ -22,13 +22,20 @@ type Options struct {
 	BaseRef   string
 	BaseTip   string
 	BaseSHA   string
+	// KeepWorktree restacks a workspace that is not checked out: its refs
+	// move but the working tree and HEAD are left alone.
+	KeepWorktree bool
 }
 
 type Result struct {
 	NewDraftSHA    string
 	NewParentSHA   string
+	OldCheckpoints []string
 	NewCheckpoints []string
 	ChangeID       string
+	BaseTip        string
+	// Original is the workspace as it was before the restack.
+	Original wsconfig.Snapshot
 }
 
 func Run(opts Options) (Result, error) {
@@ -54,6 +61,14 @@ func Run(opts Options) (Result, error) {
 	if user == "" {
 		return Result{}, fmt.Errorf("user required for restack")
 	}
+	lease, err := agent.AcquireMergeWorktree(repoRoot, "restack")
+	if err != nil {
+		return Result{}, err
+	}
+	defer lease.Release()
+	if _, ok := ReadState(repoRoot); ok {
+		return Result{}, ErrInProgress
+	}
 
 	baseRef := strings.TrimSpace(opts.BaseRef)
 	baseTip := strings.TrimSpace(opts.BaseTip)
@@ -73,6 +88,10 @@ func Run(opts Options) (Result, error) {
 	syncRef := fmt.Sprintf("refs/jul/sync/%s/%s/%s", user, deviceID, workspace)
 
 	draftSHA, err := gitutil.ResolveRef(syncRef)
+	if err != nil && opts.KeepWorktree {
+		// Workspaces last used on another device have no local draft.
+		draftSHA, err = gitutil.ResolveRef(workspaceRef)
+	}
 	if err != nil {
 		return Result{}, fmt.Errorf("failed to resolve draft: %w", err)
 	}
@@ -106,102 +125,153 @@ func Run(opts Options) (Result, error) {
 		return Result{}, fmt.Errorf("no checkpoints found for change")
 	}
 
-	worktree, err := agent.EnsureWorktree(repoRoot, baseTip, agent.WorktreeOptions{})
+	changeRef := fmt.Sprintf("refs/jul/changes/%s", changeID)
+	anchorRef := fmt.Sprintf("refs/jul/anchors/%s", changeID)
+	original, err := wsconfig.TakeSnapshot(repoRoot, user, workspace, changeRef, anchorRef)
 	if err != nil {
 		return Result{}, err
 	}
 
-	newParent := baseTip
-	prevTrace := ""
-	lastAttested := ""
-	newCheckpoints := make([]string, 0, len(chain))
-	for idx, oldSHA := range chain {
-		if att, _ := metadata.GetAttestation(oldSHA); att != nil {
-			if strings.TrimSpace(att.Status) != "" {
-				lastAttested = oldSHA
-			} else if inheritFrom := strings.TrimSpace(att.AttestationInheritFrom); inheritFrom != "" {
-				if inherited, _ := metadata.GetAttestation(inheritFrom); inherited != nil && strings.TrimSpace(inherited.Status) != "" {
-					lastAttested = inheritFrom
-				}
-			}
-		}
-		if _, err := gitDir(worktree, nil, "cherry-pick", "--no-commit", oldSHA); err != nil {
+	worktree, err := agent.EnsureWorktree(repoRoot, baseTip, agent.WorktreeOptions{Name: agent.MergeWorktree})
+	if err != nil {
+		return Result{}, err
+	}
+
+	baseSHA := strings.TrimSpace(opts.BaseSHA)
+	if baseSHA == "" {
+		baseSHA = baseTip
+	}
+	state := &State{
+		User:         user,
+		Workspace:    workspace,
+		DeviceID:     deviceID,
+		ChangeID:     changeID,
+		BaseRef:      baseRef,
+		BaseTip:      baseTip,
+		BaseSHA:      baseSHA,
+		KeepWorktree: opts.KeepWorktree,
+		Worktree:     worktree,
+		Chain:        chain,
+		NewParent:    baseTip,
+		Original:     original,
+	}
+	return replay(repoRoot, state)
+}
+
+// replay cherry-picks the checkpoints of state.Chain that are not done yet.
+// On a conflict the cherry-pick is left in the agent worktree and the state
+// is saved so Continue can pick up from the conflicted checkpoint.
+func replay(repoRoot string, state *State) (Result, error) {
+	for state.Done < len(state.Chain) {
+		oldSHA := state.Chain[state.Done]
+		if _, err := gitDir(state.Worktree, nil, "cherry-pick", "--no-commit", oldSHA); err != nil {
 			if isEmptyCherryPick(err) {
-				_, _ = gitDir(worktree, nil, "cherry-pick", "--skip")
+				_, _ = gitDir(state.Worktree, nil, "cherry-pick", "--skip")
 			} else {
-				conflicts := restackConflictFiles(worktree)
-				_, _ = gitDir(worktree, nil, "cherry-pick", "--abort")
-				return Result{}, ConflictError{CheckpointSHA: oldSHA, Conflicts: conflicts}
+				state.Conflict = oldSHA
+				state.Conflicts = restackConflictFiles(state.Worktree)
+				if err := writeState(repoRoot, *state); err != nil {
+					return Result{}, err
+				}
+				return Result{}, ConflictError{CheckpointSHA: oldSHA, Conflicts: state.Conflicts, Worktree: state.Worktree}
 			}
 		}
-		treeSHA, err := gitOutputDir(worktree, "write-tree")
-		if err != nil {
-			return Result{}, fmt.Errorf("failed to snapshot restack tree: %v", err)
+		if err := commitCheckpoint(state, oldSHA); err != nil {
+			return Result{}, err
 		}
+	}
+	return finish(repoRoot, state)
+}
 
-		oldMsg, _ := gitutil.CommitMessage(oldSHA)
-		oldTraceBase := strings.TrimSpace(gitutil.ExtractTraceBase(oldMsg))
-		oldTraceHead := strings.TrimSpace(gitutil.ExtractTraceHead(oldMsg))
-		if idx == 0 && prevTrace == "" {
-			prevTrace = oldTraceBase
-		}
+// commitCheckpoint commits the staged replay of oldSHA in the agent worktree
+// as the next restacked checkpoint.
+func commitCheckpoint(state *State, oldSHA string) error {
+	if from := attestedSource(oldSHA); from != "" {
+		state.LastAttested = from
+	}
+	worktree := state.Worktree
+	treeSHA, err := gitOutputDir(worktree, "write-tree")
+	if err != nil {
+		return fmt.Errorf("failed to snapshot restack tree: %v", err)
+	}
 
-		traceParents := []string{}
-		if strings.TrimSpace(prevTrace) != "" {
-			traceParents = append(traceParents, strings.TrimSpace(prevTrace))
-		}
-		if strings.TrimSpace(oldTraceHead) != "" && strings.TrimSpace(oldTraceHead) != strings.TrimSpace(prevTrace) {
-			traceParents = append(traceParents, strings.TrimSpace(oldTraceHead))
-		}
-		traceSHA, err := createRestackTrace(treeSHA, traceParents, deviceID)
-		if err != nil {
-			return Result{}, fmt.Errorf("failed to create restack trace: %v", err)
-		}
+	oldMsg, _ := gitutil.CommitMessage(oldSHA)
+	oldTraceBase := strings.TrimSpace(gitutil.ExtractTraceBase(oldMsg))
+	oldTraceHead := strings.TrimSpace(gitutil.ExtractTraceHead(oldMsg))
+	if state.Done == 0 && state.PrevTrace == "" {
+		state.PrevTrace = oldTraceBase
+	}
+	prevTrace := state.PrevTrace
 
-		newMsg := stripTrailer(stripTrailer(oldMsg, "Trace-Head"), "Trace-Base")
-		if prevTrace != "" {
-			newMsg = addTrailer(newMsg, "Trace-Base", prevTrace)
-		}
-		if traceSHA != "" {
-			newMsg = addTrailer(newMsg, "Trace-Head", traceSHA)
-		}
+	traceParents := []string{}
+	if strings.TrimSpace(prevTrace) != "" {
+		traceParents = append(traceParents, strings.TrimSpace(prevTrace))
+	}
+	if strings.TrimSpace(oldTraceHead) != "" && strings.TrimSpace(oldTraceHead) != strings.TrimSpace(prevTrace) {
+		traceParents = append(traceParents, strings.TrimSpace(oldTraceHead))
+	}
+	traceSHA, err := createRestackTrace(treeSHA, traceParents, state.DeviceID)
+	if err != nil {
+		return fmt.Errorf("failed to create restack trace: %v", err)
+	}
 
-		msgFile, err := os.CreateTemp("", "jul-restack-msg-")
-		if err != nil {
-			return Result{}, fmt.Errorf("failed to create message file: %v", err)
-		}
-		if _, err := msgFile.WriteString(newMsg); err != nil {
-			_ = msgFile.Close()
-			_ = os.Remove(msgFile.Name())
-			return Result{}, fmt.Errorf("failed to write message file: %v", err)
-		}
+	newMsg := stripTrailer(stripTrailer(oldMsg, "Trace-Head"), "Trace-Base")
+	if prevTrace != "" {
+		newMsg = addTrailer(newMsg, "Trace-Base", prevTrace)
+	}
+	if traceSHA != "" {
+		newMsg = addTrailer(newMsg, "Trace-Head", traceSHA)
+	}
+
+	msgFile, err := os.CreateTemp("", "jul-restack-msg-")
+	if err != nil {
+		return fmt.Errorf("failed to create message file: %v", err)
+	}
+	if _, err := msgFile.WriteString(newMsg); err != nil {
 		_ = msgFile.Close()
-		if _, err := gitDir(worktree, nil, "commit", "--no-verify", "--allow-empty", "-F", msgFile.Name()); err != nil {
-			_ = os.Remove(msgFile.Name())
-			return Result{}, fmt.Errorf("failed to create checkpoint: %v", err)
-		}
 		_ = os.Remove(msgFile.Name())
+		return fmt.Errorf("failed to write message file: %v", err)
+	}
+	_ = msgFile.Close()
+	if _, err := gitDir(worktree, nil, "commit", "--no-verify", "--allow-empty", "-F", msgFile.Name()); err != nil {
+		_ = os.Remove(msgFile.Name())
+		return fmt.Errorf("failed to create checkpoint: %v", err)
+	}
+	_ = os.Remove(msgFile.Name())
 
-		newSHA, err := gitOutputDir(worktree, "rev-parse", "HEAD")
-		if err != nil {
-			return Result{}, fmt.Errorf("failed to resolve checkpoint: %v", err)
-		}
-		newSHA = strings.TrimSpace(newSHA)
-		newParent = newSHA
-		newCheckpoints = append(newCheckpoints, newSHA)
-		prevTrace = traceSHA
+	newSHA, err := gitOutputDir(worktree, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve checkpoint: %v", err)
+	}
+	newSHA = strings.TrimSpace(newSHA)
+	state.NewParent = newSHA
+	state.NewCheckpoints = append(state.NewCheckpoints, newSHA)
+	state.PrevTrace = traceSHA
+	state.Done++
+	state.Conflict = ""
+	state.Conflicts = nil
 
-		if lastAttested != "" {
-			_ = metadata.WriteAttestationInheritance(newSHA, lastAttested)
-		}
+	if state.LastAttested != "" {
+		_ = metadata.WriteAttestationInheritance(newSHA, state.LastAttested)
+	}
 
-		keepRef := keepRefPrefix(user, workspace) + changeID + "/" + newSHA
-		if err := gitutil.UpdateRef(keepRef, newSHA); err != nil {
-			return Result{}, fmt.Errorf("failed to update keep-ref: %v", err)
-		}
+	keepRef := keepRefPrefix(state.User, state.Workspace) + state.ChangeID + "/" + newSHA
+	if err := gitutil.UpdateRef(keepRef, newSHA); err != nil {
+		return fmt.Errorf("failed to update keep-ref: %v", err)
 	}
+	return nil
+}
+
+// finish moves the workspace onto the replayed checkpoints and clears the
+// saved state.
+func finish(repoRoot string, state *State) (Result, error) {
+	user, workspace, changeID := state.User, state.Workspace, state.ChangeID
+	newParent := state.NewParent
+	newCheckpoints := state.NewCheckpoints
+	workspaceRef := fmt.Sprintf("refs/jul/workspaces/%s/%s", user, workspace)
+	syncRef := fmt.Sprintf("refs/jul/sync/%s/%s/%s", user, state.DeviceID, workspace)
 
-	newDraft, err := gitutil.CreateDraftCommit(newParent, changeID)
+	newDraft, err := restackedDraft(state)
 	if err != nil {
 		return Result{}, fmt.Errorf("failed to create new draft: %v", err)
 	}
@@ -225,39 +295,66 @@ func Run(opts Options) (Result, error) {
 	if err := writeWorkspaceLease(repoRoot, workspace, newParent); err != nil {
 		return Result{}, err
 	}
-	if err := ensureWorkspaceHead(repoRoot, workspace, newParent); err != nil {
+	if state.KeepWorktree {
+		if err := gitutil.UpdateRef(workspaceHeadRef(workspace), newParent); err != nil {
+			return Result{}, err
+		}
+	} else if err := ensureWorkspaceHead(repoRoot, workspace, newParent); err != nil {
 		return Result{}, err
 	}
-	baseSHA := strings.TrimSpace(opts.BaseSHA)
-	if baseSHA == "" {
-		baseSHA = baseTip
-	}
 	trackRef := ""
 	trackTip := ""
-	if strings.HasPrefix(baseRef, "refs/heads/") {
-		trackRef = baseRef
-		trackTip = baseTip
+	if strings.HasPrefix(state.BaseRef, "refs/heads/") {
+		trackRef = state.BaseRef
+		trackTip = state.BaseTip
 	}
 	if err := wsconfig.WriteConfig(repoRoot, workspace, wsconfig.Config{
-		BaseRef:  baseRef,
-		BaseSHA:  baseSHA,
+		BaseRef:  state.BaseRef,
+		BaseSHA:  state.BaseSHA,
 		TrackRef: trackRef,
 		TrackTip: trackTip,
 	}); err != nil {
 		return Result{}, err
 	}
-	if err := updateWorktree(repoRoot, newDraft); err != nil {
-		return Result{}, err
+	if !state.KeepWorktree {
+		if err := updateWorktree(repoRoot, newDraft); err != nil {
+			return Result{}, err
+		}
 	}
+	clearState(repoRoot)
 
 	return Result{
 		NewDraftSHA:    newDraft,
 		NewParentSHA:   newParent,
+		OldCheckpoints: state.Chain,
 		NewCheckpoints: newCheckpoints,
 		ChangeID:       changeID,
+		BaseTip:        state.BaseTip,
+		Original:       state.Original,
 	}, nil
 }
 
+// restackedDraft carries the working tree over as the new draft. A draft with
+// no edits on top of the old checkpoint takes the restacked tree instead, so
+// base changes and resolved conflicts are not reverted by the stale files.
+func restackedDraft(state *State) (string, error) {
+	draftTree, err := gitutil.DraftTree()
+	if err != nil {
+		return "", err
+	}
+	if len(state.Chain) > 0 {
+		oldTree, err := gitutil.TreeOf(state.Chain[len(state.Chain)-1])
+		if err == nil && strings.TrimSpace(oldTree) == strings.TrimSpace(draftTree) {
+			newTree, err := gitutil.TreeOf(state.NewParent)
+			if err != nil {
+				return "", err
+			}
+			return gitutil.CreateDraftCommitFromTree(newTree, state.NewParent, state.ChangeID)
+		}
+	}
+	return gitutil.CreateDraftCommitFromTree(draftTree, state.NewParent, state.ChangeID)
+}
+
 type keepRefInfo struct {
 	Ref           string
 	SHA           string
@@ -474,8 +571,11 @@ func writeWorkspaceLease(repoRoot, workspace, sha string) error {
 }
 
 func ensureWorkspaceHead(repoRoot, workspace, sha string) error {
-	ref := fmt.Sprintf("refs/heads/jul/%s", workspace)
-	return gitutil.EnsureHeadRef(repoRoot, ref, sha)
+	return gitutil.EnsureHeadRef(repoRoot, workspaceHeadRef(workspace), sha)
+}
+
+func workspaceHeadRef(workspace string) string {
+	return fmt.Sprintf("refs/heads/jul/%s", workspace)
 }
 
 func isEmptyCherryPick(err error) bool {
@@ -501,6 +601,8 @@ func isEmptyCherryPick(err error) bool {
 type ConflictError struct {
 	CheckpointSHA string
 	Conflicts     []string
+	// Worktree is where the conflicted cherry-pick was left for resolving.
+	Worktree string
 }
 
 func (e ConflictError) Error() string {
@@ -0,0 +1,340 @@
+package restack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	wsconfig "github.com/lydakis/jul/cli/internal/workspace"
+)
+
+const (
+	LayerRestacked = "restacked"
+	LayerUpToDate  = "up_to_date"
+	LayerSkipped   = "skipped"
+	LayerConflict  = "conflict"
+	LayerPending   = "pending"
+)
+
+// Layer reports what a stack restack did to one workspace.
+type Layer struct {
+	Workspace      string   `json:"workspace_id"`
+	BaseRef        string   `json:"base_ref,omitempty"`
+	BaseTip        string   `json:"base_tip,omitempty"`
+	ChangeID       string   `json:"change_id,omitempty"`
+	Status         string   `json:"status"`
+	Reason         string   `json:"reason,omitempty"`
+	OldCheckpoints []string `json:"old_checkpoints,omitempty"`
+	NewCheckpoints []string `json:"new_checkpoints,omitempty"`
+	Conflicts      []string `json:"conflicts,omitempty"`
+}
+
+type StackOptions struct {
+	RepoRoot string `json:"-"`
+	User     string `json:"user"`
+	// Workspace is the lowest layer to restack onto BaseRef/BaseTip; every
+	// workspace stacked on it follows onto its parent's new tip.
+	Workspace string `json:"workspace"`
+	// Current is the checked-out workspace. Only it has its working tree
+	// updated; other layers are restacked in place.
+	Current string `json:"current"`
+	BaseRef string `json:"base_ref"`
+	BaseTip string `json:"base_tip"`
+}
+
+type StackResult struct {
+	Layers []Layer `json:"layers"`
+}
+
+// stackPlan is an interrupted stack restack: the options to resume it with,
+// the layers reported so far and how the finished layers looked before they
+// were restacked.
+type stackPlan struct {
+	Options   StackOptions        `json:"options"`
+	Layers    []Layer             `json:"layers"`
+	Originals []wsconfig.Snapshot `json:"originals,omitempty"`
+}
+
+// RunStack restacks opts.Workspace and its descendants, parents first. It
+// stops at the first conflict and records the finished layers under
+// .jul/restack/stack.json, so ContinueStack, or running it again after the
+// conflict is resolved, skips the layers already on their new base and
+// reports the whole stack.
+func RunStack(opts StackOptions) (StackResult, error) {
+	repoRoot := strings.TrimSpace(opts.RepoRoot)
+	if repoRoot == "" {
+		var err error
+		repoRoot, err = gitutil.RepoTopLevel()
+		if err != nil {
+			return StackResult{}, err
+		}
+	}
+	user := strings.TrimSpace(opts.User)
+	root := strings.TrimSpace(opts.Workspace)
+	if root == "" {
+		root = "@"
+	}
+	if _, ok := ReadState(repoRoot); ok {
+		return StackResult{}, ErrInProgress
+	}
+
+	stack, err := wsconfig.LoadStack(repoRoot, user)
+	if err != nil {
+		return StackResult{}, err
+	}
+	order := append([]string{root}, stack.Descendants(root)...)
+	plan, _ := readStackPlan(repoRoot)
+	originals := plan.Originals
+	previous := map[string]Layer{}
+	for _, layer := range plan.Layers {
+		previous[layer.Workspace] = layer
+	}
+
+	result := StackResult{Layers: make([]Layer, 0, len(order))}
+	// Resuming from a layer higher up the stack: keep reporting the layers
+	// below it that the interrupted run already restacked.
+	inOrder := map[string]bool{}
+	for _, name := range order {
+		inOrder[user+"/"+name] = true
+	}
+	for _, layer := range plan.Layers {
+		if layer.Status == LayerRestacked && !inOrder[layer.Workspace] {
+			result.Layers = append(result.Layers, layer)
+		}
+	}
+	for idx, name := range order {
+		layer := Layer{Workspace: user + "/" + name, Status: LayerPending}
+		node := stack.Nodes[name]
+		if node != nil {
+			layer.ChangeID = node.ChangeID
+		}
+		if idx == 0 {
+			layer.BaseRef = strings.TrimSpace(opts.BaseRef)
+			layer.BaseTip = strings.TrimSpace(opts.BaseTip)
+		} else {
+			layer.BaseRef = strings.TrimSpace(node.Config.BaseRef)
+			tip, err := ResolveBaseTip(repoRoot, layer.BaseRef)
+			if err != nil {
+				layer.Status = LayerSkipped
+				layer.Reason = fmt.Sprintf("failed to resolve base: %v", err)
+				result.Layers = append(result.Layers, layer)
+				continue
+			}
+			layer.BaseTip = tip
+		}
+
+		if node != nil && layer.BaseRef == strings.TrimSpace(node.Config.BaseRef) {
+			onBase, checkpoints := layerOnBase(user, *node, layer.BaseTip)
+			if len(checkpoints) == 0 {
+				layer.Status = LayerSkipped
+				layer.Reason = "no checkpoints"
+				result.Layers = append(result.Layers, layer)
+				continue
+			}
+			if onBase {
+				if prev, ok := previous[layer.Workspace]; ok && prev.Status == LayerRestacked {
+					layer = prev
+				} else {
+					layer.Status = LayerUpToDate
+				}
+				result.Layers = append(result.Layers, layer)
+				continue
+			}
+		}
+
+		res, err := Run(Options{
+			RepoRoot:     repoRoot,
+			User:         user,
+			Workspace:    name,
+			BaseRef:      layer.BaseRef,
+			BaseTip:      layer.BaseTip,
+			KeepWorktree: name != strings.TrimSpace(opts.Current),
+		})
+		if err != nil {
+			var conflict ConflictError
+			if !errors.As(err, &conflict) {
+				return result, fmt.Errorf("restack %s failed: %w", layer.Workspace, err)
+			}
+			layer.Status = LayerConflict
+			layer.Conflicts = conflict.Conflicts
+			result.Layers = append(result.Layers, layer)
+			for _, rest := range order[idx+1:] {
+				result.Layers = append(result.Layers, Layer{Workspace: user + "/" + rest, Status: LayerPending})
+			}
+			opts.RepoRoot = repoRoot
+			if err := writeStackPlan(repoRoot, stackPlan{Options: opts, Layers: result.Layers, Originals: originals}); err != nil {
+				return result, err
+			}
+			return result, err
+		}
+		originals = append(originals, res.Original)
+		layer.Status = LayerRestacked
+		layer.ChangeID = res.ChangeID
+		layer.OldCheckpoints = res.OldCheckpoints
+		layer.NewCheckpoints = res.NewCheckpoints
+		result.Layers = append(result.Layers, layer)
+	}
+	clearStackPlan(repoRoot)
+	return result, nil
+}
+
+// ResolveBaseTip returns the commit a workspace based on baseRef should sit
+// on. A workspace ref points at the draft, so its parent checkpoint is used.
+func ResolveBaseTip(repoRoot, baseRef string) (string, error) {
+	if strings.TrimSpace(baseRef) == "" {
+		return "", fmt.Errorf("base ref required")
+	}
+	sha, err := gitutil.Git("-C", repoRoot, "rev-parse", baseRef)
+	if err != nil {
+		return "", err
+	}
+	sha = strings.TrimSpace(sha)
+	if strings.HasPrefix(baseRef, "refs/jul/workspaces/") {
+		parent, err := gitutil.ParentOf(sha)
+		if err == nil && strings.TrimSpace(parent) != "" {
+			return strings.TrimSpace(parent), nil
+		}
+		return "", fmt.Errorf("base workspace has no checkpoint")
+	}
+	if strings.HasPrefix(baseRef, "refs/jul/changes/") {
+		if sha == "" {
+			return "", fmt.Errorf("change ref missing")
+		}
+		return sha, nil
+	}
+	return sha, nil
+}
+
+// layerOnBase reports whether the oldest checkpoint of the workspace's change
+// already sits on baseTip, along with the checkpoint chain it found. The
+// workspace ref is trusted first: after a restack the keep refs still hold
+// the replaced checkpoints.
+func layerOnBase(user string, node wsconfig.Node, baseTip string) (bool, []string) {
+	if node.ChangeID == "" {
+		return false, nil
+	}
+	latest := ""
+	if msg, err := gitutil.CommitMessage(node.Tip); err == nil && gitutil.ExtractChangeID(msg) == node.ChangeID {
+		latest = node.Tip
+	}
+	if latest == "" {
+		found, err := latestCheckpointForChange(user, node.Name, node.ChangeID)
+		if err != nil || found == "" {
+			return false, nil
+		}
+		latest = found
+	}
+	chain, err := checkpointChain(latest, node.ChangeID)
+	if err != nil || len(chain) == 0 {
+		return false, nil
+	}
+	parent, err := gitutil.ParentOf(chain[0])
+	if err != nil {
+		return false, chain
+	}
+	return strings.TrimSpace(parent) == strings.TrimSpace(baseTip), chain
+}
+
+func stackPlanPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".jul", "restack", "stack.json")
+}
+
+// StackInProgress reports whether a stack restack stopped on a conflict.
+func StackInProgress(repoRoot string) bool {
+	_, ok := readStackPlan(repoRoot)
+	return ok
+}
+
+// ContinueStack finishes the conflicted layer with Continue and then restacks
+// the rest of the stack.
+func ContinueStack(repoRoot string) (StackResult, error) {
+	plan, ok := readStackPlan(repoRoot)
+	if !ok {
+		return StackResult{}, ErrNotInProgress
+	}
+	if state, ok := ReadState(repoRoot); ok {
+		name := state.User + "/" + state.Workspace
+		res, err := Continue(repoRoot)
+		if err != nil {
+			var conflict ConflictError
+			if errors.As(err, &conflict) {
+				for i := range plan.Layers {
+					if plan.Layers[i].Workspace == name {
+						plan.Layers[i].Conflicts = conflict.Conflicts
+					}
+				}
+			}
+			return StackResult{Layers: plan.Layers}, err
+		}
+		plan.Originals = append(plan.Originals, res.Original)
+		for i := range plan.Layers {
+			if plan.Layers[i].Workspace != name {
+				continue
+			}
+			plan.Layers[i].Status = LayerRestacked
+			plan.Layers[i].Conflicts = nil
+			plan.Layers[i].ChangeID = res.ChangeID
+			plan.Layers[i].OldCheckpoints = res.OldCheckpoints
+			plan.Layers[i].NewCheckpoints = res.NewCheckpoints
+		}
+		if err := writeStackPlan(repoRoot, plan); err != nil {
+			return StackResult{}, err
+		}
+	}
+	opts := plan.Options
+	opts.RepoRoot = repoRoot
+	return RunStack(opts)
+}
+
+// AbortStack aborts the conflicted layer and puts every layer the stack
+// restack already finished back where it was, newest first.
+func AbortStack(repoRoot string) (StackResult, error) {
+	plan, ok := readStackPlan(repoRoot)
+	if !ok {
+		return StackResult{}, ErrNotInProgress
+	}
+	if _, err := Abort(repoRoot); err != nil && !errors.Is(err, ErrNotInProgress) {
+		return StackResult{}, err
+	}
+	current := strings.TrimSpace(plan.Options.Current)
+	for i := len(plan.Originals) - 1; i >= 0; i-- {
+		snap := plan.Originals[i]
+		if err := restoreSnapshot(repoRoot, snap, snap.Workspace == current); err != nil {
+			return StackResult{}, err
+		}
+	}
+	clearStackPlan(repoRoot)
+	return StackResult{Layers: plan.Layers}, nil
+}
+
+func readStackPlan(repoRoot string) (stackPlan, bool) {
+	data, err := os.ReadFile(stackPlanPath(repoRoot))
+	if err != nil {
+		return stackPlan{}, false
+	}
+	var plan stackPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return stackPlan{}, false
+	}
+	return plan, true
+}
+
+func writeStackPlan(repoRoot string, plan stackPlan) error {
+	path := stackPlanPath(repoRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func clearStackPlan(repoRoot string) {
+	_ = os.Remove(stackPlanPath(repoRoot))
+}
@@ -0,0 +1,198 @@
+package restack
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/agent"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	wsconfig "github.com/lydakis/jul/cli/internal/workspace"
+)
+
+var (
+	ErrInProgress    = errors.New("restack already in progress; run 'jul ws restack --continue' or 'jul ws restack --abort'")
+	ErrNotInProgress = errors.New("no restack in progress")
+)
+
+// State is a restack stopped on a conflict, saved under .jul/restack/state.json.
+// Chain holds every checkpoint being replayed; the first Done of them are
+// already committed as NewCheckpoints and Conflict is the one waiting to be
+// resolved in Worktree.
+type State struct {
+	User           string            `json:"user"`
+	Workspace      string            `json:"workspace"`
+	DeviceID       string            `json:"device_id"`
+	ChangeID       string            `json:"change_id"`
+	BaseRef        string            `json:"base_ref"`
+	BaseTip        string            `json:"base_tip"`
+	BaseSHA        string            `json:"base_sha"`
+	KeepWorktree   bool              `json:"keep_worktree,omitempty"`
+	Worktree       string            `json:"worktree"`
+	Chain          []string          `json:"chain"`
+	Done           int               `json:"done"`
+	NewCheckpoints []string          `json:"new_checkpoints,omitempty"`
+	NewParent      string            `json:"new_parent"`
+	PrevTrace      string            `json:"prev_trace,omitempty"`
+	LastAttested   string            `json:"last_attested,omitempty"`
+	Conflict       string            `json:"conflict,omitempty"`
+	Conflicts      []string          `json:"conflicts,omitempty"`
+	Original       wsconfig.Snapshot `json:"original"`
+}
+
+// Pending returns the checkpoints not replayed yet, including the
+// conflicted one.
+func (s State) Pending() []string {
+	if s.Done >= len(s.Chain) {
+		return nil
+	}
+	return s.Chain[s.Done:]
+}
+
+// Continue commits the resolved conflict and replays the remaining
+// checkpoints. Files still holding conflict markers are reported as a new
+// ConflictError and the state is kept.
+func Continue(repoRoot string) (Result, error) {
+	lease, err := agent.AcquireMergeWorktree(repoRoot, "restack")
+	if err != nil {
+		return Result{}, err
+	}
+	defer lease.Release()
+	state, ok := ReadState(repoRoot)
+	if !ok {
+		return Result{}, ErrNotInProgress
+	}
+	if state.Conflict != "" {
+		head, err := gitOutputDir(state.Worktree, "rev-parse", "HEAD")
+		if err != nil || strings.TrimSpace(head) != state.NewParent {
+			return Result{}, fmt.Errorf("agent worktree changed since the conflict; run 'jul ws restack --abort'")
+		}
+		if unresolved := unresolvedFiles(state.Worktree, state.Conflicts); len(unresolved) > 0 {
+			return Result{}, ConflictError{CheckpointSHA: state.Conflict, Conflicts: unresolved, Worktree: state.Worktree}
+		}
+		if _, err := gitDir(state.Worktree, nil, "add", "-A"); err != nil {
+			return Result{}, err
+		}
+		if err := commitCheckpoint(&state, state.Conflict); err != nil {
+			return Result{}, err
+		}
+	}
+	return replay(repoRoot, &state)
+}
+
+// Abort drops the restack in progress: the replayed checkpoints are
+// forgotten and the workspace refs, lease, config and HEAD go back to what
+// they were before it started.
+func Abort(repoRoot string) (State, error) {
+	lease, err := agent.AcquireMergeWorktree(repoRoot, "restack")
+	if err != nil {
+		return State{}, err
+	}
+	defer lease.Release()
+	state, ok := ReadState(repoRoot)
+	if !ok {
+		return State{}, ErrNotInProgress
+	}
+	if state.Worktree != "" {
+		_, _ = gitDir(state.Worktree, nil, "reset", "--hard", state.BaseTip)
+		_, _ = gitDir(state.Worktree, nil, "clean", "-fd")
+	}
+	prefix := keepRefPrefix(state.User, state.Workspace) + state.ChangeID + "/"
+	for _, sha := range state.NewCheckpoints {
+		_, _ = gitutil.Git("-C", repoRoot, "update-ref", "-d", prefix+sha)
+	}
+	if err := restoreSnapshot(repoRoot, state.Original, !state.KeepWorktree); err != nil {
+		return state, err
+	}
+	clearState(repoRoot)
+	return state, nil
+}
+
+// restoreSnapshot restores snap and, for the checked-out workspace, resets the
+// working tree to the restored draft when the restack had already moved it.
+func restoreSnapshot(repoRoot string, snap wsconfig.Snapshot, checkedOut bool) error {
+	moved := snap.Moved(repoRoot)
+	if err := snap.Restore(repoRoot); err != nil {
+		return err
+	}
+	if moved && checkedOut && snap.SyncTip() != "" {
+		return updateWorktree(repoRoot, snap.SyncTip())
+	}
+	return nil
+}
+
+// unresolvedFiles returns the files of conflicts, plus any still unmerged in
+// the index, that contain conflict markers.
+func unresolvedFiles(worktree string, conflicts []string) []string {
+	files := append([]string{}, conflicts...)
+	files = append(files, restackConflictFiles(worktree)...)
+	seen := map[string]bool{}
+	var out []string
+	for _, file := range files {
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+		if hasConflictMarkers(filepath.Join(worktree, file)) {
+			out = append(out, file)
+		}
+	}
+	return out
+}
+
+func hasConflictMarkers(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+			return true
+		}
+	}
+	return false
+}
+
+func statePath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".jul", "restack", "state.json")
+}
+
+// ReadState returns the restack in progress, if any.
+func ReadState(repoRoot string) (State, bool) {
+	data, err := os.ReadFile(statePath(repoRoot))
+	if err != nil {
+		return State{}, false
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, false
+	}
+	if strings.TrimSpace(state.Workspace) == "" || len(state.Chain) == 0 {
+		return State{}, false
+	}
+	return state, true
+}
+
+func writeState(repoRoot string, state State) error {
+	path := statePath(repoRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func clearState(repoRoot string) {
+	_ = os.Remove(statePath(repoRoot))
+}
@@ -43,7 +43,7 @@ func DraftPushAllowed(repoRoot, baseSHA, draftSHA string, allowSecrets bool) (bo
 		if err != nil {
 			continue
 		}
-		if containsSecret(content) {
+		if ContainsSecret(content) {
 			return false, fmt.Sprintf("draft sync blocked: potential secret in %s (use --allow-secrets to override)", path), nil
 		}
 	}
@@ -65,7 +65,9 @@ func draftChangedFiles(repoRoot, baseSHA, draftSHA string) ([]string, error) {
 	return lines, nil
 }
 
-func containsSecret(content string) bool {
+// ContainsSecret reports whether content matches one of the draft secret
+// patterns.
+func ContainsSecret(content string) bool {
 	for _, re := range draftSecretPatterns {
 		if re.MatchString(content) {
 			return true
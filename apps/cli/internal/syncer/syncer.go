@@ -941,6 +941,7 @@ func pushJulNotes(remoteName string) error {
 		notes.RefAttestationsCheckpoint,
 		notes.RefSuggestions,
 		notes.RefAgentReview,
+		notes.RefFindings,
 		notes.RefCRState,
 		notes.RefCRComments,
 		notes.RefMeta,
@@ -1284,7 +1285,7 @@ func autoMerge(repoRoot, workspaceRemote, draftSHA, changeID string) (string, bo
 }
 
 func mergeTree(repoRoot, baseSHA, theirsSHA, oursSHA string) (string, bool, error) {
-	cmd := exec.Command("git", "-C", repoRoot, "merge-tree", "--write-tree", "--merge-base", baseSHA, oursSHA, theirsSHA)
+	cmd := exec.Command("git", mergeTreeArgs(repoRoot, baseSHA, theirsSHA, oursSHA)...)
 	cmd.Env = os.Environ()
 	output, err := cmd.CombinedOutput()
 	out := strings.TrimSpace(string(output))
@@ -1301,6 +1302,16 @@ func mergeTree(repoRoot, baseSHA, theirsSHA, oursSHA string) (string, bool, erro
 	return treeSHA, false, nil
 }
 
+// mergeTreeArgs only passes --merge-base (git 2.40+) when baseSHA is not
+// already the merge base git would pick itself.
+func mergeTreeArgs(repoRoot, baseSHA, theirsSHA, oursSHA string, extra ...string) []string {
+	args := append([]string{"-C", repoRoot, "merge-tree", "--write-tree"}, extra...)
+	if mb, err := gitutil.MergeBase(oursSHA, theirsSHA); err != nil || strings.TrimSpace(mb) != strings.TrimSpace(baseSHA) {
+		args = append(args, "--merge-base", baseSHA)
+	}
+	return append(args, oursSHA, theirsSHA)
+}
+
 func gitWithEnv(dir string, env map[string]string, args ...string) (string, error) {
 	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
 	cmd.Env = append(os.Environ(), flattenEnv(env)...)
@@ -0,0 +1,153 @@
+package syncer
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+type TransplantResult struct {
+	Status        string
+	DraftSHA      string
+	ChangeID      string
+	OldBase       string
+	NewBase       string
+	SyncRef       string
+	Conflicts     []string
+	RemoteName    string
+	RemotePushed  bool
+	RemoteProblem string
+}
+
+// Transplant carries the draft from the base it was started on to the
+// workspace's current tip when another device has checkpointed in between.
+// The draft's changes are three-way merged onto the new base and committed as
+// a new draft with the same Change-Id. On conflicts the snapshotted draft is
+// left on the sync ref for 'jul merge' and nothing else changes.
+func Transplant() (TransplantResult, error) {
+	repoRoot, err := gitutil.RepoTopLevel()
+	if err != nil {
+		return TransplantResult{}, err
+	}
+	// Sync first: it snapshots the working tree and fetches the workspace tip.
+	syncRes, err := SyncWithOptions(SyncOptions{})
+	if err != nil {
+		return TransplantResult{}, err
+	}
+	res := TransplantResult{
+		DraftSHA:   syncRes.DraftSHA,
+		ChangeID:   syncRes.ChangeID,
+		SyncRef:    syncRes.SyncRef,
+		RemoteName: syncRes.RemoteName,
+	}
+	if syncRes.Diverged {
+		problem := strings.TrimSpace(syncRes.RemoteProblem)
+		if problem == "" {
+			problem = "workspace diverged; run 'jul merge'"
+		}
+		return res, fmt.Errorf("cannot transplant: %s", problem)
+	}
+	if !syncRes.BaseAdvanced {
+		res.Status = "up_to_date"
+		return res, nil
+	}
+
+	_, workspace := workspaceParts()
+	if workspace == "" {
+		workspace = "@"
+	}
+	draftSHA := strings.TrimSpace(syncRes.DraftSHA)
+	oldBase, err := gitutil.ParentOf(draftSHA)
+	if err != nil || strings.TrimSpace(oldBase) == "" {
+		return res, fmt.Errorf("failed to resolve draft base")
+	}
+	oldBase = strings.TrimSpace(oldBase)
+	newBase, err := gitutil.ResolveRef(syncRes.WorkspaceRef)
+	if err != nil || strings.TrimSpace(newBase) == "" {
+		return res, fmt.Errorf("failed to resolve workspace tip")
+	}
+	newBase = strings.TrimSpace(newBase)
+	if msg, err := gitutil.CommitMessage(newBase); err == nil && isDraftMessage(msg) {
+		if parent, err := gitutil.ParentOf(newBase); err == nil && strings.TrimSpace(parent) != "" {
+			newBase = strings.TrimSpace(parent)
+		}
+	}
+	res.OldBase = oldBase
+	res.NewBase = newBase
+	if msg, err := gitutil.CommitMessage(draftSHA); err == nil {
+		if changeID := normalizeChangeID(gitutil.ExtractChangeID(msg)); isValidChangeID(changeID) {
+			res.ChangeID = changeID
+		}
+	}
+
+	treeSHA, conflicted, err := mergeTree(repoRoot, oldBase, newBase, draftSHA)
+	if err != nil {
+		return res, err
+	}
+	if conflicted {
+		res.Status = "conflicts"
+		res.Conflicts = mergeTreeConflicts(repoRoot, oldBase, newBase, draftSHA)
+		return res, nil
+	}
+
+	newDraft, err := gitutil.CreateDraftCommitFromTree(treeSHA, newBase, res.ChangeID)
+	if err != nil {
+		return res, err
+	}
+	if err := gitutil.UpdateRef(syncRes.SyncRef, newDraft); err != nil {
+		return res, err
+	}
+	if err := writeWorkspaceLease(repoRoot, workspace, newBase); err != nil {
+		return res, err
+	}
+	if err := ensureWorkspaceHead(repoRoot, workspace, newBase); err != nil {
+		return res, err
+	}
+	if err := updateWorktree(repoRoot, newDraft); err != nil {
+		return res, err
+	}
+	res.Status = "transplanted"
+	res.DraftSHA = newDraft
+
+	if res.RemoteName != "" && config.DraftSyncEnabled() {
+		ok, reason, err := DraftPushAllowed(repoRoot, newBase, newDraft, config.AllowDraftSecrets())
+		if err != nil {
+			return res, err
+		}
+		if !ok {
+			res.RemoteProblem = strings.TrimSpace(reason)
+		} else if err := pushRef(res.RemoteName, newDraft, syncRes.SyncRef, true); err != nil {
+			res.RemoteProblem = err.Error()
+		} else {
+			res.RemotePushed = true
+		}
+	}
+	return res, nil
+}
+
+// mergeTreeConflicts lists the paths mergeTree reports as conflicted.
+func mergeTreeConflicts(repoRoot, baseSHA, theirsSHA, oursSHA string) []string {
+	cmd := exec.Command("git", mergeTreeArgs(repoRoot, baseSHA, theirsSHA, oursSHA, "--name-only", "--no-messages")...)
+	out, _ := cmd.Output()
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < 2 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var files []string
+	// The first line is the tree; conflicted paths follow until a blank line.
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if !seen[line] {
+			seen[line] = true
+			files = append(files, line)
+		}
+	}
+	return files
+}
@@ -0,0 +1,170 @@
+package syncer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+// setupBaseAdvanced leaves the working tree on base with the workspace ref
+// moved to a checkpoint that rewrote shared.txt and added remote.txt.
+func setupBaseAdvanced(t *testing.T) (string, string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmp := t.TempDir()
+	t.Setenv("HOME", filepath.Join(tmp, "home"))
+	t.Setenv("JUL_WORKSPACE", "tester/@")
+
+	repoDir := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(repoDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.name", "Test User"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		if err := run(repoDir, "git", args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "shared.txt"), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(repoDir, "git", "add", "shared.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(repoDir, "git", "commit", "-m", "base"); err != nil {
+		t.Fatal(err)
+	}
+	baseSHA, err := gitOut(repoDir, "git", "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "shared.txt"), []byte("ONE\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "remote.txt"), []byte("remote\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(repoDir, "git", "add", "shared.txt", "remote.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(repoDir, "git", "commit", "-m", "checkpoint2"); err != nil {
+		t.Fatal(err)
+	}
+	checkpointSHA, err := gitOut(repoDir, "git", "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run(repoDir, "git", "update-ref", "refs/jul/workspaces/tester/@", checkpointSHA); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(repoDir, "git", "reset", "--hard", baseSHA); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeWorkspaceLease(repoDir, "@", baseSHA); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(repoDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	return repoDir, baseSHA, checkpointSHA
+}
+
+func TestTransplantMergesDraftOntoNewBase(t *testing.T) {
+	repoDir, _, checkpointSHA := setupBaseAdvanced(t)
+	if err := os.WriteFile(filepath.Join(repoDir, "shared.txt"), []byte("one\ntwo\nTHREE\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := Sync()
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if !before.BaseAdvanced {
+		t.Fatalf("expected base advanced, got %+v", before)
+	}
+
+	res, err := Transplant()
+	if err != nil {
+		t.Fatalf("transplant failed: %v", err)
+	}
+	if res.Status != "transplanted" {
+		t.Fatalf("expected transplanted, got %+v", res)
+	}
+	if res.ChangeID != before.ChangeID {
+		t.Fatalf("expected Change-Id %s to be kept, got %s", before.ChangeID, res.ChangeID)
+	}
+	parent, err := gitutil.ParentOf(res.DraftSHA)
+	if err != nil || strings.TrimSpace(parent) != checkpointSHA {
+		t.Fatalf("expected new draft on %s, got %s (%v)", checkpointSHA, parent, err)
+	}
+	msg, _ := gitutil.CommitMessage(res.DraftSHA)
+	if gitutil.ExtractChangeID(msg) != before.ChangeID {
+		t.Fatalf("expected draft message to carry Change-Id, got %q", msg)
+	}
+	shared, err := os.ReadFile(filepath.Join(repoDir, "shared.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(shared) != "ONE\ntwo\nTHREE\n" {
+		t.Fatalf("expected merged shared.txt, got %q", string(shared))
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "remote.txt")); err != nil {
+		t.Fatalf("expected remote.txt from new base: %v", err)
+	}
+	lease, _ := readWorkspaceLease(repoDir, "@")
+	if lease != checkpointSHA {
+		t.Fatalf("expected lease %s, got %s", checkpointSHA, lease)
+	}
+
+	after, err := Sync()
+	if err != nil {
+		t.Fatalf("sync after transplant failed: %v", err)
+	}
+	if after.BaseAdvanced || after.DraftSHA != res.DraftSHA {
+		t.Fatalf("expected sync to keep transplanted draft, got %+v", after)
+	}
+}
+
+func TestTransplantLeavesConflictsForMerge(t *testing.T) {
+	repoDir, baseSHA, _ := setupBaseAdvanced(t)
+	if err := os.WriteFile(filepath.Join(repoDir, "shared.txt"), []byte("uno\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Transplant()
+	if err != nil {
+		t.Fatalf("transplant failed: %v", err)
+	}
+	if res.Status != "conflicts" {
+		t.Fatalf("expected conflicts, got %+v", res)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0] != "shared.txt" {
+		t.Fatalf("expected shared.txt conflict, got %v", res.Conflicts)
+	}
+	syncTip, err := gitutil.ResolveRef(res.SyncRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent, _ := gitutil.ParentOf(syncTip); strings.TrimSpace(parent) != baseSHA {
+		t.Fatalf("expected draft to stay on %s, got %s", baseSHA, parent)
+	}
+	shared, err := os.ReadFile(filepath.Join(repoDir, "shared.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(shared) != "uno\ntwo\nthree\n" {
+		t.Fatalf("expected working tree untouched, got %q", string(shared))
+	}
+}
@@ -0,0 +1,243 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+// RefMove moves SHA from one ref to another. An empty From creates To; an
+// empty To only deletes From.
+type RefMove struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	SHA  string `json:"sha"`
+}
+
+// Archived is a closed workspace kept under refs/jul/archive/<user>/<name>/.
+type Archived struct {
+	Name string
+	SHA  string
+}
+
+// ArchivePrefix returns the ref namespace a closed workspace is moved under.
+func ArchivePrefix(user, workspace string) string {
+	return fmt.Sprintf("refs/jul/archive/%s/%s/", user, workspace)
+}
+
+// PlanClose lists the moves that archive a workspace: its workspace ref and
+// head branch, every device's sync and trace-sync refs, its trace ref and its
+// keep refs. The workspace config is stored as a blob so it travels with the
+// archive.
+func PlanClose(repoRoot, user, workspace string) ([]RefMove, error) {
+	archive := ArchivePrefix(user, workspace)
+	refs, err := listRefs(repoRoot, "refs/jul/", "refs/heads/jul/")
+	if err != nil {
+		return nil, err
+	}
+	var moves []RefMove
+	for ref, sha := range refs {
+		if suffix, ok := archiveSuffix(ref, user, workspace); ok {
+			moves = append(moves, RefMove{From: ref, To: archive + suffix, SHA: sha})
+		}
+	}
+	if path := ConfigPath(repoRoot, workspace); fileExists(path) {
+		blob, err := gitutil.Git("-C", repoRoot, "hash-object", "-w", path)
+		if err != nil {
+			return nil, err
+		}
+		moves = append(moves, RefMove{To: archive + "config", SHA: strings.TrimSpace(blob)})
+	}
+	sortMoves(moves)
+	return moves, nil
+}
+
+// PlanReopen lists the moves that bring an archived workspace back. The
+// config blob is only removed from the archive; ApplyReopen writes it back.
+func PlanReopen(repoRoot, user, workspace string) ([]RefMove, error) {
+	archive := ArchivePrefix(user, workspace)
+	refs, err := listRefs(repoRoot, archive)
+	if err != nil {
+		return nil, err
+	}
+	var moves []RefMove
+	for ref, sha := range refs {
+		suffix := strings.TrimPrefix(ref, archive)
+		if suffix == "config" {
+			moves = append(moves, RefMove{From: ref, SHA: sha})
+			continue
+		}
+		to, ok := unarchiveRef(suffix, user, workspace)
+		if !ok {
+			continue
+		}
+		moves = append(moves, RefMove{From: ref, To: to, SHA: sha})
+	}
+	sortMoves(moves)
+	return moves, nil
+}
+
+// ApplyClose moves the refs into the archive and drops the workspace's local
+// config and lease.
+func ApplyClose(repoRoot, workspace string, moves []RefMove) error {
+	if err := applyMoves(repoRoot, moves); err != nil {
+		return err
+	}
+	if err := os.Remove(LeasePath(repoRoot, workspace)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(ConfigPath(repoRoot, workspace)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ApplyReopen restores the archived config and moves the refs back.
+func ApplyReopen(repoRoot, user, workspace string, moves []RefMove) error {
+	configRef := ArchivePrefix(user, workspace) + "config"
+	for _, move := range moves {
+		if move.From != configRef {
+			continue
+		}
+		data, err := gitutil.ReadBlob(repoRoot, move.SHA)
+		if err != nil {
+			return err
+		}
+		path := ConfigPath(repoRoot, workspace)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+	}
+	return applyMoves(repoRoot, moves)
+}
+
+// ListArchived returns the closed workspaces of user.
+func ListArchived(repoRoot, user string) ([]Archived, error) {
+	prefix := "refs/jul/archive/" + user + "/"
+	refs, err := listRefs(repoRoot, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var out []Archived
+	for ref, sha := range refs {
+		rest := strings.TrimPrefix(ref, prefix)
+		name, ok := strings.CutSuffix(rest, "/workspace")
+		if !ok || name == "" || strings.Contains(name, "/") {
+			continue
+		}
+		out = append(out, Archived{Name: name, SHA: sha})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// archiveSuffix maps a live ref of the workspace to its place in the archive.
+func archiveSuffix(ref, user, workspace string) (string, bool) {
+	switch ref {
+	case fmt.Sprintf("refs/jul/workspaces/%s/%s", user, workspace):
+		return "workspace", true
+	case fmt.Sprintf("refs/heads/jul/%s", workspace):
+		return "head", true
+	case fmt.Sprintf("refs/jul/traces/%s/%s", user, workspace):
+		return "traces", true
+	}
+	if rest, ok := strings.CutPrefix(ref, fmt.Sprintf("refs/jul/keep/%s/%s/", user, workspace)); ok && rest != "" {
+		return "keep/" + rest, true
+	}
+	for _, kind := range []string{"sync", "trace-sync"} {
+		rest, ok := strings.CutPrefix(ref, fmt.Sprintf("refs/jul/%s/%s/", kind, user))
+		if !ok {
+			continue
+		}
+		device, ok := strings.CutSuffix(rest, "/"+workspace)
+		if ok && device != "" && !strings.Contains(device, "/") {
+			return kind + "/" + device, true
+		}
+	}
+	return "", false
+}
+
+// unarchiveRef is the inverse of archiveSuffix.
+func unarchiveRef(suffix, user, workspace string) (string, bool) {
+	switch suffix {
+	case "workspace":
+		return fmt.Sprintf("refs/jul/workspaces/%s/%s", user, workspace), true
+	case "head":
+		return fmt.Sprintf("refs/heads/jul/%s", workspace), true
+	case "traces":
+		return fmt.Sprintf("refs/jul/traces/%s/%s", user, workspace), true
+	}
+	if rest, ok := strings.CutPrefix(suffix, "keep/"); ok && rest != "" {
+		return fmt.Sprintf("refs/jul/keep/%s/%s/%s", user, workspace, rest), true
+	}
+	for _, kind := range []string{"sync", "trace-sync"} {
+		if device, ok := strings.CutPrefix(suffix, kind+"/"); ok && device != "" {
+			return fmt.Sprintf("refs/jul/%s/%s/%s/%s", kind, user, device, workspace), true
+		}
+	}
+	return "", false
+}
+
+// applyMoves creates every destination before deleting any source. A push to
+// a remote whose fetch refspecs map jul refs onto themselves may already have
+// made some of the moves locally.
+func applyMoves(repoRoot string, moves []RefMove) error {
+	for _, move := range moves {
+		if move.To != "" {
+			if _, err := gitutil.Git("-C", repoRoot, "update-ref", move.To, move.SHA); err != nil {
+				return err
+			}
+		}
+	}
+	for _, move := range moves {
+		if move.From != "" && refExists(repoRoot, move.From) {
+			if _, err := gitutil.Git("-C", repoRoot, "update-ref", "-d", move.From, move.SHA); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func refExists(repoRoot, ref string) bool {
+	_, err := gitutil.Git("-C", repoRoot, "rev-parse", "-q", "--verify", ref)
+	return err == nil
+}
+
+func listRefs(repoRoot string, prefixes ...string) (map[string]string, error) {
+	args := append([]string{"-C", repoRoot, "for-each-ref", "--format=%(objectname) %(refname)"}, prefixes...)
+	out, err := gitutil.Git(args...)
+	if err != nil {
+		return nil, err
+	}
+	refs := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		refs[fields[1]] = fields[0]
+	}
+	return refs, nil
+}
+
+func sortMoves(moves []RefMove) {
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].From != moves[j].From {
+			return moves[i].From < moves[j].From
+		}
+		return moves[i].To < moves[j].To
+	})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
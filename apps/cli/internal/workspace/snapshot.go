@@ -0,0 +1,152 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+// Snapshot records a workspace's refs, lease, config and HEAD before a
+// multi-step operation so an aborted restack or promote can put them back.
+// Refs maps each recorded ref to its tip; an empty tip means the ref did not
+// exist and is deleted on restore.
+type Snapshot struct {
+	User      string            `json:"user"`
+	Workspace string            `json:"workspace"`
+	SyncRef   string            `json:"sync_ref,omitempty"`
+	Refs      map[string]string `json:"refs"`
+	Lease     string            `json:"lease,omitempty"`
+	Head      string            `json:"head,omitempty"`
+	Config    Config            `json:"config"`
+	HasConfig bool              `json:"has_config"`
+}
+
+func LeasePath(repoRoot, workspace string) string {
+	return filepath.Join(repoRoot, ".jul", "workspaces", workspace, "lease")
+}
+
+// TakeSnapshot records the workspace ref, this device's sync ref, the
+// workspace's local head branch and any extra refs, such as the change and
+// anchor refs an operation is about to move.
+func TakeSnapshot(repoRoot, user, workspace string, extraRefs ...string) (Snapshot, error) {
+	snap := Snapshot{User: user, Workspace: workspace, Refs: map[string]string{}}
+	refs := []string{
+		fmt.Sprintf("refs/jul/workspaces/%s/%s", user, workspace),
+		fmt.Sprintf("refs/heads/jul/%s", workspace),
+	}
+	if deviceID, err := config.DeviceID(); err == nil && deviceID != "" {
+		snap.SyncRef = fmt.Sprintf("refs/jul/sync/%s/%s/%s", user, deviceID, workspace)
+		refs = append(refs, snap.SyncRef)
+	}
+	refs = append(refs, extraRefs...)
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		snap.Refs[ref] = refTip(repoRoot, ref)
+	}
+	if data, err := os.ReadFile(LeasePath(repoRoot, workspace)); err == nil {
+		snap.Lease = strings.TrimSpace(string(data))
+	}
+	if head, err := gitutil.Git("-C", repoRoot, "symbolic-ref", "-q", "HEAD"); err == nil {
+		snap.Head = strings.TrimSpace(head)
+	}
+	cfg, ok, err := ReadConfig(repoRoot, workspace)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snap.Config = cfg
+	snap.HasConfig = ok
+	return snap, nil
+}
+
+// SyncTip returns the draft the sync ref pointed at when the snapshot was
+// taken.
+func (s Snapshot) SyncTip() string {
+	if s.SyncRef == "" {
+		return ""
+	}
+	return s.Refs[s.SyncRef]
+}
+
+// Moved reports whether any recorded ref has changed since the snapshot.
+func (s Snapshot) Moved(repoRoot string) bool {
+	for ref, sha := range s.Refs {
+		if refTip(repoRoot, ref) != sha {
+			return true
+		}
+	}
+	return false
+}
+
+// Restore puts the recorded refs, lease, config and HEAD back. It does not
+// touch the working tree.
+func (s Snapshot) Restore(repoRoot string) error {
+	refs := make([]string, 0, len(s.Refs))
+	for ref := range s.Refs {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		sha := s.Refs[ref]
+		current := refTip(repoRoot, ref)
+		if current == sha {
+			continue
+		}
+		if sha == "" {
+			if _, err := gitutil.Git("-C", repoRoot, "update-ref", "-d", ref); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := gitutil.Git("-C", repoRoot, "update-ref", ref, sha); err != nil {
+			return err
+		}
+	}
+
+	leasePath := LeasePath(repoRoot, s.Workspace)
+	if s.Lease == "" {
+		if err := os.Remove(leasePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(leasePath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(leasePath, []byte(s.Lease+"\n"), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if s.HasConfig {
+		if err := WriteConfig(repoRoot, s.Workspace, s.Config); err != nil {
+			return err
+		}
+	} else if err := os.Remove(ConfigPath(repoRoot, s.Workspace)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if s.Head != "" {
+		current, _ := gitutil.Git("-C", repoRoot, "symbolic-ref", "-q", "HEAD")
+		if strings.TrimSpace(current) != s.Head {
+			if _, err := gitutil.Git("-C", repoRoot, "symbolic-ref", "HEAD", s.Head); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func refTip(repoRoot, ref string) string {
+	sha, err := gitutil.Git("-C", repoRoot, "rev-parse", "-q", "--verify", ref+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(sha)
+}
@@ -0,0 +1,139 @@
+package workspace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/config"
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+const changeRefPrefix = "refs/jul/changes/"
+
+// Node is one workspace in the stack graph. Parent is empty when the
+// workspace is based on a branch rather than on another workspace.
+type Node struct {
+	Name     string
+	Parent   string
+	Config   Config
+	ChangeID string
+	Tip      string
+	Children []string
+}
+
+// Stack is the graph of a user's local workspaces, linked through each
+// workspace config's BaseRef.
+type Stack struct {
+	Nodes map[string]*Node
+	Roots []string
+}
+
+// LoadStack reads every workspace under refs/jul/workspaces/<user>/ and links
+// it to its parent. A base ref of refs/jul/workspaces/<user>/<name> points at
+// that workspace; refs/jul/changes/<id> points at the workspace whose draft
+// carries that change id.
+func LoadStack(repoRoot, user string) (Stack, error) {
+	user = strings.TrimSpace(user)
+	if user == "" {
+		return Stack{}, fmt.Errorf("user required for workspace stack")
+	}
+	prefix := "refs/jul/workspaces/" + user + "/"
+	out, err := gitutil.Git("-C", repoRoot, "for-each-ref", "--format=%(objectname) %(refname)", prefix)
+	if err != nil {
+		return Stack{}, err
+	}
+	deviceID, _ := config.DeviceID()
+
+	stack := Stack{Nodes: map[string]*Node{}}
+	byChange := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[1], prefix)
+		if name == "" {
+			continue
+		}
+		cfg, _, err := ReadConfig(repoRoot, name)
+		if err != nil {
+			return Stack{}, err
+		}
+		node := &Node{Name: name, Config: cfg, Tip: fields[0]}
+		node.ChangeID = workspaceChangeID(user, deviceID, name, node.Tip)
+		stack.Nodes[name] = node
+		if node.ChangeID != "" {
+			byChange[node.ChangeID] = name
+		}
+	}
+
+	names := make([]string, 0, len(stack.Nodes))
+	for name := range stack.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		node := stack.Nodes[name]
+		baseRef := strings.TrimSpace(node.Config.BaseRef)
+		parent := ""
+		switch {
+		case strings.HasPrefix(baseRef, prefix):
+			parent = strings.TrimPrefix(baseRef, prefix)
+		case strings.HasPrefix(baseRef, changeRefPrefix):
+			parent = byChange[strings.TrimPrefix(baseRef, changeRefPrefix)]
+		}
+		if _, ok := stack.Nodes[parent]; !ok || parent == name {
+			parent = ""
+		}
+		node.Parent = parent
+		if parent == "" {
+			stack.Roots = append(stack.Roots, name)
+			continue
+		}
+		stack.Nodes[parent].Children = append(stack.Nodes[parent].Children, name)
+	}
+	return stack, nil
+}
+
+// Descendants returns the workspaces stacked on name, parents before their
+// children, so restacking in this order never rebases onto a stale layer.
+func (s Stack) Descendants(name string) []string {
+	var out []string
+	seen := map[string]bool{name: true}
+	var walk func(string)
+	walk = func(current string) {
+		node, ok := s.Nodes[current]
+		if !ok {
+			return
+		}
+		for _, child := range node.Children {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			out = append(out, child)
+			walk(child)
+		}
+	}
+	walk(name)
+	return out
+}
+
+// workspaceChangeID prefers the sync draft, which carries the workspace's
+// own change id even before its first checkpoint; the workspace ref of a
+// fresh stacked workspace still points at the parent's checkpoint.
+func workspaceChangeID(user, deviceID, name, tip string) string {
+	if deviceID != "" {
+		syncRef := fmt.Sprintf("refs/jul/sync/%s/%s/%s", user, deviceID, name)
+		if msg, err := gitutil.CommitMessage(syncRef); err == nil {
+			if id := gitutil.ExtractChangeID(msg); id != "" {
+				return id
+			}
+		}
+	}
+	if msg, err := gitutil.CommitMessage(tip); err == nil {
+		return gitutil.ExtractChangeID(msg)
+	}
+	return ""
+}
@@ -0,0 +1,222 @@
+package promote
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+	"github.com/lydakis/jul/cli/internal/notes"
+)
+
+// Notes is the notes backend promote events are written through. The CLI
+// uses its cached notes package; GitNotes works against any Repo.
+type Notes interface {
+	ReadJSON(ref, objectSHA string, target any) (bool, error)
+	AddJSON(ref, objectSHA string, payload any) error
+}
+
+type GitNotes struct {
+	Repo Repo
+}
+
+func (n GitNotes) ReadJSON(ref, objectSHA string, target any) (bool, error) {
+	if _, err := n.Repo.Git("rev-parse", "--verify", "--quiet", ref); err != nil {
+		return false, nil
+	}
+	out, err := n.Repo.Git("notes", "--ref", ref, "show", objectSHA)
+	if err != nil {
+		if strings.Contains(err.Error(), "no note found") {
+			return false, nil
+		}
+		return false, err
+	}
+	if strings.TrimSpace(out) == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(out), target); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (n GitNotes) AddJSON(ref, objectSHA string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if len(data) > notes.MaxNoteSize {
+		return fmt.Errorf("%w: %d bytes", notes.ErrNoteTooLarge, len(data))
+	}
+	_, err = n.Repo.gitInput(data, "notes", "--ref", ref, "add", "-f", "-F", "-", objectSHA)
+	return err
+}
+
+// changeMeta, promoteEvent and changeIDNote are the note payloads the CLI
+// reads back through internal/metadata; their JSON must stay in step.
+type changeMeta struct {
+	ChangeID      string         `json:"change_id"`
+	AnchorSHA     string         `json:"anchor_sha"`
+	Checkpoints   []Checkpoint   `json:"checkpoints,omitempty"`
+	PromoteEvents []promoteEvent `json:"promote_events,omitempty"`
+}
+
+type promoteEvent struct {
+	Target         string    `json:"target"`
+	Strategy       string    `json:"strategy"`
+	Timestamp      time.Time `json:"timestamp"`
+	Published      []string  `json:"published,omitempty"`
+	CheckpointSHAs []string  `json:"checkpoint_shas,omitempty"`
+	PublishedSHAs  []string  `json:"published_shas,omitempty"`
+	MergeCommitSHA *string   `json:"merge_commit_sha,omitempty"`
+	Mainline       *int      `json:"mainline,omitempty"`
+}
+
+type changeIDNote struct {
+	ChangeID            string   `json:"change_id"`
+	PromoteEventID      int      `json:"promote_event_id,omitempty"`
+	Strategy            string   `json:"strategy,omitempty"`
+	SourceCheckpointSHA string   `json:"source_checkpoint_sha,omitempty"`
+	CheckpointSHAs      []string `json:"checkpoint_shas,omitempty"`
+	TraceBase           string   `json:"trace_base,omitempty"`
+	TraceHead           string   `json:"trace_head,omitempty"`
+}
+
+type Event struct {
+	Branch         string
+	Strategy       string
+	ChangeID       string
+	AnchorSHA      string
+	Checkpoints    []Checkpoint
+	CheckpointSHAs []string
+	PublishedSHAs  []string
+	MergeCommitSHA *string
+	Mainline       *int
+}
+
+// RecordEvent appends a PromoteEvent to the change's meta note and writes the
+// change-id reverse index on every published commit. It returns the event ID.
+func RecordEvent(repo Repo, store Notes, ev Event) (int, error) {
+	if strings.TrimSpace(ev.ChangeID) == "" {
+		return 0, nil
+	}
+	anchorSHA := strings.TrimSpace(ev.AnchorSHA)
+	if anchorSHA == "" {
+		if len(ev.Checkpoints) > 0 {
+			anchorSHA = ev.Checkpoints[0].SHA
+		} else if len(ev.PublishedSHAs) > 0 {
+			anchorSHA = ev.PublishedSHAs[0]
+		}
+	}
+	if anchorSHA == "" {
+		return 0, nil
+	}
+
+	var meta changeMeta
+	ok, err := store.ReadJSON(notes.RefMeta, anchorSHA, &meta)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		meta = changeMeta{}
+	}
+	if meta.ChangeID == "" {
+		meta.ChangeID = ev.ChangeID
+	}
+	if meta.AnchorSHA == "" {
+		meta.AnchorSHA = anchorSHA
+	}
+	if len(ev.Checkpoints) > 0 {
+		meta.Checkpoints = ev.Checkpoints
+	}
+	eventID := len(meta.PromoteEvents) + 1
+	strategy := strings.TrimSpace(ev.Strategy)
+	if strategy == "" {
+		strategy = "rebase"
+	}
+	meta.PromoteEvents = append(meta.PromoteEvents, promoteEvent{
+		Target:         strings.TrimSpace(ev.Branch),
+		Strategy:       strategy,
+		Timestamp:      time.Now().UTC(),
+		Published:      ev.PublishedSHAs,
+		CheckpointSHAs: ev.CheckpointSHAs,
+		PublishedSHAs:  ev.PublishedSHAs,
+		MergeCommitSHA: ev.MergeCommitSHA,
+		Mainline:       ev.Mainline,
+	})
+	if err := store.AddJSON(notes.RefMeta, meta.AnchorSHA, meta); err != nil {
+		return 0, err
+	}
+	if err := writeChangeIDNotes(repo, store, ev.ChangeID, eventID, strategy, ev.Checkpoints, ev.PublishedSHAs); err != nil {
+		return 0, err
+	}
+	return eventID, nil
+}
+
+func writeChangeIDNotes(repo Repo, store Notes, changeID string, eventID int, strategy string, checkpoints []Checkpoint, published []string) error {
+	if strings.TrimSpace(changeID) == "" || len(published) == 0 {
+		return nil
+	}
+	switch strategy {
+	case "rebase":
+		for i, publishedSHA := range published {
+			cpSHA := ""
+			if i < len(checkpoints) {
+				cpSHA = strings.TrimSpace(checkpoints[i].SHA)
+			}
+			base, head := traceAnchorsForCommit(repo, cpSHA)
+			note := changeIDNote{
+				ChangeID:            changeID,
+				PromoteEventID:      eventID,
+				Strategy:            strategy,
+				SourceCheckpointSHA: cpSHA,
+				TraceBase:           base,
+				TraceHead:           head,
+			}
+			if err := store.AddJSON(notes.RefChangeID, publishedSHA, note); err != nil {
+				return err
+			}
+		}
+	default:
+		traceBase, traceHead := traceRange(repo, checkpoints)
+		checkpointSHAs := make([]string, 0, len(checkpoints))
+		for _, cp := range checkpoints {
+			checkpointSHAs = append(checkpointSHAs, strings.TrimSpace(cp.SHA))
+		}
+		for _, publishedSHA := range published {
+			note := changeIDNote{
+				ChangeID:       changeID,
+				PromoteEventID: eventID,
+				Strategy:       strategy,
+				CheckpointSHAs: checkpointSHAs,
+				TraceBase:      traceBase,
+				TraceHead:      traceHead,
+			}
+			if err := store.AddJSON(notes.RefChangeID, publishedSHA, note); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func traceAnchorsForCommit(repo Repo, sha string) (string, string) {
+	if strings.TrimSpace(sha) == "" {
+		return "", ""
+	}
+	msg, err := repo.CommitMessage(sha)
+	if err != nil {
+		return "", ""
+	}
+	return strings.TrimSpace(gitutil.ExtractTraceBase(msg)), strings.TrimSpace(gitutil.ExtractTraceHead(msg))
+}
+
+func traceRange(repo Repo, checkpoints []Checkpoint) (string, string) {
+	if len(checkpoints) == 0 {
+		return "", ""
+	}
+	baseMsg, _ := repo.CommitMessage(checkpoints[0].SHA)
+	headMsg, _ := repo.CommitMessage(checkpoints[len(checkpoints)-1].SHA)
+	return strings.TrimSpace(gitutil.ExtractTraceBase(baseMsg)), strings.TrimSpace(gitutil.ExtractTraceHead(headMsg))
+}
@@ -0,0 +1,202 @@
+package promote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/notes"
+	"github.com/lydakis/jul/cli/internal/policy"
+)
+
+type Policy = policy.PromotePolicy
+
+// LoadPolicy reads <root>/.jul/policy.toml. For the server, root is the bare
+// repository directory.
+func LoadPolicy(root, target string) (Policy, bool, error) {
+	return policy.LoadPromotePolicy(root, target)
+}
+
+type Attestation struct {
+	Status            string
+	TestStatus        string
+	CompileStatus     string
+	CoverageLinePct   *float64
+	CoverageBranchPct *float64
+}
+
+type PolicyInput struct {
+	CheckpointSHA      string
+	Attestation        *Attestation
+	Stale              bool
+	PendingSuggestions bool
+	// FindingSeverities are the severities of the checkpoint's review findings.
+	FindingSeverities []string
+}
+
+func PolicyNeedsAttestation(cfg Policy) bool {
+	return cfg.MinCoveragePct != nil || len(cfg.RequiredChecks) > 0 || cfg.RequireSuggestionsAddressed != nil
+}
+
+func RequiresSuggestionsAddressed(cfg Policy) bool {
+	return cfg.RequireSuggestionsAddressed != nil && *cfg.RequireSuggestionsAddressed
+}
+
+// GatesFindings reports whether the policy caps review finding severity.
+func GatesFindings(cfg Policy) bool {
+	return policy.SeverityRank(cfg.MaxSeverity) >= 0
+}
+
+// FindingSeverities reads the severities of the review findings recorded for
+// checkpointSHA.
+func FindingSeverities(store Notes, checkpointSHA string) ([]string, error) {
+	var note struct {
+		Findings []struct {
+			Severity string `json:"severity"`
+		} `json:"findings"`
+	}
+	found, err := store.ReadJSON(notes.RefFindings, checkpointSHA, &note)
+	if err != nil || !found {
+		return nil, err
+	}
+	severities := make([]string, 0, len(note.Findings))
+	for _, finding := range note.Findings {
+		severities = append(severities, finding.Severity)
+	}
+	return severities, nil
+}
+
+func EnforcePolicy(cfg Policy, in PolicyInput) error {
+	if PolicyNeedsAttestation(cfg) {
+		if err := enforceCIPolicy(cfg, in); err != nil {
+			return err
+		}
+	}
+	if GatesFindings(cfg) {
+		limit := policy.SeverityRank(cfg.MaxSeverity)
+		over := 0
+		worst := ""
+		for _, severity := range in.FindingSeverities {
+			rank := policy.SeverityRank(policy.NormalizeSeverity(severity))
+			if rank <= limit {
+				continue
+			}
+			over++
+			if rank > policy.SeverityRank(worst) {
+				worst = policy.NormalizeSeverity(severity)
+			}
+		}
+		if over > 0 {
+			return Error{
+				Code:    "promote_policy_failed",
+				Message: fmt.Sprintf("promote blocked: %d review finding(s) above max severity %s (worst: %s)", over, cfg.MaxSeverity, worst),
+				Next: []NextAction{
+					{Action: "inspect", Command: fmt.Sprintf("jul show %s", in.CheckpointSHA)},
+					{Action: "bypass", Command: "jul promote --no-policy --json"},
+				},
+			}
+		}
+	}
+	return nil
+}
+
+func enforceCIPolicy(cfg Policy, in PolicyInput) error {
+	rerun := []NextAction{
+		{Action: "rerun", Command: fmt.Sprintf("jul ci run --target %s --json", in.CheckpointSHA)},
+	}
+	if in.Stale {
+		return Error{
+			Code:    "promote_policy_failed",
+			Message: "promote blocked: CI results are stale; rerun CI on the latest checkpoint",
+			Next:    rerun,
+		}
+	}
+	att := in.Attestation
+	if att == nil {
+		return Error{
+			Code:    "promote_policy_failed",
+			Message: "promote blocked: no CI results found for latest checkpoint",
+			Next:    rerun,
+		}
+	}
+
+	for _, check := range cfg.RequiredChecks {
+		name := strings.ToLower(strings.TrimSpace(check))
+		if name == "" {
+			continue
+		}
+		switch name {
+		case "test", "tests":
+			if !IsPassingStatus(att.TestStatus) {
+				return Error{
+					Code:    "promote_policy_failed",
+					Message: fmt.Sprintf("promote blocked: test status %s", statusLabel(att.TestStatus)),
+					Next:    rerun,
+				}
+			}
+		case "compile", "build":
+			if !IsPassingStatus(att.CompileStatus) {
+				return Error{
+					Code:    "promote_policy_failed",
+					Message: fmt.Sprintf("promote blocked: compile status %s", statusLabel(att.CompileStatus)),
+					Next:    rerun,
+				}
+			}
+		default:
+			if !IsPassingStatus(att.Status) {
+				return Error{
+					Code:    "promote_policy_failed",
+					Message: fmt.Sprintf("promote blocked: CI status %s", statusLabel(att.Status)),
+					Next:    rerun,
+				}
+			}
+		}
+	}
+
+	if cfg.MinCoveragePct != nil {
+		coverage := att.CoverageLinePct
+		if coverage == nil {
+			coverage = att.CoverageBranchPct
+		}
+		if coverage == nil {
+			return Error{
+				Code:    "promote_policy_failed",
+				Message: "promote blocked: coverage data missing for latest checkpoint",
+				Next:    rerun,
+			}
+		}
+		if *coverage < *cfg.MinCoveragePct {
+			return Error{
+				Code:    "promote_policy_failed",
+				Message: fmt.Sprintf("promote blocked: coverage %.1f%% below policy threshold %.1f%%", *coverage, *cfg.MinCoveragePct),
+				Next: append(rerun,
+					NextAction{Action: "bypass", Command: "jul promote --no-policy --json"},
+				),
+			}
+		}
+	}
+
+	if RequiresSuggestionsAddressed(cfg) && in.PendingSuggestions {
+		return Error{
+			Code:    "promote_policy_failed",
+			Message: "promote blocked: pending suggestions must be addressed",
+		}
+	}
+
+	return nil
+}
+
+func IsPassingStatus(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "pass", "passed", "ok", "success", "succeeded":
+		return true
+	default:
+		return false
+	}
+}
+
+func statusLabel(status string) string {
+	if strings.TrimSpace(status) == "" {
+		return "missing"
+	}
+	return strings.TrimSpace(status)
+}
@@ -0,0 +1,108 @@
+package promote
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEnforcePolicy(t *testing.T) {
+	minCoverage := 80.0
+	lowCoverage := 72.5
+	required := true
+	cases := []struct {
+		name    string
+		cfg     Policy
+		in      PolicyInput
+		wantMsg string
+	}{
+		{
+			name: "no policy",
+			cfg:  Policy{},
+		},
+		{
+			name:    "stale",
+			cfg:     Policy{RequiredChecks: []string{"ci"}},
+			in:      PolicyInput{Stale: true},
+			wantMsg: "CI results are stale",
+		},
+		{
+			name:    "missing attestation",
+			cfg:     Policy{RequiredChecks: []string{"ci"}},
+			wantMsg: "no CI results found",
+		},
+		{
+			name:    "failing tests",
+			cfg:     Policy{RequiredChecks: []string{"test"}},
+			in:      PolicyInput{Attestation: &Attestation{Status: "pass", TestStatus: "fail"}},
+			wantMsg: "test status fail",
+		},
+		{
+			name:    "missing compile status",
+			cfg:     Policy{RequiredChecks: []string{"build"}},
+			in:      PolicyInput{Attestation: &Attestation{Status: "pass"}},
+			wantMsg: "compile status missing",
+		},
+		{
+			name:    "coverage below threshold",
+			cfg:     Policy{MinCoveragePct: &minCoverage},
+			in:      PolicyInput{Attestation: &Attestation{Status: "pass", CoverageBranchPct: &lowCoverage}},
+			wantMsg: "coverage 72.5% below policy threshold 80.0%",
+		},
+		{
+			name:    "pending suggestions",
+			cfg:     Policy{RequireSuggestionsAddressed: &required},
+			in:      PolicyInput{Attestation: &Attestation{Status: "pass"}, PendingSuggestions: true},
+			wantMsg: "pending suggestions must be addressed",
+		},
+		{
+			name: "passing",
+			cfg:  Policy{RequiredChecks: []string{"ci", "test"}, RequireSuggestionsAddressed: &required},
+			in:   PolicyInput{Attestation: &Attestation{Status: "passed", TestStatus: "ok"}},
+		},
+		{
+			name:    "findings above max severity",
+			cfg:     Policy{MaxSeverity: "medium"},
+			in:      PolicyInput{FindingSeverities: []string{"low", "high", "critical", "medium"}},
+			wantMsg: "2 review finding(s) above max severity medium (worst: critical)",
+		},
+		{
+			name: "findings within max severity",
+			cfg:  Policy{MaxSeverity: "high"},
+			in:   PolicyInput{FindingSeverities: []string{"info", "high"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := EnforcePolicy(tc.cfg, tc.in)
+			if tc.wantMsg == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var perr Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected promote error, got %v", err)
+			}
+			if perr.Code != "promote_policy_failed" || !strings.Contains(perr.Message, tc.wantMsg) {
+				t.Fatalf("expected %q, got %s: %s", tc.wantMsg, perr.Code, perr.Message)
+			}
+		})
+	}
+}
+
+func TestResolveStrategy(t *testing.T) {
+	if got, err := ResolveStrategy("", "Squash", true); err != nil || got != "squash" {
+		t.Fatalf("expected policy strategy squash, got %q (%v)", got, err)
+	}
+	if got, err := ResolveStrategy("merge", "squash", true); err != nil || got != "merge" {
+		t.Fatalf("expected explicit strategy merge, got %q (%v)", got, err)
+	}
+	if got, err := ResolveStrategy("", "squash", false); err != nil || got != "rebase" {
+		t.Fatalf("expected default rebase, got %q (%v)", got, err)
+	}
+	if _, err := ResolveStrategy("octopus", "", false); err == nil {
+		t.Fatalf("expected invalid strategy error")
+	}
+}
@@ -0,0 +1,113 @@
+package promote
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+// Repo locates the repository a promote runs against. The CLI passes its
+// working tree via WorkDir; the server passes a bare repository via GitDir.
+type Repo struct {
+	WorkDir string
+	GitDir  string
+	// ScratchDir holds temporary worktrees. Defaults to <WorkDir>/.jul or
+	// <GitDir>/jul-promote.
+	ScratchDir string
+	// Env is the full environment for git commands; nil inherits os.Environ.
+	Env []string
+}
+
+func (r Repo) Git(args ...string) (string, error) {
+	return r.run("", nil, args...)
+}
+
+func (r Repo) gitIn(dir string, args ...string) (string, error) {
+	return r.run(dir, nil, args...)
+}
+
+func (r Repo) gitInput(stdin []byte, args ...string) (string, error) {
+	return r.run("", stdin, args...)
+}
+
+func (r Repo) run(dir string, stdin []byte, args ...string) (string, error) {
+	full := make([]string, 0, len(args)+2)
+	switch {
+	case dir != "":
+		full = append(full, "-C", dir)
+	case strings.TrimSpace(r.GitDir) != "":
+		full = append(full, "--git-dir", r.GitDir)
+	case strings.TrimSpace(r.WorkDir) != "":
+		full = append(full, "-C", r.WorkDir)
+	}
+	full = append(full, args...)
+	cmd := exec.Command("git", full...)
+	if r.Env != nil {
+		cmd.Env = r.Env
+	}
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()+" "+out.String()))
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+func (r Repo) scratchDir() string {
+	if strings.TrimSpace(r.ScratchDir) != "" {
+		return r.ScratchDir
+	}
+	if strings.TrimSpace(r.WorkDir) != "" {
+		return filepath.Join(r.WorkDir, ".jul")
+	}
+	return filepath.Join(r.GitDir, "jul-promote")
+}
+
+func (r Repo) CommitMessage(sha string) (string, error) {
+	return r.Git("log", "-1", "--format=%B", sha)
+}
+
+// ChangeID returns the Change-Id trailer of sha's commit message, if any.
+func (r Repo) ChangeID(sha string) string {
+	msg, err := r.CommitMessage(sha)
+	if err != nil {
+		return ""
+	}
+	return gitutil.ExtractChangeID(msg)
+}
+
+func (r Repo) IsAncestor(ancestor, descendant string) bool {
+	_, err := r.Git("merge-base", "--is-ancestor", ancestor, descendant)
+	return err == nil
+}
+
+func (r Repo) createWorktree(baseSHA string) (string, func(), error) {
+	root := r.scratchDir()
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", nil, err
+	}
+	dir, err := os.MkdirTemp(root, "promote-worktree-")
+	if err != nil {
+		return "", nil, err
+	}
+	if _, err := r.Git("worktree", "add", "--detach", dir, baseSHA); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", nil, err
+	}
+	cleanup := func() {
+		_, _ = r.Git("worktree", "remove", "--force", dir)
+		_ = os.RemoveAll(dir)
+		_, _ = r.Git("worktree", "prune")
+	}
+	return dir, cleanup, nil
+}
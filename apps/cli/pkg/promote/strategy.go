@@ -0,0 +1,256 @@
+package promote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lydakis/jul/cli/internal/gitutil"
+)
+
+// NextAction mirrors the CLI's JSON next-action shape so the server can
+// return it without linking the CLI's output package.
+type NextAction struct {
+	Action  string `json:"action"`
+	Command string `json:"command"`
+}
+
+// Error is a promote blocker with a stable code that both the CLI JSON
+// output and the server API surface to callers.
+type Error struct {
+	Code    string
+	Message string
+	Next    []NextAction
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+func ResolveStrategy(explicit, policyStrategy string, policyOK bool) (string, error) {
+	strategy := strings.TrimSpace(explicit)
+	if strategy == "" && policyOK {
+		strategy = strings.TrimSpace(policyStrategy)
+	}
+	if strategy == "" {
+		strategy = "rebase"
+	}
+	strategy = strings.ToLower(strategy)
+	switch strategy {
+	case "rebase", "squash", "merge":
+		return strategy, nil
+	default:
+		return "", Error{
+			Code:    "promote_invalid_strategy",
+			Message: fmt.Sprintf("unsupported promote strategy %q", strategy),
+		}
+	}
+}
+
+// Checkpoint is the wire shape of a checkpoint in the change meta note.
+type Checkpoint struct {
+	SHA     string `json:"sha"`
+	Message string `json:"message,omitempty"`
+}
+
+type Plan struct {
+	Strategy    string
+	ChangeID    string
+	Checkpoints []Checkpoint
+	// TargetTip is the authoritative tip of the target branch (the remote
+	// tip for the CLI, the bare repo ref for the server).
+	TargetTip string
+	// LocalTip is used as the squash/merge base when TargetTip is empty.
+	LocalTip string
+	Force    bool
+}
+
+type Result struct {
+	Published      []string
+	PublishedTip   string
+	MergeCommitSHA *string
+	Mainline       *int
+}
+
+// Apply rewrites the plan's checkpoints onto the target according to its
+// strategy. It does not move any refs.
+func Apply(repo Repo, plan Plan) (Result, error) {
+	if len(plan.Checkpoints) == 0 {
+		return Result{}, fmt.Errorf("promote checkpoints required")
+	}
+	checkpointSHAs := make([]string, 0, len(plan.Checkpoints))
+	for _, cp := range plan.Checkpoints {
+		checkpointSHAs = append(checkpointSHAs, strings.TrimSpace(cp.SHA))
+	}
+	sha := checkpointSHAs[len(checkpointSHAs)-1]
+	targetTip := strings.TrimSpace(plan.TargetTip)
+
+	var res Result
+	switch plan.Strategy {
+	case "rebase":
+		if targetTip == "" || repo.IsAncestor(targetTip, sha) || plan.Force {
+			res.Published = append(res.Published, checkpointSHAs...)
+		} else {
+			published, err := Rebase(repo, targetTip, checkpointSHAs)
+			if err != nil {
+				return Result{}, err
+			}
+			if len(published) == 0 {
+				return Result{}, fmt.Errorf("rebase produced no published commits")
+			}
+			res.Published = published
+		}
+		res.PublishedTip = res.Published[len(res.Published)-1]
+	case "squash", "merge":
+		baseTip := BaseTip(repo, targetTip, plan.LocalTip, plan.Checkpoints)
+		msg, _ := repo.CommitMessage(sha)
+		msg = EnsureChangeID(msg, plan.ChangeID)
+		var err error
+		if plan.Strategy == "squash" {
+			res.PublishedTip, err = Squash(repo, baseTip, checkpointSHAs, msg)
+		} else {
+			res.PublishedTip, err = Merge(repo, baseTip, sha, msg)
+		}
+		if err != nil {
+			return Result{}, err
+		}
+		res.Published = []string{res.PublishedTip}
+		if plan.Strategy == "merge" {
+			mergeCommitSHA := res.PublishedTip
+			mainline := 1
+			res.MergeCommitSHA = &mergeCommitSHA
+			res.Mainline = &mainline
+		}
+	default:
+		return Result{}, Error{
+			Code:    "promote_invalid_strategy",
+			Message: fmt.Sprintf("unsupported promote strategy %q", plan.Strategy),
+		}
+	}
+	return res, nil
+}
+
+// BaseTip picks the commit squash and merge build on: the target tip, then
+// the local tip, then the parent of the first checkpoint.
+func BaseTip(repo Repo, targetTip, localTip string, checkpoints []Checkpoint) string {
+	if strings.TrimSpace(targetTip) != "" {
+		return strings.TrimSpace(targetTip)
+	}
+	if strings.TrimSpace(localTip) != "" {
+		return strings.TrimSpace(localTip)
+	}
+	if len(checkpoints) == 0 {
+		return ""
+	}
+	parent, _ := repo.Git("rev-parse", checkpoints[0].SHA+"^")
+	return strings.TrimSpace(parent)
+}
+
+func Rebase(repo Repo, baseTip string, checkpoints []string) ([]string, error) {
+	if strings.TrimSpace(baseTip) == "" || len(checkpoints) == 0 {
+		return nil, fmt.Errorf("rebase base and checkpoints required")
+	}
+	worktree, cleanup, err := repo.createWorktree(baseTip)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	published := make([]string, 0, len(checkpoints))
+	for _, sha := range checkpoints {
+		if _, err := repo.gitIn(worktree, "cherry-pick", "--allow-empty", sha); err != nil {
+			_, _ = repo.gitIn(worktree, "cherry-pick", "--abort")
+			if strings.Contains(strings.ToLower(err.Error()), "conflict") {
+				return nil, Error{
+					Code:    "promote_rebase_conflict",
+					Message: "promote rebase conflict; resolve and retry",
+					Next: []NextAction{
+						{Action: "merge", Command: "jul merge --json"},
+					},
+				}
+			}
+			return nil, err
+		}
+		head, err := repo.gitIn(worktree, "rev-parse", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		published = append(published, strings.TrimSpace(head))
+	}
+	return published, nil
+}
+
+func Squash(repo Repo, baseTip string, checkpoints []string, message string) (string, error) {
+	if strings.TrimSpace(baseTip) == "" || len(checkpoints) == 0 {
+		return "", fmt.Errorf("squash base and checkpoints required")
+	}
+	worktree, cleanup, err := repo.createWorktree(baseTip)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	for _, sha := range checkpoints {
+		if _, err := repo.gitIn(worktree, "cherry-pick", "--allow-empty", "--no-commit", sha); err != nil {
+			_, _ = repo.gitIn(worktree, "cherry-pick", "--abort")
+			if strings.Contains(strings.ToLower(err.Error()), "conflict") {
+				return "", Error{
+					Code:    "promote_squash_conflict",
+					Message: "promote squash conflict; resolve and retry",
+					Next: []NextAction{
+						{Action: "merge", Command: "jul merge --json"},
+					},
+				}
+			}
+			return "", err
+		}
+	}
+	return repo.commitWorktree(worktree, message)
+}
+
+func Merge(repo Repo, baseTip, mergeSHA, message string) (string, error) {
+	if strings.TrimSpace(baseTip) == "" || strings.TrimSpace(mergeSHA) == "" {
+		return "", fmt.Errorf("merge base and checkpoint required")
+	}
+	worktree, cleanup, err := repo.createWorktree(baseTip)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	if _, err := repo.gitIn(worktree, "merge", "--no-ff", "--no-commit", mergeSHA); err != nil {
+		_, _ = repo.gitIn(worktree, "merge", "--abort")
+		if strings.Contains(strings.ToLower(err.Error()), "conflict") {
+			return "", Error{
+				Code:    "promote_merge_conflict",
+				Message: "promote merge conflict; resolve and retry",
+				Next: []NextAction{
+					{Action: "merge", Command: "jul merge --json"},
+				},
+			}
+		}
+		return "", err
+	}
+	return repo.commitWorktree(worktree, message)
+}
+
+func (r Repo) commitWorktree(worktree, message string) (string, error) {
+	wt := Repo{WorkDir: worktree, Env: r.Env}
+	if _, err := wt.gitInput([]byte(message), "commit", "--allow-empty", "-F", "-"); err != nil {
+		return "", fmt.Errorf("git commit failed: %w", err)
+	}
+	head, err := wt.Git("rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(head), nil
+}
+
+func EnsureChangeID(message, changeID string) string {
+	if strings.TrimSpace(changeID) == "" {
+		return message
+	}
+	if gitutil.ExtractChangeID(message) != "" {
+		return message
+	}
+	return strings.TrimSpace(message) + "\n\nChange-Id: " + strings.TrimSpace(changeID) + "\n"
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/lydakis/jul/server/internal/events"
 	"github.com/lydakis/jul/server/internal/server"
@@ -13,15 +14,23 @@ import (
 const version = "0.0.1"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
 	addr := flag.String("addr", ":8000", "HTTP listen address")
 	dbPath := flag.String("db", "var/jul/data/jul.db", "SQLite database path")
+	dsn := flag.String("dsn", "", "Database DSN (postgres://... or sqlite://...); overrides --db")
 	baseURL := flag.String("base-url", "", "Public base URL (optional)")
 	reposDir := flag.String("repos", "./repos", "Directory containing bare git repositories")
 	flag.Parse()
 
 	fmt.Printf("jul-server %s listening on %s\n", version, *addr)
 
-	store, err := storage.Open(*dbPath)
+	store, err := storage.OpenDSN(databaseDSN(*dsn, *dbPath))
 	if err != nil {
 		log.Fatalf("failed to open database: %v", err)
 	}
@@ -37,3 +46,10 @@ func main() {
 		log.Fatalf("server error: %v", err)
 	}
 }
+
+func databaseDSN(dsn, dbPath string) string {
+	if dsn != "" {
+		return dsn
+	}
+	return dbPath
+}
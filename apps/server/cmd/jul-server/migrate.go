@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/lydakis/jul/server/internal/storage"
+)
+
+func runMigrate(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dbPath := fs.String("db", "var/jul/data/jul.db", "SQLite database path")
+	dsn := fs.String("dsn", "", "Database DSN (postgres://... or sqlite://...); overrides --db")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: jul-server migrate [--db path | --dsn url] status|up [version]|down [version]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fs.Usage()
+		return fmt.Errorf("migrate subcommand required")
+	}
+
+	store, err := storage.OpenWithoutMigrations(databaseDSN(*dsn, *dbPath))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	switch rest[0] {
+	case "status":
+		statuses, err := store.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			state := "pending"
+			if st.Applied {
+				state = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%4d  %-28s %s\n", st.Version, st.Name, state)
+		}
+		return nil
+	case "up":
+		target, err := migrateTarget(rest[1:], storage.LatestMigrationVersion())
+		if err != nil {
+			return err
+		}
+		ran, err := store.MigrateUp(ctx, target)
+		for _, st := range ran {
+			fmt.Fprintf(out, "applied %d %s\n", st.Version, st.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(ran) == 0 {
+			fmt.Fprintln(out, "schema up to date")
+		}
+		return nil
+	case "down":
+		current, err := currentMigration(ctx, store)
+		if err != nil {
+			return err
+		}
+		target, err := migrateTarget(rest[1:], current-1)
+		if err != nil {
+			return err
+		}
+		reverted, err := store.MigrateDown(ctx, target)
+		for _, st := range reverted {
+			fmt.Fprintf(out, "reverted %d %s\n", st.Version, st.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(out, "nothing to revert")
+		}
+		return nil
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown migrate subcommand %q", rest[0])
+	}
+}
+
+func migrateTarget(args []string, fallback int) (int, error) {
+	if len(args) == 0 {
+		if fallback < 0 {
+			return 0, nil
+		}
+		return fallback, nil
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid migration version %q", args[0])
+	}
+	return version, nil
+}
+
+func currentMigration(ctx context.Context, store storage.Store) (int, error) {
+	statuses, err := store.MigrationStatus(ctx)
+	if err != nil {
+		return 0, err
+	}
+	current := 0
+	for _, st := range statuses {
+		if st.Applied && st.Version > current {
+			current = st.Version
+		}
+	}
+	return current, nil
+}
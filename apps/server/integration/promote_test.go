@@ -3,7 +3,9 @@ package integration
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -164,3 +166,156 @@ func TestPromotionRejectsNonFastForward(t *testing.T) {
 		t.Fatalf("expected ref %s, got %s", commit2, refSHA)
 	}
 }
+
+func TestPromotionEnforcesPolicyAndRecordsEvent(t *testing.T) {
+	repoRoot := t.TempDir()
+	repoName := "demo"
+
+	bareRepo := filepath.Join(repoRoot, repoName+".git")
+	runCmd(t, repoRoot, nil, "git", "init", "--bare", bareRepo)
+	policy := "[promote]\nstrategy = \"squash\"\nrequired_checks = [\"test\"]\n"
+	if err := os.MkdirAll(filepath.Join(bareRepo, ".jul"), 0o755); err != nil {
+		t.Fatalf("mkdir policy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(bareRepo, ".jul", "policy.toml"), []byte(policy), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	baseURL, cleanup := startServer(t, repoRoot)
+	defer cleanup()
+
+	repo := t.TempDir()
+	runCmd(t, repo, nil, "git", "init")
+	runCmd(t, repo, nil, "git", "config", "user.name", "Test User")
+	runCmd(t, repo, nil, "git", "config", "user.email", "test@example.com")
+	runCmd(t, repo, nil, "git", "remote", "add", "origin", bareRepo)
+
+	writeFile(t, repo, "README.md", "hello\n")
+	runCmd(t, repo, nil, "git", "add", "README.md")
+	runCmd(t, repo, nil, "git", "commit", "-m", "chore: base")
+	baseSHA := strings.TrimSpace(runCmd(t, repo, nil, "git", "rev-parse", "HEAD"))
+	runCmd(t, repo, nil, "git", "push", "origin", "HEAD:main")
+
+	changeID := "I" + strings.Repeat("a", 40)
+	writeFile(t, repo, "feature.txt", "one\n")
+	runCmd(t, repo, nil, "git", "add", "feature.txt")
+	runCmd(t, repo, nil, "git", "commit", "-m", "feat: one\n\nChange-Id: "+changeID)
+	first := strings.TrimSpace(runCmd(t, repo, nil, "git", "rev-parse", "HEAD"))
+	writeFile(t, repo, "feature.txt", "one\ntwo\n")
+	runCmd(t, repo, nil, "git", "commit", "-am", "feat: two\n\nChange-Id: "+changeID)
+	second := strings.TrimSpace(runCmd(t, repo, nil, "git", "rev-parse", "HEAD"))
+	runCmd(t, repo, nil, "git", "push", "origin", "HEAD:refs/heads/feature")
+
+	workspaceID := "tester/workspace"
+	for _, sha := range []string{first, second} {
+		resp, _ := postJSON(t, baseURL+"/api/v1/sync", storage.SyncPayload{
+			WorkspaceID: workspaceID,
+			Repo:        repoName,
+			Branch:      "feature",
+			CommitSHA:   sha,
+			ChangeID:    changeID,
+			Message:     "feat",
+			Author:      "Test User",
+			CommittedAt: time.Now().UTC(),
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("sync expected 200, got %d", resp.StatusCode)
+		}
+	}
+
+	resp, _ := postJSON(t, baseURL+"/api/v1/attestations", map[string]any{
+		"commit_sha":   second,
+		"change_id":    changeID,
+		"type":         "ci",
+		"status":       "pass",
+		"test_status":  "fail",
+		"started_at":   time.Now().UTC(),
+		"finished_at":  time.Now().UTC(),
+		"signals_json": "{}",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("attestation expected 201, got %d", resp.StatusCode)
+	}
+
+	promoteURL := baseURL + "/api/v1/workspaces/" + workspaceID + "/promote"
+	resp, payload := postJSON(t, promoteURL, map[string]any{
+		"target_branch": "main",
+		"commit_sha":    second,
+	})
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", resp.StatusCode, payload)
+	}
+	var blocked struct {
+		Code     string   `json:"code"`
+		Blockers []string `json:"blockers"`
+	}
+	decodeJSON(t, string(payload), &blocked)
+	if blocked.Code != "promote_policy_failed" || len(blocked.Blockers) != 1 || !strings.Contains(blocked.Blockers[0], "test status fail") {
+		t.Fatalf("unexpected blocked response: %s", payload)
+	}
+
+	resp, payload = postJSON(t, promoteURL, map[string]any{
+		"target_branch": "main",
+		"commit_sha":    second,
+		"no_policy":     true,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, payload)
+	}
+	var promoted struct {
+		Strategy  string   `json:"strategy"`
+		Published []string `json:"published"`
+	}
+	decodeJSON(t, string(payload), &promoted)
+	if promoted.Strategy != "squash" || len(promoted.Published) != 1 {
+		t.Fatalf("unexpected promote response: %s", payload)
+	}
+
+	mainSHA := strings.TrimSpace(runCmd(t, repoRoot, nil, "git", "--git-dir", bareRepo, "rev-parse", "refs/heads/main"))
+	if mainSHA != promoted.Published[0] {
+		t.Fatalf("expected main at %s, got %s", promoted.Published[0], mainSHA)
+	}
+	parent := strings.TrimSpace(runCmd(t, repoRoot, nil, "git", "--git-dir", bareRepo, "rev-parse", mainSHA+"^"))
+	if parent != baseSHA {
+		t.Fatalf("expected squash onto %s, got parent %s", baseSHA, parent)
+	}
+
+	metaRaw := runCmd(t, repoRoot, nil, "git", "--git-dir", bareRepo, "notes", "--ref", "refs/notes/jul/meta", "show", first)
+	var meta struct {
+		ChangeID      string `json:"change_id"`
+		PromoteEvents []struct {
+			Target         string   `json:"target"`
+			Strategy       string   `json:"strategy"`
+			CheckpointSHAs []string `json:"checkpoint_shas"`
+			PublishedSHAs  []string `json:"published_shas"`
+		} `json:"promote_events"`
+	}
+	decodeJSON(t, metaRaw, &meta)
+	if meta.ChangeID != changeID || len(meta.PromoteEvents) != 1 {
+		t.Fatalf("unexpected change meta: %s", metaRaw)
+	}
+	event := meta.PromoteEvents[0]
+	if event.Target != "main" || event.Strategy != "squash" || len(event.CheckpointSHAs) != 2 || event.PublishedSHAs[0] != mainSHA {
+		t.Fatalf("unexpected promote event: %+v", event)
+	}
+
+	noteRaw := runCmd(t, repoRoot, nil, "git", "--git-dir", bareRepo, "notes", "--ref", "refs/notes/jul/change-id", "show", mainSHA)
+	if !strings.Contains(noteRaw, changeID) {
+		t.Fatalf("expected change-id note on published commit, got %s", noteRaw)
+	}
+}
+
+func postJSON(t *testing.T, url string, payload any) (*http.Response, []byte) {
+	t.Helper()
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("post %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read response failed: %v", err)
+	}
+	return resp, data
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/lydakis/jul/cli/pkg/promote"
+	"github.com/lydakis/jul/server/internal/storage"
+)
+
+func (s *Server) handlePromote(w http.ResponseWriter, r *http.Request, workspaceID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		TargetBranch string `json:"target_branch"`
+		CommitSHA    string `json:"commit_sha"`
+		Strategy     string `json:"strategy"`
+		Force        bool   `json:"force"`
+		NoPolicy     bool   `json:"no_policy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if body.TargetBranch == "" {
+		writeError(w, http.StatusBadRequest, "target_branch required")
+		return
+	}
+
+	workspace, err := s.store.GetWorkspace(r.Context(), workspaceID)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			writeError(w, http.StatusNotFound, "workspace not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	commitSHA := body.CommitSHA
+	if commitSHA == "" {
+		commitSHA = workspace.LastCommitSHA
+	}
+	if commitSHA == "" {
+		writeError(w, http.StatusBadRequest, "commit_sha required")
+		return
+	}
+
+	if !body.Force {
+		att, err := s.store.GetLatestAttestation(r.Context(), commitSHA)
+		if err == nil && att.Status != "pass" {
+			writePromoteBlocked(w, http.StatusConflict, "", "attestation.status != pass", nil)
+			return
+		}
+		if err != nil && err != storage.ErrNotFound {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	repoPath, err := s.repoPath(workspace.Repo)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := os.Stat(repoPath); err != nil {
+		if os.IsNotExist(err) {
+			writeError(w, http.StatusNotFound, "repo not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	repo := promote.Repo{GitDir: repoPath, Env: withGitIdentityEnv(os.Environ())}
+	policyCfg, policyOK, err := promote.LoadPolicy(repoPath, body.TargetBranch)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	strategy, err := promote.ResolveStrategy(body.Strategy, policyCfg.Strategy, policyOK)
+	if err != nil {
+		writePromoteError(w, err)
+		return
+	}
+
+	changeID := s.promoteChangeID(r.Context(), repo, commitSHA)
+	if !body.NoPolicy && policyOK {
+		in, err := s.promotePolicyInput(r.Context(), repo, policyCfg, commitSHA, changeID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if err := promote.EnforcePolicy(policyCfg, in); err != nil {
+			writePromoteError(w, err)
+			return
+		}
+	}
+
+	targetTip, exists, err := readRef(repoPath, "refs/heads/"+body.TargetBranch)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if exists && !body.Force && targetTip != commitSHA && repo.IsAncestor(commitSHA, targetTip) {
+		// Already contained in the target; rewriting it would only duplicate
+		// history, so treat it like any other non-fast-forward request.
+		writePromoteBlocked(w, http.StatusConflict, "", "branch requires fast-forward", nil)
+		return
+	}
+
+	checkpoints, anchorSHA := s.promoteCheckpoints(r.Context(), repo, changeID, commitSHA, targetTip)
+	res, err := promote.Apply(repo, promote.Plan{
+		Strategy:    strategy,
+		ChangeID:    changeID,
+		Checkpoints: checkpoints,
+		TargetTip:   targetTip,
+		Force:       body.Force,
+	})
+	if err != nil {
+		writePromoteError(w, err)
+		return
+	}
+
+	if err := updateRef(repoPath, body.TargetBranch, res.PublishedTip, body.Force); err != nil {
+		if errors.Is(err, ErrNonFastForward) {
+			writePromoteBlocked(w, http.StatusConflict, "", "branch requires fast-forward", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	checkpointSHAs := make([]string, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		checkpointSHAs = append(checkpointSHAs, cp.SHA)
+	}
+	if _, err := promote.RecordEvent(repo, promote.GitNotes{Repo: repo}, promote.Event{
+		Branch:         body.TargetBranch,
+		Strategy:       strategy,
+		ChangeID:       changeID,
+		AnchorSHA:      anchorSHA,
+		Checkpoints:    checkpoints,
+		CheckpointSHAs: checkpointSHAs,
+		PublishedSHAs:  res.Published,
+		MergeCommitSHA: res.MergeCommitSHA,
+		Mainline:       res.Mainline,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data := map[string]any{
+		"workspace_id":  workspaceID,
+		"target_branch": body.TargetBranch,
+		"commit_sha":    commitSHA,
+		"strategy":      strategy,
+		"published_sha": res.PublishedTip,
+	}
+	s.emitEvent(r.Context(), "promote.applied", data)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":        "promoted",
+		"branch":        body.TargetBranch,
+		"commit_sha":    commitSHA,
+		"workspace_id":  workspaceID,
+		"strategy":      strategy,
+		"published":     res.Published,
+		"published_sha": res.PublishedTip,
+	})
+}
+
+func (s *Server) promoteChangeID(ctx context.Context, repo promote.Repo, commitSHA string) string {
+	if rev, err := s.store.GetRevisionByCommit(ctx, commitSHA); err == nil && strings.TrimSpace(rev.ChangeID) != "" {
+		return rev.ChangeID
+	}
+	return repo.ChangeID(commitSHA)
+}
+
+func (s *Server) promotePolicyInput(ctx context.Context, repo promote.Repo, cfg promote.Policy, commitSHA, changeID string) (promote.PolicyInput, error) {
+	in := promote.PolicyInput{CheckpointSHA: commitSHA}
+	if promote.GatesFindings(cfg) {
+		severities, err := promote.FindingSeverities(promote.GitNotes{Repo: repo}, commitSHA)
+		if err != nil {
+			return promote.PolicyInput{}, err
+		}
+		in.FindingSeverities = severities
+	}
+	att, err := s.store.GetLatestAttestation(ctx, commitSHA)
+	switch {
+	case err == nil:
+		in.Attestation = &promote.Attestation{
+			Status:            att.Status,
+			TestStatus:        att.TestStatus,
+			CompileStatus:     att.CompileStatus,
+			CoverageLinePct:   att.CoverageLinePct,
+			CoverageBranchPct: att.CoverageBranchPct,
+		}
+	case err != storage.ErrNotFound:
+		return promote.PolicyInput{}, err
+	}
+	if promote.RequiresSuggestionsAddressed(cfg) && strings.TrimSpace(changeID) != "" {
+		pending, _, err := s.store.ListSuggestions(ctx, storage.ListFilters{ChangeID: changeID, Status: "pending", Limit: 1})
+		if err != nil {
+			return promote.PolicyInput{}, err
+		}
+		in.PendingSuggestions = len(pending) > 0
+	}
+	return in, nil
+}
+
+// promoteCheckpoints returns the synced revisions of the change that lead up
+// to commitSHA and are not yet on the target, falling back to commitSHA alone.
+func (s *Server) promoteCheckpoints(ctx context.Context, repo promote.Repo, changeID, commitSHA, targetTip string) ([]promote.Checkpoint, string) {
+	anchorSHA := ""
+	var checkpoints []promote.Checkpoint
+	if strings.TrimSpace(changeID) != "" {
+		revisions, err := s.store.ListRevisions(ctx, changeID)
+		if err == nil && len(revisions) > 0 {
+			anchorSHA = revisions[0].CommitSHA
+			for _, rev := range revisions {
+				if rev.CommitSHA != commitSHA && !repo.IsAncestor(rev.CommitSHA, commitSHA) {
+					continue
+				}
+				if targetTip != "" && repo.IsAncestor(rev.CommitSHA, targetTip) {
+					continue
+				}
+				checkpoints = append(checkpoints, promote.Checkpoint{SHA: rev.CommitSHA, Message: firstLine(rev.Message)})
+				if rev.CommitSHA == commitSHA {
+					break
+				}
+			}
+		}
+	}
+	if len(checkpoints) == 0 || checkpoints[len(checkpoints)-1].SHA != commitSHA {
+		msg, _ := repo.CommitMessage(commitSHA)
+		checkpoints = []promote.Checkpoint{{SHA: commitSHA, Message: firstLine(msg)}}
+	}
+	if anchorSHA == "" {
+		anchorSHA = checkpoints[0].SHA
+	}
+	return checkpoints, anchorSHA
+}
+
+func writePromoteError(w http.ResponseWriter, err error) {
+	var perr promote.Error
+	if !errors.As(err, &perr) {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	status := http.StatusConflict
+	if perr.Code == "promote_invalid_strategy" {
+		status = http.StatusBadRequest
+	}
+	writePromoteBlocked(w, status, perr.Code, perr.Message, perr.Next)
+}
+
+func writePromoteBlocked(w http.ResponseWriter, status int, code, blocker string, next []promote.NextAction) {
+	payload := map[string]any{
+		"error":    "promotion blocked",
+		"blockers": []string{blocker},
+	}
+	if code != "" {
+		payload["code"] = code
+	}
+	if len(next) > 0 {
+		payload["next_actions"] = next
+	}
+	writeJSON(w, status, payload)
+}
+
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lydakis/jul/server/internal/storage"
+)
+
+// repoSubresources are the list endpoints that can be scoped to a repo with
+// /api/v1/repos/{repo}/{name}.
+var repoSubresources = map[string]func(*Server, http.ResponseWriter, *http.Request){
+	"workspaces":   (*Server).handleWorkspaces,
+	"changes":      (*Server).handleChanges,
+	"attestations": (*Server).handleAttestations,
+	"suggestions":  (*Server).handleSuggestions,
+	"query":        (*Server).handleQuery,
+}
+
+type RepoInfo struct {
+	Name          string    `json:"name"`
+	Owner         string    `json:"owner"`
+	Description   string    `json:"description"`
+	DefaultBranch string    `json:"default_branch"`
+	CloneURL      string    `json:"clone_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (s *Server) repoInfo(repo storage.Repo) RepoInfo {
+	return RepoInfo{
+		Name:          repo.Name,
+		Owner:         repo.Owner,
+		Description:   repo.Description,
+		DefaultBranch: repo.DefaultBranch,
+		CloneURL:      repoCloneURL(s.cfg.BaseURL, repo.Name),
+		CreatedAt:     repo.CreatedAt,
+		UpdatedAt:     repo.UpdatedAt,
+	}
+}
+
+func (s *Server) handleRepos(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		filters, err := parseListFilters(r, 0)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		repos, next, err := s.store.ListRepos(r.Context(), filters)
+		if err != nil {
+			writeListError(w, err)
+			return
+		}
+		out := make([]RepoInfo, 0, len(repos))
+		for _, repo := range repos {
+			out = append(out, s.repoInfo(repo))
+		}
+		writePage(w, out, next)
+	case http.MethodPost:
+		s.handleCreateRepo(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleCreateRepo(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name          string `json:"name"`
+		Owner         string `json:"owner"`
+		Description   string `json:"description"`
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	name := strings.Trim(strings.TrimSpace(body.Name), "/")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "name required")
+		return
+	}
+
+	repoPath, err := s.repoPath(name)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRepoName) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	initialized, err := initBareRepo(repoPath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	owner := strings.TrimSpace(body.Owner)
+	if owner == "" {
+		owner = repoOwner(name)
+	}
+	repo, created, err := s.store.EnsureRepo(r.Context(), storage.Repo{
+		Name:          name,
+		Owner:         owner,
+		Description:   body.Description,
+		DefaultBranch: body.DefaultBranch,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status := http.StatusOK
+	if initialized || created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, s.repoInfo(repo))
+}
+
+func (s *Server) handleRepoRoutes(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/repos/")
+	path = strings.Trim(path, "/")
+	if path == "" {
+		writeError(w, http.StatusBadRequest, "repo name required")
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) >= 2 {
+		if handler, ok := repoSubresources[parts[len(parts)-1]]; ok {
+			s.handleRepoSubresource(w, r, strings.Join(parts[:len(parts)-1], "/"), handler)
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		repo, err := s.lookupRepo(r, path)
+		if err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.repoInfo(repo))
+	case http.MethodPatch:
+		var body struct {
+			Owner         *string `json:"owner"`
+			Description   *string `json:"description"`
+			DefaultBranch *string `json:"default_branch"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		if _, err := s.lookupRepo(r, path); err != nil {
+			writeRepoError(w, err)
+			return
+		}
+		repo, err := s.store.UpdateRepo(r.Context(), path, storage.RepoUpdate{
+			Owner:         body.Owner,
+			Description:   body.Description,
+			DefaultBranch: body.DefaultBranch,
+		})
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				writeRepoError(w, err)
+				return
+			}
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, s.repoInfo(repo))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleRepoSubresource(w http.ResponseWriter, r *http.Request, name string, handler func(*Server, http.ResponseWriter, *http.Request)) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	repo, err := s.lookupRepo(r, name)
+	if err != nil {
+		writeRepoError(w, err)
+		return
+	}
+	scoped := r.Clone(r.Context())
+	q := scoped.URL.Query()
+	q.Set("repo", repo.Name)
+	scoped.URL.RawQuery = q.Encode()
+	handler(s, w, scoped)
+}
+
+// lookupRepo returns the repo record, creating one for bare repos that were
+// initialised before repos were tracked in the database.
+func (s *Server) lookupRepo(r *http.Request, name string) (storage.Repo, error) {
+	repo, err := s.store.GetRepo(r.Context(), name)
+	if err == nil || !errors.Is(err, storage.ErrNotFound) {
+		return repo, err
+	}
+	repoPath, pathErr := s.repoPath(name)
+	if pathErr != nil {
+		return storage.Repo{}, pathErr
+	}
+	if _, statErr := os.Stat(repoPath); statErr != nil {
+		return storage.Repo{}, ErrRepoNotFound
+	}
+	repo, _, err = s.store.EnsureRepo(r.Context(), storage.Repo{Name: name, Owner: repoOwner(name)})
+	return repo, err
+}
+
+func writeRepoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRepoName):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrRepoNotFound), errors.Is(err, storage.ErrNotFound):
+		writeError(w, http.StatusNotFound, "repo not found")
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// initBareRepo creates the bare repo at repoPath unless it already exists and
+// reports whether it did.
+func initBareRepo(repoPath string) (bool, error) {
+	if _, err := os.Stat(repoPath); err == nil {
+		return false, nil
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(repoPath), 0o755); err != nil {
+		return false, err
+	}
+	cmd := exec.Command("git", "init", "--bare", repoPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return false, fmt.Errorf("git init --bare failed: %s", strings.TrimSpace(string(output)))
+	}
+	return true, nil
+}
+
+// repoOwner derives the owner from a namespaced repo name such as
+// "acme/api"; flat names have no owner.
+func repoOwner(name string) string {
+	name = strings.TrimSuffix(strings.Trim(name, "/"), ".git")
+	if idx := strings.LastIndex(name, "/"); idx > 0 {
+		return name[:idx]
+	}
+	return ""
+}
+
+func repoCloneURL(baseURL, name string) string {
+	base := strings.TrimRight(baseURL, "/")
+	if base == "" {
+		return ""
+	}
+	if strings.HasSuffix(name, ".git") {
+		return base + "/" + name
+	}
+	return base + "/" + name + ".git"
+}
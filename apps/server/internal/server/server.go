@@ -28,7 +28,7 @@ type Config struct {
 type Server struct {
 	cfg    Config
 	mux    *http.ServeMux
-	store  *storage.Store
+	store  storage.Store
 	broker *events.Broker
 }
 
@@ -50,7 +50,7 @@ var ErrNonFastForward = errors.New("non-fast-forward update")
 var ErrInvalidRepoName = errors.New("invalid repo name")
 var ErrRepoNotFound = errors.New("repo not found")
 
-func New(cfg Config, store *storage.Store, broker *events.Broker) *Server {
+func New(cfg Config, store storage.Store, broker *events.Broker) *Server {
 	if cfg.Address == "" {
 		cfg.Address = ":8000"
 	}
@@ -96,6 +96,7 @@ func (s *Server) routes() {
 	s.mux.HandleFunc("/api/v1/suggestions", s.handleSuggestions)
 	s.mux.HandleFunc("/api/v1/suggestions/", s.handleSuggestionRoutes)
 	s.mux.HandleFunc("/api/v1/repos", s.handleRepos)
+	s.mux.HandleFunc("/api/v1/repos/", s.handleRepoRoutes)
 	s.mux.HandleFunc("/events/stream", s.handleEvents)
 }
 
@@ -107,7 +108,7 @@ func (s *Server) handleHealthz(w http.ResponseWriter, _ *http.Request) {
 func (s *Server) handleCapabilities(w http.ResponseWriter, _ *http.Request) {
 	payload := Capabilities{
 		Version:  "v1",
-		Features: []string{"workspaces", "changes", "attestations", "suggestions", "sync"},
+		Features: []string{"repos", "workspaces", "changes", "attestations", "suggestions", "sync"},
 		RefNamespaces: []string{
 			"refs/jul/workspaces",
 			"refs/jul/keep",
@@ -153,12 +154,17 @@ func (s *Server) handleWorkspaces(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workspaces, err := s.store.ListWorkspaces(r.Context())
+	filters, err := parseListFilters(r, 0)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	workspaces, next, err := s.store.ListWorkspaces(r.Context(), filters)
+	if err != nil {
+		writeListError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, workspaces)
+	writePage(w, workspaces, next)
 }
 
 func (s *Server) handleWorkspaceRoutes(w http.ResponseWriter, r *http.Request) {
@@ -253,100 +259,6 @@ func (s *Server) handleCheckpoint(w http.ResponseWriter, r *http.Request, worksp
 	writeJSON(w, http.StatusOK, result)
 }
 
-func (s *Server) handlePromote(w http.ResponseWriter, r *http.Request, workspaceID string) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-	var body struct {
-		TargetBranch string `json:"target_branch"`
-		CommitSHA    string `json:"commit_sha"`
-		Force        bool   `json:"force"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid json")
-		return
-	}
-	if body.TargetBranch == "" {
-		writeError(w, http.StatusBadRequest, "target_branch required")
-		return
-	}
-
-	workspace, err := s.store.GetWorkspace(r.Context(), workspaceID)
-	if err != nil {
-		if err == storage.ErrNotFound {
-			writeError(w, http.StatusNotFound, "workspace not found")
-			return
-		}
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	commitSHA := body.CommitSHA
-	if commitSHA == "" {
-		commitSHA = workspace.LastCommitSHA
-	}
-	if commitSHA == "" {
-		writeError(w, http.StatusBadRequest, "commit_sha required")
-		return
-	}
-
-	if !body.Force {
-		att, err := s.store.GetLatestAttestation(r.Context(), commitSHA)
-		if err == nil && att.Status != "pass" {
-			writeJSON(w, http.StatusConflict, map[string]any{
-				"error":    "promotion blocked",
-				"blockers": []string{"attestation.status != pass"},
-			})
-			return
-		}
-		if err != nil && err != storage.ErrNotFound {
-			writeError(w, http.StatusInternalServerError, err.Error())
-			return
-		}
-	}
-
-	repoPath, err := s.repoPath(workspace.Repo)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-	if _, err := os.Stat(repoPath); err != nil {
-		if os.IsNotExist(err) {
-			writeError(w, http.StatusNotFound, "repo not found")
-			return
-		}
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	if err := updateRef(repoPath, body.TargetBranch, commitSHA, body.Force); err != nil {
-		if errors.Is(err, ErrNonFastForward) {
-			writeJSON(w, http.StatusConflict, map[string]any{
-				"error":    "promotion blocked",
-				"blockers": []string{"branch requires fast-forward"},
-			})
-			return
-		}
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	data := map[string]any{
-		"workspace_id":  workspaceID,
-		"target_branch": body.TargetBranch,
-		"commit_sha":    commitSHA,
-	}
-	s.emitEvent(r.Context(), "promote.applied", data)
-
-	writeJSON(w, http.StatusOK, map[string]string{
-		"status":       "promoted",
-		"branch":       body.TargetBranch,
-		"commit_sha":   commitSHA,
-		"workspace_id": workspaceID,
-	})
-}
-
 func (s *Server) handleReflog(w http.ResponseWriter, r *http.Request, workspaceID string) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -405,12 +317,17 @@ func (s *Server) handleChanges(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	changes, err := s.store.ListChanges(r.Context())
+	filters, err := parseListFilters(r, 0)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, changes)
+	changes, next, err := s.store.ListChanges(r.Context(), filters)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writePage(w, changes, next)
 }
 
 func (s *Server) repoPath(repo string) (string, error) {
@@ -667,15 +584,17 @@ func (s *Server) handleCommitAttestation(w http.ResponseWriter, r *http.Request,
 func (s *Server) handleAttestations(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		commitSHA := r.URL.Query().Get("commit_sha")
-		changeID := r.URL.Query().Get("change_id")
-		status := r.URL.Query().Get("status")
-		atts, err := s.store.ListAttestations(r.Context(), commitSHA, changeID, status)
+		filters, err := parseListFilters(r, 0)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, atts)
+		atts, next, err := s.store.ListAttestations(r.Context(), filters)
+		if err != nil {
+			writeListError(w, err)
+			return
+		}
+		writePage(w, atts, next)
 	case http.MethodPost:
 		var body struct {
 			storage.Attestation
@@ -813,9 +732,12 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		"coverage_max": {},
 		"change_id":    {},
 		"author":       {},
+		"repo":         {},
+		"workspace":    {},
 		"since":        {},
 		"until":        {},
 		"limit":        {},
+		"cursor":       {},
 	}
 	for key := range r.URL.Query() {
 		if _, ok := allowed[key]; !ok {
@@ -866,33 +788,10 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		coverageMax = &parsed
 	}
 
-	var since *time.Time
-	if raw := strings.TrimSpace(r.URL.Query().Get("since")); raw != "" {
-		parsed, err := time.Parse(time.RFC3339, raw)
-		if err != nil {
-			writeError(w, http.StatusBadRequest, "since must be RFC3339")
-			return
-		}
-		utc := parsed.UTC()
-		since = &utc
-	}
-
-	var until *time.Time
-	if raw := strings.TrimSpace(r.URL.Query().Get("until")); raw != "" {
-		parsed, err := time.Parse(time.RFC3339, raw)
-		if err != nil {
-			writeError(w, http.StatusBadRequest, "until must be RFC3339")
-			return
-		}
-		utc := parsed.UTC()
-		until = &utc
-	}
-
-	limit := 20
-	if raw := r.URL.Query().Get("limit"); raw != "" {
-		if parsed, err := strconv.Atoi(raw); err == nil {
-			limit = parsed
-		}
+	page, err := parseListFilters(r, 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	filters := storage.QueryFilters{
@@ -900,106 +799,38 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		Compiles:    compiles,
 		CoverageMin: coverageMin,
 		CoverageMax: coverageMax,
-		ChangeID:    strings.TrimSpace(r.URL.Query().Get("change_id")),
+		ChangeID:    page.ChangeID,
 		Author:      strings.TrimSpace(r.URL.Query().Get("author")),
-		Since:       since,
-		Until:       until,
-		Limit:       limit,
-	}
-
-	results, err := s.store.QueryCommits(r.Context(), filters)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	writeJSON(w, http.StatusOK, results)
-}
-
-func (s *Server) handleRepos(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+		Repo:        page.Repo,
+		Workspace:   page.Workspace,
+		Since:       page.Since,
+		Until:       page.Until,
+		Cursor:      page.Cursor,
+		Limit:       page.Limit,
 	}
 
-	var body struct {
-		Name string `json:"name"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid json")
-		return
-	}
-	name := strings.TrimSpace(body.Name)
-	if name == "" {
-		writeError(w, http.StatusBadRequest, "name required")
-		return
-	}
-
-	repoPath, err := s.repoPath(name)
+	results, next, err := s.store.QueryCommits(r.Context(), filters)
 	if err != nil {
-		if errors.Is(err, ErrInvalidRepoName) {
-			writeError(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	if _, err := os.Stat(repoPath); err == nil {
-		writeJSON(w, http.StatusOK, RepoInfo{Name: name, CloneURL: repoCloneURL(s.cfg.BaseURL, name)})
-		return
-	} else if !os.IsNotExist(err) {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	if err := os.MkdirAll(filepath.Dir(repoPath), 0o755); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeListError(w, err)
 		return
 	}
-
-	cmd := exec.Command("git", "init", "--bare", repoPath)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Sprintf("git init --bare failed: %s", strings.TrimSpace(string(output))))
-		return
-	}
-
-	writeJSON(w, http.StatusCreated, RepoInfo{Name: name, CloneURL: repoCloneURL(s.cfg.BaseURL, name)})
-}
-
-type RepoInfo struct {
-	Name     string `json:"name"`
-	CloneURL string `json:"clone_url"`
-}
-
-func repoCloneURL(baseURL, name string) string {
-	base := strings.TrimRight(baseURL, "/")
-	if base == "" {
-		return ""
-	}
-	if strings.HasSuffix(name, ".git") {
-		return base + "/" + name
-	}
-	return base + "/" + name + ".git"
+	writePage(w, results, next)
 }
 
 func (s *Server) handleSuggestions(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		changeID := strings.TrimSpace(r.URL.Query().Get("change_id"))
-		status := strings.TrimSpace(r.URL.Query().Get("status"))
-		limit := 50
-		if raw := r.URL.Query().Get("limit"); raw != "" {
-			if parsed, err := strconv.Atoi(raw); err == nil {
-				limit = parsed
-			}
+		filters, err := parseListFilters(r, 50)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
 		}
-
-		suggestions, err := s.store.ListSuggestions(r.Context(), changeID, status, limit)
+		suggestions, next, err := s.store.ListSuggestions(r.Context(), filters)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeListError(w, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, suggestions)
+		writePage(w, suggestions, next)
 	case http.MethodPost:
 		var body struct {
 			ChangeID           string          `json:"change_id"`
@@ -1257,6 +1088,73 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 	_ = enc.Encode(payload)
 }
 
+// NextCursorHeader carries the opaque cursor for the next page of a list
+// response. It is absent on the last page.
+const NextCursorHeader = "X-Next-Cursor"
+
+const maxPageLimit = 500
+
+// parseListFilters reads the shared list parameters: repo, workspace, status,
+// change_id, commit_sha, since, until, cursor and limit. defaultLimit 0 keeps
+// the historical "return everything" behaviour when no limit is given.
+func parseListFilters(r *http.Request, defaultLimit int) (storage.ListFilters, error) {
+	q := r.URL.Query()
+	filters := storage.ListFilters{
+		Owner:     strings.TrimSpace(q.Get("owner")),
+		Repo:      strings.TrimSpace(q.Get("repo")),
+		Workspace: strings.TrimSpace(q.Get("workspace")),
+		Status:    strings.TrimSpace(q.Get("status")),
+		ChangeID:  strings.TrimSpace(q.Get("change_id")),
+		CommitSHA: strings.TrimSpace(q.Get("commit_sha")),
+		Cursor:    strings.TrimSpace(q.Get("cursor")),
+		Limit:     defaultLimit,
+	}
+	for _, bound := range []struct {
+		name   string
+		target **time.Time
+	}{{"since", &filters.Since}, {"until", &filters.Until}} {
+		raw := strings.TrimSpace(q.Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return storage.ListFilters{}, fmt.Errorf("%s must be RFC3339", bound.name)
+		}
+		utc := parsed.UTC()
+		*bound.target = &utc
+	}
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return storage.ListFilters{}, fmt.Errorf("limit must be a non-negative integer")
+		}
+		if parsed > maxPageLimit {
+			return storage.ListFilters{}, fmt.Errorf("limit must be at most %d", maxPageLimit)
+		}
+		filters.Limit = parsed
+	}
+	if filters.Cursor != "" && filters.Limit == 0 {
+		filters.Limit = maxPageLimit
+	}
+	return filters, nil
+}
+
+func writePage(w http.ResponseWriter, items any, next string) {
+	if next != "" {
+		w.Header().Set(NextCursorHeader, next)
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func writeListError(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
 func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, map[string]string{"error": message})
 }
@@ -15,7 +15,7 @@ import (
 	"github.com/lydakis/jul/server/internal/storage"
 )
 
-func newTestServer(t *testing.T) (*Server, *storage.Store) {
+func newTestServer(t *testing.T) (*Server, storage.Store) {
 	storePath := t.TempDir() + "/jul.db"
 	store, err := storage.Open(storePath)
 	if err != nil {
@@ -112,6 +112,42 @@ func TestWorkspacePromoteNameRouting(t *testing.T) {
 	}
 }
 
+func TestPromoteNoPolicyKeepsAttestationGate(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	if _, err := store.RecordSync(ctx, storage.SyncPayload{
+		WorkspaceID: "alice/gate",
+		Repo:        "demo",
+		Branch:      "main",
+		CommitSHA:   "commit-red",
+		ChangeID:    "I4444444444444444444444444444444444444444",
+		Message:     "feat: red",
+		Author:      "alice",
+		CommittedAt: time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("RecordSync failed: %v", err)
+	}
+	if _, err := store.CreateAttestation(ctx, storage.Attestation{
+		CommitSHA: "commit-red",
+		ChangeID:  "I4444444444444444444444444444444444444444",
+		Type:      "ci",
+		Status:    "fail",
+	}); err != nil {
+		t.Fatalf("CreateAttestation failed: %v", err)
+	}
+
+	body := []byte(`{"target_branch":"main","no_policy":true}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/workspaces/alice/gate/promote", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.handleWorkspaceRoutes(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestWorkspaceReflogEndpoint(t *testing.T) {
 	srv, store := newTestServer(t)
 	defer store.Close()
@@ -178,6 +214,67 @@ func TestQueryRejectsUnsupportedFilters(t *testing.T) {
 	}
 }
 
+func TestQueryPagesWithCursorHeader(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	base := time.Now().UTC().Add(-time.Hour)
+	for i, sha := range []string{"commit-a", "commit-b", "commit-c"} {
+		_, err := store.RecordSync(context.Background(), storage.SyncPayload{
+			WorkspaceID: "bob/laptop",
+			Repo:        "demo",
+			Branch:      "main",
+			CommitSHA:   sha,
+			Message:     "feat: " + sha,
+			Author:      "bob",
+			CommittedAt: base.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("RecordSync failed: %v", err)
+		}
+	}
+
+	var seen []string
+	path := "/api/v1/query?repo=demo&workspace=bob/laptop&limit=2"
+	for page := 0; page < 3; page++ {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		srv.handleQuery(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var results []storage.QueryResult
+		if err := json.NewDecoder(w.Body).Decode(&results); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		for _, res := range results {
+			seen = append(seen, res.CommitSHA)
+		}
+		next := w.Header().Get(NextCursorHeader)
+		if next == "" {
+			break
+		}
+		path = "/api/v1/query?repo=demo&workspace=bob/laptop&limit=2&cursor=" + next
+	}
+	if len(seen) != 3 || seen[0] != "commit-c" || seen[2] != "commit-a" {
+		t.Fatalf("unexpected paged results: %v", seen)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/changes?cursor=bogus", nil)
+	w := httptest.NewRecorder()
+	srv.handleChanges(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid cursor, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/query?limit=501", nil)
+	w = httptest.NewRecorder()
+	srv.handleQuery(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a limit above the page cap, got %d", w.Code)
+	}
+}
+
 func TestCITriggerInvalidRepo(t *testing.T) {
 	srv, store := newTestServer(t)
 	defer store.Close()
@@ -325,3 +422,79 @@ func TestReposEndpointCreatesRepo(t *testing.T) {
 		t.Fatalf("expected repo at %s: %v", path, err)
 	}
 }
+
+func TestRepoRecordsAndScopedRoutes(t *testing.T) {
+	srv, store := newTestServer(t)
+	defer store.Close()
+
+	body := []byte(`{"name":"acme/api","description":"API service","default_branch":"trunk"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/repos", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, sync := range []storage.SyncPayload{
+		{WorkspaceID: "alice/laptop", Repo: "acme/api", Branch: "main", CommitSHA: "commit-api", Message: "feat: api", Author: "alice"},
+		{WorkspaceID: "bob/laptop", Repo: "web", Branch: "main", CommitSHA: "commit-web", Message: "feat: web", Author: "bob"},
+	} {
+		if _, err := store.RecordSync(context.Background(), sync); err != nil {
+			t.Fatalf("RecordSync failed: %v", err)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/repos?owner=acme", nil)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	var repos []RepoInfo
+	if err := json.NewDecoder(w.Body).Decode(&repos); err != nil {
+		t.Fatalf("failed to decode repos: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Name != "acme/api" || repos[0].Owner != "acme" || repos[0].DefaultBranch != "trunk" || repos[0].Description != "API service" {
+		t.Fatalf("unexpected repos for owner acme: %+v", repos)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/repos", nil)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	repos = nil
+	if err := json.NewDecoder(w.Body).Decode(&repos); err != nil {
+		t.Fatalf("failed to decode repos: %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("expected synced repo to be listed, got %+v", repos)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/repos/acme/api/changes", nil)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var changes []storage.Change
+	if err := json.NewDecoder(w.Body).Decode(&changes); err != nil {
+		t.Fatalf("failed to decode changes: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Title != "feat: api" {
+		t.Fatalf("expected only acme/api changes, got %+v", changes)
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/api/v1/repos/web", bytes.NewReader([]byte(`{"owner":"acme","description":"Website"}`)))
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	var updated RepoInfo
+	if err := json.NewDecoder(w.Body).Decode(&updated); err != nil {
+		t.Fatalf("failed to decode repo: %v", err)
+	}
+	if w.Code != http.StatusOK || updated.Owner != "acme" || updated.Description != "Website" || updated.DefaultBranch != "main" {
+		t.Fatalf("unexpected patch result %d: %+v", w.Code, updated)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/repos/missing/workspaces", nil)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown repo, got %d", w.Code)
+	}
+}
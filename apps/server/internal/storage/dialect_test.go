@@ -0,0 +1,32 @@
+package storage
+
+import "testing"
+
+func TestPostgresRebind(t *testing.T) {
+	got := postgresDialect{}.Rebind(`SELECT * FROM t WHERE a = ? AND b LIKE '?%' AND c IN (?, ?)`)
+	want := `SELECT * FROM t WHERE a = $1 AND b LIKE '?%' AND c IN ($2, $3)`
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPostgresDDLUsesDoublePrecision(t *testing.T) {
+	got := postgresDialect{}.DDL(`CREATE TABLE t (confidence REAL NOT NULL)`)
+	if got != `CREATE TABLE t (confidence DOUBLE PRECISION NOT NULL)` {
+		t.Fatalf("unexpected ddl: %q", got)
+	}
+}
+
+func TestIsPostgresDSN(t *testing.T) {
+	cases := map[string]bool{
+		"postgres://jul@localhost/jul":   true,
+		"postgresql://jul@localhost/jul": true,
+		"sqlite:///var/jul/jul.db":       false,
+		"var/jul/data/jul.db":            false,
+	}
+	for dsn, want := range cases {
+		if got := isPostgresDSN(dsn); got != want {
+			t.Fatalf("isPostgresDSN(%q) = %v, want %v", dsn, got, want)
+		}
+	}
+}
@@ -1,145 +1,422 @@
 package storage
 
-import "database/sql"
-
-func runMigrations(db *sql.DB) error {
-	stmts := []string{
-		`PRAGMA foreign_keys = ON;`,
-		`CREATE TABLE IF NOT EXISTS workspaces (
-			workspace_id TEXT PRIMARY KEY,
-			user TEXT NOT NULL,
-			name TEXT NOT NULL,
-			repo TEXT NOT NULL,
-			branch TEXT NOT NULL,
-			last_commit_sha TEXT NOT NULL,
-			last_change_id TEXT NOT NULL,
-			updated_at TEXT NOT NULL
-		);`,
-		`CREATE TABLE IF NOT EXISTS changes (
-			change_id TEXT PRIMARY KEY,
-			title TEXT NOT NULL,
-			author TEXT NOT NULL,
-			status TEXT NOT NULL,
-			created_at TEXT NOT NULL,
-			latest_rev_index INTEGER NOT NULL,
-			latest_commit_sha TEXT NOT NULL
-		);`,
-		`CREATE TABLE IF NOT EXISTS revisions (
-			commit_sha TEXT PRIMARY KEY,
-			change_id TEXT NOT NULL,
-			rev_index INTEGER NOT NULL,
-			author TEXT NOT NULL,
-			message TEXT NOT NULL,
-			created_at TEXT NOT NULL,
-			FOREIGN KEY(change_id) REFERENCES changes(change_id)
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_revisions_change_id ON revisions(change_id);`,
-		`CREATE TABLE IF NOT EXISTS attestations (
-			attestation_id TEXT PRIMARY KEY,
-			commit_sha TEXT NOT NULL,
-			change_id TEXT NOT NULL,
-			type TEXT NOT NULL,
-			status TEXT NOT NULL,
-			compile_status TEXT,
-			test_status TEXT,
-			coverage_line_pct REAL,
-			coverage_branch_pct REAL,
-			started_at TEXT NOT NULL,
-			finished_at TEXT NOT NULL,
-			signals_json TEXT NOT NULL,
-			created_at TEXT NOT NULL
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_attestations_commit ON attestations(commit_sha);`,
-		`CREATE INDEX IF NOT EXISTS idx_attestations_change ON attestations(change_id);`,
-		`CREATE TABLE IF NOT EXISTS suggestions (
-			suggestion_id TEXT PRIMARY KEY,
-			change_id TEXT NOT NULL,
-			base_commit_sha TEXT NOT NULL,
-			suggested_commit_sha TEXT NOT NULL,
-			created_by TEXT NOT NULL,
-			reason TEXT NOT NULL,
-			description TEXT NOT NULL,
-			confidence REAL NOT NULL,
-			status TEXT NOT NULL,
-			diffstat_json TEXT NOT NULL,
-			created_at TEXT NOT NULL,
-			resolved_at TEXT
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_suggestions_change ON suggestions(change_id);`,
-		`CREATE INDEX IF NOT EXISTS idx_suggestions_status ON suggestions(status);`,
-		`CREATE TABLE IF NOT EXISTS events (
-			event_id TEXT PRIMARY KEY,
-			type TEXT NOT NULL,
-			data_json TEXT NOT NULL,
-			created_at TEXT NOT NULL
-		);`,
-		`CREATE TABLE IF NOT EXISTS keep_refs (
-			keep_id TEXT PRIMARY KEY,
-			workspace_id TEXT NOT NULL,
-			commit_sha TEXT NOT NULL,
-			change_id TEXT NOT NULL,
-			created_at TEXT NOT NULL
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_keep_refs_workspace ON keep_refs(workspace_id);`,
-		`CREATE INDEX IF NOT EXISTS idx_keep_refs_created ON keep_refs(created_at);`,
-		`CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);`,
-	}
-
-	for _, stmt := range stmts {
-		if _, err := db.Exec(stmt); err != nil {
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrUnknownMigration = errors.New("unknown migration version")
+
+type migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx *sql.Tx, d dialect) error
+	Down    func(ctx context.Context, tx *sql.Tx, d dialect) error
+}
+
+type MigrationStatus struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Applied   bool      `json:"applied"`
+	AppliedAt time.Time `json:"applied_at,omitempty"`
+}
+
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Up: execAll(
+			`CREATE TABLE IF NOT EXISTS workspaces (
+				workspace_id TEXT PRIMARY KEY,
+				"user" TEXT NOT NULL,
+				name TEXT NOT NULL,
+				repo TEXT NOT NULL,
+				branch TEXT NOT NULL,
+				last_commit_sha TEXT NOT NULL,
+				last_change_id TEXT NOT NULL,
+				updated_at TEXT NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS changes (
+				change_id TEXT PRIMARY KEY,
+				title TEXT NOT NULL,
+				author TEXT NOT NULL,
+				status TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				latest_rev_index INTEGER NOT NULL,
+				latest_commit_sha TEXT NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS revisions (
+				commit_sha TEXT PRIMARY KEY,
+				change_id TEXT NOT NULL,
+				rev_index INTEGER NOT NULL,
+				author TEXT NOT NULL,
+				message TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				FOREIGN KEY(change_id) REFERENCES changes(change_id)
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_revisions_change_id ON revisions(change_id);`,
+			`CREATE TABLE IF NOT EXISTS attestations (
+				attestation_id TEXT PRIMARY KEY,
+				commit_sha TEXT NOT NULL,
+				change_id TEXT NOT NULL,
+				type TEXT NOT NULL,
+				status TEXT NOT NULL,
+				started_at TEXT NOT NULL,
+				finished_at TEXT NOT NULL,
+				signals_json TEXT NOT NULL,
+				created_at TEXT NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_attestations_commit ON attestations(commit_sha);`,
+			`CREATE INDEX IF NOT EXISTS idx_attestations_change ON attestations(change_id);`,
+			`CREATE TABLE IF NOT EXISTS suggestions (
+				suggestion_id TEXT PRIMARY KEY,
+				change_id TEXT NOT NULL,
+				base_commit_sha TEXT NOT NULL,
+				suggested_commit_sha TEXT NOT NULL,
+				created_by TEXT NOT NULL,
+				reason TEXT NOT NULL,
+				description TEXT NOT NULL,
+				confidence REAL NOT NULL,
+				status TEXT NOT NULL,
+				diffstat_json TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				resolved_at TEXT
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_suggestions_change ON suggestions(change_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_suggestions_status ON suggestions(status);`,
+			`CREATE TABLE IF NOT EXISTS events (
+				event_id TEXT PRIMARY KEY,
+				type TEXT NOT NULL,
+				data_json TEXT NOT NULL,
+				created_at TEXT NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS keep_refs (
+				keep_id TEXT PRIMARY KEY,
+				workspace_id TEXT NOT NULL,
+				commit_sha TEXT NOT NULL,
+				change_id TEXT NOT NULL,
+				created_at TEXT NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_keep_refs_workspace ON keep_refs(workspace_id);`,
+			`CREATE INDEX IF NOT EXISTS idx_keep_refs_created ON keep_refs(created_at);`,
+			`CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);`,
+		),
+		Down: execAll(
+			`DROP TABLE IF EXISTS keep_refs;`,
+			`DROP TABLE IF EXISTS events;`,
+			`DROP TABLE IF EXISTS suggestions;`,
+			`DROP TABLE IF EXISTS attestations;`,
+			`DROP TABLE IF EXISTS revisions;`,
+			`DROP TABLE IF EXISTS changes;`,
+			`DROP TABLE IF EXISTS workspaces;`,
+		),
+	},
+	{
+		Version: 2,
+		Name:    "revisions_repo",
+		Up: func(ctx context.Context, tx *sql.Tx, d dialect) error {
+			return ensureColumn(ctx, tx, d, "revisions", "repo", "TEXT")
+		},
+		Down: func(ctx context.Context, tx *sql.Tx, d dialect) error {
+			return dropColumn(ctx, tx, d, "revisions", "repo")
+		},
+	},
+	{
+		Version: 3,
+		Name:    "attestation_status_split",
+		Up: func(ctx context.Context, tx *sql.Tx, d dialect) error {
+			columns := []struct{ name, kind string }{
+				{"compile_status", "TEXT"},
+				{"test_status", "TEXT"},
+				{"coverage_line_pct", "REAL"},
+				{"coverage_branch_pct", "REAL"},
+			}
+			for _, col := range columns {
+				if err := ensureColumn(ctx, tx, d, "attestations", col.name, col.kind); err != nil {
+					return err
+				}
+			}
+			return execAll(
+				`UPDATE attestations SET compile_status = status WHERE compile_status IS NULL`,
+				`UPDATE attestations SET test_status = status WHERE test_status IS NULL`,
+			)(ctx, tx, d)
+		},
+		Down: func(ctx context.Context, tx *sql.Tx, d dialect) error {
+			for _, column := range []string{"coverage_branch_pct", "coverage_line_pct", "test_status", "compile_status"} {
+				if err := dropColumn(ctx, tx, d, "attestations", column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 4,
+		Name:    "list_pagination",
+		Up: func(ctx context.Context, tx *sql.Tx, d dialect) error {
+			if err := ensureColumn(ctx, tx, d, "revisions", "workspace_id", "TEXT"); err != nil {
+				return err
+			}
+			return execAll(
+				`UPDATE revisions SET workspace_id = (
+					SELECT w.workspace_id FROM workspaces w WHERE w.last_commit_sha = revisions.commit_sha LIMIT 1
+				) WHERE workspace_id IS NULL`,
+				`UPDATE revisions SET workspace_id = (
+					SELECT k.workspace_id FROM keep_refs k WHERE k.commit_sha = revisions.commit_sha LIMIT 1
+				) WHERE workspace_id IS NULL`,
+				`CREATE INDEX IF NOT EXISTS idx_revisions_created ON revisions(created_at, commit_sha);`,
+				`CREATE INDEX IF NOT EXISTS idx_revisions_workspace ON revisions(workspace_id);`,
+				`CREATE INDEX IF NOT EXISTS idx_changes_created ON changes(created_at, change_id);`,
+				`CREATE INDEX IF NOT EXISTS idx_workspaces_updated ON workspaces(updated_at, workspace_id);`,
+				`CREATE INDEX IF NOT EXISTS idx_attestations_created ON attestations(created_at, attestation_id);`,
+				`CREATE INDEX IF NOT EXISTS idx_suggestions_created ON suggestions(created_at, suggestion_id);`,
+			)(ctx, tx, d)
+		},
+		Down: func(ctx context.Context, tx *sql.Tx, d dialect) error {
+			if err := execAll(
+				`DROP INDEX IF EXISTS idx_suggestions_created;`,
+				`DROP INDEX IF EXISTS idx_attestations_created;`,
+				`DROP INDEX IF EXISTS idx_workspaces_updated;`,
+				`DROP INDEX IF EXISTS idx_changes_created;`,
+				`DROP INDEX IF EXISTS idx_revisions_workspace;`,
+				`DROP INDEX IF EXISTS idx_revisions_created;`,
+			)(ctx, tx, d); err != nil {
+				return err
+			}
+			return dropColumn(ctx, tx, d, "revisions", "workspace_id")
+		},
+	},
+	{
+		Version: 5,
+		Name:    "repos",
+		Up: execAll(
+			`CREATE TABLE IF NOT EXISTS repos (
+				name TEXT PRIMARY KEY,
+				owner TEXT NOT NULL DEFAULT '',
+				description TEXT NOT NULL DEFAULT '',
+				default_branch TEXT NOT NULL DEFAULT 'main',
+				created_at TEXT NOT NULL,
+				updated_at TEXT NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_repos_owner ON repos(owner);`,
+			`CREATE INDEX IF NOT EXISTS idx_repos_created ON repos(created_at, name);`,
+			`INSERT INTO repos (name, created_at, updated_at)
+				SELECT repo, MIN(updated_at), MAX(updated_at) FROM workspaces
+				WHERE repo <> '' GROUP BY repo;`,
+			`INSERT INTO repos (name, created_at, updated_at)
+				SELECT repo, MIN(created_at), MAX(created_at) FROM revisions
+				WHERE repo IS NOT NULL AND repo <> '' AND repo NOT IN (SELECT name FROM repos)
+				GROUP BY repo;`,
+		),
+		Down: execAll(
+			`DROP INDEX IF EXISTS idx_repos_created;`,
+			`DROP INDEX IF EXISTS idx_repos_owner;`,
+			`DROP TABLE IF EXISTS repos;`,
+		),
+	},
+}
+
+func LatestMigrationVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func runMigrations(db *sql.DB, d dialect) error {
+	if d.Name() == "sqlite" {
+		if _, err := db.Exec(`PRAGMA foreign_keys = ON;`); err != nil {
 			return err
 		}
 	}
+	_, err := migrateUp(context.Background(), db, d, LatestMigrationVersion())
+	return err
+}
 
-	if err := ensureColumn(db, "revisions", "repo", "TEXT"); err != nil {
-		return err
+func (s *sqlStore) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	return migrationStatus(ctx, s.db, s.dialect)
+}
+
+func (s *sqlStore) MigrateUp(ctx context.Context, target int) ([]MigrationStatus, error) {
+	return migrateUp(ctx, s.db, s.dialect, target)
+}
+
+func (s *sqlStore) MigrateDown(ctx context.Context, target int) ([]MigrationStatus, error) {
+	return migrateDown(ctx, s.db, s.dialect, target)
+}
+
+func migrationStatus(ctx context.Context, db *sql.DB, d dialect) ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(ctx, db, d)
+	if err != nil {
+		return nil, err
 	}
-	if err := ensureColumn(db, "attestations", "compile_status", "TEXT"); err != nil {
-		return err
+	out := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if at, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = at
+		}
+		out = append(out, status)
 	}
-	if err := ensureColumn(db, "attestations", "test_status", "TEXT"); err != nil {
-		return err
+	return out, nil
+}
+
+func migrateUp(ctx context.Context, db *sql.DB, d dialect, target int) ([]MigrationStatus, error) {
+	if target > LatestMigrationVersion() {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownMigration, target)
 	}
-	if err := ensureColumn(db, "attestations", "coverage_line_pct", "REAL"); err != nil {
-		return err
+	applied, err := appliedMigrations(ctx, db, d)
+	if err != nil {
+		return nil, err
 	}
-	if err := ensureColumn(db, "attestations", "coverage_branch_pct", "REAL"); err != nil {
-		return err
+	var ran []MigrationStatus
+	for _, m := range migrations {
+		if m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		at := time.Now().UTC()
+		skipped := false
+		err := inTx(ctx, db, func(tx *sql.Tx) error {
+			if err := d.LockMigrations(ctx, tx); err != nil {
+				return err
+			}
+			// Another replica may have applied it while we waited for the lock.
+			done, err := migrationApplied(ctx, tx, d, m.Version)
+			if err != nil || done {
+				skipped = done
+				return err
+			}
+			if err := m.Up(ctx, tx, d); err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, d.Rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+				m.Version, m.Name, at.Format(timeFormat))
+			return err
+		})
+		if err != nil {
+			return ran, fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		if skipped {
+			continue
+		}
+		ran = append(ran, MigrationStatus{Version: m.Version, Name: m.Name, Applied: true, AppliedAt: at})
 	}
-	if _, err := db.Exec(`UPDATE attestations SET compile_status = status WHERE compile_status IS NULL`); err != nil {
-		return err
+	return ran, nil
+}
+
+func migrateDown(ctx context.Context, db *sql.DB, d dialect, target int) ([]MigrationStatus, error) {
+	if target < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownMigration, target)
 	}
-	if _, err := db.Exec(`UPDATE attestations SET test_status = status WHERE test_status IS NULL`); err != nil {
-		return err
+	applied, err := appliedMigrations(ctx, db, d)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	var reverted []MigrationStatus
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version <= target {
+			break
+		}
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		err := inTx(ctx, db, func(tx *sql.Tx) error {
+			if err := d.LockMigrations(ctx, tx); err != nil {
+				return err
+			}
+			if err := m.Down(ctx, tx, d); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, d.Rebind(`DELETE FROM schema_migrations WHERE version = ?`), m.Version)
+			return err
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d (%s) rollback failed: %w", m.Version, m.Name, err)
+		}
+		reverted = append(reverted, MigrationStatus{Version: m.Version, Name: m.Name})
+	}
+	return reverted, nil
 }
 
-func ensureColumn(db *sql.DB, table, column, columnType string) error {
-	rows, err := db.Query(`PRAGMA table_info(` + table + `);`)
+func appliedMigrations(ctx context.Context, db *sql.DB, d dialect) (map[int]time.Time, error) {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	);`); err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer rows.Close()
 
+	out := map[int]time.Time{}
 	for rows.Next() {
-		var cid int
-		var name string
-		var ctype string
-		var notnull int
-		var dfltValue any
-		var pk int
-		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dfltValue, &pk); err != nil {
-			return err
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
 		}
-		if name == column {
-			return nil
+		out[version] = parseTime(appliedAt)
+	}
+	return out, rows.Err()
+}
+
+func migrationApplied(ctx context.Context, tx *sql.Tx, d dialect, version int) (bool, error) {
+	var count int
+	if err := tx.QueryRowContext(ctx, d.Rebind(`SELECT COUNT(1) FROM schema_migrations WHERE version = ?`), version).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func execAll(stmts ...string) func(ctx context.Context, tx *sql.Tx, d dialect) error {
+	return func(ctx context.Context, tx *sql.Tx, d dialect) error {
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(ctx, d.DDL(stmt)); err != nil {
+				return err
+			}
 		}
+		return nil
 	}
-	if err := rows.Err(); err != nil {
+}
+
+func ensureColumn(ctx context.Context, tx *sql.Tx, d dialect, table, column, columnType string) error {
+	exists, err := d.HasColumn(ctx, tx, table, column)
+	if err != nil || exists {
 		return err
 	}
+	_, err = tx.ExecContext(ctx, d.DDL(`ALTER TABLE `+table+` ADD COLUMN `+column+` `+columnType+`;`))
+	return err
+}
 
-	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + columnType + `;`)
+func dropColumn(ctx context.Context, tx *sql.Tx, d dialect, table, column string) error {
+	exists, err := d.HasColumn(ctx, tx, table, column)
+	if err != nil || !exists {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `ALTER TABLE `+table+` DROP COLUMN `+column+`;`)
 	return err
 }
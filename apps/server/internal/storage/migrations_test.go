@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// baselineSchema is the schema produced by the unversioned runMigrations
+// before schema_migrations existed.
+var baselineSchema = []string{
+	`CREATE TABLE workspaces (
+		workspace_id TEXT PRIMARY KEY,
+		user TEXT NOT NULL,
+		name TEXT NOT NULL,
+		repo TEXT NOT NULL,
+		branch TEXT NOT NULL,
+		last_commit_sha TEXT NOT NULL,
+		last_change_id TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);`,
+	`CREATE TABLE changes (
+		change_id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL,
+		status TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		latest_rev_index INTEGER NOT NULL,
+		latest_commit_sha TEXT NOT NULL
+	);`,
+	`CREATE TABLE revisions (
+		commit_sha TEXT PRIMARY KEY,
+		change_id TEXT NOT NULL,
+		rev_index INTEGER NOT NULL,
+		author TEXT NOT NULL,
+		message TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		repo TEXT,
+		FOREIGN KEY(change_id) REFERENCES changes(change_id)
+	);`,
+	`CREATE TABLE attestations (
+		attestation_id TEXT PRIMARY KEY,
+		commit_sha TEXT NOT NULL,
+		change_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		status TEXT NOT NULL,
+		compile_status TEXT,
+		test_status TEXT,
+		coverage_line_pct REAL,
+		coverage_branch_pct REAL,
+		started_at TEXT NOT NULL,
+		finished_at TEXT NOT NULL,
+		signals_json TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);`,
+	`CREATE TABLE suggestions (
+		suggestion_id TEXT PRIMARY KEY,
+		change_id TEXT NOT NULL,
+		base_commit_sha TEXT NOT NULL,
+		suggested_commit_sha TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		description TEXT NOT NULL,
+		confidence REAL NOT NULL,
+		status TEXT NOT NULL,
+		diffstat_json TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		resolved_at TEXT
+	);`,
+	`CREATE TABLE events (
+		event_id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		data_json TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);`,
+	`CREATE TABLE keep_refs (
+		keep_id TEXT PRIMARY KEY,
+		workspace_id TEXT NOT NULL,
+		commit_sha TEXT NOT NULL,
+		change_id TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);`,
+}
+
+func TestMigrateUpgradesBaselineDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	for _, stmt := range baselineSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to create baseline schema: %v", err)
+		}
+	}
+	now := time.Now().UTC().Format(timeFormat)
+	changeID := "I0123456789abcdef0123456789abcdef01234567"
+	if _, err := db.Exec(`INSERT INTO changes (change_id, title, author, status, created_at, latest_rev_index, latest_commit_sha)
+		VALUES (?, 'feat', 'alice', 'draft', ?, 1, 'abc123')`, changeID, now); err != nil {
+		t.Fatalf("failed to insert change: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO revisions (commit_sha, change_id, rev_index, author, message, created_at, repo)
+		VALUES ('abc123', ?, 1, 'alice', 'feat', ?, 'demo')`, changeID, now); err != nil {
+		t.Fatalf("failed to insert revision: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO attestations (attestation_id, commit_sha, change_id, type, status, started_at, finished_at, signals_json, created_at)
+		VALUES ('att-1', 'abc123', ?, 'ci', 'pass', ?, ?, '{}', ?)`, changeID, now, now, now); err != nil {
+		t.Fatalf("failed to insert attestation: %v", err)
+	}
+	_ = db.Close()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("failed to upgrade baseline db: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	statuses, err := store.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if len(statuses) != LatestMigrationVersion() {
+		t.Fatalf("expected %d migrations, got %d", LatestMigrationVersion(), len(statuses))
+	}
+	for _, st := range statuses {
+		if !st.Applied {
+			t.Fatalf("expected migration %d to be applied", st.Version)
+		}
+	}
+
+	repo, err := store.FindRepoForCommit(ctx, "abc123")
+	if err != nil || repo != "demo" {
+		t.Fatalf("expected repo demo, got %q (%v)", repo, err)
+	}
+	att, err := store.GetLatestAttestation(ctx, "abc123")
+	if err != nil {
+		t.Fatalf("GetLatestAttestation failed: %v", err)
+	}
+	if att.TestStatus != "pass" || att.CompileStatus != "pass" {
+		t.Fatalf("expected backfilled statuses, got test=%q compile=%q", att.TestStatus, att.CompileStatus)
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store *sqlStore) {
+		ctx := context.Background()
+
+		reverted, err := store.MigrateDown(ctx, 1)
+		if err != nil {
+			t.Fatalf("MigrateDown failed: %v", err)
+		}
+		if len(reverted) != LatestMigrationVersion()-1 {
+			t.Fatalf("expected %d reverted migrations, got %d", LatestMigrationVersion()-1, len(reverted))
+		}
+		tx, err := store.db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("BeginTx failed: %v", err)
+		}
+		exists, err := store.dialect.HasColumn(ctx, tx, "attestations", "test_status")
+		_ = tx.Rollback()
+		if err != nil {
+			t.Fatalf("hasColumn failed: %v", err)
+		}
+		if exists {
+			t.Fatalf("expected test_status to be dropped")
+		}
+
+		applied, err := store.MigrateUp(ctx, LatestMigrationVersion())
+		if err != nil {
+			t.Fatalf("MigrateUp failed: %v", err)
+		}
+		if len(applied) != len(reverted) {
+			t.Fatalf("expected %d reapplied migrations, got %d", len(reverted), len(applied))
+		}
+		if _, err := store.MigrateUp(ctx, LatestMigrationVersion()+1); err == nil {
+			t.Fatalf("expected error for unknown target version")
+		}
+	})
+}
@@ -2,6 +2,25 @@ package storage
 
 import "time"
 
+// Repo is a repository hosted by the server. Name is the key used by
+// workspaces, revisions and the bare repo directory; Owner groups repos by
+// user or organisation.
+type Repo struct {
+	Name          string    `json:"name"`
+	Owner         string    `json:"owner"`
+	Description   string    `json:"description"`
+	DefaultBranch string    `json:"default_branch"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// RepoUpdate holds the repo fields to change; nil fields are left as is.
+type RepoUpdate struct {
+	Owner         *string
+	Description   *string
+	DefaultBranch *string
+}
+
 type Workspace struct {
 	WorkspaceID   string    `json:"workspace_id"`
 	User          string    `json:"user"`
@@ -65,8 +84,11 @@ type QueryFilters struct {
 	CoverageMax *float64
 	ChangeID    string
 	Author      string
+	Repo        string
+	Workspace   string
 	Since       *time.Time
 	Until       *time.Time
+	Cursor      string
 	Limit       int
 }
 
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListFilters narrows a list query. Fields that do not apply to a list are
+// ignored. Cursor is the opaque value returned with the previous page; Limit
+// <= 0 returns every matching row.
+type ListFilters struct {
+	Owner     string
+	Repo      string
+	Workspace string
+	Status    string
+	ChangeID  string
+	CommitSHA string
+	Since     *time.Time
+	Until     *time.Time
+	Cursor    string
+	Limit     int
+}
+
+// cursorKey is the (timestamp, id) keyset position of the last row of a page.
+// Lists are ordered newest first, so the next page continues strictly below it.
+type cursorKey struct {
+	at string
+	id string
+}
+
+func (k cursorKey) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(k.at + "\x00" + k.id))
+}
+
+func decodeCursor(raw string) (cursorKey, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(raw))
+	if err != nil {
+		return cursorKey{}, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(data), "\x00")
+	if !ok || at == "" || id == "" {
+		return cursorKey{}, ErrInvalidCursor
+	}
+	return cursorKey{at: at, id: id}, nil
+}
+
+// appendTimeRange adds since/until bounds on column.
+func appendTimeRange(query string, args []any, column string, since, until *time.Time) (string, []any) {
+	if since != nil {
+		query += " AND " + column + " >= ?"
+		args = append(args, since.UTC().Format(timeFormat))
+	}
+	if until != nil {
+		query += " AND " + column + " <= ?"
+		args = append(args, until.UTC().Format(timeFormat))
+	}
+	return query, args
+}
+
+// appendPage adds the keyset condition, ordering and limit for a list ordered
+// by (atColumn DESC, idColumn DESC). One extra row is requested so the caller
+// can tell whether another page exists.
+func appendPage(query string, args []any, atColumn, idColumn, cursor string, limit int) (string, []any, error) {
+	if strings.TrimSpace(cursor) != "" {
+		key, err := decodeCursor(cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " AND (" + atColumn + " < ? OR (" + atColumn + " = ? AND " + idColumn + " < ?))"
+		args = append(args, key.at, key.at, key.id)
+	}
+	query += " ORDER BY " + atColumn + " DESC, " + idColumn + " DESC"
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit+1)
+	}
+	return query, args, nil
+}
+
+// trimPage drops the look-ahead row and returns the cursor for the next page.
+func trimPage[T any](items []T, keys []cursorKey, limit int) ([]T, string) {
+	if limit <= 0 || len(items) <= limit {
+		return items, ""
+	}
+	return items[:limit], keys[limit-1].encode()
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestListChangesPaginatesWithFilters(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store *sqlStore) {
+		ctx := context.Background()
+		base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		for i := 0; i < 5; i++ {
+			repo, workspace := "demo", "alice/laptop"
+			if i%2 == 1 {
+				repo, workspace = "other", "bob/desktop"
+			}
+			_, err := store.RecordSync(ctx, SyncPayload{
+				WorkspaceID: workspace,
+				Repo:        repo,
+				Branch:      "main",
+				CommitSHA:   fmt.Sprintf("commit-%d", i),
+				ChangeID:    fmt.Sprintf("I%040d", i),
+				Message:     fmt.Sprintf("feat: %d", i),
+				Author:      "alice",
+				// Two changes share a timestamp so the id tie-break is exercised.
+				CommittedAt: base.Add(time.Duration(i/2) * time.Minute),
+			})
+			if err != nil {
+				t.Fatalf("RecordSync failed: %v", err)
+			}
+		}
+
+		var seen []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatalf("pagination did not terminate")
+			}
+			changes, next, err := store.ListChanges(ctx, ListFilters{Limit: 2, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("ListChanges failed: %v", err)
+			}
+			for _, ch := range changes {
+				seen = append(seen, ch.ChangeID)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		want := []string{
+			fmt.Sprintf("I%040d", 4),
+			fmt.Sprintf("I%040d", 3),
+			fmt.Sprintf("I%040d", 2),
+			fmt.Sprintf("I%040d", 1),
+			fmt.Sprintf("I%040d", 0),
+		}
+		if fmt.Sprint(seen) != fmt.Sprint(want) {
+			t.Fatalf("expected %v, got %v", want, seen)
+		}
+
+		demo, next, err := store.ListChanges(ctx, ListFilters{Repo: "demo"})
+		if err != nil {
+			t.Fatalf("ListChanges repo filter failed: %v", err)
+		}
+		if len(demo) != 3 || next != "" {
+			t.Fatalf("expected 3 demo changes and no cursor, got %d (%q)", len(demo), next)
+		}
+
+		bob, _, err := store.ListChanges(ctx, ListFilters{Workspace: "bob/desktop"})
+		if err != nil {
+			t.Fatalf("ListChanges workspace filter failed: %v", err)
+		}
+		if len(bob) != 2 {
+			t.Fatalf("expected 2 changes for bob/desktop, got %d", len(bob))
+		}
+
+		since := base.Add(time.Minute)
+		recent, _, err := store.QueryCommits(ctx, QueryFilters{Since: &since, Repo: "demo", Limit: 10})
+		if err != nil {
+			t.Fatalf("QueryCommits failed: %v", err)
+		}
+		if len(recent) != 2 || recent[0].CommitSHA != "commit-4" || recent[1].CommitSHA != "commit-2" {
+			t.Fatalf("unexpected query results: %+v", recent)
+		}
+
+		if _, _, err := store.ListWorkspaces(ctx, ListFilters{Cursor: "not-a-cursor", Limit: 1}); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const defaultRepoBranch = "main"
+
+// EnsureRepo creates the repo record if it does not exist yet. It returns the
+// stored record and whether it was created by this call.
+func (s *sqlStore) EnsureRepo(ctx context.Context, repo Repo) (Repo, bool, error) {
+	repo.Name = strings.TrimSpace(repo.Name)
+	if repo.Name == "" {
+		return Repo{}, false, fmt.Errorf("repo name required")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Repo{}, false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	existing, err := scanRepo(tx.QueryRowContext(ctx, s.rebind(`SELECT name, owner, description, default_branch, created_at, updated_at FROM repos WHERE name = ?`), repo.Name))
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return Repo{}, false, err
+	}
+	if err := insertRepo(ctx, tx, s.dialect, repo, time.Now().UTC().Format(timeFormat)); err != nil {
+		return Repo{}, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Repo{}, false, err
+	}
+	created, err := s.GetRepo(ctx, repo.Name)
+	return created, err == nil, err
+}
+
+func (s *sqlStore) GetRepo(ctx context.Context, name string) (Repo, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT name, owner, description, default_branch, created_at, updated_at FROM repos WHERE name = ?`), strings.TrimSpace(name))
+	return scanRepo(row)
+}
+
+func (s *sqlStore) ListRepos(ctx context.Context, filters ListFilters) ([]Repo, string, error) {
+	query := `SELECT name, owner, description, default_branch, created_at, updated_at FROM repos WHERE 1=1`
+	args := []any{}
+	if filters.Owner != "" {
+		query += " AND owner = ?"
+		args = append(args, filters.Owner)
+	}
+	if filters.Repo != "" {
+		query += " AND name = ?"
+		args = append(args, filters.Repo)
+	}
+	query, args = appendTimeRange(query, args, "created_at", filters.Since, filters.Until)
+	query, args, err := appendPage(query, args, "created_at", "name", filters.Cursor, filters.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var out []Repo
+	var keys []cursorKey
+	for rows.Next() {
+		repo, err := scanRepo(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		out = append(out, repo)
+		keys = append(keys, cursorKey{at: repo.CreatedAt.UTC().Format(timeFormat), id: repo.Name})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	out, next := trimPage(out, keys, filters.Limit)
+	return out, next, nil
+}
+
+func (s *sqlStore) UpdateRepo(ctx context.Context, name string, update RepoUpdate) (Repo, error) {
+	repo, err := s.GetRepo(ctx, name)
+	if err != nil {
+		return Repo{}, err
+	}
+	if update.Owner != nil {
+		repo.Owner = strings.TrimSpace(*update.Owner)
+	}
+	if update.Description != nil {
+		repo.Description = strings.TrimSpace(*update.Description)
+	}
+	if update.DefaultBranch != nil {
+		branch := strings.TrimSpace(*update.DefaultBranch)
+		if branch == "" {
+			return Repo{}, fmt.Errorf("default_branch cannot be empty")
+		}
+		repo.DefaultBranch = branch
+	}
+	repo.UpdatedAt = time.Now().UTC()
+	_, err = s.db.ExecContext(ctx, s.rebind(`UPDATE repos SET owner = ?, description = ?, default_branch = ?, updated_at = ? WHERE name = ?`),
+		repo.Owner, repo.Description, repo.DefaultBranch, repo.UpdatedAt.Format(timeFormat), repo.Name)
+	if err != nil {
+		return Repo{}, err
+	}
+	return repo, nil
+}
+
+// insertRepo adds a repo record unless one with the same name exists. Syncs
+// use it so repos referenced by workspaces always have a record.
+func insertRepo(ctx context.Context, tx *sql.Tx, d dialect, repo Repo, now string) error {
+	branch := strings.TrimSpace(repo.DefaultBranch)
+	if branch == "" {
+		branch = defaultRepoBranch
+	}
+	_, err := tx.ExecContext(ctx, d.Rebind(`INSERT INTO repos (name, owner, description, default_branch, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO NOTHING`),
+		strings.TrimSpace(repo.Name), strings.TrimSpace(repo.Owner), strings.TrimSpace(repo.Description), branch, now, now)
+	return err
+}
+
+type repoScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRepo(row repoScanner) (Repo, error) {
+	var repo Repo
+	var createdAt, updatedAt string
+	if err := row.Scan(&repo.Name, &repo.Owner, &repo.Description, &repo.DefaultBranch, &createdAt, &updatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Repo{}, ErrNotFound
+		}
+		return Repo{}, err
+	}
+	repo.CreatedAt = parseTime(createdAt)
+	repo.UpdatedAt = parseTime(updatedAt)
+	return repo, nil
+}
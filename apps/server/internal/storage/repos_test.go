@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRepoRecords(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store *sqlStore) {
+		ctx := context.Background()
+		repo, created, err := store.EnsureRepo(ctx, Repo{Name: "acme/api", Owner: "acme", Description: "API"})
+		if err != nil || !created {
+			t.Fatalf("EnsureRepo failed: created=%v err=%v", created, err)
+		}
+		if repo.DefaultBranch != "main" {
+			t.Fatalf("expected default branch main, got %q", repo.DefaultBranch)
+		}
+		again, created, err := store.EnsureRepo(ctx, Repo{Name: "acme/api", Description: "ignored"})
+		if err != nil || created || again.Description != "API" {
+			t.Fatalf("expected existing repo, got %+v created=%v err=%v", again, created, err)
+		}
+
+		if _, err := store.RecordSync(ctx, SyncPayload{
+			WorkspaceID: "alice/laptop",
+			Repo:        "web",
+			Branch:      "main",
+			CommitSHA:   "commit-web",
+			Message:     "feat: web",
+			Author:      "alice",
+		}); err != nil {
+			t.Fatalf("RecordSync failed: %v", err)
+		}
+		if _, err := store.GetRepo(ctx, "web"); err != nil {
+			t.Fatalf("expected sync to record repo: %v", err)
+		}
+
+		owned, _, err := store.ListRepos(ctx, ListFilters{Owner: "acme"})
+		if err != nil || len(owned) != 1 || owned[0].Name != "acme/api" {
+			t.Fatalf("unexpected owner listing %+v err=%v", owned, err)
+		}
+
+		branch := "trunk"
+		updated, err := store.UpdateRepo(ctx, "web", RepoUpdate{DefaultBranch: &branch})
+		if err != nil || updated.DefaultBranch != "trunk" {
+			t.Fatalf("UpdateRepo failed: %+v err=%v", updated, err)
+		}
+		if _, err := store.UpdateRepo(ctx, "missing", RepoUpdate{}); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/oklog/ulid/v2"
 	_ "modernc.org/sqlite"
 )
@@ -20,11 +21,120 @@ var ErrNotFound = errors.New("not found")
 
 const timeFormat = time.RFC3339
 
-type Store struct {
-	db *sql.DB
+type Store interface {
+	RepoStore
+	WorkspaceStore
+	ChangeStore
+	AttestationStore
+	SuggestionStore
+	EventStore
+	KeepRefStore
+	Migrator
+	Close() error
 }
 
-func Open(path string) (*Store, error) {
+type RepoStore interface {
+	EnsureRepo(ctx context.Context, repo Repo) (Repo, bool, error)
+	GetRepo(ctx context.Context, name string) (Repo, error)
+	ListRepos(ctx context.Context, filters ListFilters) ([]Repo, string, error)
+	UpdateRepo(ctx context.Context, name string, update RepoUpdate) (Repo, error)
+}
+
+type WorkspaceStore interface {
+	RecordSync(ctx context.Context, payload SyncPayload) (SyncResult, error)
+	ListWorkspaces(ctx context.Context, filters ListFilters) ([]Workspace, string, error)
+	GetWorkspace(ctx context.Context, id string) (Workspace, error)
+	DeleteWorkspace(ctx context.Context, id string) error
+	FindRepoForCommit(ctx context.Context, commitSHA string) (string, error)
+}
+
+type ChangeStore interface {
+	ListChanges(ctx context.Context, filters ListFilters) ([]Change, string, error)
+	GetChange(ctx context.Context, changeID string) (Change, error)
+	ListRevisions(ctx context.Context, changeID string) ([]Revision, error)
+	GetRevisionByCommit(ctx context.Context, commitSHA string) (Revision, error)
+	QueryCommits(ctx context.Context, filters QueryFilters) ([]QueryResult, string, error)
+}
+
+type AttestationStore interface {
+	ListAttestations(ctx context.Context, filters ListFilters) ([]Attestation, string, error)
+	CreateAttestation(ctx context.Context, att Attestation) (Attestation, error)
+	GetLatestAttestation(ctx context.Context, commitSHA string) (Attestation, error)
+}
+
+type SuggestionStore interface {
+	CreateSuggestion(ctx context.Context, sug Suggestion) (Suggestion, error)
+	GetSuggestion(ctx context.Context, suggestionID string) (Suggestion, error)
+	ListSuggestions(ctx context.Context, filters ListFilters) ([]Suggestion, string, error)
+	UpdateSuggestionStatus(ctx context.Context, suggestionID, status string, resolvedAt time.Time) (Suggestion, error)
+}
+
+type EventStore interface {
+	InsertEvent(ctx context.Context, evt Event) (Event, error)
+	ListEventsSince(ctx context.Context, since time.Time, limit int) ([]Event, error)
+}
+
+type KeepRefStore interface {
+	ListKeepRefs(ctx context.Context, workspaceID string, limit int) ([]KeepRef, error)
+}
+
+type Migrator interface {
+	MigrationStatus(ctx context.Context) ([]MigrationStatus, error)
+	MigrateUp(ctx context.Context, target int) ([]MigrationStatus, error)
+	MigrateDown(ctx context.Context, target int) ([]MigrationStatus, error)
+}
+
+type sqlStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// Open opens a SQLite database at path. Use OpenDSN to select a backend
+// from a connection string.
+func Open(path string) (Store, error) {
+	return OpenDSN(path)
+}
+
+// OpenDSN accepts a postgres:// or postgresql:// URL for Postgres, and a
+// sqlite:// URL or plain file path for SQLite. Pending migrations are applied.
+func OpenDSN(dsn string) (Store, error) {
+	store, err := openDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runMigrations(store.db, store.dialect); err != nil {
+		_ = store.db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// OpenWithoutMigrations is used by `jul-server migrate` to inspect or change
+// the schema version without first upgrading it to the latest.
+func OpenWithoutMigrations(dsn string) (Store, error) {
+	return openDSN(dsn)
+}
+
+func openDSN(dsn string) (*sqlStore, error) {
+	dsn = strings.TrimSpace(dsn)
+	if dsn == "" {
+		return nil, fmt.Errorf("database dsn required")
+	}
+	if isPostgresDSN(dsn) {
+		db, err := sql.Open("pgx", dsn)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Ping(); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("postgres connection failed: %w", err)
+		}
+		return &sqlStore{db: db, dialect: postgresDialect{}}, nil
+	}
+
+	path := strings.TrimPrefix(dsn, "sqlite://")
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return nil, err
 	}
@@ -34,19 +144,22 @@ func Open(path string) (*Store, error) {
 		return nil, err
 	}
 
-	if err := runMigrations(db); err != nil {
-		_ = db.Close()
-		return nil, err
-	}
+	return &sqlStore{db: db, dialect: sqliteDialect{}}, nil
+}
+
+func isPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
 
-	return &Store{db: db}, nil
+func (s *sqlStore) rebind(query string) string {
+	return s.dialect.Rebind(query)
 }
 
-func (s *Store) Close() error {
+func (s *sqlStore) Close() error {
 	return s.db.Close()
 }
 
-func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult, error) {
+func (s *sqlStore) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult, error) {
 	if payload.WorkspaceID == "" || payload.CommitSHA == "" {
 		return SyncResult{}, fmt.Errorf("workspace_id and commit_sha are required")
 	}
@@ -89,7 +202,7 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 		existingRevIndex int
 		commitExists     bool
 	)
-	if err := tx.QueryRowContext(ctx, `SELECT change_id, rev_index FROM revisions WHERE commit_sha = ?`, payload.CommitSHA).
+	if err := tx.QueryRowContext(ctx, s.rebind(`SELECT change_id, rev_index FROM revisions WHERE commit_sha = ?`), payload.CommitSHA).
 		Scan(&existingChangeID, &existingRevIndex); err == nil {
 		commitExists = true
 	} else if !errors.Is(err, sql.ErrNoRows) {
@@ -107,7 +220,7 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 	}
 
 	var changeExists bool
-	row := tx.QueryRowContext(ctx, `SELECT change_id FROM changes WHERE change_id = ?`, payload.ChangeID)
+	row := tx.QueryRowContext(ctx, s.rebind(`SELECT change_id FROM changes WHERE change_id = ?`), payload.ChangeID)
 	var existingID string
 	if err := row.Scan(&existingID); err == nil {
 		changeExists = true
@@ -116,8 +229,8 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 	}
 
 	if !changeExists {
-		_, err = tx.ExecContext(ctx, `INSERT INTO changes (change_id, title, author, status, created_at, latest_rev_index, latest_commit_sha)
-			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO changes (change_id, title, author, status, created_at, latest_rev_index, latest_commit_sha)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`),
 			payload.ChangeID, title, author, "draft", committedAt.Format(timeFormat), 0, "")
 		if err != nil {
 			return SyncResult{}, err
@@ -131,14 +244,14 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 	if commitExists {
 		revIndex = existingRevIndex
 	} else {
-		if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(rev_index), 0) FROM revisions WHERE change_id = ?`, payload.ChangeID).Scan(&revIndex); err != nil {
+		if err := tx.QueryRowContext(ctx, s.rebind(`SELECT COALESCE(MAX(rev_index), 0) FROM revisions WHERE change_id = ?`), payload.ChangeID).Scan(&revIndex); err != nil {
 			return SyncResult{}, err
 		}
 		revIndex++
 
-		_, err = tx.ExecContext(ctx, `INSERT INTO revisions (commit_sha, change_id, rev_index, author, message, created_at, repo)
-			VALUES (?, ?, ?, ?, ?, ?, ?)`,
-			payload.CommitSHA, payload.ChangeID, revIndex, author, commitMessage, committedAt.Format(timeFormat), payload.Repo)
+		_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO revisions (commit_sha, change_id, rev_index, author, message, created_at, repo, workspace_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+			payload.CommitSHA, payload.ChangeID, revIndex, author, commitMessage, committedAt.Format(timeFormat), payload.Repo, payload.WorkspaceID)
 		if err != nil {
 			return SyncResult{}, err
 		}
@@ -153,7 +266,7 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 		currentLatestRev int
 		currentLatestSHA string
 	)
-	if err := tx.QueryRowContext(ctx, `SELECT title, author, status, created_at, latest_rev_index, latest_commit_sha FROM changes WHERE change_id = ?`, payload.ChangeID).
+	if err := tx.QueryRowContext(ctx, s.rebind(`SELECT title, author, status, created_at, latest_rev_index, latest_commit_sha FROM changes WHERE change_id = ?`), payload.ChangeID).
 		Scan(&currentTitle, &currentAuthor, &currentStatus, &currentCreatedAt, &currentLatestRev, &currentLatestSHA); err != nil {
 		return SyncResult{}, err
 	}
@@ -164,7 +277,7 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 	}
 
 	if shouldUpdateLatest {
-		_, err = tx.ExecContext(ctx, `UPDATE changes SET title = ?, author = ?, latest_rev_index = ?, latest_commit_sha = ? WHERE change_id = ?`,
+		_, err = tx.ExecContext(ctx, s.rebind(`UPDATE changes SET title = ?, author = ?, latest_rev_index = ?, latest_commit_sha = ? WHERE change_id = ?`),
 			title, author, revIndex, payload.CommitSHA, payload.ChangeID)
 		if err != nil {
 			return SyncResult{}, err
@@ -177,22 +290,22 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 
 	var prevCommit string
 	var prevChange string
-	if err := tx.QueryRowContext(ctx, `SELECT last_commit_sha, last_change_id FROM workspaces WHERE workspace_id = ?`, payload.WorkspaceID).
+	if err := tx.QueryRowContext(ctx, s.rebind(`SELECT last_commit_sha, last_change_id FROM workspaces WHERE workspace_id = ?`), payload.WorkspaceID).
 		Scan(&prevCommit, &prevChange); err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return SyncResult{}, err
 	}
 
 	if prevCommit != "" && prevCommit != payload.CommitSHA {
 		if prevChange == "" {
-			if err := tx.QueryRowContext(ctx, `SELECT change_id FROM revisions WHERE commit_sha = ?`, prevCommit).Scan(&prevChange); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			if err := tx.QueryRowContext(ctx, s.rebind(`SELECT change_id FROM revisions WHERE commit_sha = ?`), prevCommit).Scan(&prevChange); err != nil && !errors.Is(err, sql.ErrNoRows) {
 				return SyncResult{}, err
 			}
 		}
 		if prevChange == "" {
 			prevChange = payload.ChangeID
 		}
-		_, err = tx.ExecContext(ctx, `INSERT INTO keep_refs (keep_id, workspace_id, commit_sha, change_id, created_at)
-			VALUES (?, ?, ?, ?, ?)`,
+		_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO keep_refs (keep_id, workspace_id, commit_sha, change_id, created_at)
+			VALUES (?, ?, ?, ?, ?)`),
 			ulid.Make().String(), payload.WorkspaceID, prevCommit, prevChange, time.Now().UTC().Format(timeFormat))
 		if err != nil {
 			return SyncResult{}, err
@@ -200,16 +313,21 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 	}
 
 	updatedAt := time.Now().UTC().Format(timeFormat)
-	_, err = tx.ExecContext(ctx, `INSERT INTO workspaces (workspace_id, user, name, repo, branch, last_commit_sha, last_change_id, updated_at)
+	if payload.Repo != "" {
+		if err := insertRepo(ctx, tx, s.dialect, Repo{Name: payload.Repo}, updatedAt); err != nil {
+			return SyncResult{}, err
+		}
+	}
+	_, err = tx.ExecContext(ctx, s.rebind(`INSERT INTO workspaces (workspace_id, "user", name, repo, branch, last_commit_sha, last_change_id, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(workspace_id) DO UPDATE SET
-		user = excluded.user,
+		"user" = excluded."user",
 		name = excluded.name,
 		repo = excluded.repo,
 		branch = excluded.branch,
 		last_commit_sha = excluded.last_commit_sha,
 		last_change_id = excluded.last_change_id,
-		updated_at = excluded.updated_at`,
+		updated_at = excluded.updated_at`),
 		payload.WorkspaceID, user, name, payload.Repo, payload.Branch, payload.CommitSHA, payload.ChangeID, updatedAt)
 	if err != nil {
 		return SyncResult{}, err
@@ -256,33 +374,55 @@ func (s *Store) RecordSync(ctx context.Context, payload SyncPayload) (SyncResult
 	return SyncResult{Workspace: workspace, Change: change, Revision: revision}, nil
 }
 
-func (s *Store) ListWorkspaces(ctx context.Context) ([]Workspace, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT workspace_id, user, name, repo, branch, last_commit_sha, last_change_id, updated_at FROM workspaces ORDER BY updated_at DESC`)
+func (s *sqlStore) ListWorkspaces(ctx context.Context, filters ListFilters) ([]Workspace, string, error) {
+	query := `SELECT workspace_id, "user", name, repo, branch, last_commit_sha, last_change_id, updated_at FROM workspaces WHERE 1=1`
+	args := []any{}
+	if filters.Repo != "" {
+		query += " AND repo = ?"
+		args = append(args, filters.Repo)
+	}
+	if filters.Workspace != "" {
+		query += " AND workspace_id = ?"
+		args = append(args, filters.Workspace)
+	}
+	query, args = appendTimeRange(query, args, "updated_at", filters.Since, filters.Until)
+	query, args, err := appendPage(query, args, "updated_at", "workspace_id", filters.Cursor, filters.Limit)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var out []Workspace
+	var keys []cursorKey
 	for rows.Next() {
 		var ws Workspace
 		var updatedAt string
 		if err := rows.Scan(&ws.WorkspaceID, &ws.User, &ws.Name, &ws.Repo, &ws.Branch, &ws.LastCommitSHA, &ws.LastChangeID, &updatedAt); err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		ws.UpdatedAt = parseTime(updatedAt)
 		out = append(out, ws)
+		keys = append(keys, cursorKey{at: updatedAt, id: ws.WorkspaceID})
 	}
-	return out, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	out, next := trimPage(out, keys, filters.Limit)
+	return out, next, nil
 }
 
-func (s *Store) DeleteWorkspace(ctx context.Context, id string) error {
+func (s *sqlStore) DeleteWorkspace(ctx context.Context, id string) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	res, err := tx.ExecContext(ctx, `DELETE FROM workspaces WHERE workspace_id = ?`, id)
+	res, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM workspaces WHERE workspace_id = ?`), id)
 	if err != nil {
 		_ = tx.Rollback()
 		return err
@@ -296,7 +436,7 @@ func (s *Store) DeleteWorkspace(ctx context.Context, id string) error {
 		_ = tx.Rollback()
 		return ErrNotFound
 	}
-	if _, err := tx.ExecContext(ctx, `DELETE FROM keep_refs WHERE workspace_id = ?`, id); err != nil {
+	if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM keep_refs WHERE workspace_id = ?`), id); err != nil {
 		_ = tx.Rollback()
 		return err
 	}
@@ -306,8 +446,8 @@ func (s *Store) DeleteWorkspace(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *Store) GetWorkspace(ctx context.Context, id string) (Workspace, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT workspace_id, user, name, repo, branch, last_commit_sha, last_change_id, updated_at FROM workspaces WHERE workspace_id = ?`, id)
+func (s *sqlStore) GetWorkspace(ctx context.Context, id string) (Workspace, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT workspace_id, "user", name, repo, branch, last_commit_sha, last_change_id, updated_at FROM workspaces WHERE workspace_id = ?`), id)
 	var ws Workspace
 	var updatedAt string
 	if err := row.Scan(&ws.WorkspaceID, &ws.User, &ws.Name, &ws.Repo, &ws.Branch, &ws.LastCommitSHA, &ws.LastChangeID, &updatedAt); err != nil {
@@ -320,31 +460,69 @@ func (s *Store) GetWorkspace(ctx context.Context, id string) (Workspace, error)
 	return ws, nil
 }
 
-func (s *Store) ListChanges(ctx context.Context) ([]Change, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT change_id, title, author, status, created_at, latest_rev_index, latest_commit_sha,
+func (s *sqlStore) ListChanges(ctx context.Context, filters ListFilters) ([]Change, string, error) {
+	query := `SELECT change_id, title, author, status, created_at, latest_rev_index, latest_commit_sha,
 		(SELECT COUNT(1) FROM revisions r WHERE r.change_id = changes.change_id) as revision_count
-		FROM changes ORDER BY created_at DESC`)
+		FROM changes WHERE 1=1`
+	args := []any{}
+	if filters.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filters.Status)
+	}
+	query, args = appendRevisionScope(query, args, "changes.change_id = r.change_id", filters)
+	query, args = appendTimeRange(query, args, "created_at", filters.Since, filters.Until)
+	query, args, err := appendPage(query, args, "created_at", "change_id", filters.Cursor, filters.Limit)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var out []Change
+	var keys []cursorKey
 	for rows.Next() {
 		var ch Change
 		var createdAt string
 		if err := rows.Scan(&ch.ChangeID, &ch.Title, &ch.Author, &ch.Status, &createdAt, &ch.LatestRevIndex, &ch.LatestCommitSHA, &ch.RevisionCount); err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		ch.CreatedAt = parseTime(createdAt)
 		ch.LatestRevision = Revision{RevIndex: ch.LatestRevIndex, CommitSHA: ch.LatestCommitSHA}
 		out = append(out, ch)
+		keys = append(keys, cursorKey{at: createdAt, id: ch.ChangeID})
 	}
-	return out, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	out, next := trimPage(out, keys, filters.Limit)
+	return out, next, nil
+}
+
+// appendRevisionScope limits rows to those with a revision in the requested
+// repo and/or workspace. join correlates the revisions alias r with the outer
+// table.
+func appendRevisionScope(query string, args []any, join string, filters ListFilters) (string, []any) {
+	if filters.Repo == "" && filters.Workspace == "" {
+		return query, args
+	}
+	query += " AND EXISTS (SELECT 1 FROM revisions r WHERE " + join
+	if filters.Repo != "" {
+		query += " AND r.repo = ?"
+		args = append(args, filters.Repo)
+	}
+	if filters.Workspace != "" {
+		query += " AND r.workspace_id = ?"
+		args = append(args, filters.Workspace)
+	}
+	return query + ")", args
 }
 
-func (s *Store) GetChange(ctx context.Context, changeID string) (Change, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT change_id, title, author, status, created_at, latest_rev_index, latest_commit_sha FROM changes WHERE change_id = ?`, changeID)
+func (s *sqlStore) GetChange(ctx context.Context, changeID string) (Change, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT change_id, title, author, status, created_at, latest_rev_index, latest_commit_sha FROM changes WHERE change_id = ?`), changeID)
 	var ch Change
 	var createdAt string
 	if err := row.Scan(&ch.ChangeID, &ch.Title, &ch.Author, &ch.Status, &createdAt, &ch.LatestRevIndex, &ch.LatestCommitSHA); err != nil {
@@ -358,8 +536,8 @@ func (s *Store) GetChange(ctx context.Context, changeID string) (Change, error)
 	return ch, nil
 }
 
-func (s *Store) ListRevisions(ctx context.Context, changeID string) ([]Revision, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT change_id, rev_index, commit_sha, author, message, created_at FROM revisions WHERE change_id = ? ORDER BY rev_index ASC`, changeID)
+func (s *sqlStore) ListRevisions(ctx context.Context, changeID string) ([]Revision, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT change_id, rev_index, commit_sha, author, message, created_at FROM revisions WHERE change_id = ? ORDER BY rev_index ASC`), changeID)
 	if err != nil {
 		return nil, err
 	}
@@ -378,8 +556,8 @@ func (s *Store) ListRevisions(ctx context.Context, changeID string) ([]Revision,
 	return out, rows.Err()
 }
 
-func (s *Store) GetRevisionByCommit(ctx context.Context, commitSHA string) (Revision, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT change_id, rev_index, commit_sha, author, message, created_at FROM revisions WHERE commit_sha = ?`, commitSHA)
+func (s *sqlStore) GetRevisionByCommit(ctx context.Context, commitSHA string) (Revision, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT change_id, rev_index, commit_sha, author, message, created_at FROM revisions WHERE commit_sha = ?`), commitSHA)
 	var rev Revision
 	var createdAt string
 	if err := row.Scan(&rev.ChangeID, &rev.RevIndex, &rev.CommitSHA, &rev.Author, &rev.Message, &createdAt); err != nil {
@@ -392,30 +570,36 @@ func (s *Store) GetRevisionByCommit(ctx context.Context, commitSHA string) (Revi
 	return rev, nil
 }
 
-func (s *Store) ListAttestations(ctx context.Context, commitSHA, changeID, status string) ([]Attestation, error) {
+func (s *sqlStore) ListAttestations(ctx context.Context, filters ListFilters) ([]Attestation, string, error) {
 	query := `SELECT attestation_id, commit_sha, change_id, type, status, compile_status, test_status, coverage_line_pct, coverage_branch_pct, started_at, finished_at, signals_json, created_at FROM attestations WHERE 1=1`
 	args := []any{}
-	if commitSHA != "" {
+	if filters.CommitSHA != "" {
 		query += " AND commit_sha = ?"
-		args = append(args, commitSHA)
+		args = append(args, filters.CommitSHA)
 	}
-	if changeID != "" {
+	if filters.ChangeID != "" {
 		query += " AND change_id = ?"
-		args = append(args, changeID)
+		args = append(args, filters.ChangeID)
 	}
-	if status != "" {
+	if filters.Status != "" {
 		query += " AND status = ?"
-		args = append(args, status)
+		args = append(args, filters.Status)
+	}
+	query, args = appendRevisionScope(query, args, "attestations.commit_sha = r.commit_sha", filters)
+	query, args = appendTimeRange(query, args, "created_at", filters.Since, filters.Until)
+	query, args, err := appendPage(query, args, "created_at", "attestation_id", filters.Cursor, filters.Limit)
+	if err != nil {
+		return nil, "", err
 	}
-	query += " ORDER BY created_at DESC"
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var out []Attestation
+	var keys []cursorKey
 	for rows.Next() {
 		var att Attestation
 		var startedAt, finishedAt, createdAt string
@@ -438,7 +622,7 @@ func (s *Store) ListAttestations(ctx context.Context, commitSHA, changeID, statu
 			&att.SignalsJSON,
 			&createdAt,
 		); err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		if compileStatus.Valid {
 			att.CompileStatus = compileStatus.String
@@ -460,11 +644,16 @@ func (s *Store) ListAttestations(ctx context.Context, commitSHA, changeID, statu
 		att.FinishedAt = parseTime(finishedAt)
 		att.CreatedAt = parseTime(createdAt)
 		out = append(out, att)
+		keys = append(keys, cursorKey{at: createdAt, id: att.AttestationID})
 	}
-	return out, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	out, next := trimPage(out, keys, filters.Limit)
+	return out, next, nil
 }
 
-func (s *Store) CreateAttestation(ctx context.Context, att Attestation) (Attestation, error) {
+func (s *sqlStore) CreateAttestation(ctx context.Context, att Attestation) (Attestation, error) {
 	if att.AttestationID == "" {
 		att.AttestationID = ulid.Make().String()
 	}
@@ -493,8 +682,8 @@ func (s *Store) CreateAttestation(ctx context.Context, att Attestation) (Attesta
 		coverageBranch = *att.CoverageBranchPct
 	}
 
-	_, err := s.db.ExecContext(ctx, `INSERT INTO attestations (attestation_id, commit_sha, change_id, type, status, compile_status, test_status, coverage_line_pct, coverage_branch_pct, started_at, finished_at, signals_json, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO attestations (attestation_id, commit_sha, change_id, type, status, compile_status, test_status, coverage_line_pct, coverage_branch_pct, started_at, finished_at, signals_json, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
 		att.AttestationID, att.CommitSHA, att.ChangeID, att.Type, att.Status,
 		att.CompileStatus, att.TestStatus, coverageLine, coverageBranch,
 		att.StartedAt.Format(timeFormat), att.FinishedAt.Format(timeFormat), att.SignalsJSON, att.CreatedAt.Format(timeFormat))
@@ -504,9 +693,9 @@ func (s *Store) CreateAttestation(ctx context.Context, att Attestation) (Attesta
 	return att, nil
 }
 
-func (s *Store) GetLatestAttestation(ctx context.Context, commitSHA string) (Attestation, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT attestation_id, commit_sha, change_id, type, status, compile_status, test_status, coverage_line_pct, coverage_branch_pct, started_at, finished_at, signals_json, created_at
-		FROM attestations WHERE commit_sha = ? ORDER BY created_at DESC LIMIT 1`, commitSHA)
+func (s *sqlStore) GetLatestAttestation(ctx context.Context, commitSHA string) (Attestation, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT attestation_id, commit_sha, change_id, type, status, compile_status, test_status, coverage_line_pct, coverage_branch_pct, started_at, finished_at, signals_json, created_at
+		FROM attestations WHERE commit_sha = ? ORDER BY created_at DESC LIMIT 1`), commitSHA)
 	var att Attestation
 	var startedAt, finishedAt, createdAt string
 	var compileStatus sql.NullString
@@ -555,14 +744,14 @@ func (s *Store) GetLatestAttestation(ctx context.Context, commitSHA string) (Att
 	return att, nil
 }
 
-func (s *Store) InsertEvent(ctx context.Context, evt Event) (Event, error) {
+func (s *sqlStore) InsertEvent(ctx context.Context, evt Event) (Event, error) {
 	if evt.EventID == "" {
 		evt.EventID = ulid.Make().String()
 	}
 	if evt.CreatedAt.IsZero() {
 		evt.CreatedAt = time.Now().UTC()
 	}
-	_, err := s.db.ExecContext(ctx, `INSERT INTO events (event_id, type, data_json, created_at) VALUES (?, ?, ?, ?)`,
+	_, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO events (event_id, type, data_json, created_at) VALUES (?, ?, ?, ?)`),
 		evt.EventID, evt.Type, evt.DataJSON, evt.CreatedAt.Format(timeFormat))
 	if err != nil {
 		return Event{}, err
@@ -570,14 +759,14 @@ func (s *Store) InsertEvent(ctx context.Context, evt Event) (Event, error) {
 	return evt, nil
 }
 
-func (s *Store) ListEventsSince(ctx context.Context, since time.Time, limit int) ([]Event, error) {
+func (s *sqlStore) ListEventsSince(ctx context.Context, since time.Time, limit int) ([]Event, error) {
 	query := `SELECT event_id, type, data_json, created_at FROM events WHERE created_at >= ? ORDER BY created_at ASC`
 	args := []any{since.Format(timeFormat)}
 	if limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, limit)
 	}
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -596,7 +785,7 @@ func (s *Store) ListEventsSince(ctx context.Context, since time.Time, limit int)
 	return out, rows.Err()
 }
 
-func (s *Store) ListKeepRefs(ctx context.Context, workspaceID string, limit int) ([]KeepRef, error) {
+func (s *sqlStore) ListKeepRefs(ctx context.Context, workspaceID string, limit int) ([]KeepRef, error) {
 	query := `SELECT keep_id, workspace_id, commit_sha, change_id, created_at FROM keep_refs WHERE workspace_id = ? ORDER BY created_at DESC`
 	args := []any{workspaceID}
 	if limit > 0 {
@@ -604,7 +793,7 @@ func (s *Store) ListKeepRefs(ctx context.Context, workspaceID string, limit int)
 		args = append(args, limit)
 	}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -623,7 +812,7 @@ func (s *Store) ListKeepRefs(ctx context.Context, workspaceID string, limit int)
 	return out, rows.Err()
 }
 
-func (s *Store) QueryCommits(ctx context.Context, filters QueryFilters) ([]QueryResult, error) {
+func (s *sqlStore) QueryCommits(ctx context.Context, filters QueryFilters) ([]QueryResult, string, error) {
 	query := `SELECT r.commit_sha, r.change_id, r.author, r.message, r.created_at,
 		COALESCE((SELECT status FROM attestations a WHERE a.commit_sha = r.commit_sha ORDER BY a.created_at DESC LIMIT 1), '') AS att_status,
 		COALESCE((SELECT test_status FROM attestations a WHERE a.commit_sha = r.commit_sha ORDER BY a.created_at DESC LIMIT 1),
@@ -665,30 +854,33 @@ func (s *Store) QueryCommits(ctx context.Context, filters QueryFilters) ([]Query
 		query += " AND (SELECT coverage_line_pct FROM attestations a WHERE a.commit_sha = r.commit_sha ORDER BY a.created_at DESC LIMIT 1) <= ?"
 		args = append(args, *filters.CoverageMax)
 	}
-	if filters.Since != nil {
-		query += " AND r.created_at >= ?"
-		args = append(args, filters.Since.Format(timeFormat))
+	if filters.Repo != "" {
+		query += " AND r.repo = ?"
+		args = append(args, filters.Repo)
 	}
-	if filters.Until != nil {
-		query += " AND r.created_at <= ?"
-		args = append(args, filters.Until.Format(timeFormat))
+	if filters.Workspace != "" {
+		query += " AND r.workspace_id = ?"
+		args = append(args, filters.Workspace)
 	}
+	query, args = appendTimeRange(query, args, "r.created_at", filters.Since, filters.Until)
 
-	query += " ORDER BY r.created_at DESC"
 	limit := filters.Limit
 	if limit <= 0 {
 		limit = 20
 	}
-	query += " LIMIT ?"
-	args = append(args, limit)
+	query, args, err := appendPage(query, args, "r.created_at", "r.commit_sha", filters.Cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var out []QueryResult
+	var keys []cursorKey
 	for rows.Next() {
 		var res QueryResult
 		var createdAt string
@@ -709,7 +901,7 @@ func (s *Store) QueryCommits(ctx context.Context, filters QueryFilters) ([]Query
 			&coverageLine,
 			&coverageBranch,
 		); err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		if attStatus.Valid {
 			res.AttestationStatus = attStatus.String
@@ -728,12 +920,17 @@ func (s *Store) QueryCommits(ctx context.Context, filters QueryFilters) ([]Query
 		}
 		res.CreatedAt = parseTime(createdAt)
 		out = append(out, res)
+		keys = append(keys, cursorKey{at: createdAt, id: res.CommitSHA})
 	}
-	return out, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	out, next := trimPage(out, keys, limit)
+	return out, next, nil
 }
 
-func (s *Store) FindRepoForCommit(ctx context.Context, commitSHA string) (string, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT repo FROM revisions WHERE commit_sha = ? AND repo != '' LIMIT 1`, commitSHA)
+func (s *sqlStore) FindRepoForCommit(ctx context.Context, commitSHA string) (string, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT repo FROM revisions WHERE commit_sha = ? AND repo != '' LIMIT 1`), commitSHA)
 	var repo string
 	if err := row.Scan(&repo); err == nil {
 		return repo, nil
@@ -741,7 +938,7 @@ func (s *Store) FindRepoForCommit(ctx context.Context, commitSHA string) (string
 		return "", err
 	}
 
-	row = s.db.QueryRowContext(ctx, `SELECT repo FROM workspaces WHERE last_commit_sha = ? ORDER BY updated_at DESC LIMIT 1`, commitSHA)
+	row = s.db.QueryRowContext(ctx, s.rebind(`SELECT repo FROM workspaces WHERE last_commit_sha = ? ORDER BY updated_at DESC LIMIT 1`), commitSHA)
 	if err := row.Scan(&repo); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return "", ErrNotFound
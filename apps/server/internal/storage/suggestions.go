@@ -10,7 +10,7 @@ import (
 	"github.com/oklog/ulid/v2"
 )
 
-func (s *Store) CreateSuggestion(ctx context.Context, sug Suggestion) (Suggestion, error) {
+func (s *sqlStore) CreateSuggestion(ctx context.Context, sug Suggestion) (Suggestion, error) {
 	if sug.ChangeID == "" || sug.BaseCommitSHA == "" || sug.SuggestedCommitSHA == "" {
 		return Suggestion{}, fmt.Errorf("change_id, base_commit_sha, and suggested_commit_sha are required")
 	}
@@ -42,30 +42,34 @@ func (s *Store) CreateSuggestion(ctx context.Context, sug Suggestion) (Suggestio
 		resolvedAt = sug.ResolvedAt.Format(timeFormat)
 	}
 
-	_, err := s.db.ExecContext(ctx, `INSERT INTO suggestions (suggestion_id, change_id, base_commit_sha, suggested_commit_sha, created_by, reason, description, confidence, status, diffstat_json, created_at, resolved_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		sug.SuggestionID, sug.ChangeID, sug.BaseCommitSHA, sug.SuggestedCommitSHA, sug.CreatedBy, sug.Reason, sug.Description, sug.Confidence, sug.Status, sug.DiffstatJSON, sug.CreatedAt.Format(timeFormat), resolvedAt)
+	_, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO suggestions (suggestion_id, change_id, base_commit_sha, suggested_commit_sha, created_by, reason, description, confidence, status, diffstat_json, created_at, resolved_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		sug.SuggestionID, sug.ChangeID, sug.BaseCommitSHA, sug.SuggestedCommitSHA, sug.CreatedBy, sug.Reason, sug.Description, sug.Confidence, sug.Status, sug.DiffstatJSON, sug.CreatedAt.UTC().Format(timeFormat), resolvedAt)
 	if err != nil {
 		return Suggestion{}, err
 	}
 	return sug, nil
 }
 
-func (s *Store) GetSuggestion(ctx context.Context, suggestionID string) (Suggestion, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT suggestion_id, change_id, base_commit_sha, suggested_commit_sha, created_by, reason, description, confidence, status, diffstat_json, created_at, resolved_at
-		FROM suggestions WHERE suggestion_id = ?`, suggestionID)
+func (s *sqlStore) GetSuggestion(ctx context.Context, suggestionID string) (Suggestion, error) {
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT suggestion_id, change_id, base_commit_sha, suggested_commit_sha, created_by, reason, description, confidence, status, diffstat_json, created_at, resolved_at
+		FROM suggestions WHERE suggestion_id = ?`), suggestionID)
 	return scanSuggestion(row)
 }
 
-func (s *Store) ListSuggestions(ctx context.Context, changeID, status string, limit int) ([]Suggestion, error) {
+func (s *sqlStore) ListSuggestions(ctx context.Context, filters ListFilters) ([]Suggestion, string, error) {
 	query := `SELECT suggestion_id, change_id, base_commit_sha, suggested_commit_sha, created_by, reason, description, confidence, status, diffstat_json, created_at, resolved_at
 		FROM suggestions WHERE 1=1`
 	args := []any{}
-	if changeID != "" {
+	if filters.ChangeID != "" {
 		query += " AND change_id = ?"
-		args = append(args, changeID)
+		args = append(args, filters.ChangeID)
 	}
-	statuses := expandSuggestionStatusFilter(status)
+	if filters.CommitSHA != "" {
+		query += " AND base_commit_sha = ?"
+		args = append(args, filters.CommitSHA)
+	}
+	statuses := expandSuggestionStatusFilter(filters.Status)
 	if len(statuses) > 0 {
 		if len(statuses) == 1 {
 			query += " AND status = ?"
@@ -75,36 +79,43 @@ func (s *Store) ListSuggestions(ctx context.Context, changeID, status string, li
 			args = append(args, statuses[0], statuses[1])
 		}
 	}
-	query += " ORDER BY created_at DESC"
-	if limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, limit)
+	query, args = appendRevisionScope(query, args, "suggestions.change_id = r.change_id", filters)
+	query, args = appendTimeRange(query, args, "created_at", filters.Since, filters.Until)
+	query, args, err := appendPage(query, args, "created_at", "suggestion_id", filters.Cursor, filters.Limit)
+	if err != nil {
+		return nil, "", err
 	}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
 
 	var out []Suggestion
+	var keys []cursorKey
 	for rows.Next() {
 		sug, err := scanSuggestion(rows)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		out = append(out, sug)
+		keys = append(keys, cursorKey{at: sug.CreatedAt.UTC().Format(timeFormat), id: sug.SuggestionID})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
 	}
-	return out, rows.Err()
+	out, next := trimPage(out, keys, filters.Limit)
+	return out, next, nil
 }
 
-func (s *Store) UpdateSuggestionStatus(ctx context.Context, suggestionID, status string, resolvedAt time.Time) (Suggestion, error) {
+func (s *sqlStore) UpdateSuggestionStatus(ctx context.Context, suggestionID, status string, resolvedAt time.Time) (Suggestion, error) {
 	status = normalizeSuggestionStatus(status)
 	var resolved any
 	if !resolvedAt.IsZero() {
 		resolved = resolvedAt.Format(timeFormat)
 	}
-	_, err := s.db.ExecContext(ctx, `UPDATE suggestions SET status = ?, resolved_at = ? WHERE suggestion_id = ?`, status, resolved, suggestionID)
+	_, err := s.db.ExecContext(ctx, s.rebind(`UPDATE suggestions SET status = ?, resolved_at = ? WHERE suggestion_id = ?`), status, resolved, suggestionID)
 	if err != nil {
 		return Suggestion{}, err
 	}
@@ -49,6 +49,7 @@ export const mapRepo = (input: unknown): Repo => {
   return {
     id: repo.id,
     name: repo.name,
+    owner: repo.owner || undefined,
     description: repo.description ?? undefined,
     visibility: repo.visibility,
     defaultBranch: repo.default_branch ?? repo.defaultBranch,
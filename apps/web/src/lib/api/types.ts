@@ -6,6 +6,7 @@
 export interface Repo {
   id: string;
   name: string;
+  owner?: string;
   description?: string;
   visibility: "public" | "private";
   defaultBranch: string;